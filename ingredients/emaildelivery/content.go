@@ -0,0 +1,82 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	defaultTemplates      *Templates
+	defaultTemplatesError error
+	defaultTemplatesOnce  sync.Once
+)
+
+// DefaultGetContent returns the content rendered by the recipe, or renders the default templates if
+// there is none. It is used by the SMTP and log services if no GetContent function is given to them.
+func DefaultGetContent(input EmailType) (EmailContent, error) {
+	defaultTemplatesOnce.Do(func() {
+		defaultTemplates, defaultTemplatesError = NewTemplates(nil)
+	})
+	if defaultTemplatesError != nil {
+		return EmailContent{}, defaultTemplatesError
+	}
+
+	if input.EmailVerification != nil {
+		if input.EmailVerification.Content != nil {
+			return *input.EmailVerification.Content, nil
+		}
+		return defaultTemplates.RenderEmailVerification(nil, TemplateData{
+			AppName: input.EmailVerification.AppName,
+			User:    input.EmailVerification.User,
+			Link:    input.EmailVerification.EmailVerifyLink,
+		})
+	}
+	if input.PasswordReset != nil {
+		if input.PasswordReset.Content != nil {
+			return *input.PasswordReset.Content, nil
+		}
+		return defaultTemplates.RenderPasswordReset(nil, TemplateData{
+			AppName: input.PasswordReset.AppName,
+			User:    input.PasswordReset.User,
+			Link:    input.PasswordReset.PasswordResetLink,
+		})
+	}
+	if input.PasswordlessLogin != nil {
+		if input.PasswordlessLogin.Content != nil {
+			return *input.PasswordlessLogin.Content, nil
+		}
+		return defaultTemplates.RenderPasswordlessLogin(nil, GetPasswordlessLoginTemplateData(*input.PasswordlessLogin))
+	}
+	return EmailContent{}, errors.New("should never come here: unknown email type")
+}
+
+// GetPasswordlessLoginTemplateData returns the data that the passwordless login templates are executed with
+func GetPasswordlessLoginTemplateData(input PasswordlessLoginType) TemplateData {
+	data := TemplateData{
+		AppName:             input.AppName,
+		User:                User{Email: input.Email},
+		CodeLifetimeMinutes: int(input.CodeLifetime / 60000),
+	}
+	if input.URLWithLinkCode != nil {
+		data.Link = *input.URLWithLinkCode
+	}
+	if input.UserInputCode != nil {
+		data.UserInputCode = *input.UserInputCode
+	}
+	return data
+}
@@ -0,0 +1,88 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+var defaultEmailVerificationTemplate = EmailTemplate{
+	Subject: "Verify your email for {{.AppName}}",
+	Text: `Hello,
+
+Please click on the link below to verify your email for {{.AppName}}:
+
+{{.Link}}
+
+If you did not sign up, you can ignore this email.
+`,
+	HTML: `<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #222222;">
+<p>Hello,</p>
+<p>Please click on the button below to verify your email for {{.AppName}}.</p>
+<p><a href="{{.Link}}" style="display: inline-block; padding: 10px 20px; background-color: #ff9933; color: #ffffff; text-decoration: none; border-radius: 6px;">Verify email</a></p>
+<p>Or copy and paste this link in your browser: {{.Link}}</p>
+<p>If you did not sign up, you can ignore this email.</p>
+</body>
+</html>
+`,
+}
+
+var defaultPasswordResetTemplate = EmailTemplate{
+	Subject: "Reset your password for {{.AppName}}",
+	Text: `Hello,
+
+Please click on the link below to reset your password for {{.AppName}}:
+
+{{.Link}}
+
+If you did not ask for a password reset, you can ignore this email.
+`,
+	HTML: `<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #222222;">
+<p>Hello,</p>
+<p>Please click on the button below to reset your password for {{.AppName}}.</p>
+<p><a href="{{.Link}}" style="display: inline-block; padding: 10px 20px; background-color: #ff9933; color: #ffffff; text-decoration: none; border-radius: 6px;">Reset password</a></p>
+<p>Or copy and paste this link in your browser: {{.Link}}</p>
+<p>If you did not ask for a password reset, you can ignore this email.</p>
+</body>
+</html>
+`,
+}
+
+var defaultPasswordlessLoginTemplate = EmailTemplate{
+	Subject: "Login to {{.AppName}}",
+	Text: `Hello,
+{{if .UserInputCode}}
+Enter this code to login to {{.AppName}}: {{.UserInputCode}}
+{{end}}{{if .Link}}
+{{if .UserInputCode}}Or click{{else}}Click{{end}} on the link below to login to {{.AppName}}:
+
+{{.Link}}
+{{end}}
+This {{if .UserInputCode}}code{{else}}link{{end}} expires in {{.CodeLifetimeMinutes}} minutes. If you did not try to login, you can ignore this email.
+`,
+	HTML: `<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #222222;">
+<p>Hello,</p>
+{{if .UserInputCode}}<p>Enter this code to login to {{.AppName}}:</p>
+<p style="font-size: 24px; font-weight: bold; letter-spacing: 4px;">{{.UserInputCode}}</p>
+{{end}}{{if .Link}}<p>{{if .UserInputCode}}Or click{{else}}Click{{end}} on the button below to login to {{.AppName}}.</p>
+<p><a href="{{.Link}}" style="display: inline-block; padding: 10px 20px; background-color: #ff9933; color: #ffffff; text-decoration: none; border-radius: 6px;">Login</a></p>
+{{end}}<p>This {{if .UserInputCode}}code{{else}}link{{end}} expires in {{.CodeLifetimeMinutes}} minutes. If you did not try to login, you can ignore this email.</p>
+</body>
+</html>
+`,
+}
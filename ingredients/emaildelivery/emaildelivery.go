@@ -0,0 +1,74 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import "context"
+
+// EmailDeliveryInterface is used by the recipes to send every email. Errors returned by SendEmail are
+// returned to the API that triggered the email.
+type EmailDeliveryInterface struct {
+	SendEmail func(ctx context.Context, input EmailType) error
+}
+
+// EmailType has exactly one of its fields set, depending on the email that needs to be sent
+type EmailType struct {
+	EmailVerification *EmailVerificationType
+	PasswordReset     *PasswordResetType
+	PasswordlessLogin *PasswordlessLoginType
+}
+
+// EmailVerificationType and PasswordResetType have the Content rendered from the recipe's templates.
+// Services that build their own content can ignore it.
+type EmailVerificationType struct {
+	User            User
+	AppName         string
+	EmailVerifyLink string
+	Content         *EmailContent
+}
+
+type PasswordResetType struct {
+	User              User
+	AppName           string
+	PasswordResetLink string
+	Content           *EmailContent
+}
+
+// PasswordlessLoginType has the UserInputCode, the URLWithLinkCode, or both, depending on the flow
+// type of the passwordless recipe
+type PasswordlessLoginType struct {
+	Email            string
+	AppName          string
+	UserInputCode    *string
+	URLWithLinkCode  *string
+	PreAuthSessionID string
+	// CodeLifetime is in milliseconds
+	CodeLifetime uint64
+	Content      *EmailContent
+}
+
+type User struct {
+	ID    string
+	Email string
+}
+
+// EmailContent has a Body, an HTMLBody, or both. IsHTML means that Body is HTML too.
+type EmailContent struct {
+	ToEmail  string
+	Subject  string
+	Body     string
+	HTMLBody string
+	IsHTML   bool
+}
@@ -0,0 +1,156 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSMTPServer struct {
+	listener net.Listener
+	auth     string
+	from     string
+	to       string
+	data     string
+	done     chan struct{}
+}
+
+// startFakeSMTPServer accepts a single connection, without STARTTLS, and records what it receives
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	go func() {
+		defer close(server.done)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch command {
+			case "EHLO":
+				text.PrintfLine("250-localhost")
+				text.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				server.auth = line
+				text.PrintfLine("235 Authentication successful")
+			case "MAIL":
+				server.from = line
+				text.PrintfLine("250 OK")
+			case "RCPT":
+				server.to = line
+				text.PrintfLine("250 OK")
+			case "DATA":
+				text.PrintfLine("354 Go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				server.data = string(data)
+				text.PrintfLine("250 OK")
+			case "QUIT":
+				text.PrintfLine("221 Bye")
+				return
+			default:
+				text.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return server
+}
+
+func TestSMTPServiceSendsEmail(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	defer server.listener.Close()
+
+	service := MakeSMTPService(SMTPServiceConfig{
+		Settings: SMTPSettings{
+			Host:     "127.0.0.1",
+			Port:     server.listener.Addr().(*net.TCPAddr).Port,
+			From:     SMTPFrom{Name: "Test App", Email: "no-reply@example.com"},
+			Password: "secret",
+		},
+	})
+	err := service.SendEmail(context.Background(), EmailType{
+		PasswordReset: &PasswordResetType{
+			User:              User{ID: "user1", Email: "user@example.com"},
+			AppName:           "Test App",
+			PasswordResetLink: "https://example.com/reset-password?token=abc",
+		},
+	})
+	assert.NoError(t, err)
+	<-server.done
+
+	credentials := base64.StdEncoding.EncodeToString([]byte("\x00no-reply@example.com\x00secret"))
+	assert.Equal(t, "AUTH PLAIN "+credentials, server.auth)
+	assert.Equal(t, "MAIL FROM:<no-reply@example.com>", server.from)
+	assert.Equal(t, "RCPT TO:<user@example.com>", server.to)
+	assert.Contains(t, server.data, "Subject: Reset your password for Test App\n")
+	assert.Contains(t, server.data, "https://example.com/reset-password?token=3Dabc")
+}
+
+func TestSMTPServiceReturnsConnectionErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	service := MakeSMTPService(SMTPServiceConfig{
+		Settings: SMTPSettings{Host: "127.0.0.1", Port: port},
+	})
+	err = service.SendEmail(context.Background(), EmailType{
+		EmailVerification: &EmailVerificationType{
+			User:            User{ID: "user1", Email: "user@example.com"},
+			EmailVerifyLink: "https://example.com/verify-email?token=abc",
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestLogServiceWritesEmail(t *testing.T) {
+	var output bytes.Buffer
+	service := MakeLogService(LogServiceConfig{Writer: &output})
+	err := service.SendEmail(context.Background(), EmailType{
+		EmailVerification: &EmailVerificationType{
+			User:            User{ID: "user1", Email: "user@example.com"},
+			AppName:         "Test App",
+			EmailVerifyLink: "https://example.com/verify-email?token=abc",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), "To: user@example.com\nSubject: Verify your email for Test App\n")
+	assert.Contains(t, output.String(), "https://example.com/verify-email?token=abc")
+}
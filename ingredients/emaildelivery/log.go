@@ -0,0 +1,60 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+type LogServiceConfig struct {
+	// Writer defaults to os.Stdout
+	Writer     io.Writer
+	GetContent func(input EmailType) (EmailContent, error)
+}
+
+// MakeLogService returns an EmailDeliveryInterface that writes each email to config.Writer instead of
+// sending it
+func MakeLogService(config LogServiceConfig) EmailDeliveryInterface {
+	writer := config.Writer
+	if writer == nil {
+		writer = os.Stdout
+	}
+	getContent := config.GetContent
+	if getContent == nil {
+		getContent = DefaultGetContent
+	}
+	var mutex sync.Mutex
+	return EmailDeliveryInterface{
+		SendEmail: func(ctx context.Context, input EmailType) error {
+			content, err := getContent(input)
+			if err != nil {
+				return err
+			}
+			body := content.Body
+			if body == "" {
+				body = content.HTMLBody
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			_, err = fmt.Fprintf(writer, "To: %s\nSubject: %s\n\n%s\n", content.ToEmail, content.Subject, body)
+			return err
+		},
+	}
+}
@@ -0,0 +1,219 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+type SMTPFrom struct {
+	Name  string
+	Email string
+}
+
+type SMTPSettings struct {
+	Host string
+	Port int
+	From SMTPFrom
+	// Username defaults to From.Email
+	Username *string
+	// Password is required for the server to be authenticated with (using PLAIN auth)
+	Password string
+	// Secure uses TLS from the start of the connection (usually on port 465). Otherwise, the
+	// connection is upgraded with STARTTLS if the server supports it.
+	Secure    bool
+	TLSConfig *tls.Config
+}
+
+type SMTPServiceConfig struct {
+	Settings   SMTPSettings
+	GetContent func(input EmailType) (EmailContent, error)
+}
+
+// MakeSMTPService returns an EmailDeliveryInterface that sends emails through an SMTP server
+func MakeSMTPService(config SMTPServiceConfig) EmailDeliveryInterface {
+	getContent := config.GetContent
+	if getContent == nil {
+		getContent = DefaultGetContent
+	}
+	return EmailDeliveryInterface{
+		SendEmail: func(ctx context.Context, input EmailType) error {
+			content, err := getContent(input)
+			if err != nil {
+				return err
+			}
+			return sendSMTPEmail(ctx, config.Settings, content)
+		},
+	}
+}
+
+func sendSMTPEmail(ctx context.Context, settings SMTPSettings, content EmailContent) error {
+	message, err := makeSMTPMessage(settings.From, content)
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(settings.Host, strconv.Itoa(settings.Port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var tlsConfig *tls.Config
+	if settings.TLSConfig != nil {
+		tlsConfig = settings.TLSConfig.Clone()
+	} else {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = settings.Host
+	}
+
+	if settings.Secure {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, settings.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !settings.Secure {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			err = client.StartTLS(tlsConfig)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if settings.Password != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("SMTP server does not support authentication")
+		}
+		username := settings.From.Email
+		if settings.Username != nil {
+			username = *settings.Username
+		}
+		err = client.Auth(smtp.PlainAuth("", username, settings.Password, settings.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(settings.From.Email)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(content.ToEmail)
+	if err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(message)
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func makeSMTPMessage(from SMTPFrom, content EmailContent) ([]byte, error) {
+	fromAddress := mail.Address{Name: from.Name, Address: from.Email}
+	toAddress := mail.Address{Address: content.ToEmail}
+
+	var message bytes.Buffer
+	message.WriteString("From: " + fromAddress.String() + "\r\n")
+	message.WriteString("To: " + toAddress.String() + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", content.Subject) + "\r\n")
+	message.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+
+	if content.Body == "" || content.HTMLBody == "" {
+		body, contentType := content.Body, "text/plain"
+		if content.Body == "" {
+			body, contentType = content.HTMLBody, "text/html"
+		} else if content.IsHTML {
+			contentType = "text/html"
+		}
+		message.WriteString("Content-Type: " + contentType + "; charset=UTF-8\r\n")
+		message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+		message.WriteString("\r\n")
+		err := writeQuotedPrintable(&message, body)
+		if err != nil {
+			return nil, err
+		}
+		return message.Bytes(), nil
+	}
+
+	// both a text and an HTML body: the client shows the last part it supports
+	parts := multipart.NewWriter(&message)
+	message.WriteString("Content-Type: multipart/alternative; boundary=" + parts.Boundary() + "\r\n")
+	message.WriteString("\r\n")
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{{"text/plain", content.Body}, {"text/html", content.HTMLBody}} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = writeQuotedPrintable(writer, part.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := parts.Close()
+	if err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+func writeQuotedPrintable(writer io.Writer, body string) error {
+	encoder := quotedprintable.NewWriter(writer)
+	_, err := encoder.Write([]byte(body))
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,93 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// MakeSuperTokensService returns the EmailDeliveryInterface used by the recipes if none is configured.
+// It sends emails using SuperTokens' email service (https://api.supertokens.io), and does nothing in
+// test mode.
+func MakeSuperTokensService() EmailDeliveryInterface {
+	return EmailDeliveryInterface{
+		SendEmail: func(ctx context.Context, input EmailType) error {
+			if supertokens.IsRunningInTestMode() {
+				// if running in test mode, we do not want to send this.
+				return nil
+			}
+			if input.EmailVerification != nil {
+				return postToSuperTokensService(ctx, "https://api.supertokens.io/0/st/auth/email/verify", map[string]string{
+					"email":          input.EmailVerification.User.Email,
+					"appName":        input.EmailVerification.AppName,
+					"emailVerifyURL": input.EmailVerification.EmailVerifyLink,
+				})
+			}
+			if input.PasswordReset != nil {
+				return postToSuperTokensService(ctx, "https://api.supertokens.io/0/st/auth/password/reset", map[string]string{
+					"email":            input.PasswordReset.User.Email,
+					"appName":          input.PasswordReset.AppName,
+					"passwordResetURL": input.PasswordReset.PasswordResetLink,
+				})
+			}
+			if input.PasswordlessLogin != nil {
+				data := map[string]interface{}{
+					"email":        input.PasswordlessLogin.Email,
+					"appName":      input.PasswordlessLogin.AppName,
+					"codeLifetime": input.PasswordlessLogin.CodeLifetime,
+				}
+				if input.PasswordlessLogin.UserInputCode != nil {
+					data["userInputCode"] = *input.PasswordlessLogin.UserInputCode
+				}
+				if input.PasswordlessLogin.URLWithLinkCode != nil {
+					data["urlWithLinkCode"] = *input.PasswordlessLogin.URLWithLinkCode
+				}
+				return postToSuperTokensService(ctx, "https://api.supertokens.io/0/st/auth/passwordless/login", data)
+			}
+			return errors.New("should never come here: unknown email type")
+		},
+	}
+}
+
+func postToSuperTokensService(ctx context.Context, url string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("api-version", "0")
+
+	resp, err := supertokens.GetHTTPClientWithContext(ctx).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return errors.New("SuperTokens email service responded with status code " + strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
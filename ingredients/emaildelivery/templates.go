@@ -0,0 +1,226 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"errors"
+	htmltemplate "html/template"
+	"net/http"
+	"strings"
+	texttemplate "text/template"
+)
+
+// DefaultLocale is the locale of the default templates, and the one used if no template matches the
+// locale of an email
+const DefaultLocale = "en"
+
+// EmailTemplate is the source of the templates of one email. Subject and Text are parsed with
+// text/template and HTML with html/template, and they are all executed with a TemplateData. At least
+// one of Text and HTML is required.
+type EmailTemplate struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type TemplateData struct {
+	AppName string
+	User    User
+	Link    string
+	// UserInputCode and CodeLifetimeMinutes are only set in passwordless login emails
+	UserInputCode       string
+	CodeLifetimeMinutes int
+}
+
+// TemplatesInput overrides the default email templates. Templates are keyed by locale (for example
+// "en" or "pt-BR"), and replace the default ones of the same email and locale.
+type TemplatesInput struct {
+	EmailVerification map[string]EmailTemplate
+	PasswordReset     map[string]EmailTemplate
+	PasswordlessLogin map[string]EmailTemplate
+	// GetLocales returns the preferred locales of the user the email is sent to, in order. It
+	// defaults to the locales of the request's Accept-Language header.
+	GetLocales func(req *http.Request) []string
+}
+
+// Templates are the parsed templates of a recipe's emails
+type Templates struct {
+	emailVerification map[string]parsedEmailTemplate
+	passwordReset     map[string]parsedEmailTemplate
+	passwordlessLogin map[string]parsedEmailTemplate
+	getLocales        func(req *http.Request) []string
+}
+
+type parsedEmailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// NewTemplates parses the default templates and the ones in input, which can be nil
+func NewTemplates(input *TemplatesInput) (*Templates, error) {
+	emailVerificationSources := map[string]EmailTemplate{DefaultLocale: defaultEmailVerificationTemplate}
+	passwordResetSources := map[string]EmailTemplate{DefaultLocale: defaultPasswordResetTemplate}
+	passwordlessLoginSources := map[string]EmailTemplate{DefaultLocale: defaultPasswordlessLoginTemplate}
+	templates := &Templates{
+		getLocales: getLocalesFromAcceptLanguage,
+	}
+	if input != nil {
+		for locale, source := range input.EmailVerification {
+			emailVerificationSources[locale] = source
+		}
+		for locale, source := range input.PasswordReset {
+			passwordResetSources[locale] = source
+		}
+		for locale, source := range input.PasswordlessLogin {
+			passwordlessLoginSources[locale] = source
+		}
+		if input.GetLocales != nil {
+			templates.getLocales = input.GetLocales
+		}
+	}
+
+	var err error
+	templates.emailVerification, err = parseEmailTemplates("emailVerification", emailVerificationSources)
+	if err != nil {
+		return nil, err
+	}
+	templates.passwordReset, err = parseEmailTemplates("passwordReset", passwordResetSources)
+	if err != nil {
+		return nil, err
+	}
+	templates.passwordlessLogin, err = parseEmailTemplates("passwordlessLogin", passwordlessLoginSources)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// RenderEmailVerification executes the email verification template of the locale that best matches
+// the request
+func (t *Templates) RenderEmailVerification(req *http.Request, data TemplateData) (EmailContent, error) {
+	return t.render(t.emailVerification, req, data)
+}
+
+// RenderPasswordReset executes the password reset template of the locale that best matches the
+// request
+func (t *Templates) RenderPasswordReset(req *http.Request, data TemplateData) (EmailContent, error) {
+	return t.render(t.passwordReset, req, data)
+}
+
+// RenderPasswordlessLogin executes the passwordless login template of the locale that best matches
+// the request
+func (t *Templates) RenderPasswordlessLogin(req *http.Request, data TemplateData) (EmailContent, error) {
+	return t.render(t.passwordlessLogin, req, data)
+}
+
+func (t *Templates) render(templates map[string]parsedEmailTemplate, req *http.Request, data TemplateData) (EmailContent, error) {
+	var locales []string
+	if req != nil {
+		locales = t.getLocales(req)
+	}
+	template := templates[findLocale(templates, locales)]
+
+	content := EmailContent{
+		ToEmail: data.User.Email,
+	}
+	var buffer bytes.Buffer
+	err := template.subject.Execute(&buffer, data)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	content.Subject = buffer.String()
+
+	if template.text != nil {
+		buffer.Reset()
+		err = template.text.Execute(&buffer, data)
+		if err != nil {
+			return EmailContent{}, err
+		}
+		content.Body = buffer.String()
+	}
+	if template.html != nil {
+		buffer.Reset()
+		err = template.html.Execute(&buffer, data)
+		if err != nil {
+			return EmailContent{}, err
+		}
+		content.HTMLBody = buffer.String()
+	}
+	return content, nil
+}
+
+func parseEmailTemplates(name string, sources map[string]EmailTemplate) (map[string]parsedEmailTemplate, error) {
+	result := map[string]parsedEmailTemplate{}
+	for locale, source := range sources {
+		if source.Subject == "" {
+			return nil, errors.New("the " + name + " email template for locale " + locale + " has no Subject")
+		}
+		if source.Text == "" && source.HTML == "" {
+			return nil, errors.New("the " + name + " email template for locale " + locale + " needs a Text or an HTML template")
+		}
+		templateName := name + "-" + locale
+		var (
+			parsed parsedEmailTemplate
+			err    error
+		)
+		parsed.subject, err = texttemplate.New(templateName + "-subject").Parse(source.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if source.Text != "" {
+			parsed.text, err = texttemplate.New(templateName + "-text").Parse(source.Text)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if source.HTML != "" {
+			parsed.html, err = htmltemplate.New(templateName + "-html").Parse(source.HTML)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result[locale] = parsed
+	}
+	return result, nil
+}
+
+// findLocale returns the first of locales that has a template, trying the language alone (for example
+// "pt" for "pt-BR") if the locale does not
+func findLocale(templates map[string]parsedEmailTemplate, locales []string) string {
+	for _, locale := range locales {
+		if _, ok := templates[locale]; ok {
+			return locale
+		}
+		language := strings.SplitN(locale, "-", 2)[0]
+		if _, ok := templates[language]; ok {
+			return language
+		}
+	}
+	return DefaultLocale
+}
+
+func getLocalesFromAcceptLanguage(req *http.Request) []string {
+	var locales []string
+	for _, value := range strings.Split(req.Header.Get("Accept-Language"), ",") {
+		locale := strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+		if locale != "" && locale != "*" {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
@@ -0,0 +1,84 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplatesUseLocaleOfRequest(t *testing.T) {
+	templates, err := NewTemplates(&TemplatesInput{
+		PasswordReset: map[string]EmailTemplate{
+			"de": {
+				Subject: "Passwort für {{.AppName}} zurücksetzen",
+				Text:    "Hallo {{.User.Email}}, {{.Link}}",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	data := TemplateData{
+		AppName: "Test App",
+		User:    User{ID: "user1", Email: "user@example.com"},
+		Link:    "https://example.com/reset-password?token=abc",
+	}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Accept-Language", "de-CH, de;q=0.9, en;q=0.8")
+	content, err := templates.RenderPasswordReset(req, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", content.ToEmail)
+	assert.Equal(t, "Passwort für Test App zurücksetzen", content.Subject)
+	assert.Equal(t, "Hallo user@example.com, https://example.com/reset-password?token=abc", content.Body)
+	assert.Equal(t, "", content.HTMLBody)
+
+	req.Header.Set("Accept-Language", "fr")
+	content, err = templates.RenderPasswordReset(req, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Reset your password for Test App", content.Subject)
+	assert.Contains(t, content.HTMLBody, `href="https://example.com/reset-password?token=abc"`)
+
+	content, err = templates.RenderEmailVerification(req, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Verify your email for Test App", content.Subject)
+}
+
+func TestTemplatesEscapeHTML(t *testing.T) {
+	templates, err := NewTemplates(nil)
+	assert.NoError(t, err)
+	content, err := templates.RenderEmailVerification(nil, TemplateData{
+		AppName: "<b>App</b>",
+		User:    User{ID: "user1", Email: "user@example.com"},
+		Link:    "javascript:alert(1)",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, content.HTMLBody, "&lt;b&gt;App&lt;/b&gt;")
+	assert.NotContains(t, content.HTMLBody, `href="javascript:`)
+}
+
+func TestInvalidTemplatesAreRejected(t *testing.T) {
+	_, err := NewTemplates(&TemplatesInput{
+		EmailVerification: map[string]EmailTemplate{"en": {Subject: "Verify"}},
+	})
+	assert.Error(t, err)
+
+	_, err = NewTemplates(&TemplatesInput{
+		EmailVerification: map[string]EmailTemplate{"en": {Subject: "Verify", HTML: "{{.Link"}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package usermigration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type ExportOptions struct {
+	Format Format
+	// IncludeRecipeIDs limits the export to the users of some recipes. Defaults to emailpassword and thirdparty.
+	IncludeRecipeIDs []string
+	// PageSize is the number of users that are fetched from the core at a time. Defaults to 100.
+	PageSize int
+}
+
+// coreUser is a user as returned by the core's /users API
+type coreUser struct {
+	ID         string `json:"id"`
+	Email      string `json:"email"`
+	TimeJoined uint64 `json:"timeJoined"`
+	ThirdParty *struct {
+		ID     string `json:"id"`
+		UserID string `json:"userId"`
+	} `json:"thirdParty"`
+}
+
+// ExportUsers writes all users to writer, oldest first, in a format that ImportUsers can read. The
+// users are written one page at a time, so the export does not have to fit in memory. It returns
+// the number of users written.
+func ExportUsers(writer io.Writer, options ExportOptions) (int, error) {
+	return ExportUsersWithContext(context.Background(), writer, options)
+}
+
+func ExportUsersWithContext(ctx context.Context, writer io.Writer, options ExportOptions) (int, error) {
+	usersWriter, err := newUserWriter(options.Format, writer)
+	if err != nil {
+		return 0, err
+	}
+	functions, err := getRecipeFunctions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	includeRecipeIDs := options.IncludeRecipeIDs
+	if len(includeRecipeIDs) == 0 {
+		includeRecipeIDs = []string{emailpassword.RECIPE_ID, thirdparty.RECIPE_ID}
+	}
+	for _, recipeID := range includeRecipeIDs {
+		if recipeID != emailpassword.RECIPE_ID && recipeID != thirdparty.RECIPE_ID {
+			return 0, errors.New("users of recipe " + recipeID + " can't be exported")
+		}
+	}
+	pageSize := options.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	count := 0
+	var paginationToken *string
+	for {
+		page, err := supertokens.GetUsersOldestFirstWithContext(ctx, paginationToken, &pageSize, &includeRecipeIDs)
+		if err != nil {
+			return count, err
+		}
+		for _, pageUser := range page.Users {
+			user, err := getExportedUser(ctx, functions, pageUser.RecipeId, pageUser.User)
+			if err != nil {
+				return count, err
+			}
+			err = usersWriter.write(user)
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+		err = usersWriter.flush()
+		if err != nil {
+			return count, err
+		}
+		if page.NextPaginationToken == nil {
+			return count, nil
+		}
+		paginationToken = page.NextPaginationToken
+	}
+}
+
+func getExportedUser(ctx context.Context, functions recipeFunctions, recipeID string, userMap map[string]interface{}) (User, error) {
+	userJSON, err := json.Marshal(userMap)
+	if err != nil {
+		return User{}, err
+	}
+	var fromCore coreUser
+	err = json.Unmarshal(userJSON, &fromCore)
+	if err != nil {
+		return User{}, err
+	}
+	user := User{
+		RecipeID:   recipeID,
+		ID:         fromCore.ID,
+		Email:      fromCore.Email,
+		TimeJoined: fromCore.TimeJoined,
+	}
+
+	switch recipeID {
+	case emailpassword.RECIPE_ID:
+		user.EmailVerified, err = functions.emailPassword.isEmailVerified(ctx, user.ID)
+	case thirdparty.RECIPE_ID:
+		if fromCore.ThirdParty == nil {
+			return User{}, errors.New("third party user " + user.ID + " has no third party info")
+		}
+		user.ThirdPartyID = fromCore.ThirdParty.ID
+		user.ThirdPartyUserID = fromCore.ThirdParty.UserID
+		user.EmailVerified, err = functions.thirdParty.isEmailVerified(ctx, user.ID)
+	default:
+		return User{}, errors.New("users of recipe " + recipeID + " can't be exported")
+	}
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,223 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package usermigration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// invalidRowError is returned by userReader.read for a row that could not be parsed. Reading can
+// continue with the next row.
+type invalidRowError struct {
+	msg string
+}
+
+func (e invalidRowError) Error() string {
+	return e.msg
+}
+
+type userReader interface {
+	// read returns io.EOF after the last user
+	read() (User, error)
+}
+
+type userWriter interface {
+	write(user User) error
+	flush() error
+}
+
+func newUserReader(format Format, reader io.Reader) (userReader, error) {
+	switch format {
+	case FormatJSONLines:
+		return &jsonLinesReader{reader: bufio.NewReader(reader)}, nil
+	case FormatCSV:
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		return &csvUserReader{reader: csvReader}, nil
+	}
+	return nil, errors.New("unsupported format: " + string(format))
+}
+
+func newUserWriter(format Format, writer io.Writer) (userWriter, error) {
+	switch format {
+	case FormatJSONLines:
+		bufferedWriter := bufio.NewWriter(writer)
+		return &jsonLinesWriter{writer: bufferedWriter, encoder: json.NewEncoder(bufferedWriter)}, nil
+	case FormatCSV:
+		csvWriter := csv.NewWriter(writer)
+		err := csvWriter.Write(csvColumns)
+		if err != nil {
+			return nil, err
+		}
+		return &csvUserWriter{writer: csvWriter}, nil
+	}
+	return nil, errors.New("unsupported format: " + string(format))
+}
+
+type jsonLinesReader struct {
+	reader *bufio.Reader
+}
+
+func (r *jsonLinesReader) read() (User, error) {
+	for {
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return User{}, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err == io.EOF {
+				return User{}, io.EOF
+			}
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		var user User
+		if decodeErr := decoder.Decode(&user); decodeErr != nil {
+			return User{}, invalidRowError{msg: "invalid JSON: " + decodeErr.Error()}
+		}
+		return user, nil
+	}
+}
+
+type csvUserReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func (r *csvUserReader) read() (User, error) {
+	if r.columns == nil {
+		header, err := r.reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return User{}, io.EOF
+			}
+			return User{}, errors.New("could not read the CSV header: " + err.Error())
+		}
+		columns := make([]string, len(header))
+		for i, column := range header {
+			columns[i] = strings.TrimSpace(column)
+			if !isCSVColumn(columns[i]) {
+				return User{}, errors.New("unknown CSV column: " + column)
+			}
+		}
+		r.columns = columns
+	}
+
+	record, err := r.reader.Read()
+	if err != nil {
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			return User{}, invalidRowError{msg: "invalid CSV: " + err.Error()}
+		}
+		return User{}, err
+	}
+	if len(record) != len(r.columns) {
+		return User{}, invalidRowError{msg: "expected " + strconv.Itoa(len(r.columns)) + " columns, got " + strconv.Itoa(len(record))}
+	}
+
+	var user User
+	for i, column := range r.columns {
+		value := record[i]
+		switch column {
+		case "recipeId":
+			user.RecipeID = value
+		case "id":
+			user.ID = value
+		case "email":
+			user.Email = value
+		case "emailVerified":
+			if value != "" {
+				user.EmailVerified, err = strconv.ParseBool(value)
+				if err != nil {
+					return User{}, invalidRowError{msg: "emailVerified must be true or false"}
+				}
+			}
+		case "passwordHash":
+			user.PasswordHash = value
+		case "thirdPartyId":
+			user.ThirdPartyID = value
+		case "thirdPartyUserId":
+			user.ThirdPartyUserID = value
+		case "timeJoined":
+			if value != "" {
+				user.TimeJoined, err = strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return User{}, invalidRowError{msg: "timeJoined must be a number"}
+				}
+			}
+		}
+	}
+	return user, nil
+}
+
+func isCSVColumn(name string) bool {
+	for _, column := range csvColumns {
+		if name == column {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonLinesWriter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (w *jsonLinesWriter) write(user User) error {
+	return w.encoder.Encode(user)
+}
+
+func (w *jsonLinesWriter) flush() error {
+	return w.writer.Flush()
+}
+
+type csvUserWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvUserWriter) write(user User) error {
+	timeJoined := ""
+	if user.TimeJoined != 0 {
+		timeJoined = strconv.FormatUint(user.TimeJoined, 10)
+	}
+	return w.writer.Write([]string{
+		user.RecipeID,
+		user.ID,
+		user.Email,
+		strconv.FormatBool(user.EmailVerified),
+		user.PasswordHash,
+		user.ThirdPartyID,
+		user.ThirdPartyUserID,
+		timeJoined,
+	})
+}
+
+func (w *csvUserWriter) flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
@@ -0,0 +1,264 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package usermigration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
+)
+
+type ImportOptions struct {
+	Format Format
+	// BatchSize is the number of rows that are read and imported together, after which OnBatch is
+	// called. Defaults to 100.
+	BatchSize int
+	// Concurrency is the number of users of a batch that are imported at the same time. Defaults to 4.
+	Concurrency int
+	// DryRun validates the rows, and checks that the users don't exist yet, without importing anything
+	DryRun bool
+	// OnBatch is called with the report so far after each batch, for example to log the progress
+	OnBatch func(report ImportReport)
+}
+
+type ImportReport struct {
+	// Total is the number of rows that were read
+	Total int `json:"total"`
+	// Imported is the number of users that were imported, or that would be imported in a dry run
+	Imported int        `json:"imported"`
+	Errors   []RowError `json:"errors"`
+}
+
+// RowError is why a row was not imported
+type RowError struct {
+	// Row is the position of the user in the file, starting at 1. The CSV header and empty lines
+	// are not counted.
+	Row   int    `json:"row"`
+	Email string `json:"email,omitempty"`
+	Error string `json:"error"`
+}
+
+type importRow struct {
+	row  int
+	user User
+}
+
+// ImportUsers creates the users read from reader. A row that can't be imported (because it is
+// invalid, or the user already exists) is added to the report's errors, and the import goes on
+// with the next row. The returned error is only set if the input could not be read.
+func ImportUsers(reader io.Reader, options ImportOptions) (ImportReport, error) {
+	return ImportUsersWithContext(context.Background(), reader, options)
+}
+
+func ImportUsersWithContext(ctx context.Context, reader io.Reader, options ImportOptions) (ImportReport, error) {
+	usersReader, err := newUserReader(options.Format, reader)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	functions, err := getRecipeFunctions(ctx)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	report := ImportReport{Errors: []RowError{}}
+	// rowsByKey finds users that are more than once in the input
+	rowsByKey := map[string]int{}
+	for done := false; !done; {
+		batch := []importRow{}
+		rowsInBatch := 0
+		for rowsInBatch < batchSize {
+			user, err := usersReader.read()
+			if err == io.EOF {
+				done = true
+				break
+			}
+			if err != nil {
+				if rowErr, ok := err.(invalidRowError); ok {
+					report.Total++
+					rowsInBatch++
+					report.Errors = append(report.Errors, RowError{Row: report.Total, Error: rowErr.msg})
+					continue
+				}
+				return report, err
+			}
+			report.Total++
+			rowsInBatch++
+
+			if msg := validateUser(user); msg != "" {
+				report.Errors = append(report.Errors, RowError{Row: report.Total, Email: user.Email, Error: msg})
+				continue
+			}
+			key := getUserKey(user)
+			if firstRow, ok := rowsByKey[key]; ok {
+				report.Errors = append(report.Errors, RowError{Row: report.Total, Email: user.Email, Error: "same user as row " + strconv.Itoa(firstRow)})
+				continue
+			}
+			rowsByKey[key] = report.Total
+			batch = append(batch, importRow{row: report.Total, user: user})
+		}
+		if rowsInBatch == 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		for i, importErr := range importBatch(ctx, functions, batch, concurrency, options.DryRun) {
+			if importErr != nil {
+				report.Errors = append(report.Errors, RowError{Row: batch[i].row, Email: batch[i].user.Email, Error: importErr.Error()})
+			} else {
+				report.Imported++
+			}
+		}
+		sort.SliceStable(report.Errors, func(i, j int) bool {
+			return report.Errors[i].Row < report.Errors[j].Row
+		})
+		if options.OnBatch != nil {
+			options.OnBatch(report)
+		}
+	}
+	return report, nil
+}
+
+func getUserKey(user User) string {
+	if user.RecipeID == thirdparty.RECIPE_ID {
+		return user.RecipeID + "\x00" + user.ThirdPartyID + "\x00" + user.ThirdPartyUserID
+	}
+	return user.RecipeID + "\x00" + user.Email
+}
+
+// importBatch imports the users of a batch, concurrency at a time, and returns the error of each
+func importBatch(ctx context.Context, functions recipeFunctions, batch []importRow, concurrency int, dryRun bool) []error {
+	errs := make([]error, len(batch))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range batch {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			errs[i] = importUser(ctx, functions, batch[i].user, dryRun)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func importUser(ctx context.Context, functions recipeFunctions, user User, dryRun bool) error {
+	if user.RecipeID == thirdparty.RECIPE_ID {
+		return importThirdPartyUser(ctx, functions.thirdParty, user, dryRun)
+	}
+	return importEmailPasswordUser(ctx, functions.emailPassword, user, dryRun)
+}
+
+func importEmailPasswordUser(ctx context.Context, functions emailPasswordFunctions, user User, dryRun bool) error {
+	if dryRun {
+		exists, err := functions.userExists(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("a user with this email already exists")
+		}
+		if user.PasswordHash != "" {
+			return functions.validatePasswordHash(ctx, user.PasswordHash)
+		}
+		return nil
+	}
+
+	var userID *string
+	if user.PasswordHash != "" {
+		var err error
+		userID, err = functions.importUserWithPasswordHash(ctx, user.Email, user.PasswordHash)
+		if err != nil {
+			return err
+		}
+	} else {
+		// the user has to reset their password to sign in
+		randomBytes := make([]byte, 32)
+		_, err := rand.Read(randomBytes)
+		if err != nil {
+			return err
+		}
+		userID, err = functions.signUp(ctx, user.Email, hex.EncodeToString(randomBytes))
+		if err != nil {
+			return err
+		}
+	}
+	if userID == nil {
+		return errors.New("a user with this email already exists")
+	}
+
+	if !user.EmailVerified {
+		return nil
+	}
+	return verifyEmail(ctx, *userID, functions.createEmailVerificationToken, functions.verifyEmailUsingToken)
+}
+
+func importThirdPartyUser(ctx context.Context, functions thirdPartyFunctions, user User, dryRun bool) error {
+	// SignInUp would update the email of an existing user, so they are looked for first
+	exists, err := functions.userExists(ctx, user.ThirdPartyID, user.ThirdPartyUserID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("a user with this third party ID already exists")
+	}
+	if dryRun {
+		return nil
+	}
+
+	userID, createdNewUser, err := functions.signInUp(ctx, user.ThirdPartyID, user.ThirdPartyUserID, user.Email, user.EmailVerified)
+	if err != nil {
+		return err
+	}
+	if !createdNewUser {
+		return errors.New("a user with this third party ID already exists")
+	}
+
+	if !user.EmailVerified {
+		return nil
+	}
+	return verifyEmail(ctx, userID, functions.createEmailVerificationToken, functions.verifyEmailUsingToken)
+}
+
+func verifyEmail(ctx context.Context, userID string, createToken func(ctx context.Context, userID string) (evmodels.CreateEmailVerificationTokenResponse, error), verifyToken func(ctx context.Context, token string) error) error {
+	response, err := createToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if response.EmailAlreadyVerifiedError != nil {
+		return nil
+	}
+	return verifyToken(ctx, response.OK.Token)
+}
@@ -0,0 +1,193 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package usermigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// recipeFunctions are the functions users are imported and exported with. They are those of
+// thirdpartyemailpassword if it is initialised, and those of emailpassword and thirdparty otherwise.
+type recipeFunctions struct {
+	emailPassword emailPasswordFunctions
+	thirdParty    thirdPartyFunctions
+}
+
+type emailPasswordFunctions struct {
+	userExists           func(ctx context.Context, email string) (bool, error)
+	validatePasswordHash func(ctx context.Context, passwordHash string) error
+	// importUserWithPasswordHash and signUp return the ID of the new user, or nil if the email exists
+	importUserWithPasswordHash   func(ctx context.Context, email string, passwordHash string) (*string, error)
+	signUp                       func(ctx context.Context, email string, password string) (*string, error)
+	createEmailVerificationToken func(ctx context.Context, userID string) (evmodels.CreateEmailVerificationTokenResponse, error)
+	verifyEmailUsingToken        func(ctx context.Context, token string) error
+	isEmailVerified              func(ctx context.Context, userID string) (bool, error)
+}
+
+type thirdPartyFunctions struct {
+	userExists                   func(ctx context.Context, thirdPartyID string, thirdPartyUserID string) (bool, error)
+	signInUp                     func(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email string, isVerified bool) (userID string, createdNewUser bool, err error)
+	createEmailVerificationToken func(ctx context.Context, userID string) (evmodels.CreateEmailVerificationTokenResponse, error)
+	verifyEmailUsingToken        func(ctx context.Context, token string) error
+	isEmailVerified              func(ctx context.Context, userID string) (bool, error)
+}
+
+func getRecipeFunctions(ctx context.Context) (recipeFunctions, error) {
+	instance, err := supertokens.GetInstanceOrThrowError(ctx)
+	if err != nil {
+		return recipeFunctions{}, err
+	}
+	if instance.GetRecipeInstance(thirdpartyemailpassword.RECIPE_ID) != nil {
+		return getThirdPartyEmailPasswordFunctions(), nil
+	}
+	return recipeFunctions{
+		emailPassword: getEmailPasswordFunctions(),
+		thirdParty:    getThirdPartyFunctions(),
+	}, nil
+}
+
+func getEmailPasswordFunctions() emailPasswordFunctions {
+	return emailPasswordFunctions{
+		userExists: func(ctx context.Context, email string) (bool, error) {
+			user, err := emailpassword.GetUserByEmailWithContext(ctx, email)
+			return user != nil, err
+		},
+		validatePasswordHash: emailpassword.ValidatePasswordHashWithContext,
+		importUserWithPasswordHash: func(ctx context.Context, email string, passwordHash string) (*string, error) {
+			response, err := emailpassword.ImportUserWithPasswordHashWithContext(ctx, email, passwordHash)
+			if err != nil || response.EmailAlreadyExistsError != nil {
+				return nil, err
+			}
+			return &response.OK.User.ID, nil
+		},
+		signUp: func(ctx context.Context, email string, password string) (*string, error) {
+			response, err := emailpassword.SignUpWithContext(ctx, email, password)
+			if err != nil || response.EmailAlreadyExistsError != nil {
+				return nil, err
+			}
+			return &response.OK.User.ID, nil
+		},
+		createEmailVerificationToken: emailpassword.CreateEmailVerificationTokenWithContext,
+		verifyEmailUsingToken: func(ctx context.Context, token string) error {
+			_, err := emailpassword.VerifyEmailUsingTokenWithContext(ctx, token)
+			return err
+		},
+		isEmailVerified: emailpassword.IsEmailVerifiedWithContext,
+	}
+}
+
+func getThirdPartyFunctions() thirdPartyFunctions {
+	return thirdPartyFunctions{
+		userExists: func(ctx context.Context, thirdPartyID string, thirdPartyUserID string) (bool, error) {
+			user, err := thirdparty.GetUserByThirdPartyInfoWithContext(ctx, thirdPartyID, thirdPartyUserID)
+			return user != nil, err
+		},
+		signInUp: func(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email string, isVerified bool) (string, bool, error) {
+			response, err := thirdparty.SignInUpWithContext(ctx, thirdPartyID, thirdPartyUserID, tpmodels.EmailStruct{
+				ID:         email,
+				IsVerified: isVerified,
+			})
+			if err != nil {
+				return "", false, err
+			}
+			if response.FieldError != nil {
+				return "", false, errors.New(response.FieldError.Error)
+			}
+			return response.OK.User.ID, response.OK.CreatedNewUser, nil
+		},
+		createEmailVerificationToken: thirdparty.CreateEmailVerificationTokenWithContext,
+		verifyEmailUsingToken: func(ctx context.Context, token string) error {
+			_, err := thirdparty.VerifyEmailUsingTokenWithContext(ctx, token)
+			return err
+		},
+		isEmailVerified: thirdparty.IsEmailVerifiedWithContext,
+	}
+}
+
+func getThirdPartyEmailPasswordFunctions() recipeFunctions {
+	createEmailVerificationToken := thirdpartyemailpassword.CreateEmailVerificationTokenWithContext
+	verifyEmailUsingToken := func(ctx context.Context, token string) error {
+		_, err := thirdpartyemailpassword.VerifyEmailUsingTokenWithContext(ctx, token)
+		return err
+	}
+	isEmailVerified := thirdpartyemailpassword.IsEmailVerifiedWithContext
+
+	return recipeFunctions{
+		emailPassword: emailPasswordFunctions{
+			userExists: func(ctx context.Context, email string) (bool, error) {
+				users, err := thirdpartyemailpassword.GetUsersByEmailWithContext(ctx, email)
+				if err != nil {
+					return false, err
+				}
+				for _, user := range users {
+					if user.ThirdParty == nil {
+						return true, nil
+					}
+				}
+				return false, nil
+			},
+			validatePasswordHash: thirdpartyemailpassword.ValidatePasswordHashWithContext,
+			importUserWithPasswordHash: func(ctx context.Context, email string, passwordHash string) (*string, error) {
+				response, err := thirdpartyemailpassword.ImportUserWithPasswordHashWithContext(ctx, email, passwordHash)
+				if err != nil || response.EmailAlreadyExistsError != nil {
+					return nil, err
+				}
+				return &response.OK.User.ID, nil
+			},
+			signUp: func(ctx context.Context, email string, password string) (*string, error) {
+				response, err := thirdpartyemailpassword.SignUpWithContext(ctx, email, password)
+				if err != nil || response.EmailAlreadyExistsError != nil {
+					return nil, err
+				}
+				return &response.OK.User.ID, nil
+			},
+			createEmailVerificationToken: createEmailVerificationToken,
+			verifyEmailUsingToken:        verifyEmailUsingToken,
+			isEmailVerified:              isEmailVerified,
+		},
+		thirdParty: thirdPartyFunctions{
+			userExists: func(ctx context.Context, thirdPartyID string, thirdPartyUserID string) (bool, error) {
+				user, err := thirdpartyemailpassword.GetUserByThirdPartyInfoWithContext(ctx, thirdPartyID, thirdPartyUserID, tpmodels.EmailStruct{})
+				return user != nil, err
+			},
+			signInUp: func(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email string, isVerified bool) (string, bool, error) {
+				response, err := thirdpartyemailpassword.SignInUpWithContext(ctx, thirdPartyID, thirdPartyUserID, tpepmodels.EmailStruct{
+					ID:         email,
+					IsVerified: isVerified,
+				})
+				if err != nil {
+					return "", false, err
+				}
+				if response.FieldError != nil {
+					return "", false, errors.New(response.FieldError.Error)
+				}
+				return response.OK.User.ID, response.OK.CreatedNewUser, nil
+			},
+			createEmailVerificationToken: createEmailVerificationToken,
+			verifyEmailUsingToken:        verifyEmailUsingToken,
+			isEmailVerified:              isEmailVerified,
+		},
+	}
+}
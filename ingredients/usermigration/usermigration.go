@@ -0,0 +1,85 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package usermigration imports users into SuperTokens from JSON Lines or CSV files, and exports them
+// in the same format. It works with the emailpassword and thirdparty recipes, or with thirdpartyemailpassword
+// if it is initialised.
+package usermigration
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
+)
+
+type Format string
+
+const (
+	// FormatJSONLines is one JSON object per line, with the json fields of User
+	FormatJSONLines Format = "jsonl"
+	// FormatCSV has a header row with the json field names of User, in any order
+	FormatCSV Format = "csv"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultConcurrency = 4
+	defaultPageSize    = 100
+)
+
+// User is one row of an import or export file
+type User struct {
+	// RecipeID is "emailpassword" or "thirdparty"
+	RecipeID string `json:"recipeId"`
+	// ID is set by ExportUsers, and ignored by ImportUsers since the core creates new user IDs
+	ID            string `json:"id,omitempty"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	// PasswordHash of an emailpassword user, in a format supported by emailpassword.ImportUserWithPasswordHash.
+	// Users without one are imported with a random password, and need to reset it. The core does not
+	// return password hashes, so ExportUsers leaves it empty.
+	PasswordHash     string `json:"passwordHash,omitempty"`
+	ThirdPartyID     string `json:"thirdPartyId,omitempty"`
+	ThirdPartyUserID string `json:"thirdPartyUserId,omitempty"`
+	// TimeJoined is set by ExportUsers, and ignored by ImportUsers
+	TimeJoined uint64 `json:"timeJoined,omitempty"`
+}
+
+// csvColumns are the columns written by ExportUsers
+var csvColumns = []string{"recipeId", "id", "email", "emailVerified", "passwordHash", "thirdPartyId", "thirdPartyUserId", "timeJoined"}
+
+func validateUser(user User) string {
+	switch user.RecipeID {
+	case emailpassword.RECIPE_ID:
+		if user.Email == "" {
+			return "email is required"
+		}
+		if user.ThirdPartyID != "" || user.ThirdPartyUserID != "" {
+			return "emailpassword users can't have a thirdPartyId or thirdPartyUserId"
+		}
+	case thirdparty.RECIPE_ID:
+		if user.ThirdPartyID == "" || user.ThirdPartyUserID == "" {
+			return "thirdPartyId and thirdPartyUserId are required"
+		}
+		if user.Email == "" {
+			return "email is required"
+		}
+		if user.PasswordHash != "" {
+			return "thirdparty users can't have a passwordHash"
+		}
+	default:
+		return "recipeId must be emailpassword or thirdparty"
+	}
+	return ""
+}
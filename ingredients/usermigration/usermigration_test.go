@@ -0,0 +1,177 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package usermigration
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestImportAndExportUsers(t *testing.T) {
+	store := emailpassword.MakeInMemoryLegacyPasswordHashStore()
+	_, instance, cleanup := coretest.NewInstance(t,
+		emailpassword.Init(&epmodels.TypeInput{
+			PasswordHashing: &epmodels.TypeInputPasswordHashing{
+				Store: &store,
+			},
+		}),
+		thirdparty.Init(&tpmodels.TypeInput{
+			SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
+				Providers: []tpmodels.TypeProvider{
+					thirdparty.Github(tpmodels.GithubConfig{ClientID: "client1", ClientSecret: "secret"}),
+				},
+			},
+		}),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("legacy123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	input := strings.Join([]string{
+		`{"recipeId":"emailpassword","email":"legacy@example.com","emailVerified":true,"passwordHash":"` + string(bcryptHash) + `"}`,
+		`{"recipeId":"emailpassword","email":"nohash@example.com"}`,
+		``,
+		`{"recipeId":"thirdparty","email":"github@example.com","emailVerified":true,"thirdPartyId":"github","thirdPartyUserId":"1234"}`,
+		`{"recipeId":"thirdparty","email":"github@example.com"`,
+		`{"recipeId":"emailpassword","email":"legacy@example.com"}`,
+		`{"recipeId":"passwordless","email":"other@example.com"}`,
+	}, "\n")
+
+	var batches []ImportReport
+	report, err := ImportUsersWithContext(ctx, strings.NewReader(input), ImportOptions{
+		Format:    FormatJSONLines,
+		BatchSize: 4,
+		DryRun:    true,
+		OnBatch: func(report ImportReport) {
+			batches = append(batches, report)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, 3, report.Imported)
+	assert.Equal(t, []int{4, 5, 6}, getErrorRows(report))
+	assert.Equal(t, 2, len(batches))
+	count, err := instance.GetUserCount(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), count)
+
+	report, err = ImportUsersWithContext(ctx, strings.NewReader(input), ImportOptions{Format: FormatJSONLines})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Imported)
+	assert.Equal(t, []int{4, 5, 6}, getErrorRows(report))
+
+	signInResponse, err := emailpassword.SignInWithContext(ctx, "legacy@example.com", "legacy123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+
+	var exported bytes.Buffer
+	exportedCount, err := ExportUsersWithContext(ctx, &exported, ExportOptions{Format: FormatCSV, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exportedCount)
+
+	usersReader, err := newUserReader(FormatCSV, &exported)
+	assert.NoError(t, err)
+	usersByEmail := map[string]User{}
+	for {
+		user, err := usersReader.read()
+		if err != nil {
+			break
+		}
+		usersByEmail[user.Email] = user
+	}
+	assert.True(t, usersByEmail["legacy@example.com"].EmailVerified)
+	assert.False(t, usersByEmail["nohash@example.com"].EmailVerified)
+	assert.Equal(t, thirdparty.RECIPE_ID, usersByEmail["github@example.com"].RecipeID)
+	assert.Equal(t, "1234", usersByEmail["github@example.com"].ThirdPartyUserID)
+	assert.True(t, usersByEmail["github@example.com"].EmailVerified)
+
+	// importing the export again fails for every user, since they all exist
+	var exportedAgain bytes.Buffer
+	_, err = ExportUsersWithContext(ctx, &exportedAgain, ExportOptions{Format: FormatCSV})
+	assert.NoError(t, err)
+	report, err = ImportUsersWithContext(ctx, &exportedAgain, ImportOptions{Format: FormatCSV, DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, []int{1, 2, 3}, getErrorRows(report))
+}
+
+func TestImportAndExportUsersWithThirdPartyEmailPassword(t *testing.T) {
+	store := emailpassword.MakeInMemoryLegacyPasswordHashStore()
+	_, instance, cleanup := coretest.NewInstance(t,
+		thirdpartyemailpassword.Init(&tpepmodels.TypeInput{
+			PasswordHashing: &epmodels.TypeInputPasswordHashing{
+				Store: &store,
+			},
+			Providers: []tpmodels.TypeProvider{
+				thirdparty.Github(tpmodels.GithubConfig{ClientID: "client1", ClientSecret: "secret"}),
+			},
+		}),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("legacy123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	input := strings.Join([]string{
+		`{"recipeId":"emailpassword","email":"legacy@example.com","emailVerified":true,"passwordHash":"` + string(bcryptHash) + `"}`,
+		`{"recipeId":"thirdparty","email":"github@example.com","emailVerified":true,"thirdPartyId":"github","thirdPartyUserId":"1234"}`,
+	}, "\n")
+
+	report, err := ImportUsersWithContext(ctx, strings.NewReader(input), ImportOptions{Format: FormatJSONLines})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Imported)
+	assert.Empty(t, report.Errors)
+
+	signInResponse, err := thirdpartyemailpassword.SignInWithContext(ctx, "legacy@example.com", "legacy123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+	isVerified, err := thirdpartyemailpassword.IsEmailVerifiedWithContext(ctx, signInResponse.OK.User.ID)
+	assert.NoError(t, err)
+	assert.True(t, isVerified)
+
+	var exported bytes.Buffer
+	exportedCount, err := ExportUsersWithContext(ctx, &exported, ExportOptions{Format: FormatJSONLines})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, exportedCount)
+
+	// importing the export again fails for every user, since they all exist
+	report, err = ImportUsersWithContext(ctx, &exported, ImportOptions{Format: FormatJSONLines, DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, []int{1, 2}, getErrorRows(report))
+}
+
+func getErrorRows(report ImportReport) []int {
+	rows := []int{}
+	for _, rowError := range report.Errors {
+		rows = append(rows, rowError.Row)
+	}
+	return rows
+}
@@ -0,0 +1,116 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	signInAttemptsAction        = "signin"
+	passwordResetAttemptsAction = "passwordreset"
+)
+
+// attemptKey is an email or IP whose attempts at an action are counted
+type attemptKey struct {
+	key               string
+	maxFailedAttempts int
+}
+
+func getAttemptKeys(config *epmodels.TypeNormalisedInputBruteForceProtection, action string, email string, req *http.Request) []attemptKey {
+	keys := []attemptKey{{
+		key:               action + ":email:" + strings.ToLower(email),
+		maxFailedAttempts: config.MaxFailedAttemptsPerEmail,
+	}}
+	if ip := config.GetIP(req); ip != "" {
+		keys = append(keys, attemptKey{
+			key:               action + ":ip:" + ip,
+			maxFailedAttempts: config.MaxFailedAttemptsPerIP,
+		})
+	}
+	return keys
+}
+
+// getLockout returns how long until none of the keys is locked out, or 0 if none is
+func getLockout(ctx context.Context, config *epmodels.TypeNormalisedInputBruteForceProtection, keys []attemptKey) (time.Duration, error) {
+	var retryAfter time.Duration
+	for _, key := range keys {
+		count, ttl, err := config.Store.Get(ctx, "lockedout:"+key.key)
+		if err != nil {
+			return 0, err
+		}
+		if count > 0 && ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+	return retryAfter, nil
+}
+
+// recordFailedAttempt locks the keys that have reached their maximum number of failed attempts out,
+// for twice as long as their last lockout
+func recordFailedAttempt(ctx context.Context, config *epmodels.TypeNormalisedInputBruteForceProtection, keys []attemptKey) error {
+	for _, key := range keys {
+		failures, err := config.Store.Increment(ctx, "failures:"+key.key, config.FailedAttemptsWindow)
+		if err != nil {
+			return err
+		}
+		if failures < key.maxFailedAttempts {
+			continue
+		}
+		lockouts, err := config.Store.Increment(ctx, "lockouts:"+key.key, config.LockoutMemory)
+		if err != nil {
+			return err
+		}
+		lockoutDuration := config.LockoutDuration
+		for i := 1; i < lockouts && lockoutDuration < config.MaxLockoutDuration; i++ {
+			lockoutDuration *= 2
+		}
+		if lockoutDuration > config.MaxLockoutDuration {
+			lockoutDuration = config.MaxLockoutDuration
+		}
+		_, err = config.Store.Increment(ctx, "lockedout:"+key.key, lockoutDuration)
+		if err != nil {
+			return err
+		}
+		err = config.Store.Delete(ctx, "failures:"+key.key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearFailedAttempts forgets the failed attempts of a key after a successful one. Lockouts are
+// still remembered.
+func clearFailedAttempts(ctx context.Context, config *epmodels.TypeNormalisedInputBruteForceProtection, key attemptKey) error {
+	return config.Store.Delete(ctx, "failures:"+key.key)
+}
+
+func sendTooManyAttemptsResponse(res http.ResponseWriter, retryAfter time.Duration) error {
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	res.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	return supertokens.Send200Response(res, map[string]interface{}{
+		"status":     "TOO_MANY_ATTEMPTS_ERROR",
+		"retryAfter": seconds,
+	})
+}
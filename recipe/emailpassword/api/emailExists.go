@@ -29,7 +29,7 @@ func EmailExists(apiImplementation epmodels.APIInterface, options epmodels.APIOp
 	if email == "" {
 		return supertokens.BadInputError{Msg: "Please provide the email as a GET param"}
 	}
-	result, err := apiImplementation.EmailExistsGET(email, options)
+	result, err := apiImplementation.EmailExistsGET(options.Req.Context(), email, options)
 	if err != nil {
 		return err
 	}
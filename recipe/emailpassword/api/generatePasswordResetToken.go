@@ -44,10 +44,13 @@ func GeneratePasswordResetToken(apiImplementation epmodels.APIInterface, options
 		return err
 	}
 
-	_, err = apiImplementation.GeneratePasswordResetTokenPOST(formFields, options)
+	result, err := apiImplementation.GeneratePasswordResetTokenPOST(options.Req.Context(), formFields, options)
 	if err != nil {
 		return err
 	}
+	if result.TooManyAttemptsError != nil {
+		return sendTooManyAttemptsResponse(options.Res, result.TooManyAttemptsError.RetryAfter)
+	}
 	return supertokens.Send200Response(options.Res, map[string]interface{}{
 		"status": "OK",
 	})
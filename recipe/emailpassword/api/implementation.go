@@ -16,14 +16,17 @@
 package api
 
 import (
+	"context"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
 )
 
 func MakeAPIImplementation() epmodels.APIInterface {
 	return epmodels.APIInterface{
-		EmailExistsGET: func(email string, options epmodels.APIOptions) (epmodels.EmailExistsGETResponse, error) {
-			user, err := options.RecipeImplementation.GetUserByEmail(email)
+		EmailExistsGET: func(ctx context.Context, email string, options epmodels.APIOptions) (epmodels.EmailExistsGETResponse, error) {
+			user, err := options.RecipeImplementation.GetUserByEmail(ctx, email)
 			if err != nil {
 				return epmodels.EmailExistsGETResponse{}, err
 			}
@@ -32,7 +35,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 			}, nil
 		},
 
-		GeneratePasswordResetTokenPOST: func(formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.GeneratePasswordResetTokenPOSTResponse, error) {
+		GeneratePasswordResetTokenPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.GeneratePasswordResetTokenPOSTResponse, error) {
 			var email string
 			for _, formField := range formFields {
 				if formField.ID == "email" {
@@ -40,7 +43,25 @@ func MakeAPIImplementation() epmodels.APIInterface {
 				}
 			}
 
-			user, err := options.RecipeImplementation.GetUserByEmail(email)
+			// every request counts as a failed attempt, so that a user can't be flooded with emails
+			if bruteForceProtection := options.Config.BruteForceProtection; bruteForceProtection != nil {
+				keys := getAttemptKeys(bruteForceProtection, passwordResetAttemptsAction, email, options.Req)
+				retryAfter, err := getLockout(ctx, bruteForceProtection, keys)
+				if err != nil {
+					return epmodels.GeneratePasswordResetTokenPOSTResponse{}, err
+				}
+				if retryAfter > 0 {
+					return epmodels.GeneratePasswordResetTokenPOSTResponse{
+						TooManyAttemptsError: &epmodels.TooManyAttemptsError{RetryAfter: retryAfter},
+					}, nil
+				}
+				err = recordFailedAttempt(ctx, bruteForceProtection, keys)
+				if err != nil {
+					return epmodels.GeneratePasswordResetTokenPOSTResponse{}, err
+				}
+			}
+
+			user, err := options.RecipeImplementation.GetUserByEmail(ctx, email)
 			if err != nil {
 				return epmodels.GeneratePasswordResetTokenPOSTResponse{}, err
 			}
@@ -51,7 +72,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 				}, nil
 			}
 
-			response, err := options.RecipeImplementation.CreateResetPasswordToken(user.ID)
+			response, err := options.RecipeImplementation.CreateResetPasswordToken(ctx, user.ID)
 			if err != nil {
 				return epmodels.GeneratePasswordResetTokenPOSTResponse{}, err
 			}
@@ -69,14 +90,36 @@ func MakeAPIImplementation() epmodels.APIInterface {
 
 			passwordResetLink = passwordResetLink + "?token=" + response.OK.Token + "&rid=" + options.RecipeID
 
-			options.Config.ResetPasswordUsingTokenFeature.CreateAndSendCustomEmail(*user, passwordResetLink)
+			emailUser := emaildelivery.User{
+				ID:    user.ID,
+				Email: user.Email,
+			}
+			content, err := options.Config.EmailTemplates.RenderPasswordReset(options.Req, emaildelivery.TemplateData{
+				AppName: options.AppInfo.AppName,
+				User:    emailUser,
+				Link:    passwordResetLink,
+			})
+			if err != nil {
+				return epmodels.GeneratePasswordResetTokenPOSTResponse{}, err
+			}
+			err = options.Config.EmailDelivery.SendEmail(ctx, emaildelivery.EmailType{
+				PasswordReset: &emaildelivery.PasswordResetType{
+					User:              emailUser,
+					AppName:           options.AppInfo.AppName,
+					PasswordResetLink: passwordResetLink,
+					Content:           &content,
+				},
+			})
+			if err != nil {
+				return epmodels.GeneratePasswordResetTokenPOSTResponse{}, err
+			}
 
 			return epmodels.GeneratePasswordResetTokenPOSTResponse{
 				OK: &struct{}{},
 			}, nil
 		},
 
-		PasswordResetPOST: func(formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions) (epmodels.ResetPasswordUsingTokenResponse, error) {
+		PasswordResetPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions) (epmodels.ResetPasswordUsingTokenResponse, error) {
 			var newPassword string
 			for _, formField := range formFields {
 				if formField.ID == "password" {
@@ -84,7 +127,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 				}
 			}
 
-			response, err := options.RecipeImplementation.ResetPasswordUsingToken(token, newPassword)
+			response, err := options.RecipeImplementation.ResetPasswordUsingToken(ctx, token, newPassword)
 			if err != nil {
 				return epmodels.ResetPasswordUsingTokenResponse{}, err
 			}
@@ -92,7 +135,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 			return response, nil
 		},
 
-		SignInPOST: func(formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignInResponse, error) {
+		SignInPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignInResponse, error) {
 			var email string
 			for _, formField := range formFields {
 				if formField.ID == "email" {
@@ -106,16 +149,48 @@ func MakeAPIImplementation() epmodels.APIInterface {
 				}
 			}
 
-			response, err := options.RecipeImplementation.SignIn(email, password)
+			bruteForceProtection := options.Config.BruteForceProtection
+			var attemptKeys []attemptKey
+			if bruteForceProtection != nil {
+				attemptKeys = getAttemptKeys(bruteForceProtection, signInAttemptsAction, email, options.Req)
+				retryAfter, err := getLockout(ctx, bruteForceProtection, attemptKeys)
+				if err != nil {
+					return epmodels.SignInResponse{}, err
+				}
+				if retryAfter > 0 {
+					return epmodels.SignInResponse{
+						TooManyAttemptsError: &epmodels.TooManyAttemptsError{RetryAfter: retryAfter},
+					}, nil
+				}
+			}
+
+			response, err := options.RecipeImplementation.SignIn(ctx, email, password)
 			if err != nil {
 				return epmodels.SignInResponse{}, err
 			}
 			if response.WrongCredentialsError != nil {
+				if bruteForceProtection != nil {
+					err = recordFailedAttempt(ctx, bruteForceProtection, attemptKeys)
+					if err != nil {
+						return epmodels.SignInResponse{}, err
+					}
+				}
 				return response, nil
 			}
+			if bruteForceProtection != nil {
+				// the failed attempts of the IP are kept, or signing in to one account would allow
+				// guessing the passwords of others
+				err = clearFailedAttempts(ctx, bruteForceProtection, attemptKeys[0])
+				if err != nil {
+					return epmodels.SignInResponse{}, err
+				}
+			}
 
-			user := response.OK.User
-			_, err = session.CreateNewSession(options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{})
+			sessionUserID := response.OK.User.ID
+			if response.OK.PrimaryUserID != "" {
+				sessionUserID = response.OK.PrimaryUserID
+			}
+			_, err = session.CreateNewSessionWithContext(ctx, options.Req, options.Res, sessionUserID, map[string]interface{}{}, map[string]interface{}{})
 			if err != nil {
 				return epmodels.SignInResponse{}, err
 			}
@@ -123,7 +198,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 			return response, nil
 		},
 
-		SignUpPOST: func(formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignUpResponse, error) {
+		SignUpPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignUpResponse, error) {
 			var email string
 			for _, formField := range formFields {
 				if formField.ID == "email" {
@@ -137,7 +212,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 				}
 			}
 
-			response, err := options.RecipeImplementation.SignUp(email, password)
+			response, err := options.RecipeImplementation.SignUp(ctx, email, password)
 			if err != nil {
 				return epmodels.SignUpResponse{}, err
 			}
@@ -145,9 +220,11 @@ func MakeAPIImplementation() epmodels.APIInterface {
 				return response, nil
 			}
 
-			user := response.OK.User
-
-			_, err = session.CreateNewSession(options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{})
+			sessionUserID := response.OK.User.ID
+			if response.OK.PrimaryUserID != "" {
+				sessionUserID = response.OK.PrimaryUserID
+			}
+			_, err = session.CreateNewSessionWithContext(ctx, options.Req, options.Res, sessionUserID, map[string]interface{}{}, map[string]interface{}{})
 			if err != nil {
 				return epmodels.SignUpResponse{}, err
 			}
@@ -44,6 +44,12 @@ func PasswordReset(apiImplementation epmodels.APIInterface, options epmodels.API
 	if err != nil {
 		return err
 	}
+	if options.Config.BreachedPasswordCheck != nil {
+		err = validatePasswordNotBreachedOrThrowError(options.Req.Context(), *options.Config.BreachedPasswordCheck, formFields)
+		if err != nil {
+			return err
+		}
+	}
 
 	token, ok := formFieldsRaw["token"]
 	if !ok {
@@ -53,7 +59,7 @@ func PasswordReset(apiImplementation epmodels.APIInterface, options epmodels.API
 		return supertokens.BadInputError{Msg: "The password reset token must be a string"}
 	}
 
-	result, err := apiImplementation.PasswordResetPOST(formFields, token.(string), options)
+	result, err := apiImplementation.PasswordResetPOST(options.Req.Context(), formFields, token.(string), options)
 	if err != nil {
 		return err
 	}
@@ -44,7 +44,7 @@ func SignInAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		return err
 	}
 
-	result, err := apiImplementation.SignInPOST(formFields, options)
+	result, err := apiImplementation.SignInPOST(options.Req.Context(), formFields, options)
 	if err != nil {
 		return err
 	}
@@ -52,6 +52,8 @@ func SignInAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "WRONG_CREDENTIALS_ERROR",
 		})
+	} else if result.TooManyAttemptsError != nil {
+		return sendTooManyAttemptsResponse(options.Res, result.TooManyAttemptsError.RetryAfter)
 	} else {
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "OK",
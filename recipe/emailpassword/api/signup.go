@@ -16,11 +16,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/breachedpasswords"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/passwordpolicy"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -44,8 +47,20 @@ func SignUpAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 	if err != nil {
 		return err
 	}
+	if options.Config.PasswordPolicy != nil {
+		err = validatePasswordPersonalInfoOrThrowError(*options.Config.PasswordPolicy, formFields)
+		if err != nil {
+			return err
+		}
+	}
+	if options.Config.BreachedPasswordCheck != nil {
+		err = validatePasswordNotBreachedOrThrowError(options.Req.Context(), *options.Config.BreachedPasswordCheck, formFields)
+		if err != nil {
+			return err
+		}
+	}
 
-	result, err := apiImplementation.SignUpPOST(formFields, options)
+	result, err := apiImplementation.SignUpPOST(options.Req.Context(), formFields, options)
 	if err != nil {
 		return err
 	}
@@ -64,3 +79,53 @@ func SignUpAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		}
 	}
 }
+
+// validatePasswordPersonalInfoOrThrowError checks that the password does not contain the email, or
+// the other sign up form fields that the password policy marks as personal info
+func validatePasswordPersonalInfoOrThrowError(policy epmodels.PasswordPolicy, formFields []epmodels.TypeFormField) error {
+	var password string
+	var personalInfo []string
+	for _, formField := range formFields {
+		if formField.ID == "password" {
+			password = formField.Value
+		} else if formField.ID == "email" {
+			personalInfo = append(personalInfo, formField.Value)
+		} else {
+			for _, ID := range policy.PersonalInfoFormFieldIDs {
+				if formField.ID == ID {
+					personalInfo = append(personalInfo, formField.Value)
+				}
+			}
+		}
+	}
+	if msg := passwordpolicy.ValidatePersonalInfo(policy, password, personalInfo); msg != nil {
+		return errors.FieldError{
+			Msg: "Error in input formFields",
+			Payload: []errors.ErrorPayload{{
+				ID:    "password",
+				Error: *msg,
+			}},
+		}
+	}
+	return nil
+}
+
+// validatePasswordNotBreachedOrThrowError checks the password against the breached password source.
+// It runs after the form fields are validated, since it may need a request to a remote source.
+func validatePasswordNotBreachedOrThrowError(ctx context.Context, config epmodels.TypeNormalisedInputBreachedPasswordCheck, formFields []epmodels.TypeFormField) error {
+	for _, formField := range formFields {
+		if formField.ID != "password" {
+			continue
+		}
+		if msg := breachedpasswords.Validate(ctx, config, formField.Value); msg != nil {
+			return errors.FieldError{
+				Msg: "Error in input formFields",
+				Payload: []errors.ErrorPayload{{
+					ID:    "password",
+					Error: *msg,
+				}},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
+
+type attemptCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MakeInMemoryAttemptCounterStore returns an AttemptCounterStore backed by a map, from which expired
+// counters are removed at most once a minute
+func MakeInMemoryAttemptCounterStore() epmodels.AttemptCounterStore {
+	var mutex sync.Mutex
+	counters := map[string]attemptCounter{}
+	lastCleanup := time.Now()
+	// getCounter must be called with the mutex locked
+	getCounter := func(key string) (attemptCounter, bool) {
+		now := time.Now()
+		if now.Sub(lastCleanup) > time.Minute {
+			for otherKey, counter := range counters {
+				if now.After(counter.expiresAt) {
+					delete(counters, otherKey)
+				}
+			}
+			lastCleanup = now
+		}
+		counter, ok := counters[key]
+		if !ok || now.After(counter.expiresAt) {
+			return attemptCounter{}, false
+		}
+		return counter, true
+	}
+	return epmodels.AttemptCounterStore{
+		Increment: func(ctx context.Context, key string, ttl time.Duration) (int, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			counter, ok := getCounter(key)
+			if !ok {
+				counter = attemptCounter{expiresAt: time.Now().Add(ttl)}
+			}
+			counter.count++
+			counters[key] = counter
+			return counter.count, nil
+		},
+		Get: func(ctx context.Context, key string) (int, time.Duration, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			counter, ok := getCounter(key)
+			if !ok {
+				return 0, 0, nil
+			}
+			return counter.count, time.Until(counter.expiresAt), nil
+		},
+		Delete: func(ctx context.Context, key string) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			delete(counters, key)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,96 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package breachedpasswords checks passwords against breach corpora, by their SHA-1 hash
+package breachedpasswords
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	// HashPrefixLength is the number of hex characters of the hash that is sent to a range API
+	HashPrefixLength = 5
+	hashLength       = 2 * sha1.Size
+
+	defaultTimeout = 5 * time.Second
+)
+
+// Normalise sets the defaults of the config, and checks that it has a source that can count up to
+// MinBreachCount
+func Normalise(config epmodels.TypeInputBreachedPasswordCheck) (epmodels.TypeNormalisedInputBreachedPasswordCheck, error) {
+	if config.Source.GetBreachCount == nil {
+		return epmodels.TypeNormalisedInputBreachedPasswordCheck{}, supertokens.BadInputError{Msg: "Source must be set in the BreachedPasswordCheck config"}
+	}
+	if config.Source.WithoutCounts && config.MinBreachCount > 1 {
+		return epmodels.TypeNormalisedInputBreachedPasswordCheck{}, supertokens.BadInputError{Msg: "MinBreachCount of the BreachedPasswordCheck config can't be more than 1 with a source that doesn't keep counts, like a bloom filter"}
+	}
+	normalised := epmodels.TypeNormalisedInputBreachedPasswordCheck{
+		Source:         config.Source,
+		MinBreachCount: 1,
+		Timeout:        defaultTimeout,
+		RejectOnError:  config.RejectOnError,
+	}
+	if config.MinBreachCount > 0 {
+		normalised.MinBreachCount = config.MinBreachCount
+	}
+	if config.Timeout > 0 {
+		normalised.Timeout = config.Timeout
+	}
+	return normalised, nil
+}
+
+// Validate returns an error message if the password was seen in breaches at least MinBreachCount times.
+// The check is cancelled with ctx, or after the Timeout of the config.
+func Validate(ctx context.Context, config epmodels.TypeNormalisedInputBreachedPasswordCheck, password string) *string {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+	prefix, suffix := HashPassword(password)
+	count, err := config.Source.GetBreachCount(ctx, prefix, suffix)
+	if err != nil {
+		if config.RejectOnError {
+			msg := "Password could not be checked against known data breaches. Please try again later"
+			return &msg
+		}
+		return nil
+	}
+	if count >= config.MinBreachCount {
+		msg := "Password has appeared in a data breach. Please choose a different password"
+		return &msg
+	}
+	return nil
+}
+
+// HashPassword returns the prefix and suffix of the uppercase hex SHA-1 hash of the password
+func HashPassword(password string) (string, string) {
+	hash := sha1.Sum([]byte(password))
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	return hexHash[:HashPrefixLength], hexHash[HashPrefixLength:]
+}
+
+func isHexHash(hash string) bool {
+	if len(hash) != hashLength {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
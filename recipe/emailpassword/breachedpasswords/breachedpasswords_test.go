@@ -0,0 +1,144 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package breachedpasswords
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
+
+func getTestHashes(count int) []string {
+	hashes := []string{}
+	for i := 0; i < count; i++ {
+		hash := sha1.Sum([]byte(fmt.Sprintf("breached-%d", i)))
+		hashes = append(hashes, strings.ToUpper(hex.EncodeToString(hash[:])))
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+func TestSortedSource(t *testing.T) {
+	var lines bytes.Buffer
+	for i, hash := range getTestHashes(2000) {
+		fmt.Fprintf(&lines, "%s:%d\r\n", hash, i+1)
+	}
+	source := makeSortedSource(bytes.NewReader(lines.Bytes()), int64(lines.Len()))
+
+	for i, hash := range getTestHashes(2000) {
+		count, err := source.GetBreachCount(context.Background(), hash[:HashPrefixLength], hash[HashPrefixLength:])
+		assert.NoError(t, err)
+		assert.Equal(t, i+1, count)
+	}
+	prefix, suffix := HashPassword("not-breached")
+	count, err := source.GetBreachCount(context.Background(), prefix, suffix)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestBloomFilterSource(t *testing.T) {
+	filter, err := NewBloomFilter(1000, 0.001)
+	assert.NoError(t, err)
+	var lines bytes.Buffer
+	for _, hash := range getTestHashes(1000) {
+		fmt.Fprintf(&lines, "%s:3\n", hash)
+	}
+	assert.NoError(t, filter.AddHashLines(&lines, 2))
+
+	var serialised bytes.Buffer
+	_, err = filter.WriteTo(&serialised)
+	assert.NoError(t, err)
+	filter, err = ReadBloomFilter(&serialised)
+	assert.NoError(t, err)
+	source := MakeBloomFilterSource(filter)
+
+	for _, hash := range getTestHashes(1000) {
+		count, err := source.GetBreachCount(context.Background(), hash[:HashPrefixLength], hash[HashPrefixLength:])
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	}
+	prefix, suffix := HashPassword("not-breached")
+	count, err := source.GetBreachCount(context.Background(), prefix, suffix)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// a bloom filter can't tell how often a password was breached
+	_, err = Normalise(epmodels.TypeInputBreachedPasswordCheck{Source: source, MinBreachCount: 2})
+	assert.Error(t, err)
+	_, err = Normalise(epmodels.TypeInputBreachedPasswordCheck{Source: source, MinBreachCount: 1})
+	assert.NoError(t, err)
+}
+
+func TestRangeAPISource(t *testing.T) {
+	prefix, suffix := HashPassword("password123")
+	var requestedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		fmt.Fprintf(w, "%s:251682\r\n%s:0\r\n", suffix, strings.Repeat("0", 35))
+	}))
+	defer server.Close()
+
+	config, err := Normalise(epmodels.TypeInputBreachedPasswordCheck{
+		Source: MakeRangeAPISource(MakeHIBPRangeAPI(HIBPRangeAPIConfig{URL: server.URL + "/range/"})),
+	})
+	assert.NoError(t, err)
+	msg := Validate(context.Background(), config, "password123")
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "Password has appeared in a data breach. Please choose a different password", *msg)
+	}
+	assert.Nil(t, Validate(context.Background(), config, "not-breached"))
+	assert.Equal(t, "/range/"+prefix, requestedPaths[0])
+
+	failingSource := epmodels.BreachedPasswordSource{
+		GetBreachCount: func(ctx context.Context, hashPrefix string, hashSuffix string) (int, error) {
+			return 0, errors.New("unavailable")
+		},
+	}
+	config, err = Normalise(epmodels.TypeInputBreachedPasswordCheck{Source: failingSource})
+	assert.NoError(t, err)
+	assert.Nil(t, Validate(context.Background(), config, "password123"))
+	config, err = Normalise(epmodels.TypeInputBreachedPasswordCheck{Source: failingSource, RejectOnError: true})
+	assert.NoError(t, err)
+	assert.NotNil(t, Validate(context.Background(), config, "password123"))
+
+	// the check is cancelled with the context of the request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelledSource := epmodels.BreachedPasswordSource{
+		GetBreachCount: func(ctx context.Context, hashPrefix string, hashSuffix string) (int, error) {
+			return 0, ctx.Err()
+		},
+	}
+	config, err = Normalise(epmodels.TypeInputBreachedPasswordCheck{Source: cancelledSource, RejectOnError: true})
+	assert.NoError(t, err)
+	assert.Nil(t, Validate(context.Background(), config, "password123"))
+	assert.NotNil(t, Validate(ctx, config, "password123"))
+
+	_, err = Normalise(epmodels.TypeInputBreachedPasswordCheck{})
+	assert.Error(t, err)
+}
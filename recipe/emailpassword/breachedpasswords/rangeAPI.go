@@ -0,0 +1,121 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package breachedpasswords
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const hibpRangeAPIURL = "https://api.pwnedpasswords.com/range/"
+
+// RangeAPI is a remote service that returns the hashes of breached passwords that start with a
+// prefix, so that neither the password nor its full hash leave the backend
+type RangeAPI struct {
+	// GetRange returns the suffixes (the 35 uppercase hex characters after the prefix) of the
+	// breached hashes that start with the prefix, with how many times each was seen
+	GetRange func(ctx context.Context, hashPrefix string) (map[string]int, error)
+}
+
+type HIBPRangeAPIConfig struct {
+	// URL defaults to the Pwned Passwords API of Have I Been Pwned. The prefix is appended to it.
+	URL string
+	// UserAgent is sent with each request, since the API rejects requests without one
+	UserAgent string
+}
+
+// MakeHIBPRangeAPI returns a RangeAPI for the Pwned Passwords API of Have I Been Pwned (or a
+// self-hosted mirror of it). Responses are padded with fake hashes, so their size does not reveal
+// the prefix either.
+func MakeHIBPRangeAPI(config HIBPRangeAPIConfig) RangeAPI {
+	url := config.URL
+	if url == "" {
+		url = hibpRangeAPIURL
+	}
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = "supertokens-golang"
+	}
+	return RangeAPI{
+		GetRange: func(ctx context.Context, hashPrefix string) (map[string]int, error) {
+			req, err := http.NewRequestWithContext(ctx, "GET", url+hashPrefix, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("User-Agent", userAgent)
+			req.Header.Set("Add-Padding", "true")
+			resp, err := supertokens.GetHTTPClientWithContext(ctx).Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("request to %s failed with status code %d", url+hashPrefix, resp.StatusCode)
+			}
+
+			suffixes := map[string]int{}
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				suffix, count, err := parseHashLine(scanner.Text())
+				if err != nil {
+					return nil, err
+				}
+				// padding entries have a count of 0
+				if count > 0 {
+					suffixes[suffix] = count
+				}
+			}
+			return suffixes, scanner.Err()
+		},
+	}
+}
+
+// MakeRangeAPISource returns a source that looks up the prefix of each hash in a range API, and
+// the suffix in the response
+func MakeRangeAPISource(api RangeAPI) epmodels.BreachedPasswordSource {
+	return epmodels.BreachedPasswordSource{
+		GetBreachCount: func(ctx context.Context, hashPrefix string, hashSuffix string) (int, error) {
+			suffixes, err := api.GetRange(ctx, hashPrefix)
+			if err != nil {
+				return 0, err
+			}
+			return suffixes[hashSuffix], nil
+		},
+	}
+}
+
+// parseHashLine parses a "HASH:COUNT" line, as served by range APIs and in the downloadable hash
+// lists. A line without a count is counted once.
+func parseHashLine(line string) (string, int, error) {
+	line = strings.TrimSpace(line)
+	index := strings.IndexByte(line, ':')
+	if index < 0 {
+		return strings.ToUpper(line), 1, nil
+	}
+	count, err := strconv.Atoi(line[index+1:])
+	if err != nil {
+		return "", 0, errors.New("invalid breached password hash line: " + line)
+	}
+	return strings.ToUpper(line[:index]), count, nil
+}
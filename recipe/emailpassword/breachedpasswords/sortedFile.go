@@ -0,0 +1,139 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package breachedpasswords
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
+
+const (
+	// maxLineLength is more than the length of a "HASH:COUNT" line
+	maxLineLength = 128
+	// linearScanSize is the size of the range of the file below which the binary search stops,
+	// and the lines are compared one by one
+	linearScanSize = 4096
+)
+
+// MakeSortedFileSource returns a source that binary searches a file of "HASH:COUNT" lines (or just
+// "HASH"), sorted by hash, like the "ordered by hash" SHA-1 list of Have I Been Pwned. The file is
+// read on each lookup and not loaded into memory, so it is meant for air-gapped deployments. It is
+// kept open for as long as the process runs.
+func MakeSortedFileSource(path string) (epmodels.BreachedPasswordSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return epmodels.BreachedPasswordSource{}, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return epmodels.BreachedPasswordSource{}, err
+	}
+	return makeSortedSource(file, info.Size()), nil
+}
+
+func makeSortedSource(reader io.ReaderAt, size int64) epmodels.BreachedPasswordSource {
+	return epmodels.BreachedPasswordSource{
+		GetBreachCount: func(ctx context.Context, hashPrefix string, hashSuffix string) (int, error) {
+			return searchSortedHashes(reader, size, hashPrefix+hashSuffix)
+		},
+	}
+}
+
+// searchSortedHashes returns the count of the hash in the sorted lines, or 0 if it is not in them
+func searchSortedHashes(reader io.ReaderAt, size int64, hash string) (int, error) {
+	// the line of the hash, if there is one, starts in [low, high], and low is always the start of a line
+	low, high := int64(0), size
+	for high-low > linearScanSize {
+		middle := low + (high-low)/2
+		lineStart, line, err := readLineAfter(reader, size, middle)
+		if err != nil {
+			return 0, err
+		}
+		if lineStart >= high {
+			high = middle
+			continue
+		}
+		lineHash, count, err := parseHashLine(line)
+		if err != nil {
+			return 0, err
+		}
+		switch strings.Compare(lineHash, hash) {
+		case 0:
+			return count, nil
+		case -1:
+			low = lineStart
+		default:
+			high = lineStart
+		}
+	}
+
+	chunk, err := readAt(reader, size, low, high-low+maxLineLength)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lineHash, count, err := parseHashLine(string(line))
+		if err != nil {
+			// the last line of the chunk may be cut off
+			return 0, nil
+		}
+		switch strings.Compare(lineHash, hash) {
+		case 0:
+			return count, nil
+		case 1:
+			return 0, nil
+		}
+	}
+	return 0, nil
+}
+
+// readLineAfter returns the first line that starts after the offset, and where it starts
+func readLineAfter(reader io.ReaderAt, size int64, offset int64) (int64, string, error) {
+	chunk, err := readAt(reader, size, offset, 2*maxLineLength)
+	if err != nil {
+		return 0, "", err
+	}
+	newline := bytes.IndexByte(chunk, '\n')
+	if newline < 0 {
+		return size, "", nil
+	}
+	line := chunk[newline+1:]
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	return offset + int64(newline) + 1, string(line), nil
+}
+
+func readAt(reader io.ReaderAt, size int64, offset int64, length int64) ([]byte, error) {
+	if offset+length > size {
+		length = size - offset
+	}
+	chunk := make([]byte, length)
+	_, err := reader.ReadAt(chunk, offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return chunk, nil
+}
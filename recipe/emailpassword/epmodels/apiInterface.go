@@ -16,9 +16,11 @@
 package epmodels
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 type APIOptions struct {
@@ -26,17 +28,18 @@ type APIOptions struct {
 	EmailVerificationRecipeImplementation evmodels.RecipeInterface
 	Config                                TypeNormalisedInput
 	RecipeID                              string
+	AppInfo                               supertokens.NormalisedAppinfo
 	Req                                   *http.Request
 	Res                                   http.ResponseWriter
 	OtherHandler                          http.HandlerFunc
 }
 
 type APIInterface struct {
-	EmailExistsGET                 func(email string, options APIOptions) (EmailExistsGETResponse, error)
-	GeneratePasswordResetTokenPOST func(formFields []TypeFormField, options APIOptions) (GeneratePasswordResetTokenPOSTResponse, error)
-	PasswordResetPOST              func(formFields []TypeFormField, token string, options APIOptions) (ResetPasswordUsingTokenResponse, error)
-	SignInPOST                     func(formFields []TypeFormField, options APIOptions) (SignInResponse, error)
-	SignUpPOST                     func(formFields []TypeFormField, options APIOptions) (SignUpResponse, error)
+	EmailExistsGET                 func(ctx context.Context, email string, options APIOptions) (EmailExistsGETResponse, error)
+	GeneratePasswordResetTokenPOST func(ctx context.Context, formFields []TypeFormField, options APIOptions) (GeneratePasswordResetTokenPOSTResponse, error)
+	PasswordResetPOST              func(ctx context.Context, formFields []TypeFormField, token string, options APIOptions) (ResetPasswordUsingTokenResponse, error)
+	SignInPOST                     func(ctx context.Context, formFields []TypeFormField, options APIOptions) (SignInResponse, error)
+	SignUpPOST                     func(ctx context.Context, formFields []TypeFormField, options APIOptions) (SignUpResponse, error)
 }
 
 type EmailExistsGETResponse struct {
@@ -44,5 +47,6 @@ type EmailExistsGETResponse struct {
 }
 
 type GeneratePasswordResetTokenPOSTResponse struct {
-	OK *struct{}
+	OK                   *struct{}
+	TooManyAttemptsError *TooManyAttemptsError
 }
@@ -15,13 +15,26 @@
 
 package epmodels
 
-import "github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+)
 
 type TypeNormalisedInput struct {
 	SignUpFeature                  TypeNormalisedInputSignUp
 	SignInFeature                  TypeNormalisedInputSignIn
 	ResetPasswordUsingTokenFeature TypeNormalisedInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       evmodels.TypeInput
+	EmailDelivery                  emaildelivery.EmailDeliveryInterface
+	EmailTemplates                 *emaildelivery.Templates
+	PasswordHashing                TypeNormalisedInputPasswordHashing
+	BruteForceProtection           *TypeNormalisedInputBruteForceProtection
+	PasswordPolicy                 *PasswordPolicy
+	BreachedPasswordCheck          *TypeNormalisedInputBreachedPasswordCheck
 	Override                       OverrideStruct
 }
 
@@ -67,11 +80,144 @@ type TypeInputResetPasswordUsingTokenFeature struct {
 
 type TypeNormalisedInputResetPasswordUsingTokenFeature struct {
 	GetResetPasswordURL            func(user User) (string, error)
-	CreateAndSendCustomEmail       func(user User, passwordResetURLWithToken string)
 	FormFieldsForGenerateTokenForm []NormalisedFormField
 	FormFieldsForPasswordResetForm []NormalisedFormField
 }
 
+type TypeInputPasswordHashing struct {
+	// Store keeps the password hashes of imported users until their first sign in. Users can only be
+	// imported with ImportUserWithPasswordHash if it is set.
+	Store *LegacyPasswordHashStore
+	// Verifiers are tried before the built-in bcrypt, argon2id, scrypt and PBKDF2 verifiers
+	Verifiers []PasswordHashVerifier
+}
+
+type TypeNormalisedInputPasswordHashing struct {
+	Store     *LegacyPasswordHashStore
+	Verifiers []PasswordHashVerifier
+}
+
+// LegacyPasswordHashStore keeps the password hash of each imported user that has not signed in yet
+type LegacyPasswordHashStore struct {
+	Save func(ctx context.Context, userID string, passwordHash string) error
+	// Get returns nil if there is no hash for the user
+	Get    func(ctx context.Context, userID string) (*string, error)
+	Remove func(ctx context.Context, userID string) error
+}
+
+// PasswordHashVerifier checks passwords against hashes of one format
+type PasswordHashVerifier struct {
+	// Supports returns true if the hash is in the format of this verifier
+	Supports func(passwordHash string) bool
+	// Validate is optional. It is called when a hash is imported, and returns an error if Verify
+	// would fail for it (for example because its cost parameters are too high).
+	Validate func(passwordHash string) error
+	Verify   func(password string, passwordHash string) (bool, error)
+}
+
+// PasswordPolicy replaces the default validation of passwords (8 to 100 characters, with a letter
+// and a number) on sign up, password reset and UpdateEmailOrPassword. A Validate function of the
+// password form field is applied after the policy.
+type PasswordPolicy struct {
+	// MinLength defaults to 8
+	MinLength int
+	// MaxLength defaults to 100
+	MaxLength        int
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireNumber    bool
+	RequireSymbol    bool
+	// DeniedPasswords are rejected (ignoring case), on top of a built-in list of common passwords
+	DeniedPasswords []string
+	// MinStrengthScore is the minimum score of a zxcvbn-style estimate of how hard the password is
+	// to guess, from 0 (too guessable) to 4 (very unguessable). 0 disables the check.
+	MinStrengthScore int
+	// DisallowPersonalInfo rejects passwords that contain the user's email (or the part before the @),
+	// or the value of one of the sign up form fields in PersonalInfoFormFieldIDs (like "name")
+	DisallowPersonalInfo     bool
+	PersonalInfoFormFieldIDs []string
+}
+
+// TypeInputBruteForceProtection limits the failed sign in attempts, and the password reset emails,
+// per email and per IP. An email or IP with too many of them is locked out, for twice as long each
+// time it happens again.
+type TypeInputBruteForceProtection struct {
+	// MaxFailedAttemptsPerEmail defaults to 5
+	MaxFailedAttemptsPerEmail int
+	// MaxFailedAttemptsPerIP defaults to 20
+	MaxFailedAttemptsPerIP int
+	// FailedAttemptsWindow is how long failed attempts are counted for. Defaults to 15 minutes.
+	FailedAttemptsWindow time.Duration
+	// LockoutDuration is the length of the first lockout. Defaults to 1 minute.
+	LockoutDuration time.Duration
+	// MaxLockoutDuration defaults to 1 hour
+	MaxLockoutDuration time.Duration
+	// LockoutMemory is how long lockouts are remembered for, to make the next one longer. Defaults to 24 hours.
+	LockoutMemory time.Duration
+	// GetIP defaults to the host of the request's RemoteAddr. Behind a proxy, it should read the
+	// header that the proxy sets (like X-Forwarded-For).
+	GetIP func(req *http.Request) string
+	// Store defaults to MakeInMemoryAttemptCounterStore()
+	Store *AttemptCounterStore
+}
+
+type TypeNormalisedInputBruteForceProtection struct {
+	MaxFailedAttemptsPerEmail int
+	MaxFailedAttemptsPerIP    int
+	FailedAttemptsWindow      time.Duration
+	LockoutDuration           time.Duration
+	MaxLockoutDuration        time.Duration
+	LockoutMemory             time.Duration
+	GetIP                     func(req *http.Request) string
+	Store                     AttemptCounterStore
+}
+
+// AttemptCounterStore keeps counters that expire. Its functions map to Redis' INCR (with PEXPIRE
+// when the counter is created), GET with PTTL, and DEL. The store must be shared by all backend
+// processes, or each of them allows the maximum number of attempts.
+type AttemptCounterStore struct {
+	// Increment adds one to the key's counter, creating it with the given time to live if it does
+	// not exist, and returns the new count
+	Increment func(ctx context.Context, key string, ttl time.Duration) (int, error)
+	// Get returns the key's count and remaining time to live, or 0 if it does not exist
+	Get    func(ctx context.Context, key string) (int, time.Duration, error)
+	Delete func(ctx context.Context, key string) error
+}
+
+// TypeInputBreachedPasswordCheck rejects passwords that appear in breach corpora on sign up and
+// password reset. It runs after the other password validation.
+type TypeInputBreachedPasswordCheck struct {
+	// Source is required. The breachedpasswords package has sources for a sorted hash file, a bloom
+	// filter and a remote range API (like the one of Have I Been Pwned).
+	Source BreachedPasswordSource
+	// MinBreachCount is how many times a password must have been seen in breaches to be rejected.
+	// Defaults to 1, and must be 1 if the source is WithoutCounts.
+	MinBreachCount int
+	// Timeout of a single check. Defaults to 5 seconds.
+	Timeout time.Duration
+	// RejectOnError rejects passwords if the source returns an error. By default they are accepted,
+	// so that sign up keeps working while a remote source is down.
+	RejectOnError bool
+}
+
+type TypeNormalisedInputBreachedPasswordCheck struct {
+	Source         BreachedPasswordSource
+	MinBreachCount int
+	Timeout        time.Duration
+	RejectOnError  bool
+}
+
+// BreachedPasswordSource looks up SHA-1 hashes of passwords. The hash is passed as its first 5 hex
+// characters and the other 35 (uppercase), so that a source backed by a remote range API only has
+// to send the prefix (k-anonymity).
+type BreachedPasswordSource struct {
+	// GetBreachCount returns how many times the password with the hash was seen in breaches, or 0
+	GetBreachCount func(ctx context.Context, hashPrefix string, hashSuffix string) (int, error)
+	// WithoutCounts is set by sources whose GetBreachCount returns 1 for every breached password,
+	// since they don't know how often it was seen. MinBreachCount can't be more than 1 with them.
+	WithoutCounts bool
+}
+
 type User struct {
 	ID         string `json:"id"`
 	Email      string `json:"email"`
@@ -82,6 +228,12 @@ type TypeInput struct {
 	SignUpFeature                  *TypeInputSignUp
 	ResetPasswordUsingTokenFeature *TypeInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       *TypeInputEmailVerificationFeature
+	EmailDelivery                  *emaildelivery.EmailDeliveryInterface
+	EmailTemplates                 *emaildelivery.TemplatesInput
+	PasswordHashing                *TypeInputPasswordHashing
+	BruteForceProtection           *TypeInputBruteForceProtection
+	PasswordPolicy                 *PasswordPolicy
+	BreachedPasswordCheck          *TypeInputBreachedPasswordCheck
 	Override                       *OverrideStruct
 }
 
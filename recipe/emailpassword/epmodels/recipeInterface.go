@@ -15,19 +15,30 @@
 
 package epmodels
 
+import (
+	"context"
+	"time"
+)
+
 type RecipeInterface struct {
-	SignUp                   func(email string, password string) (SignUpResponse, error)
-	SignIn                   func(email string, password string) (SignInResponse, error)
-	GetUserByID              func(userID string) (*User, error)
-	GetUserByEmail           func(email string) (*User, error)
-	CreateResetPasswordToken func(userID string) (CreateResetPasswordTokenResponse, error)
-	ResetPasswordUsingToken  func(token string, newPassword string) (ResetPasswordUsingTokenResponse, error)
-	UpdateEmailOrPassword    func(userId string, email *string, password *string) (UpdateEmailOrPasswordResponse, error)
+	SignUp                   func(ctx context.Context, email string, password string) (SignUpResponse, error)
+	SignIn                   func(ctx context.Context, email string, password string) (SignInResponse, error)
+	GetUserByID              func(ctx context.Context, userID string) (*User, error)
+	GetUserByEmail           func(ctx context.Context, email string) (*User, error)
+	CreateResetPasswordToken func(ctx context.Context, userID string) (CreateResetPasswordTokenResponse, error)
+	ResetPasswordUsingToken  func(ctx context.Context, token string, newPassword string) (ResetPasswordUsingTokenResponse, error)
+	UpdateEmailOrPassword    func(ctx context.Context, userId string, email *string, password *string) (UpdateEmailOrPasswordResponse, error)
+	// ImportUserWithPasswordHash creates a user that signs in with the password of a hash from another
+	// system. The hash is verified on the user's first sign in, after which the password is set in the core.
+	ImportUserWithPasswordHash func(ctx context.Context, email string, passwordHash string) (ImportUserWithPasswordHashResponse, error)
 }
 
 type SignUpResponse struct {
 	OK *struct {
 		User User
+		// PrimaryUserID is set if the user is linked to another user (with the account linking of
+		// thirdpartyemailpassword). Sessions are created for the primary user.
+		PrimaryUserID string
 	}
 	EmailAlreadyExistsError *struct{}
 }
@@ -35,8 +46,24 @@ type SignUpResponse struct {
 type SignInResponse struct {
 	OK *struct {
 		User User
+		// PrimaryUserID is set if the user is linked to another user (with the account linking of
+		// thirdpartyemailpassword). Sessions are created for the primary user.
+		PrimaryUserID string
 	}
 	WrongCredentialsError *struct{}
+	// TooManyAttemptsError is only returned by SignInPOST, if brute force protection is enabled
+	TooManyAttemptsError *TooManyAttemptsError
+}
+
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+type ImportUserWithPasswordHashResponse struct {
+	OK *struct {
+		User User
+	}
+	EmailAlreadyExistsError *struct{}
 }
 
 type CreateResetPasswordTokenResponse struct {
@@ -55,4 +82,8 @@ type UpdateEmailOrPasswordResponse struct {
 	OK                      *struct{}
 	UnknownUserIdError      *struct{}
 	EmailAlreadyExistsError *struct{}
+	// PasswordPolicyViolatedError is returned if a PasswordPolicy is configured, and the new password does not follow it
+	PasswordPolicyViolatedError *struct {
+		FailureReason string
+	}
 }
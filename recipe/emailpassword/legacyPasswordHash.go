@@ -0,0 +1,127 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// importUserWithPasswordHash signs the user up with a random password, which nobody knows, and keeps
+// the imported hash until the user's first sign in
+func importUserWithPasswordHash(ctx context.Context, querier supertokens.Querier, passwordHashing epmodels.TypeNormalisedInputPasswordHashing, email, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	err := ValidatePasswordHashForImport(passwordHashing, passwordHash)
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+
+	randomBytes := make([]byte, 32)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	response, err := signUp(ctx, querier, email, hex.EncodeToString(randomBytes))
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	if response.EmailAlreadyExistsError != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{
+			EmailAlreadyExistsError: &struct{}{},
+		}, nil
+	}
+
+	err = passwordHashing.Store.Save(ctx, response.OK.User.ID, passwordHash)
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	return epmodels.ImportUserWithPasswordHashResponse{
+		OK: &struct{ User epmodels.User }{User: response.OK.User},
+	}, nil
+}
+
+// ValidatePasswordHashForImport is ValidatePasswordHash with a normalised PasswordHashing config. It is
+// used by the recipes that include emailpassword.
+func ValidatePasswordHashForImport(passwordHashing epmodels.TypeNormalisedInputPasswordHashing, passwordHash string) error {
+	if passwordHashing.Store == nil {
+		return errors.New("a PasswordHashing.Store must be configured to import users with a password hash")
+	}
+	verifier := getPasswordHashVerifier(passwordHashing.Verifiers, passwordHash)
+	if verifier == nil {
+		return errors.New("the password hash is not in a supported format")
+	}
+	if verifier.Validate != nil {
+		return verifier.Validate(passwordHash)
+	}
+	return nil
+}
+
+// signInWithLegacyPasswordHash is used when the core rejects the password. If the user was imported
+// and the password matches the imported hash, the password is set in the core and the hash is removed.
+func signInWithLegacyPasswordHash(ctx context.Context, querier supertokens.Querier, passwordHashing epmodels.TypeNormalisedInputPasswordHashing, email, password string) (epmodels.SignInResponse, error) {
+	wrongCredentials := epmodels.SignInResponse{
+		WrongCredentialsError: &struct{}{},
+	}
+	user, err := getUser(ctx, querier, map[string]string{
+		"email": email,
+	})
+	if err != nil {
+		return epmodels.SignInResponse{}, err
+	}
+	if user == nil {
+		return wrongCredentials, nil
+	}
+	passwordHash, err := passwordHashing.Store.Get(ctx, user.ID)
+	if err != nil {
+		return epmodels.SignInResponse{}, err
+	}
+	if passwordHash == nil {
+		return wrongCredentials, nil
+	}
+	verifier := getPasswordHashVerifier(passwordHashing.Verifiers, *passwordHash)
+	if verifier == nil {
+		return epmodels.SignInResponse{}, errors.New("the imported password hash of user " + user.ID + " is not in a supported format")
+	}
+	matches, err := verifier.Verify(password, *passwordHash)
+	if err != nil {
+		return epmodels.SignInResponse{}, err
+	}
+	if !matches {
+		return wrongCredentials, nil
+	}
+
+	response, err := updateEmailOrPassword(ctx, querier, user.ID, nil, &password)
+	if err != nil {
+		return epmodels.SignInResponse{}, err
+	}
+	if response.OK == nil {
+		return epmodels.SignInResponse{}, errors.New("could not set the password of imported user " + user.ID)
+	}
+	err = passwordHashing.Store.Remove(ctx, user.ID)
+	if err != nil {
+		return epmodels.SignInResponse{}, err
+	}
+	return epmodels.SignInResponse{
+		OK: &struct {
+			User          epmodels.User
+			PrimaryUserID string
+		}{User: *user},
+	}, nil
+}
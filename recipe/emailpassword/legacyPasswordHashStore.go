@@ -0,0 +1,53 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"context"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
+
+// MakeInMemoryLegacyPasswordHashStore returns a LegacyPasswordHashStore backed by a map from user ID to
+// hash
+func MakeInMemoryLegacyPasswordHashStore() epmodels.LegacyPasswordHashStore {
+	var mutex sync.Mutex
+	hashesByUser := map[string]string{}
+	return epmodels.LegacyPasswordHashStore{
+		Save: func(ctx context.Context, userID string, passwordHash string) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			hashesByUser[userID] = passwordHash
+			return nil
+		},
+		Get: func(ctx context.Context, userID string) (*string, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			passwordHash, ok := hashesByUser[userID]
+			if !ok {
+				return nil, nil
+			}
+			return &passwordHash, nil
+		},
+		Remove: func(ctx context.Context, userID string) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			delete(hashesByUser, userID)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,189 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+func TestBuiltInPasswordHashVerifiers(t *testing.T) {
+	password := "legacy password"
+	salt := []byte("0123456789abcdef")
+	encode := base64.RawStdEncoding.EncodeToString
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+	scryptHash, err := scrypt.Key([]byte(password), salt, 1<<10, 8, 1, 32)
+	assert.NoError(t, err)
+	passlibSalt := []byte{0xfb, 0xef, 0xbe}
+	passlibEncode := func(value []byte) string {
+		return strings.ReplaceAll(encode(value), "+", ".")
+	}
+	passlibHash := pbkdf2.Key([]byte(password), passlibSalt, 1000, 32, sha256.New)
+
+	hashes := []string{
+		string(bcryptHash),
+		"$argon2id$v=19$m=1024,t=2,p=1$" + encode(salt) + "$" + encode(argon2.IDKey([]byte(password), salt, 2, 1024, 1, 32)),
+		"$scrypt$ln=10,r=8,p=1$" + encode(salt) + "$" + encode(scryptHash),
+		"$pbkdf2-sha256$i=1000$" + encode(salt) + "$" + encode(pbkdf2.Key([]byte(password), salt, 1000, 32, sha256.New)),
+		// passlib's format, with "." instead of "+" and no "i="
+		"$pbkdf2-sha256$1000$" + passlibEncode(passlibSalt) + "$" + passlibEncode(passlibHash),
+	}
+	verifiers := getBuiltInPasswordHashVerifiers()
+	for _, hash := range hashes {
+		verifier := getPasswordHashVerifier(verifiers, hash)
+		if !assert.NotNil(t, verifier, hash) {
+			continue
+		}
+		matches, err := verifier.Verify(password, hash)
+		assert.NoError(t, err)
+		assert.True(t, matches, hash)
+		matches, err = verifier.Verify("wrong password", hash)
+		assert.NoError(t, err)
+		assert.False(t, matches, hash)
+	}
+
+	assert.Nil(t, getPasswordHashVerifier(verifiers, "$md5$salt$hash"))
+	assert.Nil(t, getPasswordHashVerifier(verifiers, "5f4dcc3b5aa765d61d8327deb882cf99"))
+
+	// hashes with parameters that would take too much memory or time are not computed, or imported
+	ctx, _, cleanup := makeLegacyPasswordHashTestInstance(t)
+	defer cleanup()
+	for _, hash := range []string{
+		"$argon2id$v=19$m=4194304,t=2,p=1$" + encode(salt) + "$" + encode(salt),
+		"$argon2id$v=19$m=1024,t=1000,p=1$" + encode(salt) + "$" + encode(salt),
+		"$argon2id$v=19$m=1024,t=2,p=255$" + encode(salt) + "$" + encode(salt),
+		"$scrypt$ln=30,r=8,p=1$" + encode(salt) + "$" + encode(salt),
+		"$scrypt$ln=20,r=8,p=1$" + encode(salt) + "$" + encode(salt),
+		"$scrypt$ln=10,r=1024,p=1$" + encode(salt) + "$" + encode(salt),
+		"$scrypt$ln=10,r=8,p=1000$" + encode(salt) + "$" + encode(salt),
+		"$pbkdf2-sha256$i=2000000000$" + encode(salt) + "$" + encode(salt),
+	} {
+		verifier := getPasswordHashVerifier(verifiers, hash)
+		if !assert.NotNil(t, verifier, hash) {
+			continue
+		}
+		_, err := verifier.Verify(password, hash)
+		assert.Error(t, err, hash)
+		assert.Error(t, ValidatePasswordHashWithContext(ctx, hash), hash)
+		_, err = ImportUserWithPasswordHashWithContext(ctx, "legacy@example.com", hash)
+		assert.Error(t, err, hash)
+	}
+}
+
+func makeLegacyPasswordHashTestInstance(t *testing.T) (context.Context, epmodels.LegacyPasswordHashStore, func()) {
+	store := MakeInMemoryLegacyPasswordHashStore()
+	_, instance, cleanup := coretest.NewInstance(t, Init(&epmodels.TypeInput{
+		PasswordHashing: &epmodels.TypeInputPasswordHashing{
+			Store: &store,
+		},
+	}))
+	return supertokens.WithInstance(context.Background(), instance), store, cleanup
+}
+
+func TestImportUserWithPasswordHash(t *testing.T) {
+	ctx, store, cleanup := makeLegacyPasswordHashTestInstance(t)
+	defer cleanup()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("legacy123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	importResponse, err := ImportUserWithPasswordHashWithContext(ctx, "legacy@example.com", string(bcryptHash))
+	assert.NoError(t, err)
+	userID := importResponse.OK.User.ID
+
+	importResponse, err = ImportUserWithPasswordHashWithContext(ctx, "legacy@example.com", string(bcryptHash))
+	assert.NoError(t, err)
+	assert.NotNil(t, importResponse.EmailAlreadyExistsError)
+	_, err = ImportUserWithPasswordHashWithContext(ctx, "other@example.com", "not a hash")
+	assert.Error(t, err)
+
+	signInResponse, err := SignInWithContext(ctx, "legacy@example.com", "wrong123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.WrongCredentialsError)
+
+	// the first sign in is checked against the imported hash, and sets the password in the core
+	signInResponse, err = SignInWithContext(ctx, "legacy@example.com", "legacy123")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, signInResponse.OK.User.ID)
+	storedHash, err := store.Get(ctx, userID)
+	assert.NoError(t, err)
+	assert.Nil(t, storedHash)
+
+	signInResponse, err = SignInWithContext(ctx, "legacy@example.com", "legacy123")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, signInResponse.OK.User.ID)
+}
+
+func TestImportedPasswordHashIsRemovedOnPasswordReset(t *testing.T) {
+	ctx, store, cleanup := makeLegacyPasswordHashTestInstance(t)
+	defer cleanup()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("legacy123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	importResponse, err := ImportUserWithPasswordHashWithContext(ctx, "legacy@example.com", string(bcryptHash))
+	assert.NoError(t, err)
+	tokenResponse, err := CreateResetPasswordTokenWithContext(ctx, importResponse.OK.User.ID)
+	assert.NoError(t, err)
+	resetResponse, err := ResetPasswordUsingTokenWithContext(ctx, tokenResponse.OK.Token, "newpassword123")
+	assert.NoError(t, err)
+	assert.NotNil(t, resetResponse.OK)
+	storedHash, err := store.Get(ctx, importResponse.OK.User.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, storedHash)
+
+	signInResponse, err := SignInWithContext(ctx, "legacy@example.com", "legacy123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.WrongCredentialsError)
+	signInResponse, err = SignInWithContext(ctx, "legacy@example.com", "newpassword123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+}
+
+func TestPasswordResetFailsIfTheCoreDoesNotReturnTheUserID(t *testing.T) {
+	store := MakeInMemoryLegacyPasswordHashStore()
+	core, instance, cleanup := coretest.NewInstance(t, Init(&epmodels.TypeInput{
+		PasswordHashing: &epmodels.TypeInputPasswordHashing{
+			Store: &store,
+		},
+	}))
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("legacy123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	importResponse, err := ImportUserWithPasswordHashWithContext(ctx, "legacy@example.com", string(bcryptHash))
+	assert.NoError(t, err)
+	tokenResponse, err := CreateResetPasswordTokenWithContext(ctx, importResponse.OK.User.ID)
+	assert.NoError(t, err)
+
+	// the imported hash can't be removed without the user ID, so the reset is not reported as successful
+	core.OverrideResponse("POST /recipe/user/password/reset", `{"status":"OK"}`)
+	_, err = ResetPasswordUsingTokenWithContext(ctx, tokenResponse.OK.Token, "newpassword123")
+	assert.Error(t, err)
+}
@@ -16,6 +16,7 @@
 package emailpassword
 
 import (
+	"context"
 	"errors"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
@@ -28,120 +29,198 @@ func Init(config *epmodels.TypeInput) supertokens.Recipe {
 }
 
 func SignUp(email string, password string) (epmodels.SignUpResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return SignUpWithContext(context.Background(), email, password)
+}
+
+func SignUpWithContext(ctx context.Context, email string, password string) (epmodels.SignUpResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.SignUpResponse{}, err
 	}
-	return instance.RecipeImpl.SignUp(email, password)
+	return instance.RecipeImpl.SignUp(ctx, email, password)
 }
 
 func SignIn(email string, password string) (epmodels.SignInResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return SignInWithContext(context.Background(), email, password)
+}
+
+func SignInWithContext(ctx context.Context, email string, password string) (epmodels.SignInResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.SignInResponse{}, err
 	}
-	return instance.RecipeImpl.SignIn(email, password)
+	return instance.RecipeImpl.SignIn(ctx, email, password)
 }
 
 func GetUserByID(userID string) (*epmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUserByIDWithContext(context.Background(), userID)
+}
+
+func GetUserByIDWithContext(ctx context.Context, userID string) (*epmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetUserByID(userID)
+	return instance.RecipeImpl.GetUserByID(ctx, userID)
 }
 
 func GetUserByEmail(email string) (*epmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUserByEmailWithContext(context.Background(), email)
+}
+
+func GetUserByEmailWithContext(ctx context.Context, email string) (*epmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetUserByEmail(email)
+	return instance.RecipeImpl.GetUserByEmail(ctx, email)
 }
 
 func CreateResetPasswordToken(userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return CreateResetPasswordTokenWithContext(context.Background(), userID)
+}
+
+func CreateResetPasswordTokenWithContext(ctx context.Context, userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.CreateResetPasswordTokenResponse{}, err
 	}
-	return instance.RecipeImpl.CreateResetPasswordToken(userID)
+	return instance.RecipeImpl.CreateResetPasswordToken(ctx, userID)
 }
 
 func ResetPasswordUsingToken(token string, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return ResetPasswordUsingTokenWithContext(context.Background(), token, newPassword)
+}
+
+func ResetPasswordUsingTokenWithContext(ctx context.Context, token string, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.ResetPasswordUsingTokenResponse{}, nil
 	}
-	return instance.RecipeImpl.ResetPasswordUsingToken(token, newPassword)
+	return instance.RecipeImpl.ResetPasswordUsingToken(ctx, token, newPassword)
 }
 
 func UpdateEmailOrPassword(userId string, email *string, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UpdateEmailOrPasswordWithContext(context.Background(), userId, email, password)
+}
+
+func UpdateEmailOrPasswordWithContext(ctx context.Context, userId string, email *string, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.UpdateEmailOrPasswordResponse{}, nil
 	}
-	return instance.RecipeImpl.UpdateEmailOrPassword(userId, email, password)
+	return instance.RecipeImpl.UpdateEmailOrPassword(ctx, userId, email, password)
+}
+
+// ImportUserWithPasswordHash creates a user with the password hash of another system. bcrypt, argon2id,
+// scrypt and PBKDF2 hashes are supported out of the box (see PasswordHashing in the config for other
+// formats), and a PasswordHashing.Store must be configured. The hash is verified on the user's first
+// sign in, after which the password is set with UpdateEmailOrPassword.
+func ImportUserWithPasswordHash(email string, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	return ImportUserWithPasswordHashWithContext(context.Background(), email, passwordHash)
+}
+
+func ImportUserWithPasswordHashWithContext(ctx context.Context, email string, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	return instance.RecipeImpl.ImportUserWithPasswordHash(ctx, email, passwordHash)
+}
+
+// ValidatePasswordHash returns an error if users can't be imported with the password hash, because it
+// is not in a supported format or no PasswordHashing.Store is configured
+func ValidatePasswordHash(passwordHash string) error {
+	return ValidatePasswordHashWithContext(context.Background(), passwordHash)
+}
+
+func ValidatePasswordHashWithContext(ctx context.Context, passwordHash string) error {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return err
+	}
+	return ValidatePasswordHashForImport(instance.Config.PasswordHashing, passwordHash)
 }
 
 func CreateEmailVerificationToken(userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return CreateEmailVerificationTokenWithContext(context.Background(), userID)
+}
+
+func CreateEmailVerificationTokenWithContext(ctx context.Context, userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.CreateEmailVerificationToken(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.CreateEmailVerificationToken(ctx, userID, email)
 }
 
 func VerifyEmailUsingToken(token string) (*epmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return VerifyEmailUsingTokenWithContext(context.Background(), token)
+}
+
+func VerifyEmailUsingTokenWithContext(ctx context.Context, token string) (*epmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	response, err := instance.EmailVerificationRecipe.RecipeImpl.VerifyEmailUsingToken(token)
+	response, err := instance.EmailVerificationRecipe.RecipeImpl.VerifyEmailUsingToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
 	if response.EmailVerificationInvalidTokenError != nil {
 		return nil, errors.New("email verification token is invalid")
 	}
-	return instance.RecipeImpl.GetUserByID(response.OK.User.ID)
+	return instance.RecipeImpl.GetUserByID(ctx, response.OK.User.ID)
 }
 
 func IsEmailVerified(userID string) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return IsEmailVerifiedWithContext(context.Background(), userID)
+}
+
+func IsEmailVerifiedWithContext(ctx context.Context, userID string) (bool, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return false, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return false, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.IsEmailVerified(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.IsEmailVerified(ctx, userID, email)
 }
 
 func RevokeEmailVerificationTokens(userID string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RevokeEmailVerificationTokensWithContext(context.Background(), userID)
+}
+
+func RevokeEmailVerificationTokensWithContext(ctx context.Context, userID string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.RevokeEmailVerificationTokens(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.RevokeEmailVerificationTokens(ctx, userID, email)
 }
 
 func UnverifyEmail(userID string) (evmodels.UnverifyEmailResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UnverifyEmailWithContext(context.Background(), userID)
+}
+
+func UnverifyEmailWithContext(ctx context.Context, userID string) (evmodels.UnverifyEmailResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.UnverifyEmail(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.UnverifyEmail(ctx, userID, email)
 }
@@ -0,0 +1,277 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The cost parameters of hashes are capped, so that verifying a hash cannot use unbounded memory or time.
+// The caps are well above the recommended parameters of each algorithm. Hashes above them can't be imported.
+const (
+	maxArgon2Memory      = 256 * 1024 // in KiB
+	maxArgon2Iterations  = 16
+	maxArgon2Parallelism = 16
+	maxScryptLogN        = 20
+	maxScryptBlockSize   = 32
+	maxScryptParallelism = 16
+	// maxScryptMemory is the cap of scrypt's 128 * N * r bytes
+	maxScryptMemory     = 256 * 1024 * 1024
+	maxPBKDF2Iterations = 10000000
+)
+
+// getBuiltInPasswordHashVerifiers returns the verifiers of the formats supported by default:
+//   - bcrypt: $2a$, $2b$ or $2y$ hashes
+//   - argon2id: $argon2id$v=19$m=<memory in KiB>,t=<iterations>,p=<parallelism>$<salt>$<hash>
+//   - scrypt: $scrypt$ln=<log2(N)>,r=<block size>,p=<parallelism>$<salt>$<hash>
+//   - PBKDF2: $pbkdf2-sha256$i=<iterations>$<salt>$<hash> (or $pbkdf2$ for SHA-1, $pbkdf2-sha512$ for SHA-512)
+//
+// Salts and hashes are in unpadded base64, and the passlib variants (with "." instead of "+", and
+// the PBKDF2 iterations without "i=") are accepted too.
+func getBuiltInPasswordHashVerifiers() []epmodels.PasswordHashVerifier {
+	return []epmodels.PasswordHashVerifier{{
+		Supports: func(passwordHash string) bool {
+			_, err := bcrypt.Cost([]byte(passwordHash))
+			return err == nil && (strings.HasPrefix(passwordHash, "$2a$") || strings.HasPrefix(passwordHash, "$2b$") || strings.HasPrefix(passwordHash, "$2y$"))
+		},
+		Verify: verifyBcryptHash,
+	}, {
+		Supports: isPHCHashWithID("argon2id"),
+		Validate: func(passwordHash string) error {
+			_, _, err := parseArgon2idHash(passwordHash)
+			return err
+		},
+		Verify: verifyArgon2idHash,
+	}, {
+		Supports: isPHCHashWithID("scrypt"),
+		Validate: func(passwordHash string) error {
+			_, _, err := parseScryptHash(passwordHash)
+			return err
+		},
+		Verify: verifyScryptHash,
+	}, {
+		Supports: isPHCHashWithID("pbkdf2", "pbkdf2-sha256", "pbkdf2-sha512"),
+		Validate: func(passwordHash string) error {
+			_, _, _, err := parsePBKDF2Hash(passwordHash)
+			return err
+		},
+		Verify: verifyPBKDF2Hash,
+	}}
+}
+
+func getPasswordHashVerifier(verifiers []epmodels.PasswordHashVerifier, passwordHash string) *epmodels.PasswordHashVerifier {
+	for _, verifier := range verifiers {
+		if verifier.Supports(passwordHash) {
+			return &verifier
+		}
+	}
+	return nil
+}
+
+func verifyBcryptHash(password string, passwordHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func verifyArgon2idHash(password string, passwordHash string) (bool, error) {
+	parsed, params, err := parseArgon2idHash(passwordHash)
+	if err != nil {
+		return false, err
+	}
+	computed := argon2.IDKey([]byte(password), parsed.salt, uint32(params["t"]), uint32(params["m"]), uint8(params["p"]), uint32(len(parsed.hash)))
+	return subtle.ConstantTimeCompare(computed, parsed.hash) == 1, nil
+}
+
+// parseArgon2idHash returns the hash and its m, t and p params, which must be below the caps
+func parseArgon2idHash(passwordHash string) (phcHash, map[string]int, error) {
+	parsed, err := parsePHCHash(passwordHash)
+	if err != nil {
+		return phcHash{}, nil, err
+	}
+	if parsed.version != "" && parsed.version != strconv.Itoa(argon2.Version) {
+		return phcHash{}, nil, errors.New("unsupported argon2 version: " + parsed.version)
+	}
+	params, err := parsed.getIntParams("m", "t", "p")
+	if err != nil {
+		return phcHash{}, nil, err
+	}
+	if params["m"] > maxArgon2Memory || params["t"] > maxArgon2Iterations || params["p"] > maxArgon2Parallelism {
+		return phcHash{}, nil, errors.New("argon2 parameters are too high: " + parsed.params)
+	}
+	return parsed, params, nil
+}
+
+func verifyScryptHash(password string, passwordHash string) (bool, error) {
+	parsed, params, err := parseScryptHash(passwordHash)
+	if err != nil {
+		return false, err
+	}
+	computed, err := scrypt.Key([]byte(password), parsed.salt, 1<<params["ln"], params["r"], params["p"], len(parsed.hash))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(computed, parsed.hash) == 1, nil
+}
+
+// parseScryptHash returns the hash and its ln, r and p params, which must be below the caps
+func parseScryptHash(passwordHash string) (phcHash, map[string]int, error) {
+	parsed, err := parsePHCHash(passwordHash)
+	if err != nil {
+		return phcHash{}, nil, err
+	}
+	params, err := parsed.getIntParams("ln", "r", "p")
+	if err != nil {
+		return phcHash{}, nil, err
+	}
+	if params["ln"] > maxScryptLogN || params["r"] > maxScryptBlockSize || params["p"] > maxScryptParallelism || 128*params["r"]<<params["ln"] > maxScryptMemory {
+		return phcHash{}, nil, errors.New("scrypt parameters are too high: " + parsed.params)
+	}
+	return parsed, params, nil
+}
+
+func verifyPBKDF2Hash(password string, passwordHash string) (bool, error) {
+	parsed, iterations, hashFunction, err := parsePBKDF2Hash(passwordHash)
+	if err != nil {
+		return false, err
+	}
+	computed := pbkdf2.Key([]byte(password), parsed.salt, iterations, len(parsed.hash), hashFunction)
+	return subtle.ConstantTimeCompare(computed, parsed.hash) == 1, nil
+}
+
+// parsePBKDF2Hash returns the hash, its iterations, which must be below the cap, and its hash function
+func parsePBKDF2Hash(passwordHash string) (phcHash, int, func() hash.Hash, error) {
+	parsed, err := parsePHCHash(passwordHash)
+	if err != nil {
+		return phcHash{}, 0, nil, err
+	}
+	var hashFunction func() hash.Hash
+	switch parsed.id {
+	case "pbkdf2":
+		hashFunction = sha1.New
+	case "pbkdf2-sha256":
+		hashFunction = sha256.New
+	case "pbkdf2-sha512":
+		hashFunction = sha512.New
+	default:
+		return phcHash{}, 0, nil, errors.New("unsupported PBKDF2 hash: " + parsed.id)
+	}
+	iterations, err := strconv.Atoi(strings.TrimPrefix(parsed.params, "i="))
+	if err != nil || iterations <= 0 {
+		return phcHash{}, 0, nil, errors.New("invalid PBKDF2 iterations: " + parsed.params)
+	}
+	if iterations > maxPBKDF2Iterations {
+		return phcHash{}, 0, nil, errors.New("PBKDF2 iterations are too high: " + parsed.params)
+	}
+	return parsed, iterations, hashFunction, nil
+}
+
+// phcHash is a hash in the PHC string format: $<id>[$v=<version>]$<params>$<salt>$<hash>
+type phcHash struct {
+	id      string
+	version string
+	params  string
+	salt    []byte
+	hash    []byte
+}
+
+func parsePHCHash(passwordHash string) (phcHash, error) {
+	parts := strings.Split(passwordHash, "$")
+	if parts[0] != "" || (len(parts) != 5 && len(parts) != 6) {
+		return phcHash{}, errors.New("password hash is not in the PHC string format")
+	}
+	parsed := phcHash{id: parts[1]}
+	rest := parts[2:]
+	if len(rest) == 4 {
+		if !strings.HasPrefix(rest[0], "v=") {
+			return phcHash{}, errors.New("password hash is not in the PHC string format")
+		}
+		parsed.version = strings.TrimPrefix(rest[0], "v=")
+		rest = rest[1:]
+	}
+	parsed.params = rest[0]
+	var err error
+	parsed.salt, err = decodeHashBase64(rest[1])
+	if err != nil {
+		return phcHash{}, err
+	}
+	parsed.hash, err = decodeHashBase64(rest[2])
+	if err != nil {
+		return phcHash{}, err
+	}
+	if len(parsed.hash) == 0 {
+		return phcHash{}, errors.New("password hash has no hash value")
+	}
+	return parsed, nil
+}
+
+// getIntParams reads the params, which must all be positive integers
+func (h phcHash) getIntParams(names ...string) (map[string]int, error) {
+	values := map[string]int{}
+	for _, param := range strings.Split(h.params, ",") {
+		keyAndValue := strings.SplitN(param, "=", 2)
+		if len(keyAndValue) != 2 {
+			return nil, errors.New("invalid password hash parameter: " + param)
+		}
+		value, err := strconv.Atoi(keyAndValue[1])
+		if err != nil || value <= 0 {
+			return nil, errors.New("invalid password hash parameter: " + param)
+		}
+		values[keyAndValue[0]] = value
+	}
+	for _, name := range names {
+		if _, ok := values[name]; !ok {
+			return nil, errors.New("password hash is missing the " + name + " parameter")
+		}
+	}
+	return values, nil
+}
+
+func isPHCHashWithID(ids ...string) func(passwordHash string) bool {
+	return func(passwordHash string) bool {
+		parsed, err := parsePHCHash(passwordHash)
+		if err != nil {
+			return false
+		}
+		for _, id := range ids {
+			if parsed.id == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// decodeHashBase64 decodes unpadded (or padded) base64, including passlib's variant that uses "." instead of "+"
+func decodeHashBase64(value string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.ReplaceAll(strings.TrimRight(value, "="), ".", "+"))
+}
@@ -0,0 +1,94 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&epmodels.TypeInput{
+			SignUpFeature: &epmodels.TypeInputSignUp{
+				FormFields: []epmodels.TypeInputFormField{{ID: "name"}},
+			},
+			PasswordPolicy: &epmodels.PasswordPolicy{
+				MinLength:                12,
+				RequireSymbol:            true,
+				DisallowPersonalInfo:     true,
+				PersonalInfoFormFieldIDs: []string{"name"},
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	signUp := func(password string) (string, string) {
+		body := `{"formFields":[{"id":"email","value":"jsmith@example.com"},{"id":"name","value":"Jane Smith"},{"id":"password","value":"` + password + `"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/signup", strings.NewReader(body))
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		var response struct {
+			Status     string `json:"status"`
+			FormFields []struct {
+				ID    string `json:"id"`
+				Error string `json:"error"`
+			} `json:"formFields"`
+		}
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &response))
+		if len(response.FormFields) == 0 {
+			return response.Status, ""
+		}
+		return response.Status, response.FormFields[0].Error
+	}
+
+	status, msg := signUp("password123")
+	assert.Equal(t, "FIELD_ERROR", status)
+	assert.Equal(t, "Password must contain at least 12 characters. Password must contain at least one symbol. Password is too common", msg)
+
+	status, msg = signUp("smith-rules-2022")
+	assert.Equal(t, "FIELD_ERROR", status)
+	assert.Equal(t, "Password must not contain your email or name", msg)
+
+	status, _ = signUp("correct-horse-battery")
+	assert.Equal(t, "OK", status)
+
+	user, err := GetUserByEmailWithContext(ctx, "jsmith@example.com")
+	assert.NoError(t, err)
+	newPassword := "jsmith-new-pass"
+	response, err := UpdateEmailOrPasswordWithContext(ctx, user.ID, nil, &newPassword)
+	assert.NoError(t, err)
+	if assert.NotNil(t, response.PasswordPolicyViolatedError) {
+		assert.Equal(t, "Password must not contain your email or name", response.PasswordPolicyViolatedError.FailureReason)
+	}
+	newPassword = "staple-lamp-orbit"
+	response, err = UpdateEmailOrPasswordWithContext(ctx, user.ID, nil, &newPassword)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.OK)
+}
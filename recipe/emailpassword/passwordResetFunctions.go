@@ -16,10 +16,10 @@
 package emailpassword
 
 import (
-	"bytes"
-	"encoding/json"
-	"net/http"
+	"context"
+	"errors"
 
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -30,34 +30,21 @@ func defaultGetResetPasswordURL(appInfo supertokens.NormalisedAppinfo) func(_ ep
 	}
 }
 
-// TODO: add test to see query
-func defaultCreateAndSendCustomPasswordResetEmail(appInfo supertokens.NormalisedAppinfo) func(user epmodels.User, passwordResetURLWithToken string) {
-	return func(user epmodels.User, passwordResetURLWithToken string) {
-		if supertokens.IsRunningInTestMode() {
-			// if running in test mode, we do not want to send this.
-			return
-		}
-		url := "https://api.supertokens.io/0/st/auth/password/reset"
-		data := map[string]string{
-			"email":            user.Email,
-			"appName":          appInfo.AppName,
-			"passwordResetURL": passwordResetURLWithToken,
-		}
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			return
-		}
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-		if err != nil {
-			return
-		}
-		req.Header.Set("content-type", "application/json")
-		req.Header.Set("api-version", "0")
-
-		client := &http.Client{}
-		_, err = client.Do(req)
-		if err != nil {
-			return
-		}
+func makeEmailDeliveryFromCreateAndSendCustomEmail(recipeInstance *Recipe, createAndSendCustomEmail func(user epmodels.User, passwordResetURLWithToken string)) emaildelivery.EmailDeliveryInterface {
+	return emaildelivery.EmailDeliveryInterface{
+		SendEmail: func(ctx context.Context, input emaildelivery.EmailType) error {
+			if input.PasswordReset == nil {
+				return errors.New("should never come here: unknown email type")
+			}
+			user, err := recipeInstance.RecipeImpl.GetUserByID(ctx, input.PasswordReset.User.ID)
+			if err != nil {
+				return err
+			}
+			if user == nil {
+				return errors.New("Unknown User ID provided")
+			}
+			createAndSendCustomEmail(*user, input.PasswordReset.PasswordResetLink)
+			return nil
+		},
 	}
 }
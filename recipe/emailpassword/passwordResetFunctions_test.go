@@ -0,0 +1,63 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func generatePasswordResetToken(t *testing.T, emailDelivery emaildelivery.EmailDeliveryInterface) *httptest.ResponseRecorder {
+	_, instance, cleanup := coretest.NewInstance(t, Init(&epmodels.TypeInput{EmailDelivery: &emailDelivery}))
+	defer cleanup()
+
+	_, err := SignUpWithContext(supertokens.WithInstance(context.Background(), instance), "test@example.com", "password123")
+	assert.NoError(t, err)
+
+	body := `{"formFields":[{"id":"email","value":"test@example.com"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/user/password/reset/token", strings.NewReader(body))
+	res := httptest.NewRecorder()
+	instance.Middleware(http.NotFoundHandler()).ServeHTTP(res, req)
+	return res
+}
+
+func TestPasswordResetEmailUsesEmailDelivery(t *testing.T) {
+	var output bytes.Buffer
+	res := generatePasswordResetToken(t, emaildelivery.MakeLogService(emaildelivery.LogServiceConfig{Writer: &output}))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, output.String(), "To: test@example.com\nSubject: Reset your password for "+coretest.TestAppName+"\n")
+	assert.Contains(t, output.String(), "http://localhost:3000/auth/reset-password?token=")
+}
+
+func TestPasswordResetEmailDeliveryErrorIsReturned(t *testing.T) {
+	res := generatePasswordResetToken(t, emaildelivery.EmailDeliveryInterface{
+		SendEmail: func(ctx context.Context, input emaildelivery.EmailType) error {
+			return errors.New("smtp server is down")
+		},
+	})
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+}
@@ -0,0 +1,47 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordpolicy
+
+// commonPasswords are some of the most used passwords found in data breaches, most used first
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234", "111111",
+	"1234567", "dragon", "123123", "baseball", "abc123", "football", "monkey", "letmein",
+	"696969", "shadow", "master", "666666", "qwertyuiop", "123321", "mustang", "1234567890",
+	"michael", "654321", "superman", "1qaz2wsx", "7777777", "121212", "000000", "qazwsx",
+	"123qwe", "killer", "trustno1", "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+	"buster", "soccer", "harley", "batman", "andrew", "tigger", "sunshine", "iloveyou",
+	"2000", "charlie", "robert", "thomas", "hockey", "ranger", "daniel", "starwars",
+	"klaster", "112233", "george", "computer", "michelle", "jessica", "pepper", "1111",
+	"zxcvbn", "555555", "11111111", "131313", "freedom", "777777", "pass", "maggie",
+	"159753", "aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer",
+	"love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access", "yankees",
+	"987654321", "dallas", "austin", "thunder", "taylor", "matrix", "welcome", "admin",
+	"password1", "password123", "passw0rd", "p@ssw0rd", "qwerty123", "qwe123", "1q2w3e4r",
+	"1q2w3e", "asdf", "asdfghjkl", "abcdef", "abcd1234", "welcome1", "login", "secret",
+	"hello", "whatever", "flower", "lovely", "football1", "monkey1", "letmein1", "changeme",
+	"default", "guest", "root", "test", "test123", "qwerty1", "iloveyou1", "princess1",
+}
+
+// commonPasswordRanks maps each common password to its index in commonPasswords
+var commonPasswordRanks = func() map[string]int {
+	ranks := map[string]int{}
+	for rank, password := range commonPasswords {
+		if _, ok := ranks[password]; !ok {
+			ranks[password] = rank
+		}
+	}
+	return ranks
+}()
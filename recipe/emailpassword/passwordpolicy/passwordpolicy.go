@@ -0,0 +1,151 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package passwordpolicy checks passwords against an epmodels.PasswordPolicy
+package passwordpolicy
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	defaultMinLength = 8
+	defaultMaxLength = 100
+	// minPersonalInfoLength is the shortest part of the personal info that a password can't contain
+	minPersonalInfoLength = 3
+)
+
+// Normalise sets the defaults of a policy, and checks that it can be satisfied
+func Normalise(policy epmodels.PasswordPolicy) (epmodels.PasswordPolicy, error) {
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultMinLength
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = defaultMaxLength
+	}
+	if policy.MinLength > policy.MaxLength {
+		return epmodels.PasswordPolicy{}, supertokens.BadInputError{Msg: "MinLength of the password policy must not be more than its MaxLength"}
+	}
+	if policy.MinStrengthScore < 0 || policy.MinStrengthScore > 4 {
+		return epmodels.PasswordPolicy{}, supertokens.BadInputError{Msg: "MinStrengthScore of the password policy must be between 0 and 4"}
+	}
+	return policy, nil
+}
+
+// Validate returns why the password does not follow the policy, or nil if it does. The personal
+// info check is done by ValidatePersonalInfo, since it needs the user's other form fields.
+func Validate(policy epmodels.PasswordPolicy, password string) *string {
+	var violations []string
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, "Password must contain at least "+strconv.Itoa(policy.MinLength)+" characters")
+	}
+	if length > policy.MaxLength {
+		violations = append(violations, "Password must contain at most "+strconv.Itoa(policy.MaxLength)+" characters")
+	}
+
+	var hasLowercase, hasUppercase, hasNumber, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsLower(char):
+			hasLowercase = true
+		case unicode.IsUpper(char):
+			hasUppercase = true
+		case unicode.IsDigit(char):
+			hasNumber = true
+		case !unicode.IsLetter(char) && !unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireLowercase && !hasLowercase {
+		violations = append(violations, "Password must contain at least one lowercase letter")
+	}
+	if policy.RequireUppercase && !hasUppercase {
+		violations = append(violations, "Password must contain at least one uppercase letter")
+	}
+	if policy.RequireNumber && !hasNumber {
+		violations = append(violations, "Password must contain at least one number")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, "Password must contain at least one symbol")
+	}
+
+	if isDenied(policy, password) {
+		violations = append(violations, "Password is too common")
+	} else if policy.MinStrengthScore > 0 && EstimateStrength(password) < policy.MinStrengthScore {
+		violations = append(violations, "Password is too easy to guess")
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	msg := strings.Join(violations, ". ")
+	return &msg
+}
+
+// ValidatePersonalInfo returns an error message if the policy disallows personal info, and the
+// password contains one of the given values (the user's email, name, ...)
+func ValidatePersonalInfo(policy epmodels.PasswordPolicy, password string, personalInfo []string) *string {
+	if !policy.DisallowPersonalInfo {
+		return nil
+	}
+	lowercasePassword := strings.ToLower(password)
+	for _, part := range getPersonalInfoParts(personalInfo) {
+		if strings.Contains(lowercasePassword, part) {
+			msg := "Password must not contain your email or name"
+			return &msg
+		}
+	}
+	return nil
+}
+
+// getPersonalInfoParts splits emails at the @ and names at spaces, since passwords often contain
+// only the user's first name or the part of their email before the @
+func getPersonalInfoParts(personalInfo []string) []string {
+	var parts []string
+	for _, value := range personalInfo {
+		value = strings.ToLower(strings.TrimSpace(value))
+		candidates := []string{value}
+		if at := strings.LastIndex(value, "@"); at > 0 {
+			candidates = append(candidates, value[:at])
+		}
+		candidates = append(candidates, strings.Fields(value)...)
+		for _, candidate := range candidates {
+			if utf8.RuneCountInString(candidate) >= minPersonalInfoLength {
+				parts = append(parts, candidate)
+			}
+		}
+	}
+	return parts
+}
+
+func isDenied(policy epmodels.PasswordPolicy, password string) bool {
+	lowercasePassword := strings.ToLower(password)
+	if _, ok := commonPasswordRanks[lowercasePassword]; ok {
+		return true
+	}
+	for _, denied := range policy.DeniedPasswords {
+		if strings.ToLower(denied) == lowercasePassword {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
+
+func TestValidate(t *testing.T) {
+	policy, err := Normalise(epmodels.PasswordPolicy{
+		MinLength:        10,
+		RequireUppercase: true,
+		RequireSymbol:    true,
+		DeniedPasswords:  []string{"Company2022!"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, policy.MaxLength)
+
+	msg := Validate(policy, "short")
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "Password must contain at least 10 characters. Password must contain at least one uppercase letter. Password must contain at least one symbol", *msg)
+	}
+	msg = Validate(policy, "company2022!")
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "Password must contain at least one uppercase letter. Password is too common", *msg)
+	}
+	assert.Nil(t, Validate(policy, "Correct-Horse-Battery"))
+
+	_, err = Normalise(epmodels.PasswordPolicy{MinLength: 20, MaxLength: 10})
+	assert.Error(t, err)
+	_, err = Normalise(epmodels.PasswordPolicy{MinStrengthScore: 5})
+	assert.Error(t, err)
+}
+
+func TestEstimateStrength(t *testing.T) {
+	assert.Equal(t, 0, EstimateStrength("password"))
+	assert.Equal(t, 0, EstimateStrength("qwertyuiop"))
+	assert.Equal(t, 1, EstimateStrength("aaaaaa1234"))
+	assert.True(t, EstimateStrength("johnsmith2000", "John Smith") < EstimateStrength("kqzvtrwpe2000"))
+	assert.Equal(t, 4, EstimateStrength("vX7#pL2q!Rm9zT"))
+}
+
+func TestValidatePersonalInfo(t *testing.T) {
+	policy := epmodels.PasswordPolicy{DisallowPersonalInfo: true}
+	assert.NotNil(t, ValidatePersonalInfo(policy, "JohnSmith42!", []string{"jsmith@example.com", "John Smith"}))
+	assert.NotNil(t, ValidatePersonalInfo(policy, "my-jsmith-pass", []string{"jsmith@example.com"}))
+	assert.Nil(t, ValidatePersonalInfo(policy, "unrelated-pass1", []string{"jsmith@example.com", "Jo"}))
+	assert.Nil(t, ValidatePersonalInfo(epmodels.PasswordPolicy{}, "JohnSmith42!", []string{"John Smith"}))
+}
@@ -0,0 +1,195 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordpolicy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// minDictionaryMatchLength is the shortest common password or user input that is matched inside a
+// longer password
+const minDictionaryMatchLength = 4
+
+// minPatternLength is the shortest run of repeated or sequential characters that is matched
+const minPatternLength = 3
+
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// EstimateStrength returns a score from 0 (too guessable) to 4 (very unguessable), like zxcvbn.
+// It estimates the number of guesses an attacker needs, by matching the password against common
+// passwords, the given user inputs (like their email and name), repeated characters, sequences
+// and keyboard patterns, and counting the rest of the characters by their character set.
+func EstimateStrength(password string, userInputs ...string) int {
+	if password == "" {
+		return 0
+	}
+	guessesLog10 := estimateGuessesLog10(password, userInputs)
+	switch {
+	case guessesLog10 < 3:
+		return 0
+	case guessesLog10 < 6:
+		return 1
+	case guessesLog10 < 8:
+		return 2
+	case guessesLog10 < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func estimateGuessesLog10(password string, userInputs []string) float64 {
+	lowercasePassword := strings.ToLower(password)
+	if rank, ok := commonPasswordRanks[lowercasePassword]; ok {
+		return math.Log10(float64(rank + 1))
+	}
+
+	runes := []rune(lowercasePassword)
+	charsetLog10 := math.Log10(float64(getCharsetSize(password)))
+	dictionary := getDictionary(userInputs)
+
+	// the password is split greedily from left to right into matched tokens, each of which
+	// costs about as many guesses as choosing it, and single characters
+	guessesLog10 := 0.0
+	for i := 0; i < len(runes); {
+		if length := matchDictionary(runes[i:], dictionary); length > 0 {
+			guessesLog10 += math.Log10(float64(len(commonPasswordRanks) + len(dictionary)))
+			i += length
+			continue
+		}
+		if length := matchPattern(runes[i:]); length > 0 {
+			guessesLog10 += charsetLog10 + math.Log10(float64(length))
+			i += length
+			continue
+		}
+		guessesLog10 += charsetLog10
+		i++
+	}
+	return guessesLog10
+}
+
+// getCharsetSize returns how many characters an attacker has to try for each character of the password
+func getCharsetSize(password string) int {
+	var hasLowercase, hasUppercase, hasNumber, hasSymbol, hasOther bool
+	for _, char := range password {
+		switch {
+		case char >= 'a' && char <= 'z':
+			hasLowercase = true
+		case char >= 'A' && char <= 'Z':
+			hasUppercase = true
+		case char >= '0' && char <= '9':
+			hasNumber = true
+		case char < unicode.MaxASCII:
+			hasSymbol = true
+		default:
+			hasOther = true
+		}
+	}
+	size := 0
+	if hasLowercase {
+		size += 26
+	}
+	if hasUppercase {
+		size += 26
+	}
+	if hasNumber {
+		size += 10
+	}
+	if hasSymbol {
+		size += 33
+	}
+	if hasOther {
+		size += 100
+	}
+	return size
+}
+
+func getDictionary(userInputs []string) []string {
+	dictionary := []string{}
+	for _, input := range getPersonalInfoParts(userInputs) {
+		if len([]rune(input)) >= minDictionaryMatchLength {
+			dictionary = append(dictionary, input)
+		}
+	}
+	return dictionary
+}
+
+// matchDictionary returns the length of the longest common password or user input that the
+// password starts with, or 0
+func matchDictionary(runes []rune, dictionary []string) int {
+	longest := 0
+	for length := minDictionaryMatchLength; length <= len(runes); length++ {
+		candidate := string(runes[:length])
+		if _, ok := commonPasswordRanks[candidate]; ok {
+			longest = length
+			continue
+		}
+		for _, word := range dictionary {
+			if word == candidate {
+				longest = length
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// matchPattern returns the length of the run of repeated characters, sequential characters (like
+// "abc" or "321") or adjacent keys of a keyboard row (like "qwer") that the password starts with,
+// or 0 if it is shorter than minPatternLength
+func matchPattern(runes []rune) int {
+	longest := 0
+	for _, isNext := range []func(previous, current rune) bool{
+		func(previous, current rune) bool { return current == previous },
+		func(previous, current rune) bool { return current == previous+1 },
+		func(previous, current rune) bool { return current == previous-1 },
+		isAdjacentKey(1),
+		isAdjacentKey(-1),
+	} {
+		length := 1
+		for length < len(runes) && isNext(runes[length-1], runes[length]) {
+			length++
+		}
+		if length > longest {
+			longest = length
+		}
+	}
+	if longest < minPatternLength {
+		return 0
+	}
+	return longest
+}
+
+func isAdjacentKey(direction int) func(previous, current rune) bool {
+	return func(previous, current rune) bool {
+		for _, row := range keyboardRows {
+			index := strings.IndexRune(row, previous)
+			if index < 0 {
+				continue
+			}
+			next := index + direction
+			return next >= 0 && next < len(row) && rune(row[next]) == current
+		}
+		return false
+	}
+}
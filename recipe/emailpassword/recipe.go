@@ -16,6 +16,7 @@
 package emailpassword
 
 import (
+	"context"
 	defaultErrors "errors"
 	"net/http"
 
@@ -37,23 +38,26 @@ type Recipe struct {
 	EmailVerificationRecipe emailverification.Recipe
 }
 
-var singletonInstance *Recipe
-
-func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput, emailVerificationInstance *emailverification.Recipe, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+func MakeRecipe(recipeId string, instance *supertokens.SuperTokens, config *epmodels.TypeInput, emailVerificationInstance *emailverification.Recipe) (Recipe, error) {
+	appInfo := instance.AppInfo
+	onGeneralError := instance.OnGeneralError
 	r := &Recipe{}
 	r.RecipeModule = supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, r.handleError, onGeneralError)
 
-	querierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(recipeId)
+	querierInstance, err := instance.GetNewQuerierInstanceOrThrowError(recipeId)
+	if err != nil {
+		return Recipe{}, err
+	}
+	verifiedConfig, err := validateAndNormaliseUserInput(r, appInfo, config)
 	if err != nil {
 		return Recipe{}, err
 	}
-	verifiedConfig := validateAndNormaliseUserInput(r, appInfo, config)
 	r.Config = verifiedConfig
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
-	r.RecipeImpl = verifiedConfig.Override.Functions(MakeRecipeImplementation(*querierInstance))
+	r.RecipeImpl = verifiedConfig.Override.Functions(MakeRecipeImplementation(*querierInstance, verifiedConfig.PasswordHashing, verifiedConfig.PasswordPolicy))
 
 	if emailVerificationInstance == nil {
-		emailVerificationRecipe, err := emailverification.MakeRecipe(recipeId, appInfo, verifiedConfig.EmailVerificationFeature, onGeneralError)
+		emailVerificationRecipe, err := emailverification.MakeRecipe(recipeId, instance, verifiedConfig.EmailVerificationFeature)
 		if err != nil {
 			return Recipe{}, err
 		}
@@ -67,22 +71,25 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 }
 
 func recipeInit(config *epmodels.TypeInput) supertokens.Recipe {
-	return func(appInfo supertokens.NormalisedAppinfo, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
-			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, nil, onGeneralError)
+	return func(instance *supertokens.SuperTokens) (*supertokens.RecipeModule, error) {
+		if instance.GetRecipeInstance(RECIPE_ID) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, instance, config, nil)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			instance.AddRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, defaultErrors.New("emailpassword recipe has already been initialised. Please check your code for bugs.")
 	}
 }
 
-func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+func getRecipeInstanceOrThrowError(ctx context.Context) (*Recipe, error) {
+	instance, err := supertokens.GetInstanceOrThrowError(ctx)
+	if err == nil {
+		if recipe, ok := instance.GetRecipeInstance(RECIPE_ID).(*Recipe); ok {
+			return recipe, nil
+		}
 	}
 	return nil, defaultErrors.New("initialisation not done. Did you forget to call the init function?")
 }
@@ -147,6 +154,7 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		Config:                                r.Config,
 		OtherHandler:                          theirHandler,
 		RecipeID:                              r.RecipeModule.GetRecipeID(),
+		AppInfo:                               r.RecipeModule.GetAppInfo(),
 		RecipeImplementation:                  r.RecipeImpl,
 		EmailVerificationRecipeImplementation: r.EmailVerificationRecipe.RecipeImpl,
 		Req:                                   req,
@@ -181,8 +189,8 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 	return r.EmailVerificationRecipe.RecipeModule.HandleError(err, req, res)
 }
 
-func (r *Recipe) getEmailForUserId(userID string) (string, error) {
-	userInfo, err := r.RecipeImpl.GetUserByID(userID)
+func (r *Recipe) getEmailForUserId(ctx context.Context, userID string) (string, error) {
+	userInfo, err := r.RecipeImpl.GetUserByID(ctx, userID)
 	if err != nil {
 		return "", err
 	}
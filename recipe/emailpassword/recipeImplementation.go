@@ -16,161 +16,264 @@
 package emailpassword
 
 import (
+	"context"
+	"errors"
+
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/passwordpolicy"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterface {
+type userResponse struct {
+	Status string        `json:"status"`
+	User   epmodels.User `json:"user"`
+}
+
+func MakeRecipeImplementation(querier supertokens.Querier, passwordHashing epmodels.TypeNormalisedInputPasswordHashing, passwordPolicy *epmodels.PasswordPolicy) epmodels.RecipeInterface {
 	return epmodels.RecipeInterface{
-		SignUp: func(email, password string) (epmodels.SignUpResponse, error) {
-			response, err := querier.SendPostRequest("/recipe/signup", map[string]interface{}{
-				"email":    email,
-				"password": password,
-			})
-			if err != nil {
-				return epmodels.SignUpResponse{}, err
-			}
-			status, ok := response["status"]
-			if ok && status.(string) == "OK" {
-				user, err := parseUser(response["user"])
-				if err != nil {
-					return epmodels.SignUpResponse{}, err
-				}
-				return epmodels.SignUpResponse{
-					OK: &struct{ User epmodels.User }{User: *user},
-				}, nil
-			}
-			return epmodels.SignUpResponse{
-				EmailAlreadyExistsError: &struct{}{},
-			}, nil
+		SignUp: func(ctx context.Context, email, password string) (epmodels.SignUpResponse, error) {
+			return signUp(ctx, querier, email, password)
 		},
 
-		SignIn: func(email, password string) (epmodels.SignInResponse, error) {
-			response, err := querier.SendPostRequest("/recipe/signin", map[string]interface{}{
-				"email":    email,
-				"password": password,
-			})
-			if err != nil {
-				return epmodels.SignInResponse{}, err
-			}
-			status, ok := response["status"]
-			if ok && status.(string) == "OK" {
-				user, err := parseUser(response["user"])
-				if err != nil {
-					return epmodels.SignInResponse{}, err
-				}
-				return epmodels.SignInResponse{
-					OK: &struct{ User epmodels.User }{User: *user},
-				}, nil
+		SignIn: func(ctx context.Context, email, password string) (epmodels.SignInResponse, error) {
+			response, err := signIn(ctx, querier, email, password)
+			if err != nil || response.WrongCredentialsError == nil || passwordHashing.Store == nil {
+				return response, err
 			}
-			return epmodels.SignInResponse{
-				WrongCredentialsError: &struct{}{},
-			}, nil
+			return signInWithLegacyPasswordHash(ctx, querier, passwordHashing, email, password)
 		},
 
-		GetUserByID: func(userID string) (*epmodels.User, error) {
-			response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		GetUserByID: func(ctx context.Context, userID string) (*epmodels.User, error) {
+			return getUser(ctx, querier, map[string]string{
 				"userId": userID,
 			})
-			if err != nil {
-				return nil, err
-			}
-			status, ok := response["status"]
-			if ok && status.(string) == "OK" {
-				user, err := parseUser(response["user"])
-				if err != nil {
-					return nil, err
-				}
-				return user, nil
-			}
-			return nil, nil
 		},
 
-		GetUserByEmail: func(email string) (*epmodels.User, error) {
-			response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		GetUserByEmail: func(ctx context.Context, email string) (*epmodels.User, error) {
+			return getUser(ctx, querier, map[string]string{
 				"email": email,
 			})
-			if err != nil {
-				return nil, err
-			}
-			status, ok := response["status"]
-			if ok && status.(string) == "OK" {
-				user, err := parseUser(response["user"])
-				if err != nil {
-					return nil, err
-				}
-				return user, nil
-			}
-			return nil, nil
 		},
 
-		CreateResetPasswordToken: func(userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
-			response, err := querier.SendPostRequest("/recipe/user/password/reset/token", map[string]interface{}{
+		CreateResetPasswordToken: func(ctx context.Context, userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
+			var response struct {
+				Status string `json:"status"`
+				Token  string `json:"token"`
+			}
+			err := querier.SendPostRequestAndDecode(ctx, "/recipe/user/password/reset/token", map[string]interface{}{
 				"userId": userID,
-			})
+			}, &response)
 			if err != nil {
 				return epmodels.CreateResetPasswordTokenResponse{}, err
 			}
-			status, ok := response["status"]
-			if ok && status.(string) == "OK" {
+			switch response.Status {
+			case "OK":
 				return epmodels.CreateResetPasswordTokenResponse{
-					OK: &struct{ Token string }{Token: response["token"].(string)},
+					OK: &struct{ Token string }{Token: response.Token},
 				}, nil
+			case "UNKNOWN_USER_ID_ERROR":
+				return epmodels.CreateResetPasswordTokenResponse{
+					UnknownUserIdError: &struct{}{},
+				}, nil
+			default:
+				return epmodels.CreateResetPasswordTokenResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/user/password/reset/token", response.Status)
 			}
-			return epmodels.CreateResetPasswordTokenResponse{
-				UnknownUserIdError: &struct{}{},
-			}, nil
 		},
 
-		ResetPasswordUsingToken: func(token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
-			response, err := querier.SendPostRequest("/recipe/user/password/reset", map[string]interface{}{
+		ResetPasswordUsingToken: func(ctx context.Context, token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
+			var response struct {
+				Status string `json:"status"`
+				UserID string `json:"userId"`
+			}
+			err := querier.SendPostRequestAndDecode(ctx, "/recipe/user/password/reset", map[string]interface{}{
 				"method":      "token",
 				"token":       token,
 				"newPassword": newPassword,
-			})
+			}, &response)
 			if err != nil {
-				return epmodels.ResetPasswordUsingTokenResponse{}, nil
+				return epmodels.ResetPasswordUsingTokenResponse{}, err
 			}
 
-			if response["status"].(string) == "OK" {
+			switch response.Status {
+			case "OK":
+				// the imported hash must not keep working after the password is reset
+				if passwordHashing.Store != nil {
+					if response.UserID == "" {
+						return epmodels.ResetPasswordUsingTokenResponse{}, errors.New("the core did not return the user ID of the password reset, so the imported password hash of the user could not be removed")
+					}
+					err = passwordHashing.Store.Remove(ctx, response.UserID)
+					if err != nil {
+						return epmodels.ResetPasswordUsingTokenResponse{}, err
+					}
+				}
 				return epmodels.ResetPasswordUsingTokenResponse{
 					OK: &struct{}{},
 				}, nil
-			} else {
+			case "RESET_PASSWORD_INVALID_TOKEN_ERROR":
 				return epmodels.ResetPasswordUsingTokenResponse{
 					ResetPasswordInvalidTokenError: &struct{}{},
 				}, nil
+			default:
+				return epmodels.ResetPasswordUsingTokenResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/user/password/reset", response.Status)
 			}
 		},
 
-		UpdateEmailOrPassword: func(userId string, email, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
-			requestBody := map[string]interface{}{
-				"userId": userId,
-			}
-			if email != nil {
-				requestBody["email"] = email
-			}
-			if password != nil {
-				requestBody["password"] = password
+		UpdateEmailOrPassword: func(ctx context.Context, userId string, email, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
+			if password != nil && passwordPolicy != nil {
+				failureReason, err := validatePasswordPolicy(ctx, querier, *passwordPolicy, userId, email, *password)
+				if err != nil {
+					return epmodels.UpdateEmailOrPasswordResponse{}, err
+				}
+				if failureReason != nil {
+					return epmodels.UpdateEmailOrPasswordResponse{
+						PasswordPolicyViolatedError: &struct{ FailureReason string }{FailureReason: *failureReason},
+					}, nil
+				}
 			}
-			response, err := querier.SendPutRequest("/recipe/user", requestBody)
+			response, err := updateEmailOrPassword(ctx, querier, userId, email, password)
 			if err != nil {
-				return epmodels.UpdateEmailOrPasswordResponse{}, nil
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
 			}
-
-			if response["status"].(string) == "OK" {
-				return epmodels.UpdateEmailOrPasswordResponse{
-					OK: &struct{}{},
-				}, nil
-			} else if response["status"].(string) == "EMAIL_ALREADY_EXISTS_ERROR" {
-				return epmodels.UpdateEmailOrPasswordResponse{
-					EmailAlreadyExistsError: &struct{}{},
-				}, nil
-			} else {
-				return epmodels.UpdateEmailOrPasswordResponse{
-					UnknownUserIdError: &struct{}{},
-				}, nil
+			if response.OK != nil && password != nil && passwordHashing.Store != nil {
+				err = passwordHashing.Store.Remove(ctx, userId)
+				if err != nil {
+					return epmodels.UpdateEmailOrPasswordResponse{}, err
+				}
 			}
+			return response, nil
 		},
+
+		ImportUserWithPasswordHash: func(ctx context.Context, email, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+			return importUserWithPasswordHash(ctx, querier, passwordHashing, email, passwordHash)
+		},
+	}
+}
+
+func signUp(ctx context.Context, querier supertokens.Querier, email, password string) (epmodels.SignUpResponse, error) {
+	var response userResponse
+	err := querier.SendPostRequestAndDecode(ctx, "/recipe/signup", map[string]interface{}{
+		"email":    email,
+		"password": password,
+	}, &response)
+	if err != nil {
+		return epmodels.SignUpResponse{}, err
+	}
+	switch response.Status {
+	case "OK":
+		return epmodels.SignUpResponse{
+			OK: &struct {
+				User          epmodels.User
+				PrimaryUserID string
+			}{User: response.User},
+		}, nil
+	case "EMAIL_ALREADY_EXISTS_ERROR":
+		return epmodels.SignUpResponse{
+			EmailAlreadyExistsError: &struct{}{},
+		}, nil
+	default:
+		return epmodels.SignUpResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/signup", response.Status)
+	}
+}
+
+func signIn(ctx context.Context, querier supertokens.Querier, email, password string) (epmodels.SignInResponse, error) {
+	var response userResponse
+	err := querier.SendPostRequestAndDecode(ctx, "/recipe/signin", map[string]interface{}{
+		"email":    email,
+		"password": password,
+	}, &response)
+	if err != nil {
+		return epmodels.SignInResponse{}, err
+	}
+	switch response.Status {
+	case "OK":
+		return epmodels.SignInResponse{
+			OK: &struct {
+				User          epmodels.User
+				PrimaryUserID string
+			}{User: response.User},
+		}, nil
+	case "WRONG_CREDENTIALS_ERROR":
+		return epmodels.SignInResponse{
+			WrongCredentialsError: &struct{}{},
+		}, nil
+	default:
+		return epmodels.SignInResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/signin", response.Status)
+	}
+}
+
+func updateEmailOrPassword(ctx context.Context, querier supertokens.Querier, userId string, email, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
+	requestBody := map[string]interface{}{
+		"userId": userId,
+	}
+	if email != nil {
+		requestBody["email"] = email
+	}
+	if password != nil {
+		requestBody["password"] = password
+	}
+	var response struct {
+		Status string `json:"status"`
+	}
+	err := querier.SendPutRequestAndDecode(ctx, "/recipe/user", requestBody, &response)
+	if err != nil {
+		return epmodels.UpdateEmailOrPasswordResponse{}, err
+	}
+
+	switch response.Status {
+	case "OK":
+		return epmodels.UpdateEmailOrPasswordResponse{
+			OK: &struct{}{},
+		}, nil
+	case "EMAIL_ALREADY_EXISTS_ERROR":
+		return epmodels.UpdateEmailOrPasswordResponse{
+			EmailAlreadyExistsError: &struct{}{},
+		}, nil
+	case "UNKNOWN_USER_ID_ERROR":
+		return epmodels.UpdateEmailOrPasswordResponse{
+			UnknownUserIdError: &struct{}{},
+		}, nil
+	default:
+		return epmodels.UpdateEmailOrPasswordResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/user", response.Status)
+	}
+}
+
+// validatePasswordPolicy returns why a new password of the user does not follow the policy. The
+// personal info check uses the new email if it is changed as well, or the current email otherwise.
+func validatePasswordPolicy(ctx context.Context, querier supertokens.Querier, policy epmodels.PasswordPolicy, userId string, email *string, password string) (*string, error) {
+	if failureReason := passwordpolicy.Validate(policy, password); failureReason != nil {
+		return failureReason, nil
+	}
+	if !policy.DisallowPersonalInfo {
+		return nil, nil
+	}
+	if email == nil {
+		user, err := getUser(ctx, querier, map[string]string{
+			"userId": userId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			// the core returns UNKNOWN_USER_ID_ERROR
+			return nil, nil
+		}
+		email = &user.Email
+	}
+	return passwordpolicy.ValidatePersonalInfo(policy, password, []string{*email}), nil
+}
+
+func getUser(ctx context.Context, querier supertokens.Querier, params map[string]string) (*epmodels.User, error) {
+	var response userResponse
+	err := querier.SendGetRequestAndDecode(ctx, "/recipe/user", params, &response)
+	if err != nil {
+		return nil, err
+	}
+	switch response.Status {
+	case "OK":
+		return &response.User, nil
+	case "UNKNOWN_USER_ID_ERROR", "UNKNOWN_EMAIL_ERROR":
+		return nil, nil
+	default:
+		return nil, supertokens.NewUnexpectedCoreStatusError("/recipe/user", response.Status)
 	}
 }
@@ -16,22 +16,44 @@
 package emailpassword
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
+	"net"
+	"net/http"
 	"reflect"
 	"regexp"
+	"time"
 
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/breachedpasswords"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/passwordpolicy"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput) epmodels.TypeNormalisedInput {
+func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput) (epmodels.TypeNormalisedInput, error) {
 
 	typeNormalisedInput := makeTypeNormalisedInput(recipeInstance)
 
-	if config != nil && config.SignUpFeature != nil {
-		typeNormalisedInput.SignUpFeature = validateAndNormaliseSignupConfig(config.SignUpFeature)
+	if config != nil && config.PasswordPolicy != nil {
+		passwordPolicy, err := passwordpolicy.Normalise(*config.PasswordPolicy)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.PasswordPolicy = &passwordPolicy
+	}
+
+	if config != nil && config.BreachedPasswordCheck != nil {
+		breachedPasswordCheck, err := breachedpasswords.Normalise(*config.BreachedPasswordCheck)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.BreachedPasswordCheck = &breachedPasswordCheck
+	}
+
+	if config != nil && (config.SignUpFeature != nil || config.PasswordPolicy != nil) {
+		typeNormalisedInput.SignUpFeature = validateAndNormaliseSignupConfig(config.SignUpFeature, typeNormalisedInput.PasswordPolicy)
 		typeNormalisedInput.ResetPasswordUsingTokenFeature = validateAndNormaliseResetPasswordUsingTokenConfig(appInfo, typeNormalisedInput.SignUpFeature, nil)
 	}
 
@@ -44,6 +66,33 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 
 	typeNormalisedInput.EmailVerificationFeature = validateAndNormaliseEmailVerificationConfig(recipeInstance, config)
 
+	if config != nil && config.EmailDelivery != nil {
+		typeNormalisedInput.EmailDelivery = *config.EmailDelivery
+	} else if config != nil && config.ResetPasswordUsingTokenFeature != nil && config.ResetPasswordUsingTokenFeature.CreateAndSendCustomEmail != nil {
+		typeNormalisedInput.EmailDelivery = makeEmailDeliveryFromCreateAndSendCustomEmail(recipeInstance, config.ResetPasswordUsingTokenFeature.CreateAndSendCustomEmail)
+	}
+
+	var emailTemplatesInput *emaildelivery.TemplatesInput
+	if config != nil {
+		emailTemplatesInput = config.EmailTemplates
+	}
+	emailTemplates, err := emaildelivery.NewTemplates(emailTemplatesInput)
+	if err != nil {
+		return epmodels.TypeNormalisedInput{}, err
+	}
+	typeNormalisedInput.EmailTemplates = emailTemplates
+
+	if config != nil && config.PasswordHashing != nil {
+		typeNormalisedInput.PasswordHashing, err = NormalisePasswordHashing(config.PasswordHashing)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	if config != nil && config.BruteForceProtection != nil {
+		typeNormalisedInput.BruteForceProtection = validateAndNormaliseBruteForceProtectionConfig(*config.BruteForceProtection)
+	}
+
 	if config != nil && config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
@@ -56,16 +105,20 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 		}
 	}
 
-	return typeNormalisedInput
+	return typeNormalisedInput, nil
 }
 
 func makeTypeNormalisedInput(recipeInstance *Recipe) epmodels.TypeNormalisedInput {
-	signUpConfig := validateAndNormaliseSignupConfig(nil)
+	signUpConfig := validateAndNormaliseSignupConfig(nil, nil)
 	return epmodels.TypeNormalisedInput{
 		SignUpFeature:                  signUpConfig,
 		SignInFeature:                  validateAndNormaliseSignInConfig(signUpConfig),
 		ResetPasswordUsingTokenFeature: validateAndNormaliseResetPasswordUsingTokenConfig(recipeInstance.RecipeModule.GetAppInfo(), signUpConfig, nil),
 		EmailVerificationFeature:       validateAndNormaliseEmailVerificationConfig(recipeInstance, nil),
+		EmailDelivery:                  emaildelivery.MakeSuperTokensService(),
+		PasswordHashing: epmodels.TypeNormalisedInputPasswordHashing{
+			Verifiers: getBuiltInPasswordHashVerifiers(),
+		},
 		Override: epmodels.OverrideStruct{
 			Functions: func(originalImplementation epmodels.RecipeInterface) epmodels.RecipeInterface {
 				return originalImplementation
@@ -88,10 +141,12 @@ func validateAndNormaliseEmailVerificationConfig(recipeInstance *Recipe, config
 		if config.Override != nil {
 			emailverificationTypeInput.Override = config.Override.EmailVerificationFeature
 		}
+		emailverificationTypeInput.EmailDelivery = config.EmailDelivery
+		emailverificationTypeInput.EmailTemplates = config.EmailTemplates
 		if config.EmailVerificationFeature != nil {
 			if config.EmailVerificationFeature.CreateAndSendCustomEmail != nil {
 				emailverificationTypeInput.CreateAndSendCustomEmail = func(user evmodels.User, link string) {
-					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(user.ID)
+					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(context.Background(), user.ID)
 					if err != nil {
 						return
 					}
@@ -104,7 +159,7 @@ func validateAndNormaliseEmailVerificationConfig(recipeInstance *Recipe, config
 
 			if config.EmailVerificationFeature.GetEmailVerificationURL != nil {
 				emailverificationTypeInput.GetEmailVerificationURL = func(user evmodels.User) (string, error) {
-					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(user.ID)
+					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(context.Background(), user.ID)
 					if err != nil {
 						return "", err
 					}
@@ -120,12 +175,76 @@ func validateAndNormaliseEmailVerificationConfig(recipeInstance *Recipe, config
 	return emailverificationTypeInput
 }
 
+// NormalisePasswordHashing adds the built-in verifiers after the configured ones. It is used by the
+// recipes that include emailpassword.
+func NormalisePasswordHashing(config *epmodels.TypeInputPasswordHashing) (epmodels.TypeNormalisedInputPasswordHashing, error) {
+	if config == nil {
+		return epmodels.TypeNormalisedInputPasswordHashing{
+			Verifiers: getBuiltInPasswordHashVerifiers(),
+		}, nil
+	}
+	for _, verifier := range config.Verifiers {
+		if verifier.Supports == nil || verifier.Verify == nil {
+			return epmodels.TypeNormalisedInputPasswordHashing{}, supertokens.BadInputError{Msg: "Supports and Verify must be set in every PasswordHashing verifier"}
+		}
+	}
+	return epmodels.TypeNormalisedInputPasswordHashing{
+		Store:     config.Store,
+		Verifiers: append(append([]epmodels.PasswordHashVerifier{}, config.Verifiers...), getBuiltInPasswordHashVerifiers()...),
+	}, nil
+}
+
+func validateAndNormaliseBruteForceProtectionConfig(config epmodels.TypeInputBruteForceProtection) *epmodels.TypeNormalisedInputBruteForceProtection {
+	normalised := &epmodels.TypeNormalisedInputBruteForceProtection{
+		MaxFailedAttemptsPerEmail: 5,
+		MaxFailedAttemptsPerIP:    20,
+		FailedAttemptsWindow:      15 * time.Minute,
+		LockoutDuration:           time.Minute,
+		MaxLockoutDuration:        time.Hour,
+		LockoutMemory:             24 * time.Hour,
+		GetIP:                     defaultGetIP,
+		Store:                     MakeInMemoryAttemptCounterStore(),
+	}
+	if config.MaxFailedAttemptsPerEmail > 0 {
+		normalised.MaxFailedAttemptsPerEmail = config.MaxFailedAttemptsPerEmail
+	}
+	if config.MaxFailedAttemptsPerIP > 0 {
+		normalised.MaxFailedAttemptsPerIP = config.MaxFailedAttemptsPerIP
+	}
+	if config.FailedAttemptsWindow > 0 {
+		normalised.FailedAttemptsWindow = config.FailedAttemptsWindow
+	}
+	if config.LockoutDuration > 0 {
+		normalised.LockoutDuration = config.LockoutDuration
+	}
+	if config.MaxLockoutDuration > 0 {
+		normalised.MaxLockoutDuration = config.MaxLockoutDuration
+	}
+	if config.LockoutMemory > 0 {
+		normalised.LockoutMemory = config.LockoutMemory
+	}
+	if config.GetIP != nil {
+		normalised.GetIP = config.GetIP
+	}
+	if config.Store != nil {
+		normalised.Store = *config.Store
+	}
+	return normalised
+}
+
+func defaultGetIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
 func validateAndNormaliseResetPasswordUsingTokenConfig(appInfo supertokens.NormalisedAppinfo, signUpConfig epmodels.TypeNormalisedInputSignUp, config *epmodels.TypeInputResetPasswordUsingTokenFeature) epmodels.TypeNormalisedInputResetPasswordUsingTokenFeature {
 	normalisedInputResetPasswordUsingTokenFeature := epmodels.TypeNormalisedInputResetPasswordUsingTokenFeature{
 		FormFieldsForGenerateTokenForm: nil,
 		FormFieldsForPasswordResetForm: nil,
 		GetResetPasswordURL:            defaultGetResetPasswordURL(appInfo),
-		CreateAndSendCustomEmail:       defaultCreateAndSendCustomPasswordResetEmail(appInfo),
 	}
 
 	if len(signUpConfig.FormFields) > 0 {
@@ -148,9 +267,6 @@ func validateAndNormaliseResetPasswordUsingTokenConfig(appInfo supertokens.Norma
 	if config != nil && config.GetResetPasswordURL != nil {
 		normalisedInputResetPasswordUsingTokenFeature.GetResetPasswordURL = config.GetResetPasswordURL
 	}
-	if config != nil && config.CreateAndSendCustomEmail != nil {
-		normalisedInputResetPasswordUsingTokenFeature.CreateAndSendCustomEmail = config.CreateAndSendCustomEmail
-	}
 
 	return normalisedInputResetPasswordUsingTokenFeature
 }
@@ -183,18 +299,22 @@ func normaliseSignInFormFields(formFields []epmodels.NormalisedFormField) []epmo
 	return normalisedFormFields
 }
 
-func validateAndNormaliseSignupConfig(config *epmodels.TypeInputSignUp) epmodels.TypeNormalisedInputSignUp {
+func validateAndNormaliseSignupConfig(config *epmodels.TypeInputSignUp, passwordPolicy *epmodels.PasswordPolicy) epmodels.TypeNormalisedInputSignUp {
 	if config == nil {
 		return epmodels.TypeNormalisedInputSignUp{
-			FormFields: NormaliseSignUpFormFields(nil),
+			FormFields: normaliseSignUpFormFields(nil, passwordPolicy),
 		}
 	}
 	return epmodels.TypeNormalisedInputSignUp{
-		FormFields: NormaliseSignUpFormFields(config.FormFields),
+		FormFields: normaliseSignUpFormFields(config.FormFields, passwordPolicy),
 	}
 }
 
 func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmodels.NormalisedFormField {
+	return normaliseSignUpFormFields(formFields, nil)
+}
+
+func normaliseSignUpFormFields(formFields []epmodels.TypeInputFormField, passwordPolicy *epmodels.PasswordPolicy) []epmodels.NormalisedFormField {
 	var (
 		normalisedFormFields     []epmodels.NormalisedFormField
 		formFieldPasswordIDCount = 0
@@ -209,10 +329,7 @@ func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmod
 			)
 			if formField.ID == "password" {
 				formFieldPasswordIDCount++
-				validate = defaultPasswordValidator
-				if formField.Validate != nil {
-					validate = formField.Validate
-				}
+				validate = getPasswordValidator(passwordPolicy, formField.Validate)
 			} else if formField.ID == "email" {
 				formFieldEmailIDCount++
 				validate = defaultEmailValidator
@@ -238,7 +355,7 @@ func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmod
 	if formFieldPasswordIDCount == 0 {
 		normalisedFormFields = append(normalisedFormFields, epmodels.NormalisedFormField{
 			ID:       "password",
-			Validate: defaultPasswordValidator,
+			Validate: getPasswordValidator(passwordPolicy, nil),
 			Optional: false,
 		})
 	}
@@ -252,6 +369,31 @@ func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmod
 	return normalisedFormFields
 }
 
+// getPasswordValidator returns the validator of the password form field. A custom validator replaces
+// the default one, but is applied after the password policy.
+func getPasswordValidator(passwordPolicy *epmodels.PasswordPolicy, customValidator func(value interface{}) *string) func(value interface{}) *string {
+	validate := defaultPasswordValidator
+	if passwordPolicy != nil {
+		validate = func(value interface{}) *string {
+			password, ok := value.(string)
+			if !ok {
+				msg := "Development bug: Please make sure the password field yields a string"
+				return &msg
+			}
+			if err := passwordpolicy.Validate(*passwordPolicy, password); err != nil {
+				return err
+			}
+			if customValidator != nil {
+				return customValidator(value)
+			}
+			return nil
+		}
+	} else if customValidator != nil {
+		validate = customValidator
+	}
+	return validate
+}
+
 func defaultValidator(_ interface{}) *string {
 	return nil
 }
@@ -297,16 +439,3 @@ func defaultEmailValidator(value interface{}) *string {
 	}
 	return nil
 }
-
-func parseUser(value interface{}) (*epmodels.User, error) {
-	respJSON, err := json.Marshal(value)
-	if err != nil {
-		return nil, err
-	}
-	var user epmodels.User
-	err = json.Unmarshal(respJSON, &user)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
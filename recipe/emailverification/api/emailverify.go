@@ -50,7 +50,7 @@ func EmailVerify(apiImplementation evmodels.APIInterface, options evmodels.APIOp
 			return supertokens.BadInputError{Msg: "The email verification token must be a string"}
 		}
 
-		response, err := apiImplementation.VerifyEmailPOST(token.(string), options)
+		response, err := apiImplementation.VerifyEmailPOST(options.Req.Context(), token.(string), options)
 		if err != nil {
 			return err
 		}
@@ -70,7 +70,7 @@ func EmailVerify(apiImplementation evmodels.APIInterface, options evmodels.APIOp
 			return nil
 		}
 
-		isVerified, err := apiImplementation.IsEmailVerifiedGET(options)
+		isVerified, err := apiImplementation.IsEmailVerifiedGET(options.Req.Context(), options)
 		if err != nil {
 			return err
 		}
@@ -26,7 +26,7 @@ func GenerateEmailVerifyToken(apiImplementation evmodels.APIInterface, options e
 		return nil
 	}
 
-	response, err := apiImplementation.GenerateEmailVerifyTokenPOST(options)
+	response, err := apiImplementation.GenerateEmailVerifyTokenPOST(options.Req.Context(), options)
 	if err != nil {
 		return err
 	}
@@ -16,6 +16,9 @@
 package api
 
 import (
+	"context"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
@@ -23,12 +26,12 @@ import (
 
 func MakeAPIImplementation() evmodels.APIInterface {
 	return evmodels.APIInterface{
-		VerifyEmailPOST: func(token string, options evmodels.APIOptions) (evmodels.VerifyEmailUsingTokenResponse, error) {
-			return options.RecipeImplementation.VerifyEmailUsingToken((token))
+		VerifyEmailPOST: func(ctx context.Context, token string, options evmodels.APIOptions) (evmodels.VerifyEmailUsingTokenResponse, error) {
+			return options.RecipeImplementation.VerifyEmailUsingToken(ctx, (token))
 		},
 
-		IsEmailVerifiedGET: func(options evmodels.APIOptions) (evmodels.IsEmailVerifiedGETResponse, error) {
-			session, err := session.GetSession(options.Req, options.Res, nil)
+		IsEmailVerifiedGET: func(ctx context.Context, options evmodels.APIOptions) (evmodels.IsEmailVerifiedGETResponse, error) {
+			session, err := session.GetSessionWithContext(ctx, options.Req, options.Res, nil)
 			if err != nil {
 				return evmodels.IsEmailVerifiedGETResponse{}, err
 			}
@@ -38,11 +41,11 @@ func MakeAPIImplementation() evmodels.APIInterface {
 
 			userID := session.GetUserID()
 
-			email, err := options.Config.GetEmailForUserID(userID)
+			email, err := options.Config.GetEmailForUserID(ctx, userID)
 			if err != nil {
 				return evmodels.IsEmailVerifiedGETResponse{}, err
 			}
-			isVerified, err := options.RecipeImplementation.IsEmailVerified(userID, email)
+			isVerified, err := options.RecipeImplementation.IsEmailVerified(ctx, userID, email)
 			if err != nil {
 				return evmodels.IsEmailVerifiedGETResponse{}, err
 			}
@@ -53,8 +56,8 @@ func MakeAPIImplementation() evmodels.APIInterface {
 			}, nil
 		},
 
-		GenerateEmailVerifyTokenPOST: func(options evmodels.APIOptions) (evmodels.GenerateEmailVerifyTokenPOSTResponse, error) {
-			session, err := session.GetSession(options.Req, options.Res, nil)
+		GenerateEmailVerifyTokenPOST: func(ctx context.Context, options evmodels.APIOptions) (evmodels.GenerateEmailVerifyTokenPOSTResponse, error) {
+			session, err := session.GetSessionWithContext(ctx, options.Req, options.Res, nil)
 			if err != nil {
 				return evmodels.GenerateEmailVerifyTokenPOSTResponse{}, err
 			}
@@ -63,11 +66,11 @@ func MakeAPIImplementation() evmodels.APIInterface {
 			}
 
 			userID := session.GetUserID()
-			email, err := options.Config.GetEmailForUserID(userID)
+			email, err := options.Config.GetEmailForUserID(ctx, userID)
 			if err != nil {
 				return evmodels.GenerateEmailVerifyTokenPOSTResponse{}, err
 			}
-			response, err := options.RecipeImplementation.CreateEmailVerificationToken(userID, email)
+			response, err := options.RecipeImplementation.CreateEmailVerificationToken(ctx, userID, email)
 			if err != nil {
 				return evmodels.GenerateEmailVerifyTokenPOSTResponse{}, err
 			}
@@ -88,7 +91,29 @@ func MakeAPIImplementation() evmodels.APIInterface {
 			}
 			emailVerifyLink := emailVerificationURL + "?token=" + response.OK.Token + "&rid=" + options.RecipeID
 
-			options.Config.CreateAndSendCustomEmail(user, emailVerifyLink)
+			emailUser := emaildelivery.User{
+				ID:    user.ID,
+				Email: user.Email,
+			}
+			content, err := options.Config.EmailTemplates.RenderEmailVerification(options.Req, emaildelivery.TemplateData{
+				AppName: options.AppInfo.AppName,
+				User:    emailUser,
+				Link:    emailVerifyLink,
+			})
+			if err != nil {
+				return evmodels.GenerateEmailVerifyTokenPOSTResponse{}, err
+			}
+			err = options.Config.EmailDelivery.SendEmail(ctx, emaildelivery.EmailType{
+				EmailVerification: &emaildelivery.EmailVerificationType{
+					User:            emailUser,
+					AppName:         options.AppInfo.AppName,
+					EmailVerifyLink: emailVerifyLink,
+					Content:         &content,
+				},
+			})
+			if err != nil {
+				return evmodels.GenerateEmailVerifyTokenPOSTResponse{}, err
+			}
 
 			return evmodels.GenerateEmailVerifyTokenPOSTResponse{
 				OK: &struct{}{},
@@ -16,10 +16,6 @@
 package emailverification
 
 import (
-	"bytes"
-	"encoding/json"
-	"net/http"
-
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -29,37 +25,3 @@ func DefaultGetEmailVerificationURL(appInfo supertokens.NormalisedAppinfo) func(
 		return appInfo.WebsiteDomain.GetAsStringDangerous() + appInfo.WebsiteBasePath.GetAsStringDangerous() + "/verify-email", nil
 	}
 }
-
-// TODO: add test to see query
-func DefaultCreateAndSendCustomEmail(appInfo supertokens.NormalisedAppinfo) func(user evmodels.User, emailVerifyURLWithToken string) {
-	return func(user evmodels.User, emailVerifyURLWithToken string) {
-		if supertokens.IsRunningInTestMode() {
-			// if running in test mode, we do not want to send this.
-			return
-		}
-		const url = "https://api.supertokens.io/0/st/auth/email/verify"
-
-		data := map[string]string{
-			"email":          user.Email,
-			"appName":        appInfo.AppName,
-			"emailVerifyURL": emailVerifyURLWithToken,
-		}
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			return
-		}
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-		if err != nil {
-			return
-		}
-
-		req.Header.Set("content-type", "application/json")
-		req.Header.Set("api-version", "0")
-		client := &http.Client{}
-		_, err = client.Do(req)
-		if err != nil {
-			return
-		}
-		return
-	}
-}
@@ -15,21 +15,27 @@
 
 package evmodels
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
 
 type APIOptions struct {
 	RecipeImplementation RecipeInterface
 	Config               TypeNormalisedInput
 	RecipeID             string
+	AppInfo              supertokens.NormalisedAppinfo
 	Req                  *http.Request
 	Res                  http.ResponseWriter
 	OtherHandler         http.HandlerFunc
 }
 
 type APIInterface struct {
-	VerifyEmailPOST              func(token string, options APIOptions) (VerifyEmailUsingTokenResponse, error)
-	IsEmailVerifiedGET           func(options APIOptions) (IsEmailVerifiedGETResponse, error)
-	GenerateEmailVerifyTokenPOST func(options APIOptions) (GenerateEmailVerifyTokenPOSTResponse, error)
+	VerifyEmailPOST              func(ctx context.Context, token string, options APIOptions) (VerifyEmailUsingTokenResponse, error)
+	IsEmailVerifiedGET           func(ctx context.Context, options APIOptions) (IsEmailVerifiedGETResponse, error)
+	GenerateEmailVerifyTokenPOST func(ctx context.Context, options APIOptions) (GenerateEmailVerifyTokenPOSTResponse, error)
 }
 
 type IsEmailVerifiedGETResponse struct {
@@ -15,18 +15,27 @@
 
 package evmodels
 
+import (
+	"context"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+)
+
 type TypeInput struct {
-	GetEmailForUserID        func(userID string) (string, error)
+	GetEmailForUserID        func(ctx context.Context, userID string) (string, error)
 	GetEmailVerificationURL  func(user User) (string, error)
 	CreateAndSendCustomEmail func(user User, emailVerificationURLWithToken string)
+	EmailDelivery            *emaildelivery.EmailDeliveryInterface
+	EmailTemplates           *emaildelivery.TemplatesInput
 	Override                 *OverrideStruct
 }
 
 type TypeNormalisedInput struct {
-	GetEmailForUserID        func(userID string) (string, error)
-	GetEmailVerificationURL  func(user User) (string, error)
-	CreateAndSendCustomEmail func(user User, emailVerificationURLWithToken string)
-	Override                 OverrideStruct
+	GetEmailForUserID       func(ctx context.Context, userID string) (string, error)
+	GetEmailVerificationURL func(user User) (string, error)
+	EmailDelivery           emaildelivery.EmailDeliveryInterface
+	EmailTemplates          *emaildelivery.Templates
+	Override                OverrideStruct
 }
 
 type OverrideStruct struct {
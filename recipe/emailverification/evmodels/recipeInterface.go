@@ -15,12 +15,14 @@
 
 package evmodels
 
+import "context"
+
 type RecipeInterface struct {
-	CreateEmailVerificationToken  func(userID, email string) (CreateEmailVerificationTokenResponse, error)
-	VerifyEmailUsingToken         func(token string) (VerifyEmailUsingTokenResponse, error)
-	IsEmailVerified               func(userID, email string) (bool, error)
-	RevokeEmailVerificationTokens func(userId, email string) (RevokeEmailVerificationTokensResponse, error)
-	UnverifyEmail                 func(userId, email string) (UnverifyEmailResponse, error)
+	CreateEmailVerificationToken  func(ctx context.Context, userID, email string) (CreateEmailVerificationTokenResponse, error)
+	VerifyEmailUsingToken         func(ctx context.Context, token string) (VerifyEmailUsingTokenResponse, error)
+	IsEmailVerified               func(ctx context.Context, userID, email string) (bool, error)
+	RevokeEmailVerificationTokens func(ctx context.Context, userId, email string) (RevokeEmailVerificationTokensResponse, error)
+	UnverifyEmail                 func(ctx context.Context, userId, email string) (UnverifyEmailResponse, error)
 }
 
 type CreateEmailVerificationTokenResponse struct {
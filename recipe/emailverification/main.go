@@ -16,6 +16,8 @@
 package emailverification
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -25,41 +27,61 @@ func Init(config evmodels.TypeInput) supertokens.Recipe {
 }
 
 func CreateEmailVerificationToken(userID, email string) (evmodels.CreateEmailVerificationTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return CreateEmailVerificationTokenWithContext(context.Background(), userID, email)
+}
+
+func CreateEmailVerificationTokenWithContext(ctx context.Context, userID, email string) (evmodels.CreateEmailVerificationTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
-	return instance.RecipeImpl.CreateEmailVerificationToken(userID, email)
+	return instance.RecipeImpl.CreateEmailVerificationToken(ctx, userID, email)
 }
 
 func VerifyEmailUsingToken(token string) (evmodels.VerifyEmailUsingTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return VerifyEmailUsingTokenWithContext(context.Background(), token)
+}
+
+func VerifyEmailUsingTokenWithContext(ctx context.Context, token string) (evmodels.VerifyEmailUsingTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.VerifyEmailUsingTokenResponse{}, err
 	}
-	return instance.RecipeImpl.VerifyEmailUsingToken(token)
+	return instance.RecipeImpl.VerifyEmailUsingToken(ctx, token)
 }
 
 func IsEmailVerified(userID, email string) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return IsEmailVerifiedWithContext(context.Background(), userID, email)
+}
+
+func IsEmailVerifiedWithContext(ctx context.Context, userID, email string) (bool, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return false, err
 	}
-	return instance.RecipeImpl.IsEmailVerified(userID, email)
+	return instance.RecipeImpl.IsEmailVerified(ctx, userID, email)
 }
 
 func RevokeEmailVerificationTokens(userID, email string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RevokeEmailVerificationTokensWithContext(context.Background(), userID, email)
+}
+
+func RevokeEmailVerificationTokensWithContext(ctx context.Context, userID, email string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
-	return instance.RecipeImpl.RevokeEmailVerificationTokens(userID, email)
+	return instance.RecipeImpl.RevokeEmailVerificationTokens(ctx, userID, email)
 }
 
 func UnverifyEmail(userID, email string) (evmodels.UnverifyEmailResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UnverifyEmailWithContext(context.Background(), userID, email)
+}
+
+func UnverifyEmailWithContext(ctx context.Context, userID, email string) (evmodels.UnverifyEmailResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
-	return instance.RecipeImpl.UnverifyEmail(userID, email)
+	return instance.RecipeImpl.UnverifyEmail(ctx, userID, email)
 }
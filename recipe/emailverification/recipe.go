@@ -16,6 +16,7 @@
 package emailverification
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -33,15 +34,18 @@ type Recipe struct {
 	APIImpl      evmodels.APIInterface
 }
 
-var singletonInstance *Recipe
-
-func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config evmodels.TypeInput, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+func MakeRecipe(recipeId string, instance *supertokens.SuperTokens, config evmodels.TypeInput) (Recipe, error) {
+	appInfo := instance.AppInfo
+	onGeneralError := instance.OnGeneralError
 	r := &Recipe{}
-	verifiedConfig := validateAndNormaliseUserInput(appInfo, config)
+	verifiedConfig, err := validateAndNormaliseUserInput(appInfo, config)
+	if err != nil {
+		return Recipe{}, err
+	}
 	r.Config = verifiedConfig
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
 
-	querierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(recipeId)
+	querierInstance, err := instance.GetNewQuerierInstanceOrThrowError(recipeId)
 	if err != nil {
 		return Recipe{}, err
 	}
@@ -54,22 +58,25 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config e
 	return *r, nil
 }
 
-func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+func getRecipeInstanceOrThrowError(ctx context.Context) (*Recipe, error) {
+	instance, err := supertokens.GetInstanceOrThrowError(ctx)
+	if err == nil {
+		if recipe, ok := instance.GetRecipeInstance(RECIPE_ID).(*Recipe); ok {
+			return recipe, nil
+		}
 	}
 	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
 }
 
 func recipeInit(config evmodels.TypeInput) supertokens.Recipe {
-	return func(appInfo supertokens.NormalisedAppinfo, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
-			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, onGeneralError)
+	return func(instance *supertokens.SuperTokens) (*supertokens.RecipeModule, error) {
+		if instance.GetRecipeInstance(RECIPE_ID) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, instance, config)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			instance.AddRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, errors.New("Emailverification recipe has already been initialised. Please check your code for bugs.")
 	}
@@ -109,6 +116,7 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 	options := evmodels.APIOptions{
 		Config:               r.Config,
 		RecipeID:             r.RecipeModule.GetRecipeID(),
+		AppInfo:              r.RecipeModule.GetAppInfo(),
 		RecipeImplementation: r.RecipeImpl,
 		Req:                  req,
 		Res:                  res,
@@ -16,67 +16,86 @@
 package emailverification
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterface {
 	return evmodels.RecipeInterface{
-		CreateEmailVerificationToken: func(userID, email string) (evmodels.CreateEmailVerificationTokenResponse, error) {
-			response, err := querier.SendPostRequest("/recipe/user/email/verify/token", map[string]interface{}{
+		CreateEmailVerificationToken: func(ctx context.Context, userID, email string) (evmodels.CreateEmailVerificationTokenResponse, error) {
+			var response struct {
+				Status string `json:"status"`
+				Token  string `json:"token"`
+			}
+			err := querier.SendPostRequestAndDecode(ctx, "/recipe/user/email/verify/token", map[string]interface{}{
 				"userId": userID,
 				"email":  email,
-			})
+			}, &response)
 			if err != nil {
 				return evmodels.CreateEmailVerificationTokenResponse{}, err
 			}
-			status, ok := response["status"]
-			if ok && status == "OK" {
+			switch response.Status {
+			case "OK":
 				return evmodels.CreateEmailVerificationTokenResponse{
-					OK: &struct{ Token string }{Token: response["token"].(string)},
+					OK: &struct{ Token string }{Token: response.Token},
 				}, nil
+			case "EMAIL_ALREADY_VERIFIED_ERROR":
+				return evmodels.CreateEmailVerificationTokenResponse{
+					EmailAlreadyVerifiedError: &struct{}{},
+				}, nil
+			default:
+				return evmodels.CreateEmailVerificationTokenResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/user/email/verify/token", response.Status)
 			}
-
-			return evmodels.CreateEmailVerificationTokenResponse{
-				EmailAlreadyVerifiedError: &struct{}{},
-			}, nil
 		},
 
-		VerifyEmailUsingToken: func(token string) (evmodels.VerifyEmailUsingTokenResponse, error) {
-			response, err := querier.SendPostRequest("/recipe/user/email/verify", map[string]interface{}{
+		VerifyEmailUsingToken: func(ctx context.Context, token string) (evmodels.VerifyEmailUsingTokenResponse, error) {
+			var response struct {
+				Status string `json:"status"`
+				UserID string `json:"userId"`
+				Email  string `json:"email"`
+			}
+			err := querier.SendPostRequestAndDecode(ctx, "/recipe/user/email/verify", map[string]interface{}{
 				"method": "token",
 				"token":  token,
-			})
+			}, &response)
 			if err != nil {
 				return evmodels.VerifyEmailUsingTokenResponse{}, err
 			}
-			status, ok := response["status"]
-			if ok && status == "OK" {
+			switch response.Status {
+			case "OK":
 				return evmodels.VerifyEmailUsingTokenResponse{
 					OK: &struct{ User evmodels.User }{User: evmodels.User{
-						ID:    response["userId"].(string),
-						Email: response["email"].(string),
+						ID:    response.UserID,
+						Email: response.Email,
 					}},
 				}, nil
+			case "EMAIL_VERIFICATION_INVALID_TOKEN_ERROR":
+				return evmodels.VerifyEmailUsingTokenResponse{
+					EmailVerificationInvalidTokenError: &struct{}{},
+				}, nil
+			default:
+				return evmodels.VerifyEmailUsingTokenResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/user/email/verify", response.Status)
 			}
-			return evmodels.VerifyEmailUsingTokenResponse{
-				EmailVerificationInvalidTokenError: &struct{}{},
-			}, nil
 		},
 
-		IsEmailVerified: func(userID, email string) (bool, error) {
-			response, err := querier.SendGetRequest("/recipe/user/email/verify", map[string]string{
+		IsEmailVerified: func(ctx context.Context, userID, email string) (bool, error) {
+			var response struct {
+				IsVerified bool `json:"isVerified"`
+			}
+			err := querier.SendGetRequestAndDecode(ctx, "/recipe/user/email/verify", map[string]string{
 				"userId": userID,
 				"email":  email,
-			})
+			}, &response)
 			if err != nil {
 				return false, err
 			}
-			return response["isVerified"].(bool), nil
+			return response.IsVerified, nil
 		},
 
-		RevokeEmailVerificationTokens: func(userId string, email string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
-			_, err := querier.SendPostRequest("/recipe/user/email/verify/token/remove", map[string]interface{}{
+		RevokeEmailVerificationTokens: func(ctx context.Context, userId string, email string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
+			_, err := querier.SendPostRequestWithContext(ctx, "/recipe/user/email/verify/token/remove", map[string]interface{}{
 				"userId": userId,
 				"email":  email,
 			})
@@ -88,8 +107,8 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 			}, nil
 		},
 
-		UnverifyEmail: func(userId string, email string) (evmodels.UnverifyEmailResponse, error) {
-			_, err := querier.SendPostRequest("/recipe/user/email/verify/remove", map[string]interface{}{
+		UnverifyEmail: func(ctx context.Context, userId string, email string) (evmodels.UnverifyEmailResponse, error) {
+			_, err := querier.SendPostRequestWithContext(ctx, "/recipe/user/email/verify/remove", map[string]interface{}{
 				"userId": userId,
 				"email":  email,
 			})
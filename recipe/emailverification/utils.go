@@ -16,23 +16,33 @@
 package emailverification
 
 import (
+	"context"
 	"errors"
 
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config evmodels.TypeInput) evmodels.TypeNormalisedInput {
+func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config evmodels.TypeInput) (evmodels.TypeNormalisedInput, error) {
 	typeNormalisedInput := makeTypeNormalisedInput(appInfo)
 
 	if config.GetEmailVerificationURL != nil {
 		typeNormalisedInput.GetEmailVerificationURL = config.GetEmailVerificationURL
 	}
 
-	if config.CreateAndSendCustomEmail != nil {
-		typeNormalisedInput.CreateAndSendCustomEmail = config.CreateAndSendCustomEmail
+	if config.EmailDelivery != nil {
+		typeNormalisedInput.EmailDelivery = *config.EmailDelivery
+	} else if config.CreateAndSendCustomEmail != nil {
+		typeNormalisedInput.EmailDelivery = makeEmailDeliveryFromCreateAndSendCustomEmail(config.CreateAndSendCustomEmail)
 	}
 
+	emailTemplates, err := emaildelivery.NewTemplates(config.EmailTemplates)
+	if err != nil {
+		return evmodels.TypeNormalisedInput{}, err
+	}
+	typeNormalisedInput.EmailTemplates = emailTemplates
+
 	if config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
@@ -45,14 +55,14 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 	if config.GetEmailForUserID != nil {
 		typeNormalisedInput.GetEmailForUserID = config.GetEmailForUserID
 	}
-	return typeNormalisedInput
+	return typeNormalisedInput, nil
 }
 
 func makeTypeNormalisedInput(appInfo supertokens.NormalisedAppinfo) evmodels.TypeNormalisedInput {
 	return evmodels.TypeNormalisedInput{
-		GetEmailForUserID:        func(userID string) (string, error) { return "", errors.New("not defined by user") },
-		GetEmailVerificationURL:  DefaultGetEmailVerificationURL(appInfo),
-		CreateAndSendCustomEmail: DefaultCreateAndSendCustomEmail(appInfo),
+		GetEmailForUserID:       func(ctx context.Context, userID string) (string, error) { return "", errors.New("not defined by user") },
+		GetEmailVerificationURL: DefaultGetEmailVerificationURL(appInfo),
+		EmailDelivery:           emaildelivery.MakeSuperTokensService(),
 		Override: evmodels.OverrideStruct{
 			Functions: func(originalImplementation evmodels.RecipeInterface) evmodels.RecipeInterface {
 				return originalImplementation
@@ -63,3 +73,18 @@ func makeTypeNormalisedInput(appInfo supertokens.NormalisedAppinfo) evmodels.Typ
 		},
 	}
 }
+
+func makeEmailDeliveryFromCreateAndSendCustomEmail(createAndSendCustomEmail func(user evmodels.User, emailVerificationURLWithToken string)) emaildelivery.EmailDeliveryInterface {
+	return emaildelivery.EmailDeliveryInterface{
+		SendEmail: func(ctx context.Context, input emaildelivery.EmailType) error {
+			if input.EmailVerification == nil {
+				return errors.New("should never come here: unknown email type")
+			}
+			createAndSendCustomEmail(evmodels.User{
+				ID:    input.EmailVerification.User.ID,
+				Email: input.EmailVerification.User.Email,
+			}, input.EmailVerification.EmailVerifyLink)
+			return nil
+		},
+	}
+}
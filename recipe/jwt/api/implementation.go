@@ -16,13 +16,15 @@
 package api
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/jwt/jwtmodels"
 )
 
 func MakeAPIImplementation() jwtmodels.APIInterface {
 	return jwtmodels.APIInterface{
-		GetJWKSGET: func(options jwtmodels.APIOptions) (jwtmodels.GetJWKSResponse, error) {
-			return options.RecipeImplementation.GetJWKS()
+		GetJWKSGET: func(ctx context.Context, options jwtmodels.APIOptions) (jwtmodels.GetJWKSResponse, error) {
+			return options.RecipeImplementation.GetJWKS(ctx)
 		},
 	}
 }
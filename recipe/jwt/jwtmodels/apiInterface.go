@@ -15,7 +15,10 @@
 
 package jwtmodels
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 type APIOptions struct {
 	RecipeImplementation RecipeInterface
@@ -27,5 +30,5 @@ type APIOptions struct {
 }
 
 type APIInterface struct {
-	GetJWKSGET func(options APIOptions) (GetJWKSResponse, error)
+	GetJWKSGET func(ctx context.Context, options APIOptions) (GetJWKSResponse, error)
 }
@@ -15,9 +15,11 @@
 
 package jwtmodels
 
+import "context"
+
 type RecipeInterface struct {
-	CreateJWT func(payload map[string]interface{}, validitySeconds *uint64) (CreateJWTResponse, error)
-	GetJWKS   func() (GetJWKSResponse, error)
+	CreateJWT func(ctx context.Context, payload map[string]interface{}, validitySeconds *uint64) (CreateJWTResponse, error)
+	GetJWKS   func(ctx context.Context) (GetJWKSResponse, error)
 }
 
 type CreateJWTResponse struct {
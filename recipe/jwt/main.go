@@ -16,6 +16,8 @@
 package jwt
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/jwt/jwtmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -25,17 +27,25 @@ func Init(config *jwtmodels.TypeInput) supertokens.Recipe {
 }
 
 func CreateJWT(payload map[string]interface{}, validitySecondsPointer *uint64) (jwtmodels.CreateJWTResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return CreateJWTWithContext(context.Background(), payload, validitySecondsPointer)
+}
+
+func CreateJWTWithContext(ctx context.Context, payload map[string]interface{}, validitySecondsPointer *uint64) (jwtmodels.CreateJWTResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return jwtmodels.CreateJWTResponse{}, err
 	}
-	return instance.RecipeImpl.CreateJWT(payload, validitySecondsPointer)
+	return instance.RecipeImpl.CreateJWT(ctx, payload, validitySecondsPointer)
 }
 
 func GetJWKS() (jwtmodels.GetJWKSResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetJWKSWithContext(context.Background())
+}
+
+func GetJWKSWithContext(ctx context.Context) (jwtmodels.GetJWKSResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return jwtmodels.GetJWKSResponse{}, err
 	}
-	return instance.RecipeImpl.GetJWKS()
+	return instance.RecipeImpl.GetJWKS(ctx)
 }
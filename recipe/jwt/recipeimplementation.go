@@ -16,13 +16,15 @@
 package jwt
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/jwt/jwtmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 func makeRecipeImplementation(querier supertokens.Querier, config jwtmodels.TypeNormalisedInput, appInfo supertokens.NormalisedAppinfo) jwtmodels.RecipeInterface {
 	return jwtmodels.RecipeInterface{
-		CreateJWT: func(payload map[string]interface{}, validitySecondsPointer *uint64) (jwtmodels.CreateJWTResponse, error) {
+		CreateJWT: func(ctx context.Context, payload map[string]interface{}, validitySecondsPointer *uint64) (jwtmodels.CreateJWTResponse, error) {
 			validitySeconds := config.JwtValiditySeconds
 			if validitySecondsPointer != nil {
 				validitySeconds = *validitySecondsPointer
@@ -31,46 +33,51 @@ func makeRecipeImplementation(querier supertokens.Querier, config jwtmodels.Type
 				payload = map[string]interface{}{}
 			}
 
-			response, err := querier.SendPostRequest("/recipe/jwt", map[string]interface{}{
+			var response struct {
+				Status string `json:"status"`
+				Jwt    string `json:"jwt"`
+			}
+			err := querier.SendPostRequestAndDecode(ctx, "/recipe/jwt", map[string]interface{}{
 				"payload":    payload,
 				"validity":   validitySeconds,
 				"algorithm":  "RS256",
 				"jwksDomain": appInfo.APIDomain.GetAsStringDangerous(),
-			})
+			}, &response)
 			if err != nil {
 				return jwtmodels.CreateJWTResponse{}, err
 			}
 
-			status, ok := response["status"]
-			if ok && status == "OK" {
+			switch response.Status {
+			case "OK":
 				return jwtmodels.CreateJWTResponse{
 					OK: &struct{ Jwt string }{
-						Jwt: response["jwt"].(string),
+						Jwt: response.Jwt,
 					},
 				}, nil
-			} else {
+			case "UNSUPPORTED_ALGORITHM_ERROR":
 				return jwtmodels.CreateJWTResponse{
 					UnsupportedAlgorithmError: &struct{}{},
 				}, nil
+			default:
+				return jwtmodels.CreateJWTResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/jwt", response.Status)
 			}
 		},
-		GetJWKS: func() (jwtmodels.GetJWKSResponse, error) {
-			response, err := querier.SendGetRequest("/recipe/jwt/jwks", map[string]string{})
+		GetJWKS: func(ctx context.Context) (jwtmodels.GetJWKSResponse, error) {
+			var response struct {
+				Status string                  `json:"status"`
+				Keys   []jwtmodels.JsonWebKeys `json:"keys"`
+			}
+			err := querier.SendGetRequestAndDecode(ctx, "/recipe/jwt/jwks", map[string]string{}, &response)
 			if err != nil {
 				return jwtmodels.GetJWKSResponse{}, err
 			}
+			if response.Status != "OK" {
+				return jwtmodels.GetJWKSResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/jwt/jwks", response.Status)
+			}
 
-			keys := []jwtmodels.JsonWebKeys{}
-
-			for _, v := range response["keys"].([]interface{}) {
-				keys = append(keys, jwtmodels.JsonWebKeys{
-					Kty: (v.(map[string]interface{}))["kty"].(string),
-					Kid: (v.(map[string]interface{}))["kid"].(string),
-					N:   (v.(map[string]interface{}))["n"].(string),
-					E:   (v.(map[string]interface{}))["e"].(string),
-					Alg: (v.(map[string]interface{}))["alg"].(string),
-					Use: (v.(map[string]interface{}))["use"].(string),
-				})
+			keys := response.Keys
+			if keys == nil {
+				keys = []jwtmodels.JsonWebKeys{}
 			}
 
 			return jwtmodels.GetJWKSResponse{
@@ -0,0 +1,46 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func TestGetJWKSReturnsErrorsForUnexpectedCoreResponses(t *testing.T) {
+	core, instance, cleanup := coretest.NewInstance(t, Init(nil))
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	response, err := GetJWKSWithContext(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.OK)
+
+	for _, body := range []string{
+		`{"status":"OK","keys":"key"}`,
+		`{"status":"OK","keys":[{"kty":1}]}`,
+		`{"status":"UNKNOWN_ERROR"}`,
+		`not json`,
+	} {
+		core.OverrideResponse("GET /recipe/jwt/jwks", body)
+		assert.NotPanics(t, func() { _, err = GetJWKSWithContext(ctx) }, body)
+		assert.ErrorAs(t, err, &supertokens.UnexpectedCoreResponseError{}, body)
+	}
+}
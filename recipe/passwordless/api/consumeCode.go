@@ -0,0 +1,96 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type consumeCodeBody struct {
+	PreAuthSessionID string  `json:"preAuthSessionId"`
+	LinkCode         *string `json:"linkCode"`
+	DeviceID         *string `json:"deviceId"`
+	UserInputCode    *string `json:"userInputCode"`
+}
+
+func ConsumeCode(apiImplementation pwlmodels.APIInterface, options pwlmodels.APIOptions) error {
+	if apiImplementation.ConsumeCodePOST == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	var bodyParams consumeCodeBody
+	err = json.Unmarshal(body, &bodyParams)
+	if err != nil {
+		return err
+	}
+
+	if bodyParams.PreAuthSessionID == "" {
+		return supertokens.BadInputError{Msg: "Please provide the preAuthSessionId in request body"}
+	}
+
+	var userInput *pwlmodels.UserInputCodeWithDeviceID
+	if bodyParams.DeviceID != nil || bodyParams.UserInputCode != nil {
+		if bodyParams.LinkCode != nil {
+			return supertokens.BadInputError{Msg: "Please provide either the linkCode or the deviceId and userInputCode, but not both"}
+		}
+		if bodyParams.DeviceID == nil || bodyParams.UserInputCode == nil {
+			return supertokens.BadInputError{Msg: "Please provide both the deviceId and userInputCode in request body"}
+		}
+		userInput = &pwlmodels.UserInputCodeWithDeviceID{
+			Code:     *bodyParams.UserInputCode,
+			DeviceID: *bodyParams.DeviceID,
+		}
+	} else if bodyParams.LinkCode == nil {
+		return supertokens.BadInputError{Msg: "Please provide either the linkCode or the deviceId and userInputCode in request body"}
+	}
+
+	result, err := apiImplementation.ConsumeCodePOST(options.Req.Context(), userInput, bodyParams.LinkCode, bodyParams.PreAuthSessionID, options)
+	if err != nil {
+		return err
+	}
+
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":         "OK",
+			"createdNewUser": result.OK.CreatedNewUser,
+			"user":           result.OK.User,
+		})
+	} else if result.IncorrectUserInputCodeError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":                      "INCORRECT_USER_INPUT_CODE_ERROR",
+			"failedCodeInputAttemptCount": result.IncorrectUserInputCodeError.FailedCodeInputAttemptCount,
+			"maximumCodeInputAttempts":    result.IncorrectUserInputCodeError.MaximumCodeInputAttempts,
+		})
+	} else if result.ExpiredUserInputCodeError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":                      "EXPIRED_USER_INPUT_CODE_ERROR",
+			"failedCodeInputAttemptCount": result.ExpiredUserInputCodeError.FailedCodeInputAttemptCount,
+			"maximumCodeInputAttempts":    result.ExpiredUserInputCodeError.MaximumCodeInputAttempts,
+		})
+	}
+	return supertokens.Send200Response(options.Res, map[string]interface{}{
+		"status": "RESTART_FLOW_ERROR",
+	})
+}
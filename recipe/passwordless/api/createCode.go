@@ -0,0 +1,87 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type createCodeBody struct {
+	Email       *string `json:"email"`
+	PhoneNumber *string `json:"phoneNumber"`
+}
+
+func CreateCode(apiImplementation pwlmodels.APIInterface, options pwlmodels.APIOptions) error {
+	if apiImplementation.CreateCodePOST == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	var bodyParams createCodeBody
+	err = json.Unmarshal(body, &bodyParams)
+	if err != nil {
+		return err
+	}
+
+	if (bodyParams.Email == nil) == (bodyParams.PhoneNumber == nil) {
+		return supertokens.BadInputError{Msg: "Please provide exactly one of email or phoneNumber"}
+	}
+	contactMethod := options.Config.ContactMethod
+	if bodyParams.Email != nil && contactMethod == pwlmodels.ContactMethodPhone {
+		return supertokens.BadInputError{Msg: "Please provide a phoneNumber, since the contactMethod is PHONE"}
+	}
+	if bodyParams.PhoneNumber != nil && contactMethod == pwlmodels.ContactMethodEmail {
+		return supertokens.BadInputError{Msg: "Please provide an email, since the contactMethod is EMAIL"}
+	}
+
+	var validationError *string
+	if bodyParams.Email != nil {
+		email := strings.TrimSpace(*bodyParams.Email)
+		bodyParams.Email = &email
+		validationError = options.Config.ValidateEmailAddress(email)
+	} else {
+		phoneNumber := strings.TrimSpace(*bodyParams.PhoneNumber)
+		bodyParams.PhoneNumber = &phoneNumber
+		validationError = options.Config.ValidatePhoneNumber(phoneNumber)
+	}
+	if validationError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":  "GENERAL_ERROR",
+			"message": *validationError,
+		})
+	}
+
+	result, err := apiImplementation.CreateCodePOST(options.Req.Context(), bodyParams.Email, bodyParams.PhoneNumber, options)
+	if err != nil {
+		return err
+	}
+
+	return supertokens.Send200Response(options.Res, map[string]interface{}{
+		"status":           "OK",
+		"deviceId":         result.OK.DeviceID,
+		"preAuthSessionId": result.OK.PreAuthSessionID,
+		"flowType":         result.OK.FlowType,
+	})
+}
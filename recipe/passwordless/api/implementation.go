@@ -0,0 +1,182 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+)
+
+// maxResendAttempts limits how often a new code is generated when a custom user input code
+// collides with one that the device already used
+const maxResendAttempts = 3
+
+func MakeAPIImplementation() pwlmodels.APIInterface {
+	return pwlmodels.APIInterface{
+		CreateCodePOST: func(ctx context.Context, email *string, phoneNumber *string, options pwlmodels.APIOptions) (pwlmodels.CreateCodePOSTResponse, error) {
+			userInputCode, err := getCustomUserInputCode(ctx, options)
+			if err != nil {
+				return pwlmodels.CreateCodePOSTResponse{}, err
+			}
+			response, err := options.RecipeImplementation.CreateCode(ctx, email, phoneNumber, userInputCode)
+			if err != nil {
+				return pwlmodels.CreateCodePOSTResponse{}, err
+			}
+			err = sendCode(ctx, *response.OK, email, phoneNumber, options)
+			if err != nil {
+				return pwlmodels.CreateCodePOSTResponse{}, err
+			}
+			return pwlmodels.CreateCodePOSTResponse{
+				OK: &struct {
+					DeviceID         string
+					PreAuthSessionID string
+					FlowType         string
+				}{
+					DeviceID:         response.OK.DeviceID,
+					PreAuthSessionID: response.OK.PreAuthSessionID,
+					FlowType:         options.Config.FlowType,
+				},
+			}, nil
+		},
+
+		ResendCodePOST: func(ctx context.Context, deviceID string, preAuthSessionID string, options pwlmodels.APIOptions) (pwlmodels.ResendCodePOSTResponse, error) {
+			restartFlowResponse := pwlmodels.ResendCodePOSTResponse{
+				RestartFlowError: &struct{}{},
+			}
+			device, err := options.RecipeImplementation.ListCodesByDeviceID(ctx, deviceID)
+			if err != nil {
+				return pwlmodels.ResendCodePOSTResponse{}, err
+			}
+			if device == nil || device.PreAuthSessionID != preAuthSessionID {
+				return restartFlowResponse, nil
+			}
+
+			for attempt := 0; attempt < maxResendAttempts; attempt++ {
+				userInputCode, err := getCustomUserInputCode(ctx, options)
+				if err != nil {
+					return pwlmodels.ResendCodePOSTResponse{}, err
+				}
+				response, err := options.RecipeImplementation.CreateNewCodeForDevice(ctx, deviceID, userInputCode)
+				if err != nil {
+					return pwlmodels.ResendCodePOSTResponse{}, err
+				}
+				if response.RestartFlowError != nil {
+					return restartFlowResponse, nil
+				}
+				if response.UserInputCodeAlreadyUsedError != nil {
+					continue
+				}
+				err = sendCode(ctx, *response.OK, device.Email, device.PhoneNumber, options)
+				if err != nil {
+					return pwlmodels.ResendCodePOSTResponse{}, err
+				}
+				return pwlmodels.ResendCodePOSTResponse{
+					OK: &struct{}{},
+				}, nil
+			}
+			return pwlmodels.ResendCodePOSTResponse{}, errors.New("failed to generate a user input code that the device has not used yet")
+		},
+
+		ConsumeCodePOST: func(ctx context.Context, userInput *pwlmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, options pwlmodels.APIOptions) (pwlmodels.ConsumeCodePOSTResponse, error) {
+			response, err := options.RecipeImplementation.ConsumeCode(ctx, userInput, linkCode, preAuthSessionID)
+			if err != nil {
+				return pwlmodels.ConsumeCodePOSTResponse{}, err
+			}
+			if response.OK == nil {
+				return pwlmodels.ConsumeCodePOSTResponse{
+					IncorrectUserInputCodeError: response.IncorrectUserInputCodeError,
+					ExpiredUserInputCodeError:   response.ExpiredUserInputCodeError,
+					RestartFlowError:            response.RestartFlowError,
+				}, nil
+			}
+
+			_, err = session.CreateNewSessionWithContext(ctx, options.Req, options.Res, response.OK.User.ID, nil, nil)
+			if err != nil {
+				return pwlmodels.ConsumeCodePOSTResponse{}, err
+			}
+			return pwlmodels.ConsumeCodePOSTResponse{
+				OK: &struct {
+					CreatedNewUser bool
+					User           pwlmodels.User
+				}{
+					CreatedNewUser: response.OK.CreatedNewUser,
+					User:           response.OK.User,
+				},
+			}, nil
+		},
+	}
+}
+
+func getCustomUserInputCode(ctx context.Context, options pwlmodels.APIOptions) (*string, error) {
+	if options.Config.GetCustomUserInputCode == nil {
+		return nil, nil
+	}
+	userInputCode, err := options.Config.GetCustomUserInputCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &userInputCode, nil
+}
+
+// sendCode sends the user input code, the magic link, or both (depending on the flow type) to the
+// email or phone number of a device
+func sendCode(ctx context.Context, code pwlmodels.NewCode, email *string, phoneNumber *string, options pwlmodels.APIOptions) error {
+	var userInputCode *string
+	if options.Config.FlowType != pwlmodels.FlowTypeMagicLink {
+		userInputCode = &code.UserInputCode
+	}
+	var urlWithLinkCode *string
+	if options.Config.FlowType != pwlmodels.FlowTypeUserInputCode {
+		linkDomainAndPath, err := options.Config.GetLinkDomainAndPath(email, phoneNumber)
+		if err != nil {
+			return err
+		}
+		link := linkDomainAndPath + "?rid=" + options.RecipeID + "&preAuthSessionId=" + url.QueryEscape(code.PreAuthSessionID) + "#" + code.LinkCode
+		urlWithLinkCode = &link
+	}
+
+	if email == nil {
+		return options.Config.SendTextMessage(ctx, pwlmodels.TextMessageInput{
+			PhoneNumber:      *phoneNumber,
+			UserInputCode:    userInputCode,
+			URLWithLinkCode:  urlWithLinkCode,
+			PreAuthSessionID: code.PreAuthSessionID,
+			CodeLifetime:     code.CodeLifetime,
+		})
+	}
+
+	passwordlessLogin := emaildelivery.PasswordlessLoginType{
+		Email:            *email,
+		AppName:          options.AppInfo.AppName,
+		UserInputCode:    userInputCode,
+		URLWithLinkCode:  urlWithLinkCode,
+		PreAuthSessionID: code.PreAuthSessionID,
+		CodeLifetime:     code.CodeLifetime,
+	}
+	content, err := options.Config.EmailTemplates.RenderPasswordlessLogin(options.Req, emaildelivery.GetPasswordlessLoginTemplateData(passwordlessLogin))
+	if err != nil {
+		return err
+	}
+	passwordlessLogin.Content = &content
+	return options.Config.EmailDelivery.SendEmail(ctx, emaildelivery.EmailType{
+		PasswordlessLogin: &passwordlessLogin,
+	})
+}
@@ -0,0 +1,68 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type resendCodeBody struct {
+	DeviceID         string `json:"deviceId"`
+	PreAuthSessionID string `json:"preAuthSessionId"`
+}
+
+func ResendCode(apiImplementation pwlmodels.APIInterface, options pwlmodels.APIOptions) error {
+	if apiImplementation.ResendCodePOST == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	var bodyParams resendCodeBody
+	err = json.Unmarshal(body, &bodyParams)
+	if err != nil {
+		return err
+	}
+
+	if bodyParams.DeviceID == "" {
+		return supertokens.BadInputError{Msg: "Please provide the deviceId in request body"}
+	}
+
+	if bodyParams.PreAuthSessionID == "" {
+		return supertokens.BadInputError{Msg: "Please provide the preAuthSessionId in request body"}
+	}
+
+	result, err := apiImplementation.ResendCodePOST(options.Req.Context(), bodyParams.DeviceID, bodyParams.PreAuthSessionID, options)
+	if err != nil {
+		return err
+	}
+
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	}
+	return supertokens.Send200Response(options.Res, map[string]interface{}{
+		"status": "RESTART_FLOW_ERROR",
+	})
+}
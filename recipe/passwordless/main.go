@@ -0,0 +1,159 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"context"
+
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func Init(config *pwlmodels.TypeInput) supertokens.Recipe {
+	return recipeInit(config)
+}
+
+func CreateCode(email *string, phoneNumber *string, userInputCode *string) (pwlmodels.CreateCodeResponse, error) {
+	return CreateCodeWithContext(context.Background(), email, phoneNumber, userInputCode)
+}
+
+func CreateCodeWithContext(ctx context.Context, email *string, phoneNumber *string, userInputCode *string) (pwlmodels.CreateCodeResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return pwlmodels.CreateCodeResponse{}, err
+	}
+	return instance.RecipeImpl.CreateCode(ctx, email, phoneNumber, userInputCode)
+}
+
+func CreateNewCodeForDevice(deviceID string, userInputCode *string) (pwlmodels.CreateNewCodeForDeviceResponse, error) {
+	return CreateNewCodeForDeviceWithContext(context.Background(), deviceID, userInputCode)
+}
+
+func CreateNewCodeForDeviceWithContext(ctx context.Context, deviceID string, userInputCode *string) (pwlmodels.CreateNewCodeForDeviceResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return pwlmodels.CreateNewCodeForDeviceResponse{}, err
+	}
+	return instance.RecipeImpl.CreateNewCodeForDevice(ctx, deviceID, userInputCode)
+}
+
+func ConsumeCode(userInput *pwlmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string) (pwlmodels.ConsumeCodeResponse, error) {
+	return ConsumeCodeWithContext(context.Background(), userInput, linkCode, preAuthSessionID)
+}
+
+func ConsumeCodeWithContext(ctx context.Context, userInput *pwlmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string) (pwlmodels.ConsumeCodeResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return pwlmodels.ConsumeCodeResponse{}, err
+	}
+	return instance.RecipeImpl.ConsumeCode(ctx, userInput, linkCode, preAuthSessionID)
+}
+
+func GetUserByID(userID string) (*pwlmodels.User, error) {
+	return GetUserByIDWithContext(context.Background(), userID)
+}
+
+func GetUserByIDWithContext(ctx context.Context, userID string) (*pwlmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instance.RecipeImpl.GetUserByID(ctx, userID)
+}
+
+func GetUserByEmail(email string) (*pwlmodels.User, error) {
+	return GetUserByEmailWithContext(context.Background(), email)
+}
+
+func GetUserByEmailWithContext(ctx context.Context, email string) (*pwlmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instance.RecipeImpl.GetUserByEmail(ctx, email)
+}
+
+func GetUserByPhoneNumber(phoneNumber string) (*pwlmodels.User, error) {
+	return GetUserByPhoneNumberWithContext(context.Background(), phoneNumber)
+}
+
+func GetUserByPhoneNumberWithContext(ctx context.Context, phoneNumber string) (*pwlmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instance.RecipeImpl.GetUserByPhoneNumber(ctx, phoneNumber)
+}
+
+func UpdateUser(userID string, email *string, phoneNumber *string) (pwlmodels.UpdateUserResponse, error) {
+	return UpdateUserWithContext(context.Background(), userID, email, phoneNumber)
+}
+
+func UpdateUserWithContext(ctx context.Context, userID string, email *string, phoneNumber *string) (pwlmodels.UpdateUserResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return pwlmodels.UpdateUserResponse{}, err
+	}
+	return instance.RecipeImpl.UpdateUser(ctx, userID, email, phoneNumber)
+}
+
+func RevokeAllCodes(email *string, phoneNumber *string) error {
+	return RevokeAllCodesWithContext(context.Background(), email, phoneNumber)
+}
+
+func RevokeAllCodesWithContext(ctx context.Context, email *string, phoneNumber *string) error {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return err
+	}
+	return instance.RecipeImpl.RevokeAllCodes(ctx, email, phoneNumber)
+}
+
+func RevokeCode(codeID string) error {
+	return RevokeCodeWithContext(context.Background(), codeID)
+}
+
+func RevokeCodeWithContext(ctx context.Context, codeID string) error {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return err
+	}
+	return instance.RecipeImpl.RevokeCode(ctx, codeID)
+}
+
+func ListCodesByDeviceID(deviceID string) (*pwlmodels.DeviceType, error) {
+	return ListCodesByDeviceIDWithContext(context.Background(), deviceID)
+}
+
+func ListCodesByDeviceIDWithContext(ctx context.Context, deviceID string) (*pwlmodels.DeviceType, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instance.RecipeImpl.ListCodesByDeviceID(ctx, deviceID)
+}
+
+func ListCodesByPreAuthSessionID(preAuthSessionID string) (*pwlmodels.DeviceType, error) {
+	return ListCodesByPreAuthSessionIDWithContext(context.Background(), preAuthSessionID)
+}
+
+func ListCodesByPreAuthSessionIDWithContext(ctx context.Context, preAuthSessionID string) (*pwlmodels.DeviceType, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instance.RecipeImpl.ListCodesByPreAuthSessionID(ctx, preAuthSessionID)
+}
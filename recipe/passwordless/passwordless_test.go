@@ -0,0 +1,215 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func setUpPasswordless(t *testing.T, config *pwlmodels.TypeInput) (http.Handler, func()) {
+	_, instance, cleanup := coretest.NewInstance(t, Init(config), session.Init(nil))
+	return instance.Middleware(http.NotFoundHandler()), cleanup
+}
+
+func postJSON(t *testing.T, handler http.Handler, path string, body string) (map[string]interface{}, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &response))
+	return response, res
+}
+
+func TestPasswordlessUserInputCodeFlow(t *testing.T) {
+	var emails []emaildelivery.PasswordlessLoginType
+	emailDelivery := emaildelivery.EmailDeliveryInterface{
+		SendEmail: func(ctx context.Context, input emaildelivery.EmailType) error {
+			emails = append(emails, *input.PasswordlessLogin)
+			return nil
+		},
+	}
+	handler, closeCore := setUpPasswordless(t, &pwlmodels.TypeInput{
+		ContactMethod:        pwlmodels.ContactMethodEmail,
+		FlowType:             pwlmodels.FlowTypeUserInputCode,
+		EmailDelivery:        &emailDelivery,
+		MaxCodeInputAttempts: 3,
+	})
+	defer closeCore()
+
+	response, _ := postJSON(t, handler, "/auth/signinup/code", `{"email":"invalid"}`)
+	assert.Equal(t, "GENERAL_ERROR", response["status"])
+	assert.Equal(t, "Email is invalid", response["message"])
+
+	_, res := postJSON(t, handler, "/auth/signinup/code", `{"phoneNumber":"+14155552671"}`)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	response, _ = postJSON(t, handler, "/auth/signinup/code", `{"email":" user@example.com "}`)
+	assert.Equal(t, "OK", response["status"])
+	assert.Equal(t, pwlmodels.FlowTypeUserInputCode, response["flowType"])
+	deviceID := response["deviceId"].(string)
+	preAuthSessionID := response["preAuthSessionId"].(string)
+	assert.Len(t, emails, 1)
+	assert.Equal(t, "user@example.com", emails[0].Email)
+	assert.Nil(t, emails[0].URLWithLinkCode)
+	assert.Equal(t, uint64(15*60*1000), emails[0].CodeLifetime)
+
+	response, _ = postJSON(t, handler, "/auth/signinup/code/resend", `{"deviceId":"`+deviceID+`","preAuthSessionId":"wrong"}`)
+	assert.Equal(t, "RESTART_FLOW_ERROR", response["status"])
+	response, _ = postJSON(t, handler, "/auth/signinup/code/resend", `{"deviceId":"`+deviceID+`","preAuthSessionId":"`+preAuthSessionID+`"}`)
+	assert.Equal(t, "OK", response["status"])
+	assert.Len(t, emails, 2)
+
+	consume := func(code string) (map[string]interface{}, *httptest.ResponseRecorder) {
+		return postJSON(t, handler, "/auth/signinup/code/consume", `{"deviceId":"`+deviceID+`","preAuthSessionId":"`+preAuthSessionID+`","userInputCode":"`+code+`"}`)
+	}
+
+	// the code that was resent replaces the first one
+	if *emails[0].UserInputCode != *emails[1].UserInputCode {
+		response, _ = consume(*emails[0].UserInputCode)
+		assert.Equal(t, "INCORRECT_USER_INPUT_CODE_ERROR", response["status"])
+		assert.Equal(t, float64(1), response["failedCodeInputAttemptCount"])
+		assert.Equal(t, float64(3), response["maximumCodeInputAttempts"])
+	}
+
+	response, res = consume(*emails[1].UserInputCode)
+	assert.Equal(t, "OK", response["status"])
+	assert.Equal(t, true, response["createdNewUser"])
+	assert.Equal(t, "user@example.com", response["user"].(map[string]interface{})["email"])
+	assert.NotEmpty(t, res.Header().Get("Set-Cookie"))
+
+	// the device is gone once it has been used
+	response, _ = consume(*emails[1].UserInputCode)
+	assert.Equal(t, "RESTART_FLOW_ERROR", response["status"])
+
+	// too many wrong codes restart the flow, with the limit of the config
+	response, _ = postJSON(t, handler, "/auth/signinup/code", `{"email":"user@example.com"}`)
+	deviceID = response["deviceId"].(string)
+	preAuthSessionID = response["preAuthSessionId"].(string)
+	for i := 1; i < 3; i++ {
+		response, _ = consume("wrong")
+		assert.Equal(t, "INCORRECT_USER_INPUT_CODE_ERROR", response["status"])
+		assert.Equal(t, float64(i), response["failedCodeInputAttemptCount"])
+	}
+	response, _ = consume("wrong")
+	assert.Equal(t, "RESTART_FLOW_ERROR", response["status"])
+	response, _ = consume(*emails[len(emails)-1].UserInputCode)
+	assert.Equal(t, "RESTART_FLOW_ERROR", response["status"])
+}
+
+func TestPasswordlessMagicLinkWithPhoneNumber(t *testing.T) {
+	var textMessages []pwlmodels.TextMessageInput
+	handler, closeCore := setUpPasswordless(t, &pwlmodels.TypeInput{
+		ContactMethod: pwlmodels.ContactMethodEmailOrPhone,
+		FlowType:      pwlmodels.FlowTypeMagicLink,
+		SendTextMessage: func(ctx context.Context, input pwlmodels.TextMessageInput) error {
+			textMessages = append(textMessages, input)
+			return nil
+		},
+	})
+	defer closeCore()
+
+	response, _ := postJSON(t, handler, "/auth/signinup/code", `{"phoneNumber":"4155552671"}`)
+	assert.Equal(t, "GENERAL_ERROR", response["status"])
+
+	response, _ = postJSON(t, handler, "/auth/signinup/code", `{"phoneNumber":"+14155552671"}`)
+	assert.Equal(t, "OK", response["status"])
+	assert.Len(t, textMessages, 1)
+	assert.Nil(t, textMessages[0].UserInputCode)
+
+	link, err := url.Parse(*textMessages[0].URLWithLinkCode)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:3000/auth/verify", link.Scheme+"://"+link.Host+link.Path)
+	assert.Equal(t, "passwordless", link.Query().Get("rid"))
+	assert.Equal(t, response["preAuthSessionId"], link.Query().Get("preAuthSessionId"))
+
+	response, _ = postJSON(t, handler, "/auth/signinup/code/consume", `{"preAuthSessionId":"`+link.Query().Get("preAuthSessionId")+`","linkCode":"wrong"}`)
+	assert.Equal(t, "RESTART_FLOW_ERROR", response["status"])
+}
+
+func TestPasswordlessConfigIsValidated(t *testing.T) {
+	for _, config := range []*pwlmodels.TypeInput{
+		nil,
+		{ContactMethod: "SMS", FlowType: pwlmodels.FlowTypeMagicLink},
+		{ContactMethod: pwlmodels.ContactMethodEmail, FlowType: "CODE"},
+		{ContactMethod: pwlmodels.ContactMethodPhone, FlowType: pwlmodels.FlowTypeMagicLink},
+	} {
+		_, err := validateAndNormaliseUserInput(supertokens.NormalisedAppinfo{}, config)
+		assert.Error(t, err)
+	}
+}
+
+func TestListCodes(t *testing.T) {
+	_, instance, cleanup := coretest.NewInstance(t, Init(&pwlmodels.TypeInput{
+		ContactMethod: pwlmodels.ContactMethodEmail,
+		FlowType:      pwlmodels.FlowTypeUserInputCode,
+		EmailDelivery: &emaildelivery.EmailDeliveryInterface{
+			SendEmail: func(ctx context.Context, input emaildelivery.EmailType) error {
+				return nil
+			},
+		},
+	}), session.Init(nil))
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	email := "user@example.com"
+	response, err := CreateCodeWithContext(ctx, &email, nil, nil)
+	assert.NoError(t, err)
+
+	device, err := ListCodesByDeviceIDWithContext(ctx, response.OK.DeviceID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, device) {
+		assert.Equal(t, response.OK.PreAuthSessionID, device.PreAuthSessionID)
+		assert.Equal(t, email, *device.Email)
+		assert.Len(t, device.Codes, 1)
+	}
+	device, err = ListCodesByPreAuthSessionIDWithContext(ctx, response.OK.PreAuthSessionID)
+	assert.NoError(t, err)
+	assert.NotNil(t, device)
+
+	device, err = ListCodesByDeviceIDWithContext(ctx, "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, device)
+}
+
+func TestPasswordlessNeedsCDI2_11(t *testing.T) {
+	core := coretest.New(nil)
+	defer core.Close()
+	core.OverrideResponse("GET /apiversion", `{"versions":["2.8","2.9"]}`)
+	instance := core.NewInstance(t, Init(&pwlmodels.TypeInput{
+		ContactMethod: pwlmodels.ContactMethodEmail,
+		FlowType:      pwlmodels.FlowTypeUserInputCode,
+	}), session.Init(nil))
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	email := "user@example.com"
+	_, err := CreateCodeWithContext(ctx, &email, nil, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "CDI 2.11")
+	}
+}
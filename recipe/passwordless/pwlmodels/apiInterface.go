@@ -0,0 +1,62 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package pwlmodels
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type APIOptions struct {
+	RecipeImplementation RecipeInterface
+	Config               TypeNormalisedInput
+	RecipeID             string
+	AppInfo              supertokens.NormalisedAppinfo
+	Req                  *http.Request
+	Res                  http.ResponseWriter
+	OtherHandler         http.HandlerFunc
+}
+
+type APIInterface struct {
+	CreateCodePOST  func(ctx context.Context, email *string, phoneNumber *string, options APIOptions) (CreateCodePOSTResponse, error)
+	ResendCodePOST  func(ctx context.Context, deviceID string, preAuthSessionID string, options APIOptions) (ResendCodePOSTResponse, error)
+	ConsumeCodePOST func(ctx context.Context, userInput *UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, options APIOptions) (ConsumeCodePOSTResponse, error)
+}
+
+type CreateCodePOSTResponse struct {
+	OK *struct {
+		DeviceID         string
+		PreAuthSessionID string
+		FlowType         string
+	}
+}
+
+type ResendCodePOSTResponse struct {
+	OK               *struct{}
+	RestartFlowError *struct{}
+}
+
+type ConsumeCodePOSTResponse struct {
+	OK *struct {
+		CreatedNewUser bool
+		User           User
+	}
+	IncorrectUserInputCodeError *CodeInputAttempts
+	ExpiredUserInputCodeError   *CodeInputAttempts
+	RestartFlowError            *struct{}
+}
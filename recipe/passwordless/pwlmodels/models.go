@@ -0,0 +1,120 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package pwlmodels
+
+import (
+	"context"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+)
+
+const (
+	ContactMethodEmail        = "EMAIL"
+	ContactMethodPhone        = "PHONE"
+	ContactMethodEmailOrPhone = "EMAIL_OR_PHONE"
+
+	// FlowTypeUserInputCode sends a code that the user types in on the device they started the login
+	// on, and FlowTypeMagicLink a link that logs them in on the device they open it on
+	FlowTypeUserInputCode             = "USER_INPUT_CODE"
+	FlowTypeMagicLink                 = "MAGIC_LINK"
+	FlowTypeUserInputCodeAndMagicLink = "USER_INPUT_CODE_AND_MAGIC_LINK"
+)
+
+type User struct {
+	ID          string  `json:"id"`
+	Email       *string `json:"email,omitempty"`
+	PhoneNumber *string `json:"phoneNumber,omitempty"`
+	TimeJoined  uint64  `json:"timeJoined"`
+}
+
+// DeviceType is a login attempt of an email or phone number, with the codes created for it
+type DeviceType struct {
+	PreAuthSessionID            string  `json:"preAuthSessionId"`
+	FailedCodeInputAttemptCount int     `json:"failedCodeInputAttemptCount"`
+	Email                       *string `json:"email,omitempty"`
+	PhoneNumber                 *string `json:"phoneNumber,omitempty"`
+	Codes                       []Code  `json:"codes"`
+}
+
+type Code struct {
+	CodeID      string `json:"codeId"`
+	TimeCreated uint64 `json:"timeCreated"`
+	// CodeLifetime is in milliseconds
+	CodeLifetime uint64 `json:"codeLifetime"`
+}
+
+type UserInputCodeWithDeviceID struct {
+	Code     string
+	DeviceID string
+}
+
+// TextMessageInput has the UserInputCode, the URLWithLinkCode, or both, depending on the flow type
+type TextMessageInput struct {
+	PhoneNumber      string
+	UserInputCode    *string
+	URLWithLinkCode  *string
+	PreAuthSessionID string
+	// CodeLifetime is in milliseconds
+	CodeLifetime uint64
+}
+
+type TypeInput struct {
+	// ContactMethod is ContactMethodEmail, ContactMethodPhone or ContactMethodEmailOrPhone
+	ContactMethod string
+	// FlowType is FlowTypeUserInputCode, FlowTypeMagicLink or FlowTypeUserInputCodeAndMagicLink
+	FlowType string
+	// ValidateEmailAddress returns an error message if the email is invalid. It defaults to a format check.
+	ValidateEmailAddress func(email string) *string
+	// ValidatePhoneNumber defaults to checking that the number is in the E.164 format (like +14155552671)
+	ValidatePhoneNumber func(phoneNumber string) *string
+	EmailDelivery       *emaildelivery.EmailDeliveryInterface
+	EmailTemplates      *emaildelivery.TemplatesInput
+	// SendTextMessage sends the code to a phone number. It is required if the contact method allows phone numbers.
+	SendTextMessage func(ctx context.Context, input TextMessageInput) error
+	// GetLinkDomainAndPath returns the page of the website that magic links open, which needs to call
+	// the consume code API. It defaults to WebsiteDomain + WebsiteBasePath + "/verify".
+	GetLinkDomainAndPath func(email *string, phoneNumber *string) (string, error)
+	// GetCustomUserInputCode generates the codes that users type in. By default, the core generates
+	// codes of 6 digits.
+	GetCustomUserInputCode func(ctx context.Context) (string, error)
+	// CodeLifetime defaults to 15 minutes, and MaxCodeInputAttempts to 5. After too many wrong codes,
+	// the user has to start the login again. The core's limits apply too, so these can only make
+	// them stricter.
+	CodeLifetime         time.Duration
+	MaxCodeInputAttempts int
+	Override             *OverrideStruct
+}
+
+type TypeNormalisedInput struct {
+	ContactMethod          string
+	FlowType               string
+	ValidateEmailAddress   func(email string) *string
+	ValidatePhoneNumber    func(phoneNumber string) *string
+	EmailDelivery          emaildelivery.EmailDeliveryInterface
+	EmailTemplates         *emaildelivery.Templates
+	SendTextMessage        func(ctx context.Context, input TextMessageInput) error
+	GetLinkDomainAndPath   func(email *string, phoneNumber *string) (string, error)
+	GetCustomUserInputCode func(ctx context.Context) (string, error)
+	CodeLifetime           time.Duration
+	MaxCodeInputAttempts   int
+	Override               OverrideStruct
+}
+
+type OverrideStruct struct {
+	Functions func(originalImplementation RecipeInterface) RecipeInterface
+	APIs      func(originalImplementation APIInterface) APIInterface
+}
@@ -0,0 +1,78 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package pwlmodels
+
+import "context"
+
+type RecipeInterface struct {
+	CreateCode                  func(ctx context.Context, email *string, phoneNumber *string, userInputCode *string) (CreateCodeResponse, error)
+	CreateNewCodeForDevice      func(ctx context.Context, deviceID string, userInputCode *string) (CreateNewCodeForDeviceResponse, error)
+	ConsumeCode                 func(ctx context.Context, userInput *UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string) (ConsumeCodeResponse, error)
+	GetUserByID                 func(ctx context.Context, userID string) (*User, error)
+	GetUserByEmail              func(ctx context.Context, email string) (*User, error)
+	GetUserByPhoneNumber        func(ctx context.Context, phoneNumber string) (*User, error)
+	UpdateUser                  func(ctx context.Context, userID string, email *string, phoneNumber *string) (UpdateUserResponse, error)
+	RevokeAllCodes              func(ctx context.Context, email *string, phoneNumber *string) error
+	RevokeCode                  func(ctx context.Context, codeID string) error
+	ListCodesByDeviceID         func(ctx context.Context, deviceID string) (*DeviceType, error)
+	ListCodesByPreAuthSessionID func(ctx context.Context, preAuthSessionID string) (*DeviceType, error)
+}
+
+type NewCode struct {
+	PreAuthSessionID string
+	CodeID           string
+	DeviceID         string
+	UserInputCode    string
+	LinkCode         string
+	TimeCreated      uint64
+	// CodeLifetime is in milliseconds
+	CodeLifetime uint64
+}
+
+type CreateCodeResponse struct {
+	OK *NewCode
+}
+
+type CreateNewCodeForDeviceResponse struct {
+	OK *NewCode
+	// RestartFlowError is returned if the device does not exist (anymore)
+	RestartFlowError              *struct{}
+	UserInputCodeAlreadyUsedError *struct{}
+}
+
+type CodeInputAttempts struct {
+	FailedCodeInputAttemptCount int
+	MaximumCodeInputAttempts    int
+}
+
+type ConsumeCodeResponse struct {
+	OK *struct {
+		CreatedNewUser bool
+		User           User
+	}
+	IncorrectUserInputCodeError *CodeInputAttempts
+	ExpiredUserInputCodeError   *CodeInputAttempts
+	// RestartFlowError is returned if the device does not exist, the link code is wrong or has
+	// expired, or there were too many wrong codes
+	RestartFlowError *struct{}
+}
+
+type UpdateUserResponse struct {
+	OK                            *struct{}
+	UnknownUserIdError            *struct{}
+	EmailAlreadyExistsError       *struct{}
+	PhoneNumberAlreadyExistsError *struct{}
+}
@@ -0,0 +1,140 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/api"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const RECIPE_ID = "passwordless"
+
+type Recipe struct {
+	RecipeModule supertokens.RecipeModule
+	Config       pwlmodels.TypeNormalisedInput
+	RecipeImpl   pwlmodels.RecipeInterface
+	APIImpl      pwlmodels.APIInterface
+}
+
+func MakeRecipe(recipeId string, instance *supertokens.SuperTokens, config *pwlmodels.TypeInput) (Recipe, error) {
+	appInfo := instance.AppInfo
+	onGeneralError := instance.OnGeneralError
+	r := &Recipe{}
+
+	r.RecipeModule = supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, r.handleError, onGeneralError)
+
+	querierInstance, err := instance.GetNewQuerierInstanceOrThrowError(recipeId)
+	if err != nil {
+		return Recipe{}, err
+	}
+	verifiedConfig, err := validateAndNormaliseUserInput(appInfo, config)
+	if err != nil {
+		return Recipe{}, err
+	}
+	r.Config = verifiedConfig
+	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
+	r.RecipeImpl = verifiedConfig.Override.Functions(MakeRecipeImplementation(*querierInstance, verifiedConfig))
+
+	return *r, nil
+}
+
+func recipeInit(config *pwlmodels.TypeInput) supertokens.Recipe {
+	return func(instance *supertokens.SuperTokens) (*supertokens.RecipeModule, error) {
+		if instance.GetRecipeInstance(RECIPE_ID) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, instance, config)
+			if err != nil {
+				return nil, err
+			}
+			instance.AddRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
+		}
+		return nil, errors.New("Passwordless recipe has already been initialised. Please check your code for bugs.")
+	}
+}
+
+func getRecipeInstanceOrThrowError(ctx context.Context) (*Recipe, error) {
+	instance, err := supertokens.GetInstanceOrThrowError(ctx)
+	if err == nil {
+		if recipe, ok := instance.GetRecipeInstance(RECIPE_ID).(*Recipe); ok {
+			return recipe, nil
+		}
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+// implement RecipeModule
+
+func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
+	createCodeAPI, err := supertokens.NewNormalisedURLPath(CreateCodeAPI)
+	if err != nil {
+		return nil, err
+	}
+	resendCodeAPI, err := supertokens.NewNormalisedURLPath(ResendCodeAPI)
+	if err != nil {
+		return nil, err
+	}
+	consumeCodeAPI, err := supertokens.NewNormalisedURLPath(ConsumeCodeAPI)
+	if err != nil {
+		return nil, err
+	}
+	return []supertokens.APIHandled{{
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: createCodeAPI,
+		ID:                     CreateCodeAPI,
+		Disabled:               r.APIImpl.CreateCodePOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: resendCodeAPI,
+		ID:                     ResendCodeAPI,
+		Disabled:               r.APIImpl.ResendCodePOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: consumeCodeAPI,
+		ID:                     ConsumeCodeAPI,
+		Disabled:               r.APIImpl.ConsumeCodePOST == nil,
+	}}, nil
+}
+
+func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, _ supertokens.NormalisedURLPath, _ string) error {
+	options := pwlmodels.APIOptions{
+		Config:               r.Config,
+		OtherHandler:         theirHandler,
+		RecipeID:             r.RecipeModule.GetRecipeID(),
+		AppInfo:              r.RecipeModule.GetAppInfo(),
+		RecipeImplementation: r.RecipeImpl,
+		Req:                  req,
+		Res:                  res,
+	}
+	if id == CreateCodeAPI {
+		return api.CreateCode(r.APIImpl, options)
+	} else if id == ResendCodeAPI {
+		return api.ResendCode(r.APIImpl, options)
+	}
+	return api.ConsumeCode(r.APIImpl, options)
+}
+
+func (r *Recipe) getAllCORSHeaders() []string {
+	return []string{}
+}
+
+func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,391 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"context"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type codeResponse struct {
+	Status           string `json:"status"`
+	PreAuthSessionID string `json:"preAuthSessionId"`
+	CodeID           string `json:"codeId"`
+	DeviceID         string `json:"deviceId"`
+	UserInputCode    string `json:"userInputCode"`
+	LinkCode         string `json:"linkCode"`
+	TimeCreated      uint64 `json:"timeCreated"`
+	CodeLifetime     uint64 `json:"codeLifetime"`
+}
+
+// MakeRecipeImplementation enforces the CodeLifetime and MaxCodeInputAttempts of the config on
+// top of the core's limits. A code created for an existing device replaces its other codes, so that
+// every device has a single code whose age can be checked.
+func MakeRecipeImplementation(querier supertokens.Querier, config pwlmodels.TypeNormalisedInput) pwlmodels.RecipeInterface {
+	codeLifetime := uint64(config.CodeLifetime / time.Millisecond)
+
+	getNewCode := func(response codeResponse) *pwlmodels.NewCode {
+		if response.CodeLifetime > codeLifetime {
+			response.CodeLifetime = codeLifetime
+		}
+		return &pwlmodels.NewCode{
+			PreAuthSessionID: response.PreAuthSessionID,
+			CodeID:           response.CodeID,
+			DeviceID:         response.DeviceID,
+			UserInputCode:    response.UserInputCode,
+			LinkCode:         response.LinkCode,
+			TimeCreated:      response.TimeCreated,
+			CodeLifetime:     response.CodeLifetime,
+		}
+	}
+
+	var recipeImplementation pwlmodels.RecipeInterface
+	recipeImplementation = pwlmodels.RecipeInterface{
+		CreateCode: func(ctx context.Context, email, phoneNumber, userInputCode *string) (pwlmodels.CreateCodeResponse, error) {
+			body := map[string]interface{}{}
+			if email != nil {
+				body["email"] = *email
+			}
+			if phoneNumber != nil {
+				body["phoneNumber"] = *phoneNumber
+			}
+			if userInputCode != nil {
+				body["userInputCode"] = *userInputCode
+			}
+			var response codeResponse
+			err := sendPostRequest(ctx, querier, "/recipe/signinup/code", body, &response)
+			if err != nil {
+				return pwlmodels.CreateCodeResponse{}, err
+			}
+			if response.Status != "OK" {
+				return pwlmodels.CreateCodeResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/signinup/code", response.Status)
+			}
+			return pwlmodels.CreateCodeResponse{
+				OK: getNewCode(response),
+			}, nil
+		},
+
+		CreateNewCodeForDevice: func(ctx context.Context, deviceID string, userInputCode *string) (pwlmodels.CreateNewCodeForDeviceResponse, error) {
+			device, err := recipeImplementation.ListCodesByDeviceID(ctx, deviceID)
+			if err != nil {
+				return pwlmodels.CreateNewCodeForDeviceResponse{}, err
+			}
+			if device == nil {
+				return pwlmodels.CreateNewCodeForDeviceResponse{
+					RestartFlowError: &struct{}{},
+				}, nil
+			}
+
+			body := map[string]interface{}{
+				"deviceId": deviceID,
+			}
+			if userInputCode != nil {
+				body["userInputCode"] = *userInputCode
+			}
+			var response codeResponse
+			err = sendPostRequest(ctx, querier, "/recipe/signinup/code", body, &response)
+			if err != nil {
+				return pwlmodels.CreateNewCodeForDeviceResponse{}, err
+			}
+			switch response.Status {
+			case "OK":
+				for _, code := range device.Codes {
+					err = recipeImplementation.RevokeCode(ctx, code.CodeID)
+					if err != nil {
+						return pwlmodels.CreateNewCodeForDeviceResponse{}, err
+					}
+				}
+				return pwlmodels.CreateNewCodeForDeviceResponse{
+					OK: getNewCode(response),
+				}, nil
+			case "RESTART_FLOW_ERROR":
+				return pwlmodels.CreateNewCodeForDeviceResponse{
+					RestartFlowError: &struct{}{},
+				}, nil
+			case "USER_INPUT_CODE_ALREADY_USED_ERROR":
+				return pwlmodels.CreateNewCodeForDeviceResponse{
+					UserInputCodeAlreadyUsedError: &struct{}{},
+				}, nil
+			default:
+				return pwlmodels.CreateNewCodeForDeviceResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/signinup/code", response.Status)
+			}
+		},
+
+		ConsumeCode: func(ctx context.Context, userInput *pwlmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string) (pwlmodels.ConsumeCodeResponse, error) {
+			restartFlowResponse := pwlmodels.ConsumeCodeResponse{
+				RestartFlowError: &struct{}{},
+			}
+			device, err := recipeImplementation.ListCodesByPreAuthSessionID(ctx, preAuthSessionID)
+			if err != nil {
+				return pwlmodels.ConsumeCodeResponse{}, err
+			}
+			if device == nil {
+				return restartFlowResponse, nil
+			}
+			if !hasUnexpiredCode(*device, codeLifetime) {
+				if userInput == nil {
+					return restartFlowResponse, nil
+				}
+				return pwlmodels.ConsumeCodeResponse{
+					ExpiredUserInputCodeError: &pwlmodels.CodeInputAttempts{
+						FailedCodeInputAttemptCount: device.FailedCodeInputAttemptCount,
+						MaximumCodeInputAttempts:    config.MaxCodeInputAttempts,
+					},
+				}, nil
+			}
+
+			body := map[string]interface{}{
+				"preAuthSessionId": preAuthSessionID,
+			}
+			if userInput != nil {
+				body["deviceId"] = userInput.DeviceID
+				body["userInputCode"] = userInput.Code
+			} else if linkCode != nil {
+				body["linkCode"] = *linkCode
+			}
+			var response struct {
+				Status                      string         `json:"status"`
+				CreatedNewUser              bool           `json:"createdNewUser"`
+				User                        pwlmodels.User `json:"user"`
+				FailedCodeInputAttemptCount int            `json:"failedCodeInputAttemptCount"`
+				MaximumCodeInputAttempts    int            `json:"maximumCodeInputAttempts"`
+			}
+			err = sendPostRequest(ctx, querier, "/recipe/signinup/code/consume", body, &response)
+			if err != nil {
+				return pwlmodels.ConsumeCodeResponse{}, err
+			}
+			switch response.Status {
+			case "OK":
+				return pwlmodels.ConsumeCodeResponse{
+					OK: &struct {
+						CreatedNewUser bool
+						User           pwlmodels.User
+					}{
+						CreatedNewUser: response.CreatedNewUser,
+						User:           response.User,
+					},
+				}, nil
+			case "INCORRECT_USER_INPUT_CODE_ERROR", "EXPIRED_USER_INPUT_CODE_ERROR":
+				attempts := &pwlmodels.CodeInputAttempts{
+					FailedCodeInputAttemptCount: response.FailedCodeInputAttemptCount,
+					MaximumCodeInputAttempts:    response.MaximumCodeInputAttempts,
+				}
+				if attempts.MaximumCodeInputAttempts > config.MaxCodeInputAttempts {
+					attempts.MaximumCodeInputAttempts = config.MaxCodeInputAttempts
+				}
+				if attempts.FailedCodeInputAttemptCount >= attempts.MaximumCodeInputAttempts {
+					// like the core does when its own limit is reached
+					err = recipeImplementation.RevokeAllCodes(ctx, device.Email, device.PhoneNumber)
+					if err != nil {
+						return pwlmodels.ConsumeCodeResponse{}, err
+					}
+					return restartFlowResponse, nil
+				}
+				if response.Status == "EXPIRED_USER_INPUT_CODE_ERROR" {
+					return pwlmodels.ConsumeCodeResponse{
+						ExpiredUserInputCodeError: attempts,
+					}, nil
+				}
+				return pwlmodels.ConsumeCodeResponse{
+					IncorrectUserInputCodeError: attempts,
+				}, nil
+			case "RESTART_FLOW_ERROR":
+				return restartFlowResponse, nil
+			default:
+				return pwlmodels.ConsumeCodeResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/signinup/code/consume", response.Status)
+			}
+		},
+
+		GetUserByID: func(ctx context.Context, userID string) (*pwlmodels.User, error) {
+			return getUser(ctx, querier, map[string]string{
+				"userId": userID,
+			})
+		},
+
+		GetUserByEmail: func(ctx context.Context, email string) (*pwlmodels.User, error) {
+			return getUser(ctx, querier, map[string]string{
+				"email": email,
+			})
+		},
+
+		GetUserByPhoneNumber: func(ctx context.Context, phoneNumber string) (*pwlmodels.User, error) {
+			return getUser(ctx, querier, map[string]string{
+				"phoneNumber": phoneNumber,
+			})
+		},
+
+		UpdateUser: func(ctx context.Context, userID string, email, phoneNumber *string) (pwlmodels.UpdateUserResponse, error) {
+			body := map[string]interface{}{
+				"userId": userID,
+			}
+			if email != nil {
+				body["email"] = *email
+			}
+			if phoneNumber != nil {
+				body["phoneNumber"] = *phoneNumber
+			}
+			var response struct {
+				Status string `json:"status"`
+			}
+			err := sendPutRequest(ctx, querier, "/recipe/user", body, &response)
+			if err != nil {
+				return pwlmodels.UpdateUserResponse{}, err
+			}
+			switch response.Status {
+			case "OK":
+				return pwlmodels.UpdateUserResponse{
+					OK: &struct{}{},
+				}, nil
+			case "UNKNOWN_USER_ID_ERROR":
+				return pwlmodels.UpdateUserResponse{
+					UnknownUserIdError: &struct{}{},
+				}, nil
+			case "EMAIL_ALREADY_EXISTS_ERROR":
+				return pwlmodels.UpdateUserResponse{
+					EmailAlreadyExistsError: &struct{}{},
+				}, nil
+			case "PHONE_NUMBER_ALREADY_EXISTS_ERROR":
+				return pwlmodels.UpdateUserResponse{
+					PhoneNumberAlreadyExistsError: &struct{}{},
+				}, nil
+			default:
+				return pwlmodels.UpdateUserResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/user", response.Status)
+			}
+		},
+
+		RevokeAllCodes: func(ctx context.Context, email, phoneNumber *string) error {
+			body := map[string]interface{}{}
+			if email != nil {
+				body["email"] = *email
+			}
+			if phoneNumber != nil {
+				body["phoneNumber"] = *phoneNumber
+			}
+			return sendRequestWithOKStatus(ctx, querier, "/recipe/signinup/codes/remove", body)
+		},
+
+		RevokeCode: func(ctx context.Context, codeID string) error {
+			return sendRequestWithOKStatus(ctx, querier, "/recipe/signinup/code/remove", map[string]interface{}{
+				"codeId": codeID,
+			})
+		},
+
+		ListCodesByDeviceID: func(ctx context.Context, deviceID string) (*pwlmodels.DeviceType, error) {
+			return getDevice(ctx, querier, map[string]string{
+				"deviceId": deviceID,
+			})
+		},
+
+		ListCodesByPreAuthSessionID: func(ctx context.Context, preAuthSessionID string) (*pwlmodels.DeviceType, error) {
+			return getDevice(ctx, querier, map[string]string{
+				"preAuthSessionId": preAuthSessionID,
+			})
+		},
+	}
+	return recipeImplementation
+}
+
+// hasUnexpiredCode checks the codes of a device against the lifetime of the config, in milliseconds
+func hasUnexpiredCode(device pwlmodels.DeviceType, codeLifetime uint64) bool {
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for _, code := range device.Codes {
+		if now <= code.TimeCreated+codeLifetime {
+			return true
+		}
+	}
+	return false
+}
+
+func getUser(ctx context.Context, querier supertokens.Querier, params map[string]string) (*pwlmodels.User, error) {
+	var response struct {
+		Status string         `json:"status"`
+		User   pwlmodels.User `json:"user"`
+	}
+	err := sendGetRequest(ctx, querier, "/recipe/user", params, &response)
+	if err != nil {
+		return nil, err
+	}
+	switch response.Status {
+	case "OK":
+		return &response.User, nil
+	case "UNKNOWN_USER_ID_ERROR", "UNKNOWN_EMAIL_ERROR", "UNKNOWN_PHONE_NUMBER_ERROR":
+		return nil, nil
+	default:
+		return nil, supertokens.NewUnexpectedCoreStatusError("/recipe/user", response.Status)
+	}
+}
+
+// getDevice returns the device with the deviceId or preAuthSessionId of the params, or nil
+func getDevice(ctx context.Context, querier supertokens.Querier, params map[string]string) (*pwlmodels.DeviceType, error) {
+	var response struct {
+		Status  string                 `json:"status"`
+		Devices []pwlmodels.DeviceType `json:"devices"`
+	}
+	err := sendGetRequest(ctx, querier, "/recipe/signinup/codes", params, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Status != "OK" {
+		return nil, supertokens.NewUnexpectedCoreStatusError("/recipe/signinup/codes", response.Status)
+	}
+	if len(response.Devices) == 0 {
+		return nil, nil
+	}
+	return &response.Devices[0], nil
+}
+
+func sendRequestWithOKStatus(ctx context.Context, querier supertokens.Querier, path string, body map[string]interface{}) error {
+	var response struct {
+		Status string `json:"status"`
+	}
+	err := sendPostRequest(ctx, querier, path, body, &response)
+	if err != nil {
+		return err
+	}
+	if response.Status != "OK" {
+		return supertokens.NewUnexpectedCoreStatusError(path, response.Status)
+	}
+	return nil
+}
+
+// sendPostRequest, sendGetRequest and sendPutRequest fail clearly if the core is too old to have the
+// passwordless APIs
+func sendPostRequest(ctx context.Context, querier supertokens.Querier, path string, body map[string]interface{}, result interface{}) error {
+	err := querier.CheckAPIVersion(ctx, minCDIVersion)
+	if err != nil {
+		return err
+	}
+	return querier.SendPostRequestAndDecode(ctx, path, body, result)
+}
+
+func sendGetRequest(ctx context.Context, querier supertokens.Querier, path string, params map[string]string, result interface{}) error {
+	err := querier.CheckAPIVersion(ctx, minCDIVersion)
+	if err != nil {
+		return err
+	}
+	return querier.SendGetRequestAndDecode(ctx, path, params, result)
+}
+
+func sendPutRequest(ctx context.Context, querier supertokens.Querier, path string, body map[string]interface{}, result interface{}) error {
+	err := querier.CheckAPIVersion(ctx, minCDIVersion)
+	if err != nil {
+		return err
+	}
+	return querier.SendPutRequestAndDecode(ctx, path, body, result)
+}
@@ -0,0 +1,127 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/pwlmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+var (
+	emailRegex       = regexp.MustCompile(`^(([^<>()\[\]\\.,;:\s@"]+(\.[^<>()\[\]\\.,;:\s@"]+)*)|(".+"))@((\[[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\])|(([a-zA-Z\-0-9]+\.)+[a-zA-Z]{2,}))$`)
+	phoneNumberRegex = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+)
+
+func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config *pwlmodels.TypeInput) (pwlmodels.TypeNormalisedInput, error) {
+	if config == nil {
+		return pwlmodels.TypeNormalisedInput{}, errors.New("passwordless recipe requires a config with the ContactMethod and FlowType")
+	}
+	if config.ContactMethod != pwlmodels.ContactMethodEmail && config.ContactMethod != pwlmodels.ContactMethodPhone && config.ContactMethod != pwlmodels.ContactMethodEmailOrPhone {
+		return pwlmodels.TypeNormalisedInput{}, errors.New("passwordless ContactMethod must be one of EMAIL, PHONE or EMAIL_OR_PHONE")
+	}
+	if config.FlowType != pwlmodels.FlowTypeUserInputCode && config.FlowType != pwlmodels.FlowTypeMagicLink && config.FlowType != pwlmodels.FlowTypeUserInputCodeAndMagicLink {
+		return pwlmodels.TypeNormalisedInput{}, errors.New("passwordless FlowType must be one of USER_INPUT_CODE, MAGIC_LINK or USER_INPUT_CODE_AND_MAGIC_LINK")
+	}
+	if config.ContactMethod != pwlmodels.ContactMethodEmail && config.SendTextMessage == nil {
+		return pwlmodels.TypeNormalisedInput{}, errors.New("passwordless SendTextMessage is required if the ContactMethod allows phone numbers")
+	}
+	if config.CodeLifetime < 0 || config.MaxCodeInputAttempts < 0 {
+		return pwlmodels.TypeNormalisedInput{}, errors.New("passwordless CodeLifetime and MaxCodeInputAttempts cannot be negative")
+	}
+
+	typeNormalisedInput := makeTypeNormalisedInput(appInfo)
+	typeNormalisedInput.ContactMethod = config.ContactMethod
+	typeNormalisedInput.FlowType = config.FlowType
+	typeNormalisedInput.SendTextMessage = config.SendTextMessage
+	typeNormalisedInput.GetCustomUserInputCode = config.GetCustomUserInputCode
+
+	if config.ValidateEmailAddress != nil {
+		typeNormalisedInput.ValidateEmailAddress = config.ValidateEmailAddress
+	}
+	if config.ValidatePhoneNumber != nil {
+		typeNormalisedInput.ValidatePhoneNumber = config.ValidatePhoneNumber
+	}
+	if config.EmailDelivery != nil {
+		typeNormalisedInput.EmailDelivery = *config.EmailDelivery
+	}
+	emailTemplates, err := emaildelivery.NewTemplates(config.EmailTemplates)
+	if err != nil {
+		return pwlmodels.TypeNormalisedInput{}, err
+	}
+	typeNormalisedInput.EmailTemplates = emailTemplates
+	if config.GetLinkDomainAndPath != nil {
+		typeNormalisedInput.GetLinkDomainAndPath = config.GetLinkDomainAndPath
+	}
+	if config.CodeLifetime != 0 {
+		typeNormalisedInput.CodeLifetime = config.CodeLifetime
+	}
+	if config.MaxCodeInputAttempts != 0 {
+		typeNormalisedInput.MaxCodeInputAttempts = config.MaxCodeInputAttempts
+	}
+
+	if config.Override != nil {
+		if config.Override.Functions != nil {
+			typeNormalisedInput.Override.Functions = config.Override.Functions
+		}
+		if config.Override.APIs != nil {
+			typeNormalisedInput.Override.APIs = config.Override.APIs
+		}
+	}
+
+	return typeNormalisedInput, nil
+}
+
+func makeTypeNormalisedInput(appInfo supertokens.NormalisedAppinfo) pwlmodels.TypeNormalisedInput {
+	return pwlmodels.TypeNormalisedInput{
+		ValidateEmailAddress: defaultValidateEmailAddress,
+		ValidatePhoneNumber:  defaultValidatePhoneNumber,
+		EmailDelivery:        emaildelivery.MakeSuperTokensService(),
+		GetLinkDomainAndPath: func(email *string, phoneNumber *string) (string, error) {
+			return appInfo.WebsiteDomain.GetAsStringDangerous() + appInfo.WebsiteBasePath.GetAsStringDangerous() + "/verify", nil
+		},
+		CodeLifetime:         15 * time.Minute,
+		MaxCodeInputAttempts: 5,
+		Override: pwlmodels.OverrideStruct{
+			Functions: func(originalImplementation pwlmodels.RecipeInterface) pwlmodels.RecipeInterface {
+				return originalImplementation
+			},
+			APIs: func(originalImplementation pwlmodels.APIInterface) pwlmodels.APIInterface {
+				return originalImplementation
+			},
+		},
+	}
+}
+
+func defaultValidateEmailAddress(email string) *string {
+	if !emailRegex.MatchString(email) {
+		msg := "Email is invalid"
+		return &msg
+	}
+	return nil
+}
+
+func defaultValidatePhoneNumber(phoneNumber string) *string {
+	if !phoneNumberRegex.MatchString(phoneNumber) {
+		msg := "Phone number is invalid"
+		return &msg
+	}
+	return nil
+}
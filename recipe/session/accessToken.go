@@ -46,22 +46,21 @@ func getInfoFromAccessToken(token string, jwtSigningPublicKey string, doAntiCsrf
 	parentRefreshTokenHash1 := sanitizeStringInput(payload["parentRefreshTokenHash1"])
 
 	var userData *map[string]interface{} = nil
-	if payload["userData"] != nil {
-		temp := payload["userData"].(map[string]interface{})
+	if temp, ok := payload["userData"].(map[string]interface{}); ok {
 		userData = &temp
 	}
 
 	antiCsrfToken := sanitizeStringInput(payload["antiCsrfToken"])
 
 	var expiryTime *uint64 = nil
-	if payload["expiryTime"] != nil {
-		temp := uint64(payload["expiryTime"].(float64))
+	if value, ok := payload["expiryTime"].(float64); ok {
+		temp := uint64(value)
 		expiryTime = &temp
 	}
 
 	var timeCreated *uint64 = nil
-	if payload["timeCreated"] != nil {
-		temp := uint64(payload["timeCreated"].(float64))
+	if value, ok := payload["timeCreated"].(float64); ok {
+		temp := uint64(value)
 		timeCreated = &temp
 	}
 
@@ -16,6 +16,7 @@
 package api
 
 import (
+	"context"
 	defaultErrors "errors"
 	"net/http"
 
@@ -26,12 +27,12 @@ import (
 
 func MakeAPIImplementation() sessmodels.APIInterface {
 	return sessmodels.APIInterface{
-		RefreshPOST: func(options sessmodels.APIOptions) error {
-			_, err := options.RecipeImplementation.RefreshSession(options.Req, options.Res)
+		RefreshPOST: func(ctx context.Context, options sessmodels.APIOptions) error {
+			_, err := options.RecipeImplementation.RefreshSession(ctx, options.Req, options.Res)
 			return err
 		},
 
-		VerifySession: func(verifySessionOptions *sessmodels.VerifySessionOptions, options sessmodels.APIOptions) (*sessmodels.SessionContainer, error) {
+		VerifySession: func(ctx context.Context, verifySessionOptions *sessmodels.VerifySessionOptions, options sessmodels.APIOptions) (*sessmodels.SessionContainer, error) {
 			method := options.Req.Method
 			if method == http.MethodOptions || method == http.MethodTrace {
 				return nil, nil
@@ -44,15 +45,15 @@ func MakeAPIImplementation() sessmodels.APIInterface {
 
 			refreshTokenPath := options.Config.RefreshTokenPath
 			if incomingPath.Equals(refreshTokenPath) && method == http.MethodPost {
-				session, err := options.RecipeImplementation.RefreshSession(options.Req, options.Res)
+				session, err := options.RecipeImplementation.RefreshSession(ctx, options.Req, options.Res)
 				return &session, err
 			} else {
-				return options.RecipeImplementation.GetSession(options.Req, options.Res, verifySessionOptions)
+				return options.RecipeImplementation.GetSession(ctx, options.Req, options.Res, verifySessionOptions)
 			}
 		},
 
-		SignOutPOST: func(options sessmodels.APIOptions) (sessmodels.SignOutPOSTResponse, error) {
-			session, err := options.RecipeImplementation.GetSession(options.Req, options.Res, nil)
+		SignOutPOST: func(ctx context.Context, options sessmodels.APIOptions) (sessmodels.SignOutPOSTResponse, error) {
+			session, err := options.RecipeImplementation.GetSession(ctx, options.Req, options.Res, nil)
 			if err != nil {
 				if defaultErrors.As(err, &errors.UnauthorizedError{}) {
 					return sessmodels.SignOutPOSTResponse{
@@ -26,4 +26,12 @@ const (
 	cookieSameSite_NONE   = "none"
 	cookieSameSite_LAX    = "lax"
 	cookieSameSite_STRICT = "strict"
+
+	tokenTransferMethod_COOKIE = "cookie"
+	tokenTransferMethod_HEADER = "header"
+	tokenTransferMethod_ANY    = "any"
+
+	parentRefreshTokenRotation_ACCEPT                        = "ACCEPT"
+	parentRefreshTokenRotation_VERIFY_WITH_CORE              = "VERIFY_WITH_CORE"
+	parentRefreshTokenRotation_VERIFY_WITH_CORE_IF_AVAILABLE = "VERIFY_WITH_CORE_IF_AVAILABLE"
 )
@@ -36,6 +36,12 @@ const (
 	idRefreshTokenCookieKey = "sIdRefreshToken"
 	idRefreshTokenHeaderKey = "id-refresh-token"
 
+	// used instead of cookies when the tokens are transferred via headers
+	accessTokenHeaderKey   = "st-access-token"
+	refreshTokenHeaderKey  = "st-refresh-token"
+	authorizationHeaderKey = "authorization"
+	authModeHeaderKey      = "st-auth-mode"
+
 	antiCsrfHeaderKey = "anti-csrf"
 	ridHeaderKey      = "rid"
 
@@ -59,6 +65,38 @@ func clearSessionFromCookie(config sessmodels.TypeNormalisedInput, res http.Resp
 	setHeader(res, "Access-Control-Expose-Headers", idRefreshTokenHeaderKey, true)
 }
 
+func clearSessionFromHeaders(res http.ResponseWriter) {
+	setHeader(res, accessTokenHeaderKey, "", false)
+	setHeader(res, refreshTokenHeaderKey, "", false)
+	setHeader(res, "Access-Control-Expose-Headers", accessTokenHeaderKey+", "+refreshTokenHeaderKey, true)
+}
+
+func clearSession(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, tokenTransferMethod string) {
+	if tokenTransferMethod == tokenTransferMethod_HEADER {
+		clearSessionFromHeaders(res)
+	} else {
+		clearSessionFromCookie(config, res)
+	}
+}
+
+func attachAccessToken(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64, tokenTransferMethod string) {
+	if tokenTransferMethod == tokenTransferMethod_HEADER {
+		attachAccessTokenToHeader(res, token)
+	} else {
+		attachAccessTokenToCookie(config, res, token, expiry)
+	}
+}
+
+func attachAccessTokenToHeader(res http.ResponseWriter, token string) {
+	setHeader(res, accessTokenHeaderKey, token, false)
+	setHeader(res, "Access-Control-Expose-Headers", accessTokenHeaderKey, true)
+}
+
+func attachRefreshTokenToHeader(res http.ResponseWriter, token string) {
+	setHeader(res, refreshTokenHeaderKey, token, false)
+	setHeader(res, "Access-Control-Expose-Headers", refreshTokenHeaderKey, true)
+}
+
 func attachAccessTokenToCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64) {
 	setCookie(config, res, accessTokenCookieKey, token, expiry, "accessTokenPath")
 }
@@ -75,6 +113,27 @@ func getRefreshTokenFromCookie(req *http.Request) *string {
 	return getCookieValue(req, refreshTokenCookieKey)
 }
 
+// getTokenFromAuthorizationHeader returns the token from an "Authorization: Bearer <token>" header
+func getTokenFromAuthorizationHeader(req *http.Request) *string {
+	value := getHeader(req, authorizationHeaderKey)
+	if value == nil {
+		return nil
+	}
+	parts := strings.SplitN(strings.TrimSpace(*value), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return nil
+	}
+	token := strings.TrimSpace(parts[1])
+	if token == "" {
+		return nil
+	}
+	return &token
+}
+
+func getAuthModeFromHeader(req *http.Request) *string {
+	return getHeader(req, authModeHeaderKey)
+}
+
 func getAntiCsrfTokenFromHeaders(req *http.Request) *string {
 	return getHeader(req, antiCsrfHeaderKey)
 }
@@ -113,7 +172,7 @@ func setFrontTokenInHeaders(res http.ResponseWriter, userId string, atExpiry uin
 
 func getCORSAllowedHeaders() []string {
 	return []string{
-		antiCsrfHeaderKey, ridHeaderKey,
+		antiCsrfHeaderKey, ridHeaderKey, authorizationHeaderKey, authModeHeaderKey,
 	}
 }
 
@@ -0,0 +1,97 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+type GetTokenFromAuthorizationHeaderTest struct {
+	Input  string
+	Output *string
+}
+
+func TestGetTokenFromAuthorizationHeader(t *testing.T) {
+	token := "abc.def"
+	input := []GetTokenFromAuthorizationHeaderTest{{
+		Input:  "Bearer abc.def",
+		Output: &token,
+	}, {
+		Input:  "bearer  abc.def ",
+		Output: &token,
+	}, {
+		Input:  "Basic abc.def",
+		Output: nil,
+	}, {
+		Input:  "Bearer ",
+		Output: nil,
+	}, {
+		Input:  "",
+		Output: nil,
+	}}
+	for _, val := range input {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", val.Input)
+		assert.Equal(t, val.Output, getTokenFromAuthorizationHeader(req), val.Input)
+	}
+}
+
+func TestSessionTokensInHeaders(t *testing.T) {
+	anyMethod := tokenTransferMethod_ANY
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&sessmodels.TypeInput{TokenTransferMethod: &anyMethod}),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil).WithContext(ctx)
+	req.Header.Set(authModeHeaderKey, tokenTransferMethod_HEADER)
+	res := httptest.NewRecorder()
+	_, err := CreateNewSession(req, res, "user", nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Result().Cookies())
+	accessToken := res.Header().Get(accessTokenHeaderKey)
+	refreshToken := res.Header().Get(refreshTokenHeaderKey)
+	assert.NotEmpty(t, accessToken)
+	assert.NotEmpty(t, refreshToken)
+
+	req = httptest.NewRequest(http.MethodPost, "/api", nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	sessionContainer, err := GetSession(req, httptest.NewRecorder(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", sessionContainer.GetUserID())
+
+	req = httptest.NewRequest(http.MethodPost, "/session/refresh", nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+refreshToken)
+	res = httptest.NewRecorder()
+	_, err = RefreshSession(req, res)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Result().Cookies())
+	assert.NotEmpty(t, res.Header().Get(accessTokenHeaderKey))
+	assert.NotEqual(t, refreshToken, res.Header().Get(refreshTokenHeaderKey))
+
+	req = httptest.NewRequest(http.MethodPost, "/api", nil).WithContext(ctx)
+	_, err = GetSession(req, httptest.NewRecorder(), nil)
+	assert.Error(t, err)
+}
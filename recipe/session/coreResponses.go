@@ -0,0 +1,75 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+// jwtSigningPublicKeyResponse holds the signing key fields that the core adds to some responses
+type jwtSigningPublicKeyResponse struct {
+	JwtSigningPublicKey           string               `json:"jwtSigningPublicKey"`
+	JwtSigningPublicKeyExpiryTime uint64               `json:"jwtSigningPublicKeyExpiryTime"`
+	JwtSigningPublicKeyList       []sessmodels.KeyInfo `json:"jwtSigningPublicKeyList"`
+}
+
+func (response jwtSigningPublicKeyResponse) hasKeys() bool {
+	return response.JwtSigningPublicKey != "" || len(response.JwtSigningPublicKeyList) > 0
+}
+
+type handshakeResponse struct {
+	jwtSigningPublicKeyResponse
+	AccessTokenBlacklistingEnabled bool   `json:"accessTokenBlacklistingEnabled"`
+	AccessTokenValidity            uint64 `json:"accessTokenValidity"`
+	RefreshTokenValidity           uint64 `json:"refreshTokenValidity"`
+}
+
+type statusResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type createOrRefreshSessionResponse struct {
+	statusResponse
+	jwtSigningPublicKeyResponse
+	sessmodels.CreateOrRefreshAPIResponse
+}
+
+type verifySessionResponse struct {
+	statusResponse
+	jwtSigningPublicKeyResponse
+	sessmodels.GetSessionResponse
+}
+
+type sessionInformationResponse struct {
+	statusResponse
+	SessionHandle      string                 `json:"sessionHandle"`
+	UserID             string                 `json:"userId"`
+	UserDataInDatabase map[string]interface{} `json:"userDataInDatabase"`
+	UserDataInJWT      map[string]interface{} `json:"userDataInJWT"`
+	Expiry             uint64                 `json:"expiry"`
+	TimeCreated        uint64                 `json:"timeCreated"`
+}
+
+type revokeSessionsResponse struct {
+	statusResponse
+	SessionHandlesRevoked []string `json:"sessionHandlesRevoked"`
+}
+
+type sessionHandlesResponse struct {
+	statusResponse
+	SessionHandles []string `json:"sessionHandles"`
+}
@@ -0,0 +1,69 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func TestUnexpectedCoreResponsesAreReturnedAsErrors(t *testing.T) {
+	core, instance, cleanup := coretest.NewInstance(t, Init(nil))
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	revokeAllSessionsForUser := func() error {
+		_, err := RevokeAllSessionsForUserWithContext(ctx, "user")
+		return err
+	}
+	getAllSessionHandlesForUser := func() error {
+		_, err := GetAllSessionHandlesForUserWithContext(ctx, "user")
+		return err
+	}
+	getSessionInformation := func() error {
+		_, err := GetSessionInformationWithContext(ctx, "handle")
+		return err
+	}
+
+	core.OverrideResponse("POST /recipe/session/remove", `{"status":"OK","sessionHandlesRevoked":["handle1","handle2"]}`)
+	revoked, err := RevokeAllSessionsForUserWithContext(ctx, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"handle1", "handle2"}, revoked)
+
+	for _, testCase := range []struct {
+		methodAndPath string
+		body          string
+		call          func() error
+	}{
+		{"POST /recipe/session/remove", `{"status":"OK","sessionHandlesRevoked":"handle1"}`, revokeAllSessionsForUser},
+		{"POST /recipe/session/remove", `{"status":"OK","sessionHandlesRevoked":[1,2]}`, revokeAllSessionsForUser},
+		{"POST /recipe/session/remove", `{"status":"UNKNOWN_ERROR"}`, revokeAllSessionsForUser},
+		{"GET /recipe/session/user", `{"status":"OK","sessionHandles":{"handle1":true}}`, getAllSessionHandlesForUser},
+		{"GET /recipe/session/user", `not json`, getAllSessionHandlesForUser},
+		{"GET /recipe/session", `{"status":"OK","userDataInDatabase":[1]}`, getSessionInformation},
+		{"GET /recipe/session", `{"status":"OK","expiry":"tomorrow"}`, getSessionInformation},
+		{"GET /recipe/session", `{"status":"SESSION_EXPIRED"}`, getSessionInformation},
+	} {
+		core.OverrideResponse(testCase.methodAndPath, testCase.body)
+		var err error
+		assert.NotPanics(t, func() { err = testCase.call() }, testCase.body)
+		assert.ErrorAs(t, err, &supertokens.UnexpectedCoreResponseError{}, testCase.body)
+	}
+}
@@ -27,92 +27,142 @@ func Init(config *sessmodels.TypeInput) supertokens.Recipe {
 	return recipeInit(config)
 }
 
-func CreateNewSession(res http.ResponseWriter, userID string, jwtPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+// CreateNewSession creates a session for the user and attaches its tokens to the response. req is
+// used to find out how the tokens should be sent if TokenTransferMethod is "any", and can be nil.
+func CreateNewSession(req *http.Request, res http.ResponseWriter, userID string, jwtPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	return CreateNewSessionWithContext(ctx, req, res, userID, jwtPayload, sessionData)
+}
+
+func CreateNewSessionWithContext(ctx context.Context, req *http.Request, res http.ResponseWriter, userID string, jwtPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return sessmodels.SessionContainer{}, err
 	}
-	return instance.RecipeImpl.CreateNewSession(res, userID, jwtPayload, sessionData)
+	return instance.RecipeImpl.CreateNewSession(ctx, req, res, userID, jwtPayload, sessionData)
 }
 
 func GetSession(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions) (*sessmodels.SessionContainer, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetSessionWithContext(req.Context(), req, res, options)
+}
+
+func GetSessionWithContext(ctx context.Context, req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions) (*sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetSession(req, res, options)
+	return instance.RecipeImpl.GetSession(ctx, req, res, options)
 }
 
 func GetSessionInformation(sessionHandle string) (sessmodels.SessionInformation, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetSessionInformationWithContext(context.Background(), sessionHandle)
+}
+
+func GetSessionInformationWithContext(ctx context.Context, sessionHandle string) (sessmodels.SessionInformation, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return sessmodels.SessionInformation{}, err
 	}
-	return instance.RecipeImpl.GetSessionInformation(sessionHandle)
+	return instance.RecipeImpl.GetSessionInformation(ctx, sessionHandle)
 }
 
 func RefreshSession(req *http.Request, res http.ResponseWriter) (sessmodels.SessionContainer, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RefreshSessionWithContext(req.Context(), req, res)
+}
+
+func RefreshSessionWithContext(ctx context.Context, req *http.Request, res http.ResponseWriter) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return sessmodels.SessionContainer{}, err
 	}
-	return instance.RecipeImpl.RefreshSession(req, res)
+	return instance.RecipeImpl.RefreshSession(ctx, req, res)
 }
 
 func RevokeAllSessionsForUser(userID string) ([]string, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RevokeAllSessionsForUserWithContext(context.Background(), userID)
+}
+
+func RevokeAllSessionsForUserWithContext(ctx context.Context, userID string) ([]string, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.RevokeAllSessionsForUser(userID)
+	return instance.RecipeImpl.RevokeAllSessionsForUser(ctx, userID)
 }
 
 func GetAllSessionHandlesForUser(userID string) ([]string, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetAllSessionHandlesForUserWithContext(context.Background(), userID)
+}
+
+func GetAllSessionHandlesForUserWithContext(ctx context.Context, userID string) ([]string, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetAllSessionHandlesForUser(userID)
+	return instance.RecipeImpl.GetAllSessionHandlesForUser(ctx, userID)
 }
 
 func RevokeSession(sessionHandle string) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RevokeSessionWithContext(context.Background(), sessionHandle)
+}
+
+func RevokeSessionWithContext(ctx context.Context, sessionHandle string) (bool, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return false, err
 	}
-	return instance.RecipeImpl.RevokeSession(sessionHandle)
+	return instance.RecipeImpl.RevokeSession(ctx, sessionHandle)
 }
 
 func RevokeMultipleSessions(sessionHandles []string) ([]string, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RevokeMultipleSessionsWithContext(context.Background(), sessionHandles)
+}
+
+func RevokeMultipleSessionsWithContext(ctx context.Context, sessionHandles []string) ([]string, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.RevokeMultipleSessions(sessionHandles)
+	return instance.RecipeImpl.RevokeMultipleSessions(ctx, sessionHandles)
 }
 
 func UpdateSessionData(sessionHandle string, newSessionData map[string]interface{}) error {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UpdateSessionDataWithContext(context.Background(), sessionHandle, newSessionData)
+}
+
+func UpdateSessionDataWithContext(ctx context.Context, sessionHandle string, newSessionData map[string]interface{}) error {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return err
 	}
-	return instance.RecipeImpl.UpdateSessionData(sessionHandle, newSessionData)
+	return instance.RecipeImpl.UpdateSessionData(ctx, sessionHandle, newSessionData)
 }
 
 func UpdateJWTPayload(sessionHandle string, newJWTPayload map[string]interface{}) error {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UpdateJWTPayloadWithContext(context.Background(), sessionHandle, newJWTPayload)
+}
+
+func UpdateJWTPayloadWithContext(ctx context.Context, sessionHandle string, newJWTPayload map[string]interface{}) error {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return err
 	}
-	return instance.RecipeImpl.UpdateJWTPayload(sessionHandle, newJWTPayload)
+	return instance.RecipeImpl.UpdateJWTPayload(ctx, sessionHandle, newJWTPayload)
 }
 
 func VerifySession(options *sessmodels.VerifySessionOptions, otherHandler http.HandlerFunc) http.HandlerFunc {
-	instance, err := getRecipeInstanceOrThrowError()
-	if err != nil {
-		panic("can't fetch supertokens instance. You should call the supertokens.Init function before using the VerifySession function.")
-	}
-	return VerifySessionHelper(*instance, options, otherHandler)
+	// the session recipe is looked up for every request so that the one of the
+	// instance whose middleware is handling the request is used.
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		instance, err := getRecipeInstanceOrThrowError(r.Context())
+		if err != nil {
+			panic("can't fetch supertokens instance. You should call the supertokens.Init function before using the VerifySession function.")
+		}
+		VerifySessionHelper(*instance, options, otherHandler)(w, r)
+	})
 }
 
 func GetSessionFromRequestContext(ctx context.Context) *sessmodels.SessionContainer {
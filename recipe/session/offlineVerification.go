@@ -0,0 +1,135 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	defaultErrors "errors"
+	"io/ioutil"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// jwtSigningPublicKeysFileReloadInterval is how often the keys file can be read again for an access
+// token that none of its keys verify
+var jwtSigningPublicKeysFileReloadInterval = 30 * time.Second
+
+// offlineJwtSigningPublicKeys holds the signing keys used to verify access tokens without the core
+type offlineJwtSigningPublicKeys struct {
+	lock       sync.Mutex
+	configKeys []sessmodels.KeyInfo
+	fileKeys   []sessmodels.KeyInfo
+	file       *string
+	fileReadAt time.Time
+}
+
+func newOfflineJwtSigningPublicKeys(config *sessmodels.NormalisedOfflineVerification) *offlineJwtSigningPublicKeys {
+	return &offlineJwtSigningPublicKeys{
+		configKeys: config.JwtSigningPublicKeys,
+		file:       config.JwtSigningPublicKeysFile,
+	}
+}
+
+// get returns the keys that have not expired. The file is read the first time, and again once none of
+// its keys are valid anymore.
+func (o *offlineJwtSigningPublicKeys) get() ([]sessmodels.KeyInfo, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.file != nil && len(getValidKeys(o.fileKeys)) == 0 {
+		err := o.readFile()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(getValidKeys(o.configKeys), getValidKeys(o.fileKeys)...), nil
+}
+
+// reload reads the file again, in case a key has been added since it was read, and returns the keys
+// that have not expired. It returns false if there is no file or if it was read too recently.
+func (o *offlineJwtSigningPublicKeys) reload() ([]sessmodels.KeyInfo, bool, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.file == nil || time.Since(o.fileReadAt) < jwtSigningPublicKeysFileReloadInterval {
+		return nil, false, nil
+	}
+	err := o.readFile()
+	if err != nil {
+		return nil, false, err
+	}
+	return append(getValidKeys(o.configKeys), getValidKeys(o.fileKeys)...), true, nil
+}
+
+func (o *offlineJwtSigningPublicKeys) readFile() error {
+	keys, err := readJwtSigningPublicKeysFile(*o.file)
+	if err != nil {
+		return err
+	}
+	o.fileKeys = keys
+	o.fileReadAt = time.Now()
+	return nil
+}
+
+func getValidKeys(keys []sessmodels.KeyInfo) []sessmodels.KeyInfo {
+	result := []sessmodels.KeyInfo{}
+	now := getCurrTimeInMS()
+	for _, key := range keys {
+		if key.ExpiryTime > now {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+func readJwtSigningPublicKeysFile(path string) ([]sessmodels.KeyInfo, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []sessmodels.KeyInfo
+	err = json.Unmarshal(content, &keys)
+	if err != nil {
+		return nil, defaultErrors.New("the JWT signing public keys file must contain a list of keys: " + err.Error())
+	}
+	return normaliseJwtSigningPublicKeys(keys)
+}
+
+func normaliseJwtSigningPublicKeys(keys []sessmodels.KeyInfo) ([]sessmodels.KeyInfo, error) {
+	result := []sessmodels.KeyInfo{}
+	for _, key := range keys {
+		_, err := getPublicKeyFromStr("-----BEGIN PUBLIC KEY-----\n" + key.PublicKey + "\n-----END PUBLIC KEY-----")
+		if err != nil {
+			return nil, err
+		}
+		if key.ExpiryTime == 0 {
+			key.ExpiryTime = math.MaxUint64
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// isCoreUnreachableError returns true if the request to the core failed because it could not be reached
+func isCoreUnreachableError(err error) bool {
+	var netErr net.Error
+	return defaultErrors.As(err, &supertokens.CoreUnavailableError{}) || defaultErrors.As(err, &netErr)
+}
@@ -0,0 +1,188 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func makeOfflineTestInstance(t *testing.T, core *coretest.Core, config *sessmodels.TypeInput) context.Context {
+	return supertokens.WithInstance(context.Background(), core.NewInstance(t, Init(config)))
+}
+
+func TestOfflineVerificationWithoutCore(t *testing.T) {
+	core := coretest.New(nil)
+	otherCore := coretest.New(nil)
+	defer otherCore.Close()
+
+	ctx := makeOfflineTestInstance(t, core, nil)
+	offlineCtx := makeOfflineTestInstance(t, core, &sessmodels.TypeInput{
+		OfflineVerification: &sessmodels.OfflineVerificationInput{
+			JwtSigningPublicKeys: []sessmodels.KeyInfo{{PublicKey: core.JwtSigningPublicKey()}},
+		},
+	})
+	wrongKeyCtx := makeOfflineTestInstance(t, core, &sessmodels.TypeInput{
+		OfflineVerification: &sessmodels.OfflineVerificationInput{
+			JwtSigningPublicKeys: []sessmodels.KeyInfo{{PublicKey: otherCore.JwtSigningPublicKey()}},
+		},
+	})
+
+	res := httptest.NewRecorder()
+	_, err := CreateNewSessionWithContext(ctx, nil, res, "user", map[string]interface{}{"a": "b"}, nil)
+	assert.NoError(t, err)
+	core.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range res.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	sessionContainer, err := GetSessionWithContext(offlineCtx, req, httptest.NewRecorder(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", sessionContainer.GetUserID())
+	assert.Equal(t, "b", sessionContainer.GetJWTPayload()["a"])
+
+	_, err = GetSessionWithContext(wrongKeyCtx, req, httptest.NewRecorder(), nil)
+	assert.ErrorAs(t, err, &errors.TryRefreshTokenError{})
+}
+
+func TestOfflineVerificationWithRotatedKeys(t *testing.T) {
+	core := coretest.New(nil)
+	rotatedOutCore := coretest.New(nil)
+	defer rotatedOutCore.Close()
+
+	ctx := makeOfflineTestInstance(t, core, nil)
+	currentKey := sessmodels.KeyInfo{PublicKey: core.JwtSigningPublicKey()}
+	rotatedOutKey := sessmodels.KeyInfo{PublicKey: rotatedOutCore.JwtSigningPublicKey()}
+	var offlineCtxs []context.Context
+	for _, keys := range [][]sessmodels.KeyInfo{{rotatedOutKey, currentKey}, {currentKey, rotatedOutKey}} {
+		offlineCtxs = append(offlineCtxs, makeOfflineTestInstance(t, core, &sessmodels.TypeInput{
+			OfflineVerification: &sessmodels.OfflineVerificationInput{
+				JwtSigningPublicKeys: keys,
+			},
+		}))
+	}
+
+	res := httptest.NewRecorder()
+	_, err := CreateNewSessionWithContext(ctx, nil, res, "user", nil, nil)
+	assert.NoError(t, err)
+	core.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range res.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	// the token is verified whatever the position of its key in the list
+	for _, offlineCtx := range offlineCtxs {
+		sessionContainer, err := GetSessionWithContext(offlineCtx, req, httptest.NewRecorder(), nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "user", sessionContainer.GetUserID())
+		}
+	}
+}
+
+func TestOfflineVerificationReadsKeysAddedToTheFile(t *testing.T) {
+	defer func(interval time.Duration) {
+		jwtSigningPublicKeysFileReloadInterval = interval
+	}(jwtSigningPublicKeysFileReloadInterval)
+	jwtSigningPublicKeysFileReloadInterval = 0
+
+	core := coretest.New(nil)
+	otherCore := coretest.New(nil)
+	defer otherCore.Close()
+	dir, err := ioutil.TempDir("", "offlineVerification")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "keys.json")
+	writeKeys := func(keys ...sessmodels.KeyInfo) {
+		content, err := json.Marshal(keys)
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(file, content, 0600))
+	}
+	writeKeys(sessmodels.KeyInfo{PublicKey: otherCore.JwtSigningPublicKey()})
+
+	ctx := makeOfflineTestInstance(t, core, nil)
+	offlineCtx := makeOfflineTestInstance(t, core, &sessmodels.TypeInput{
+		OfflineVerification: &sessmodels.OfflineVerificationInput{
+			JwtSigningPublicKeysFile: &file,
+		},
+	})
+
+	res := httptest.NewRecorder()
+	_, err = CreateNewSessionWithContext(ctx, nil, res, "user", nil, nil)
+	assert.NoError(t, err)
+	core.Close()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range res.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	_, err = GetSessionWithContext(offlineCtx, req, httptest.NewRecorder(), nil)
+	assert.ErrorAs(t, err, &errors.TryRefreshTokenError{})
+
+	// the key of the core is added while the other one, which doesn't expire, is still valid
+	writeKeys(sessmodels.KeyInfo{PublicKey: otherCore.JwtSigningPublicKey()}, sessmodels.KeyInfo{PublicKey: core.JwtSigningPublicKey()})
+	sessionContainer, err := GetSessionWithContext(offlineCtx, req, httptest.NewRecorder(), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user", sessionContainer.GetUserID())
+	}
+}
+
+func TestOfflineVerificationConfig(t *testing.T) {
+	apiDomain, _ := supertokens.NewNormalisedURLDomain("http://localhost:3001")
+	websiteDomain, _ := supertokens.NewNormalisedURLDomain("http://localhost:3000")
+	appInfo := supertokens.NormalisedAppinfo{
+		APIDomain:     apiDomain,
+		WebsiteDomain: websiteDomain,
+	}
+
+	_, err := validateAndNormaliseUserInput(appInfo, &sessmodels.TypeInput{
+		OfflineVerification: &sessmodels.OfflineVerificationInput{},
+	})
+	assert.Error(t, err)
+
+	_, err = validateAndNormaliseUserInput(appInfo, &sessmodels.TypeInput{
+		OfflineVerification: &sessmodels.OfflineVerificationInput{
+			JwtSigningPublicKeys: []sessmodels.KeyInfo{{PublicKey: "not a key"}},
+		},
+	})
+	assert.Error(t, err)
+
+	rotation := "SOMETIMES"
+	core := coretest.New(nil)
+	defer core.Close()
+	_, err = validateAndNormaliseUserInput(appInfo, &sessmodels.TypeInput{
+		OfflineVerification: &sessmodels.OfflineVerificationInput{
+			JwtSigningPublicKeys:       []sessmodels.KeyInfo{{PublicKey: core.JwtSigningPublicKey()}},
+			ParentRefreshTokenRotation: &rotation,
+		},
+	})
+	assert.Error(t, err)
+}
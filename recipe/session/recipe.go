@@ -16,6 +16,7 @@
 package session
 
 import (
+	"context"
 	defaultErrors "errors"
 	"net/http"
 
@@ -34,9 +35,9 @@ type Recipe struct {
 
 const RECIPE_ID = "session"
 
-var singletonInstance *Recipe
-
-func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *sessmodels.TypeInput, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+func MakeRecipe(recipeId string, instance *supertokens.SuperTokens, config *sessmodels.TypeInput) (Recipe, error) {
+	appInfo := instance.AppInfo
+	onGeneralError := instance.OnGeneralError
 	r := &Recipe{}
 
 	r.RecipeModule = supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, r.handleError, onGeneralError)
@@ -48,7 +49,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	r.Config = verifiedConfig
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
 
-	querierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(recipeId)
+	querierInstance, err := instance.GetNewQuerierInstanceOrThrowError(recipeId)
 	if err != nil {
 		return Recipe{}, err
 	}
@@ -58,22 +59,25 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	return *r, nil
 }
 
-func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+func getRecipeInstanceOrThrowError(ctx context.Context) (*Recipe, error) {
+	instance, err := supertokens.GetInstanceOrThrowError(ctx)
+	if err == nil {
+		if recipe, ok := instance.GetRecipeInstance(RECIPE_ID).(*Recipe); ok {
+			return recipe, nil
+		}
 	}
 	return nil, defaultErrors.New("Initialisation not done. Did you forget to call the init function?")
 }
 
 func recipeInit(config *sessmodels.TypeInput) supertokens.Recipe {
-	return func(appInfo supertokens.NormalisedAppinfo, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
-			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, onGeneralError)
+	return func(instance *supertokens.SuperTokens) (*supertokens.RecipeModule, error) {
+		if instance.GetRecipeInstance(RECIPE_ID) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, instance, config)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			instance.AddRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, defaultErrors.New("Session recipe has already been initialised. Please check your code for bugs.")
 	}
@@ -135,6 +139,6 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 	return false, nil
 }
 
-func ResetForTest() {
-	singletonInstance = nil
-}
+// ResetForTest is kept so that existing tests keep compiling. The session recipe now belongs to the
+// SuperTokens instance, so supertokens.ResetForTest resets it as well.
+func ResetForTest() {}
@@ -16,6 +16,7 @@
 package session
 
 import (
+	"context"
 	defaultErrors "errors"
 	"net/http"
 	"reflect"
@@ -31,42 +32,70 @@ var handshakeInfoLock sync.Mutex
 func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.TypeNormalisedInput) sessmodels.RecipeInterface {
 
 	var recipeImplHandshakeInfo *sessmodels.HandshakeInfo = nil
-	getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+	var offlineKeys *offlineJwtSigningPublicKeys = nil
+	if config.OfflineVerification != nil {
+		offlineKeys = newOfflineJwtSigningPublicKeys(config.OfflineVerification)
+	} else {
+		getHandshakeInfo(context.Background(), &recipeImplHandshakeInfo, config, querier, false)
+	}
 
 	return sessmodels.RecipeInterface{
-		CreateNewSession: func(res http.ResponseWriter, userID string, jwtPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
-			response, err := createNewSessionHelper(recipeImplHandshakeInfo, config, querier, userID, jwtPayload, sessionData)
+		CreateNewSession: func(ctx context.Context, req *http.Request, res http.ResponseWriter, userID string, jwtPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
+			tokenTransferMethod := getTokenTransferMethodForNewSession(config, req)
+			response, err := createNewSessionHelper(ctx, recipeImplHandshakeInfo, config, querier, userID, jwtPayload, sessionData, tokenTransferMethod == tokenTransferMethod_HEADER)
 			if err != nil {
 				return sessmodels.SessionContainer{}, err
 			}
-			attachCreateOrRefreshSessionResponseToRes(config, res, response)
-			sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInJWT, res)
-			return newSessionContainer(querier, config, &sessionContainerInput), nil
+			attachCreateOrRefreshSessionResponseToRes(config, res, response, tokenTransferMethod)
+			sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInJWT, res, tokenTransferMethod)
+			return newSessionContainer(ctx, querier, config, &sessionContainerInput), nil
 		},
 
-		GetSession: func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions) (*sessmodels.SessionContainer, error) {
+		GetSession: func(ctx context.Context, req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions) (*sessmodels.SessionContainer, error) {
 			var doAntiCsrfCheck *bool = nil
 			if options != nil {
 				doAntiCsrfCheck = options.AntiCsrfCheck
 			}
 
-			idRefreshToken := getIDRefreshTokenFromCookie(req)
-			if idRefreshToken == nil {
-				if options != nil && options.SessionRequired != nil &&
-					!(*options.SessionRequired) {
-					return nil, nil
+			tokenTransferMethod := tokenTransferMethod_COOKIE
+			var accessToken *string = nil
+			if config.TokenTransferMethod != tokenTransferMethod_COOKIE {
+				accessToken = getTokenFromAuthorizationHeader(req)
+				if accessToken != nil || config.TokenTransferMethod == tokenTransferMethod_HEADER {
+					tokenTransferMethod = tokenTransferMethod_HEADER
 				}
-				return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
 			}
 
-			accessToken := getAccessTokenFromCookie(req)
-			if accessToken == nil {
-				if options == nil || (options.SessionRequired != nil && *options.SessionRequired) || frontendHasInterceptor(req) || req.Method == http.MethodGet {
-					return nil, errors.TryRefreshTokenError{
-						Msg: "Access token has expired. Please call the refresh API",
+			if tokenTransferMethod == tokenTransferMethod_HEADER {
+				if accessToken == nil {
+					if options != nil && options.SessionRequired != nil &&
+						!(*options.SessionRequired) {
+						return nil, nil
+					}
+					return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the access token in the Authorization header?"}
+				}
+				// browsers do not attach the Authorization header on their own, so there is no CSRF risk
+				doAntiCsrfCheckBool := false
+				doAntiCsrfCheck = &doAntiCsrfCheckBool
+			} else {
+				idRefreshToken := getIDRefreshTokenFromCookie(req)
+				if idRefreshToken == nil {
+					if options != nil && options.SessionRequired != nil &&
+						!(*options.SessionRequired) {
+						return nil, nil
+					}
+					return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
+				}
+
+				accessToken = getAccessTokenFromCookie(req)
+				if accessToken == nil {
+					if options == nil || (options.SessionRequired != nil && *options.SessionRequired) || frontendHasInterceptor(req) || req.Method == http.MethodGet {
+						return nil, errors.TryRefreshTokenError{
+							Msg: "Access token has expired. Please call the refresh API",
+						}
 					}
+					return nil, nil
 				}
-				return nil, nil
 			}
 
 			antiCsrfToken := getAntiCsrfTokenFromHeaders(req)
@@ -75,90 +104,105 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 				doAntiCsrfCheck = &doAntiCsrfCheckBool
 			}
 
-			response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, *accessToken, antiCsrfToken, *doAntiCsrfCheck, getRidFromHeader(req) != nil)
+			response, err := getSessionHelper(ctx, recipeImplHandshakeInfo, config, querier, offlineKeys, *accessToken, antiCsrfToken, *doAntiCsrfCheck, getRidFromHeader(req) != nil)
 			if err != nil {
 				if defaultErrors.As(err, &errors.UnauthorizedError{}) {
-					clearSessionFromCookie(config, res)
+					clearSession(config, res, tokenTransferMethod)
 				}
 				return nil, err
 			}
 
 			if !reflect.DeepEqual(response.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
 				setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInJWT)
-				attachAccessTokenToCookie(config, res, response.AccessToken.Token, response.AccessToken.Expiry)
+				attachAccessToken(config, res, response.AccessToken.Token, response.AccessToken.Expiry, tokenTransferMethod)
 				accessToken = &response.AccessToken.Token
 			}
-			sessionContainerInput := makeSessionContainerInput(*accessToken, response.Session.Handle, response.Session.UserID, response.Session.UserDataInJWT, res)
-			sessionContainer := newSessionContainer(querier, config, &sessionContainerInput)
+			sessionContainerInput := makeSessionContainerInput(*accessToken, response.Session.Handle, response.Session.UserID, response.Session.UserDataInJWT, res, tokenTransferMethod)
+			sessionContainer := newSessionContainer(ctx, querier, config, &sessionContainerInput)
 			return &sessionContainer, nil
 		},
 
-		GetSessionInformation: func(sessionHandle string) (sessmodels.SessionInformation, error) {
-			return getSessionInformationHelper(querier, sessionHandle)
+		GetSessionInformation: func(ctx context.Context, sessionHandle string) (sessmodels.SessionInformation, error) {
+			return getSessionInformationHelper(ctx, querier, sessionHandle)
 		},
 
-		RefreshSession: func(req *http.Request, res http.ResponseWriter) (sessmodels.SessionContainer, error) {
-			inputIdRefreshToken := getIDRefreshTokenFromCookie(req)
-			if inputIdRefreshToken == nil {
-				return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
+		RefreshSession: func(ctx context.Context, req *http.Request, res http.ResponseWriter) (sessmodels.SessionContainer, error) {
+			tokenTransferMethod := tokenTransferMethod_COOKIE
+			var inputRefreshToken *string = nil
+			if config.TokenTransferMethod != tokenTransferMethod_COOKIE {
+				inputRefreshToken = getTokenFromAuthorizationHeader(req)
+				if inputRefreshToken != nil || config.TokenTransferMethod == tokenTransferMethod_HEADER {
+					tokenTransferMethod = tokenTransferMethod_HEADER
+				}
 			}
 
-			inputRefreshToken := getRefreshTokenFromCookie(req)
-			if inputRefreshToken == nil {
-				clearSessionFromCookie(config, res)
-				return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Refresh token not found. Are you sending the refresh token in the request as a cookie?"}
+			if tokenTransferMethod == tokenTransferMethod_HEADER {
+				if inputRefreshToken == nil {
+					return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Refresh token not found. Are you sending the refresh token in the Authorization header?"}
+				}
+			} else {
+				inputIdRefreshToken := getIDRefreshTokenFromCookie(req)
+				if inputIdRefreshToken == nil {
+					return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
+				}
+
+				inputRefreshToken = getRefreshTokenFromCookie(req)
+				if inputRefreshToken == nil {
+					clearSessionFromCookie(config, res)
+					return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Refresh token not found. Are you sending the refresh token in the request as a cookie?"}
+				}
 			}
 
 			antiCsrfToken := getAntiCsrfTokenFromHeaders(req)
-			response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, *inputRefreshToken, antiCsrfToken, getRidFromHeader(req) != nil)
+			response, err := refreshSessionHelper(ctx, recipeImplHandshakeInfo, config, querier, *inputRefreshToken, antiCsrfToken, getRidFromHeader(req) != nil, tokenTransferMethod == tokenTransferMethod_HEADER)
 			if err != nil {
 				// we clear cookies if it is UnauthorizedError & ClearCookies in it is nil or true
 				// we clear cookies if it is TokenTheftDetectedError
 				if (defaultErrors.As(err, &errors.UnauthorizedError{}) && (err.(errors.UnauthorizedError).ClearCookies == nil || *err.(errors.UnauthorizedError).ClearCookies)) || defaultErrors.As(err, &errors.TokenTheftDetectedError{}) {
-					clearSessionFromCookie(config, res)
+					clearSession(config, res, tokenTransferMethod)
 				}
 				return sessmodels.SessionContainer{}, err
 			}
-			attachCreateOrRefreshSessionResponseToRes(config, res, response)
-			sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInJWT, res)
-			sessionContainer := newSessionContainer(querier, config, &sessionContainerInput)
+			attachCreateOrRefreshSessionResponseToRes(config, res, response, tokenTransferMethod)
+			sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInJWT, res, tokenTransferMethod)
+			sessionContainer := newSessionContainer(ctx, querier, config, &sessionContainerInput)
 			return sessionContainer, nil
 		},
 
-		RevokeAllSessionsForUser: func(userID string) ([]string, error) {
-			return revokeAllSessionsForUserHelper(querier, userID)
+		RevokeAllSessionsForUser: func(ctx context.Context, userID string) ([]string, error) {
+			return revokeAllSessionsForUserHelper(ctx, querier, userID)
 		},
 
-		GetAllSessionHandlesForUser: func(userID string) ([]string, error) {
-			return getAllSessionHandlesForUserHelper(querier, userID)
+		GetAllSessionHandlesForUser: func(ctx context.Context, userID string) ([]string, error) {
+			return getAllSessionHandlesForUserHelper(ctx, querier, userID)
 		},
 
-		RevokeSession: func(sessionHandle string) (bool, error) {
-			return revokeSessionHelper(querier, sessionHandle)
+		RevokeSession: func(ctx context.Context, sessionHandle string) (bool, error) {
+			return revokeSessionHelper(ctx, querier, sessionHandle)
 		},
 
-		RevokeMultipleSessions: func(sessionHandles []string) ([]string, error) {
-			return revokeMultipleSessionsHelper(querier, sessionHandles)
+		RevokeMultipleSessions: func(ctx context.Context, sessionHandles []string) ([]string, error) {
+			return revokeMultipleSessionsHelper(ctx, querier, sessionHandles)
 		},
 
-		UpdateSessionData: func(sessionHandle string, newSessionData map[string]interface{}) error {
-			return updateSessionDataHelper(querier, sessionHandle, newSessionData)
+		UpdateSessionData: func(ctx context.Context, sessionHandle string, newSessionData map[string]interface{}) error {
+			return updateSessionDataHelper(ctx, querier, sessionHandle, newSessionData)
 		},
 
-		UpdateJWTPayload: func(sessionHandle string, newJWTPayload map[string]interface{}) error {
-			return updateJWTPayloadHelper(querier, sessionHandle, newJWTPayload)
+		UpdateJWTPayload: func(ctx context.Context, sessionHandle string, newJWTPayload map[string]interface{}) error {
+			return updateJWTPayloadHelper(ctx, querier, sessionHandle, newJWTPayload)
 		},
 
-		GetAccessTokenLifeTimeMS: func() (uint64, error) {
-			err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+		GetAccessTokenLifeTimeMS: func(ctx context.Context) (uint64, error) {
+			err := getHandshakeInfo(ctx, &recipeImplHandshakeInfo, config, querier, false)
 			if err != nil {
 				return 0, err
 			}
 			return recipeImplHandshakeInfo.AccessTokenValidity, nil
 		},
 
-		GetRefreshTokenLifeTimeMS: func() (uint64, error) {
-			err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+		GetRefreshTokenLifeTimeMS: func(ctx context.Context) (uint64, error) {
+			err := getHandshakeInfo(ctx, &recipeImplHandshakeInfo, config, querier, false)
 			if err != nil {
 				return 0, err
 			}
@@ -168,25 +212,26 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 }
 
 // updates recipeImplHandshakeInfo in place.
-func getHandshakeInfo(recipeImplHandshakeInfo **sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, forceFetch bool) error {
+func getHandshakeInfo(ctx context.Context, recipeImplHandshakeInfo **sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, forceFetch bool) error {
 	handshakeInfoLock.Lock()
 	defer handshakeInfoLock.Unlock()
 	if *recipeImplHandshakeInfo == nil ||
 		len((*recipeImplHandshakeInfo).GetJwtSigningPublicKeyList()) == 0 ||
 		forceFetch {
-		response, err := querier.SendPostRequest("/recipe/handshake", nil)
+		var response handshakeResponse
+		err := querier.SendPostRequestAndDecode(ctx, "/recipe/handshake", nil, &response)
 		if err != nil {
 			return err
 		}
 
 		*recipeImplHandshakeInfo = &sessmodels.HandshakeInfo{
 			AntiCsrf:                       config.AntiCsrf,
-			AccessTokenBlacklistingEnabled: response["accessTokenBlacklistingEnabled"].(bool),
-			AccessTokenValidity:            uint64(response["accessTokenValidity"].(float64)),
-			RefreshTokenValidity:           uint64(response["refreshTokenValidity"].(float64)),
+			AccessTokenBlacklistingEnabled: response.AccessTokenBlacklistingEnabled,
+			AccessTokenValidity:            response.AccessTokenValidity,
+			RefreshTokenValidity:           response.RefreshTokenValidity,
 		}
 
-		updateJwtSigningPublicKeyInfoWithoutLock(recipeImplHandshakeInfo, getKeyInfoFromJson(response), response["jwtSigningPublicKey"].(string), uint64(response["jwtSigningPublicKeyExpiryTime"].(float64)))
+		updateJwtSigningPublicKeyInfoWithoutLock(recipeImplHandshakeInfo, response.JwtSigningPublicKeyList, response.JwtSigningPublicKey, response.JwtSigningPublicKeyExpiryTime)
 
 	}
 	return nil
@@ -216,3 +261,9 @@ func updateJwtSigningPublicKeyInfo(recipeImplHandshakeInfo **sessmodels.Handshak
 	updateJwtSigningPublicKeyInfoWithoutLock(recipeImplHandshakeInfo, keyList, newKey, newExpiry)
 
 }
+
+func updateJwtSigningPublicKeyInfoFromResponse(recipeImplHandshakeInfo **sessmodels.HandshakeInfo, response jwtSigningPublicKeyResponse) {
+	if response.hasKeys() {
+		updateJwtSigningPublicKeyInfo(recipeImplHandshakeInfo, response.JwtSigningPublicKeyList, response.JwtSigningPublicKey, response.JwtSigningPublicKeyExpiryTime)
+	}
+}
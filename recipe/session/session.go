@@ -16,7 +16,7 @@
 package session
 
 import (
-	"encoding/json"
+	"context"
 	defaultErrors "errors"
 	"net/http"
 	"reflect"
@@ -32,37 +32,40 @@ type SessionContainerInput struct {
 	userDataInJWT map[string]interface{}
 	res           http.ResponseWriter
 	accessToken   string
+	// tokenTransferMethod is how the tokens of this session are sent to and received from the frontend
+	tokenTransferMethod string
 }
 
-func makeSessionContainerInput(accessToken string, sessionHandle string, userID string, userDataInJWT map[string]interface{}, res http.ResponseWriter) SessionContainerInput {
+func makeSessionContainerInput(accessToken string, sessionHandle string, userID string, userDataInJWT map[string]interface{}, res http.ResponseWriter, tokenTransferMethod string) SessionContainerInput {
 	return SessionContainerInput{
-		sessionHandle: sessionHandle,
-		userID:        userID,
-		userDataInJWT: userDataInJWT,
-		res:           res,
-		accessToken:   accessToken,
+		sessionHandle:       sessionHandle,
+		userID:              userID,
+		userDataInJWT:       userDataInJWT,
+		res:                 res,
+		accessToken:         accessToken,
+		tokenTransferMethod: tokenTransferMethod,
 	}
 }
 
-func newSessionContainer(querier supertokens.Querier, config sessmodels.TypeNormalisedInput, session *SessionContainerInput) sessmodels.SessionContainer {
+func newSessionContainer(ctx context.Context, querier supertokens.Querier, config sessmodels.TypeNormalisedInput, session *SessionContainerInput) sessmodels.SessionContainer {
 
 	return sessmodels.SessionContainer{
 		RevokeSession: func() error {
-			success, err := revokeSessionHelper(querier, session.sessionHandle)
+			success, err := revokeSessionHelper(ctx, querier, session.sessionHandle)
 			if err != nil {
 				return err
 			}
 			if success {
-				clearSessionFromCookie(config, session.res)
+				clearSession(config, session.res, session.tokenTransferMethod)
 			}
 			return nil
 		},
 
 		GetSessionData: func() (map[string]interface{}, error) {
-			sessionInformation, err := getSessionInformationHelper(querier, session.sessionHandle)
+			sessionInformation, err := getSessionInformationHelper(ctx, querier, session.sessionHandle)
 			if err != nil {
 				if defaultErrors.As(err, &errors.UnauthorizedError{}) {
-					clearSessionFromCookie(config, session.res)
+					clearSession(config, session.res, session.tokenTransferMethod)
 				}
 				return nil, err
 			}
@@ -70,10 +73,10 @@ func newSessionContainer(querier supertokens.Querier, config sessmodels.TypeNorm
 		},
 
 		UpdateSessionData: func(newSessionData map[string]interface{}) error {
-			err := updateSessionDataHelper(querier, session.sessionHandle, newSessionData)
+			err := updateSessionDataHelper(ctx, querier, session.sessionHandle, newSessionData)
 			if err != nil {
 				if defaultErrors.As(err, &errors.UnauthorizedError{}) {
-					clearSessionFromCookie(config, session.res)
+					clearSession(config, session.res, session.tokenTransferMethod)
 				}
 				return err
 			}
@@ -84,33 +87,29 @@ func newSessionContainer(querier supertokens.Querier, config sessmodels.TypeNorm
 			if newJWTPayload == nil {
 				newJWTPayload = map[string]interface{}{}
 			}
-			response, err := querier.SendPostRequest("/recipe/session/regenerate", map[string]interface{}{
+			var response verifySessionResponse
+			err := querier.SendPostRequestAndDecode(ctx, "/recipe/session/regenerate", map[string]interface{}{
 				"accessToken":   session.accessToken,
 				"userDataInJWT": newJWTPayload,
-			})
+			}, &response)
 			if err != nil {
 				return err
 			}
-			if response["status"].(string) == errors.UnauthorizedErrorStr {
-				clearSessionFromCookie(config, session.res)
+			if response.Status == errors.UnauthorizedErrorStr {
+				clearSession(config, session.res, session.tokenTransferMethod)
 				return errors.UnauthorizedError{Msg: "Session has probably been revoked while updating JWT payload"}
 			}
-
-			responseByte, err := json.Marshal(response)
-			if err != nil {
-				return err
-			}
-			var resp sessmodels.GetSessionResponse
-			err = json.Unmarshal(responseByte, &resp)
-			if err != nil {
-				return err
+			if response.Status != "OK" {
+				return supertokens.NewUnexpectedCoreStatusError("/recipe/session/regenerate", response.Status)
 			}
 
+			resp := response.GetSessionResponse
+
 			session.userDataInJWT = resp.Session.UserDataInJWT
 			if !reflect.DeepEqual(resp.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
 				session.accessToken = resp.AccessToken.Token
 				setFrontTokenInHeaders(session.res, resp.Session.UserID, resp.AccessToken.Expiry, resp.Session.UserDataInJWT)
-				attachAccessTokenToCookie(config, session.res, resp.AccessToken.Token, resp.AccessToken.Expiry)
+				attachAccessToken(config, session.res, resp.AccessToken.Token, resp.AccessToken.Expiry, session.tokenTransferMethod)
 			}
 			return nil
 		},
@@ -127,20 +126,20 @@ func newSessionContainer(querier supertokens.Querier, config sessmodels.TypeNorm
 			return session.accessToken
 		},
 		GetTimeCreated: func() (uint64, error) {
-			sessionInformation, err := getSessionInformationHelper(querier, session.sessionHandle)
+			sessionInformation, err := getSessionInformationHelper(ctx, querier, session.sessionHandle)
 			if err != nil {
 				if defaultErrors.As(err, &errors.UnauthorizedError{}) {
-					clearSessionFromCookie(config, session.res)
+					clearSession(config, session.res, session.tokenTransferMethod)
 				}
 				return 0, err
 			}
 			return sessionInformation.TimeCreated, nil
 		},
 		GetExpiry: func() (uint64, error) {
-			sessionInformation, err := getSessionInformationHelper(querier, session.sessionHandle)
+			sessionInformation, err := getSessionInformationHelper(ctx, querier, session.sessionHandle)
 			if err != nil {
 				if defaultErrors.As(err, &errors.UnauthorizedError{}) {
-					clearSessionFromCookie(config, session.res)
+					clearSession(config, session.res, session.tokenTransferMethod)
 				}
 				return 0, err
 			}
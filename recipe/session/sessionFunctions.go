@@ -16,7 +16,7 @@
 package session
 
 import (
-	"encoding/json"
+	"context"
 	defaultErrors "errors"
 
 	"github.com/supertokens/supertokens-golang/recipe/session/errors"
@@ -24,7 +24,7 @@ import (
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, userID string, JWTPayload, sessionData map[string]interface{}) (sessmodels.CreateOrRefreshAPIResponse, error) {
+func createNewSessionHelper(ctx context.Context, recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, userID string, JWTPayload, sessionData map[string]interface{}, disableAntiCsrf bool) (sessmodels.CreateOrRefreshAPIResponse, error) {
 	if JWTPayload == nil {
 		JWTPayload = map[string]interface{}{}
 	}
@@ -36,49 +36,55 @@ func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, c
 		"userDataInJWT":      JWTPayload,
 		"userDataInDatabase": sessionData,
 	}
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+	err := getHandshakeInfo(ctx, &recipeImplHandshakeInfo, config, querier, false)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
-	requestBody["enableAntiCsrf"] = recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN
-	response, err := querier.SendPostRequest("/recipe/session", requestBody)
+	requestBody["enableAntiCsrf"] = !disableAntiCsrf && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN
+	var response createOrRefreshSessionResponse
+	err = querier.SendPostRequestAndDecode(ctx, "/recipe/session", requestBody, &response)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
-	updateJwtSigningPublicKeyInfo(&recipeImplHandshakeInfo, getKeyInfoFromJson(response), response["jwtSigningPublicKey"].(string), uint64(response["jwtSigningPublicKeyExpiryTime"].(float64)))
-
-	delete(response, "status")
-	delete(response, "jwtSigningPublicKey")
-	delete(response, "jwtSigningPublicKeyExpiryTime")
-	delete(response, "jwtSigningPublicKeyList")
-
-	responseByte, err := json.Marshal(response)
-	if err != nil {
-		return sessmodels.CreateOrRefreshAPIResponse{}, err
-	}
-	var resp sessmodels.CreateOrRefreshAPIResponse
-	err = json.Unmarshal(responseByte, &resp)
-	if err != nil {
-		return sessmodels.CreateOrRefreshAPIResponse{}, err
+	if response.Status != "OK" {
+		return sessmodels.CreateOrRefreshAPIResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/session", response.Status)
 	}
-	return resp, nil
+	updateJwtSigningPublicKeyInfoFromResponse(&recipeImplHandshakeInfo, response.jwtSigningPublicKeyResponse)
+	return response.CreateOrRefreshAPIResponse, nil
 }
 
-func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, accessToken string, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool) (sessmodels.GetSessionResponse, error) {
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
-	if err != nil {
-		return sessmodels.GetSessionResponse{}, err
+func getSessionHelper(ctx context.Context, recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, offlineKeys *offlineJwtSigningPublicKeys, accessToken string, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool) (sessmodels.GetSessionResponse, error) {
+	var jwtSigningPublicKeyList []sessmodels.KeyInfo
+	accessTokenBlacklistingEnabled := false
+	if offlineKeys != nil {
+		keys, err := offlineKeys.get()
+		if err != nil {
+			return sessmodels.GetSessionResponse{}, err
+		}
+		jwtSigningPublicKeyList = keys
+	} else {
+		err := getHandshakeInfo(ctx, &recipeImplHandshakeInfo, config, querier, false)
+		if err != nil {
+			return sessmodels.GetSessionResponse{}, err
+		}
+		jwtSigningPublicKeyList = recipeImplHandshakeInfo.GetJwtSigningPublicKeyList()
+		accessTokenBlacklistingEnabled = recipeImplHandshakeInfo.AccessTokenBlacklistingEnabled
 	}
 
+	var err error
 	var accessTokenInfo *accessTokenInfoStruct = nil
 	foundASigningKeyThatIsOlderThanTheAccessToken := false
-	for _, key := range recipeImplHandshakeInfo.GetJwtSigningPublicKeyList() {
+	for _, key := range jwtSigningPublicKeyList {
 
-		accessTokenInfo, err = getInfoFromAccessToken(accessToken, key.PublicKey, recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN && doAntiCsrfCheck)
+		accessTokenInfo, err = getInfoFromAccessToken(accessToken, key.PublicKey, config.AntiCsrf == antiCSRF_VIA_TOKEN && doAntiCsrfCheck)
 		if err != nil {
 			if !defaultErrors.As(err, &errors.TryRefreshTokenError{}) {
 				return sessmodels.GetSessionResponse{}, err
 			}
+			if offlineKeys != nil {
+				// configured keys have no creation time, so all of them are tried
+				continue
+			}
 
 			payload, errFromPayload := getPayloadWithoutVerifying(accessToken)
 
@@ -87,19 +93,48 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 				return sessmodels.GetSessionResponse{}, err
 			}
 
-			expiryTime := uint64(payload["expiryTime"].(float64))
-			timeCreated := uint64(payload["timeCreated"].(float64))
+			expiryTime, okExpiry := payload["expiryTime"].(float64)
+			timeCreated, okTimeCreated := payload["timeCreated"].(float64)
+			if !okExpiry || !okTimeCreated {
+				return sessmodels.GetSessionResponse{}, err
+			}
 
-			if expiryTime < getCurrTimeInMS() {
+			if uint64(expiryTime) < getCurrTimeInMS() {
 				return sessmodels.GetSessionResponse{}, err
 			}
 
-			if timeCreated >= key.CreatedAt {
+			if uint64(timeCreated) >= key.CreatedAt {
 				foundASigningKeyThatIsOlderThanTheAccessToken = true
 				break
 			}
 		} else {
 			foundASigningKeyThatIsOlderThanTheAccessToken = true
+			break
+		}
+	}
+
+	if offlineKeys != nil && accessTokenInfo == nil {
+		// the token may be signed with a key that was added to the keys file after it was read
+		keys, reloaded, err := offlineKeys.reload()
+		if err != nil {
+			return sessmodels.GetSessionResponse{}, err
+		}
+		if reloaded {
+			for _, key := range keys {
+				accessTokenInfo, err = getInfoFromAccessToken(accessToken, key.PublicKey, config.AntiCsrf == antiCSRF_VIA_TOKEN && doAntiCsrfCheck)
+				if err == nil {
+					foundASigningKeyThatIsOlderThanTheAccessToken = true
+					break
+				}
+				if !defaultErrors.As(err, &errors.TryRefreshTokenError{}) {
+					return sessmodels.GetSessionResponse{}, err
+				}
+			}
+		}
+		if accessTokenInfo == nil {
+			return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{
+				Msg: "Access token could not be verified using the configured signing keys",
+			}
 		}
 	}
 
@@ -110,7 +145,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	}
 
 	if doAntiCsrfCheck {
-		if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN {
+		if config.AntiCsrf == antiCSRF_VIA_TOKEN {
 			if accessTokenInfo != nil {
 				if antiCsrfToken == nil || *antiCsrfToken != *accessTokenInfo.antiCsrfToken {
 					if antiCsrfToken == nil {
@@ -120,7 +155,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 					}
 				}
 			}
-		} else if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
+		} else if config.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
 			if !containsCustomHeader {
 				return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "anti-csrf check failed. Please pass 'rid: \"session\"' header in the request, or set doAntiCsrfCheck to false for this API"}
 			}
@@ -128,83 +163,90 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	}
 
 	if accessTokenInfo != nil &&
-		!recipeImplHandshakeInfo.AccessTokenBlacklistingEnabled &&
+		!accessTokenBlacklistingEnabled &&
 		accessTokenInfo.parentRefreshTokenHash1 == nil {
-		return sessmodels.GetSessionResponse{
-			Session: sessmodels.SessionStruct{
-				Handle:        accessTokenInfo.sessionHandle,
-				UserID:        accessTokenInfo.userID,
-				UserDataInJWT: accessTokenInfo.userData,
-			},
-		}, nil
+		return getSessionResponseFromAccessTokenInfo(*accessTokenInfo), nil
 	}
+
+	if offlineKeys != nil && config.OfflineVerification.ParentRefreshTokenRotation == parentRefreshTokenRotation_ACCEPT {
+		return getSessionResponseFromAccessTokenInfo(*accessTokenInfo), nil
+	}
+
 	requestBody := map[string]interface{}{
 		"accessToken":     accessToken,
 		"doAntiCsrfCheck": doAntiCsrfCheck,
-		"enableAntiCsrf":  recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN,
+		"enableAntiCsrf":  config.AntiCsrf == antiCSRF_VIA_TOKEN,
 	}
 	if antiCsrfToken != nil {
 		requestBody["antiCsrfToken"] = *antiCsrfToken
 	}
 
-	response, err := querier.SendPostRequest("/recipe/session/verify", requestBody)
+	var response verifySessionResponse
+	err = querier.SendPostRequestAndDecode(ctx, "/recipe/session/verify", requestBody, &response)
 	if err != nil {
+		if offlineKeys != nil && config.OfflineVerification.ParentRefreshTokenRotation == parentRefreshTokenRotation_VERIFY_WITH_CORE_IF_AVAILABLE && isCoreUnreachableError(err) {
+			return getSessionResponseFromAccessTokenInfo(*accessTokenInfo), nil
+		}
 		return sessmodels.GetSessionResponse{}, err
 	}
 
-	status := response["status"]
-	if status.(string) == "OK" {
-		updateJwtSigningPublicKeyInfo(&recipeImplHandshakeInfo, getKeyInfoFromJson(response), response["jwtSigningPublicKey"].(string), uint64(response["jwtSigningPublicKeyExpiryTime"].(float64)))
-		delete(response, "status")
-		delete(response, "jwtSigningPublicKey")
-		delete(response, "jwtSigningPublicKeyExpiryTime")
-		responseByte, err := json.Marshal(response)
-		if err != nil {
-			return sessmodels.GetSessionResponse{}, err
-		}
-		var result sessmodels.GetSessionResponse
-		err = json.Unmarshal(responseByte, &result)
-		if err != nil {
-			return sessmodels.GetSessionResponse{}, err
-		}
-		return result, nil
-	} else if response["status"].(string) == errors.UnauthorizedErrorStr {
-		return sessmodels.GetSessionResponse{}, errors.UnauthorizedError{Msg: response["message"].(string)}
-	} else {
-		updateJwtSigningPublicKeyInfo(&recipeImplHandshakeInfo, getKeyInfoFromJson(response), response["jwtSigningPublicKey"].(string), uint64(response["jwtSigningPublicKeyExpiryTime"].(float64)))
+	switch response.Status {
+	case "OK":
+		updateJwtSigningPublicKeyInfoFromResponse(&recipeImplHandshakeInfo, response.jwtSigningPublicKeyResponse)
+		return response.GetSessionResponse, nil
+	case errors.UnauthorizedErrorStr:
+		return sessmodels.GetSessionResponse{}, errors.UnauthorizedError{Msg: response.Message}
+	case errors.TryRefreshTokenErrorStr:
+		updateJwtSigningPublicKeyInfoFromResponse(&recipeImplHandshakeInfo, response.jwtSigningPublicKeyResponse)
+		return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: response.Message}
+	default:
+		return sessmodels.GetSessionResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/session/verify", response.Status)
+	}
+}
 
-		return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: response["message"].(string)}
+func getSessionResponseFromAccessTokenInfo(accessTokenInfo accessTokenInfoStruct) sessmodels.GetSessionResponse {
+	return sessmodels.GetSessionResponse{
+		Session: sessmodels.SessionStruct{
+			Handle:        accessTokenInfo.sessionHandle,
+			UserID:        accessTokenInfo.userID,
+			UserDataInJWT: accessTokenInfo.userData,
+		},
 	}
 }
 
-func getSessionInformationHelper(querier supertokens.Querier, sessionHandle string) (sessmodels.SessionInformation, error) {
-	response, err := querier.SendGetRequest("/recipe/session",
+func getSessionInformationHelper(ctx context.Context, querier supertokens.Querier, sessionHandle string) (sessmodels.SessionInformation, error) {
+	var response sessionInformationResponse
+	err := querier.SendGetRequestAndDecode(ctx, "/recipe/session",
 		map[string]string{
 			"sessionHandle": sessionHandle,
-		})
+		}, &response)
 	if err != nil {
 		return sessmodels.SessionInformation{}, err
 	}
-	if response["status"] == "OK" {
+	switch response.Status {
+	case "OK":
 		return sessmodels.SessionInformation{
-			SessionHandle: response["sessionHandle"].(string),
-			UserId:        response["userId"].(string),
-			SessionData:   response["userDataInDatabase"].(map[string]interface{}),
-			Expiry:        uint64(response["expiry"].(float64)),
-			TimeCreated:   uint64(response["timeCreated"].(float64)),
-			JwtPayload:    response["userDataInJWT"].(map[string]interface{}),
+			SessionHandle: response.SessionHandle,
+			UserId:        response.UserID,
+			SessionData:   response.UserDataInDatabase,
+			Expiry:        response.Expiry,
+			TimeCreated:   response.TimeCreated,
+			JwtPayload:    response.UserDataInJWT,
 		}, nil
+	case errors.UnauthorizedErrorStr:
+		return sessmodels.SessionInformation{}, errors.UnauthorizedError{Msg: response.Message}
+	default:
+		return sessmodels.SessionInformation{}, supertokens.NewUnexpectedCoreStatusError("/recipe/session", response.Status)
 	}
-	return sessmodels.SessionInformation{}, errors.UnauthorizedError{Msg: response["message"].(string)}
 }
 
-func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, refreshToken string, antiCsrfToken *string, containsCustomHeader bool) (sessmodels.CreateOrRefreshAPIResponse, error) {
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+func refreshSessionHelper(ctx context.Context, recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, refreshToken string, antiCsrfToken *string, containsCustomHeader bool, disableAntiCsrf bool) (sessmodels.CreateOrRefreshAPIResponse, error) {
+	err := getHandshakeInfo(ctx, &recipeImplHandshakeInfo, config, querier, false)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
 
-	if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
+	if !disableAntiCsrf && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
 		if !containsCustomHeader {
 			clearCookies := false
 			return sessmodels.CreateOrRefreshAPIResponse{}, errors.UnauthorizedError{
@@ -216,114 +258,126 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 
 	requestBody := map[string]interface{}{
 		"refreshToken":   refreshToken,
-		"enableAntiCsrf": recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN,
+		"enableAntiCsrf": !disableAntiCsrf && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN,
 	}
 	if antiCsrfToken != nil {
 		requestBody["antiCsrfToken"] = *antiCsrfToken
 	}
-	response, err := querier.SendPostRequest("/recipe/session/refresh", requestBody)
+	var response createOrRefreshSessionResponse
+	err = querier.SendPostRequestAndDecode(ctx, "/recipe/session/refresh", requestBody, &response)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
-	if response["status"] == "OK" {
-		delete(response, "status")
-		responseByte, err := json.Marshal(response)
-		if err != nil {
-			return sessmodels.CreateOrRefreshAPIResponse{}, err
-		}
-		var result sessmodels.CreateOrRefreshAPIResponse
-		err = json.Unmarshal(responseByte, &result)
-		if err != nil {
-			return sessmodels.CreateOrRefreshAPIResponse{}, err
-		}
-		return result, nil
-	} else if response["status"].(string) == errors.UnauthorizedErrorStr {
-		return sessmodels.CreateOrRefreshAPIResponse{}, errors.UnauthorizedError{Msg: response["message"].(string)}
-	} else {
+	switch response.Status {
+	case "OK":
+		return response.CreateOrRefreshAPIResponse, nil
+	case errors.UnauthorizedErrorStr:
+		return sessmodels.CreateOrRefreshAPIResponse{}, errors.UnauthorizedError{Msg: response.Message}
+	case errors.TokenTheftDetectedErrorStr:
 		sessionInfo := errors.TokenTheftDetectedErrorPayload{
-			SessionHandle: (response["session"].(map[string]interface{}))["handle"].(string),
-			UserID:        (response["session"].(map[string]interface{}))["userId"].(string),
+			SessionHandle: response.Session.Handle,
+			UserID:        response.Session.UserID,
 		}
 		return sessmodels.CreateOrRefreshAPIResponse{}, errors.TokenTheftDetectedError{
 			Msg:     "Token theft detected",
 			Payload: sessionInfo,
 		}
+	default:
+		return sessmodels.CreateOrRefreshAPIResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/session/refresh", response.Status)
 	}
 }
 
-func revokeAllSessionsForUserHelper(querier supertokens.Querier, userID string) ([]string, error) {
-	response, err := querier.SendPostRequest("/recipe/session/remove", map[string]interface{}{
+func revokeAllSessionsForUserHelper(ctx context.Context, querier supertokens.Querier, userID string) ([]string, error) {
+	var response revokeSessionsResponse
+	err := querier.SendPostRequestAndDecode(ctx, "/recipe/session/remove", map[string]interface{}{
 		"userId": userID,
-	})
+	}, &response)
 	if err != nil {
 		return nil, err
 	}
-	return response["sessionHandlesRevoked"].([]string), nil
+	if response.Status != "OK" {
+		return nil, supertokens.NewUnexpectedCoreStatusError("/recipe/session/remove", response.Status)
+	}
+	return response.SessionHandlesRevoked, nil
 }
 
-func getAllSessionHandlesForUserHelper(querier supertokens.Querier, userID string) ([]string, error) {
-	response, err := querier.SendGetRequest("/recipe/session/user", map[string]string{
+func getAllSessionHandlesForUserHelper(ctx context.Context, querier supertokens.Querier, userID string) ([]string, error) {
+	var response sessionHandlesResponse
+	err := querier.SendGetRequestAndDecode(ctx, "/recipe/session/user", map[string]string{
 		"userId": userID,
-	})
+	}, &response)
 	if err != nil {
 		return nil, err
 	}
-	return response["sessionHandles"].([]string), nil
+	if response.Status != "OK" {
+		return nil, supertokens.NewUnexpectedCoreStatusError("/recipe/session/user", response.Status)
+	}
+	return response.SessionHandles, nil
 }
 
-func revokeSessionHelper(querier supertokens.Querier, sessionHandle string) (bool, error) {
-	response, err := querier.SendPostRequest("/recipe/session/remove",
+func revokeSessionHelper(ctx context.Context, querier supertokens.Querier, sessionHandle string) (bool, error) {
+	var response revokeSessionsResponse
+	err := querier.SendPostRequestAndDecode(ctx, "/recipe/session/remove",
 		map[string]interface{}{
 			"sessionHandles": [1]string{sessionHandle},
-		})
+		}, &response)
 	if err != nil {
 		return false, err
 	}
-	return len(response["sessionHandlesRevoked"].([]interface{})) == 1, nil
+	if response.Status != "OK" {
+		return false, supertokens.NewUnexpectedCoreStatusError("/recipe/session/remove", response.Status)
+	}
+	return len(response.SessionHandlesRevoked) == 1, nil
 }
 
-func revokeMultipleSessionsHelper(querier supertokens.Querier, sessionHandles []string) ([]string, error) {
-	response, err := querier.SendPostRequest("/recipe/session/remove",
+func revokeMultipleSessionsHelper(ctx context.Context, querier supertokens.Querier, sessionHandles []string) ([]string, error) {
+	var response revokeSessionsResponse
+	err := querier.SendPostRequestAndDecode(ctx, "/recipe/session/remove",
 		map[string]interface{}{
 			"sessionHandles": sessionHandles,
-		})
+		}, &response)
 	if err != nil {
 		return nil, err
 	}
-	return response["sessionHandlesRevoked"].([]string), nil
+	if response.Status != "OK" {
+		return nil, supertokens.NewUnexpectedCoreStatusError("/recipe/session/remove", response.Status)
+	}
+	return response.SessionHandlesRevoked, nil
 }
 
-func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string, newSessionData map[string]interface{}) error {
+func updateSessionDataHelper(ctx context.Context, querier supertokens.Querier, sessionHandle string, newSessionData map[string]interface{}) error {
 	if newSessionData == nil {
 		newSessionData = map[string]interface{}{}
 	}
-	response, err := querier.SendPutRequest("/recipe/session/data",
+	var response statusResponse
+	err := querier.SendPutRequestAndDecode(ctx, "/recipe/session/data",
 		map[string]interface{}{
 			"sessionHandle":      sessionHandle,
 			"userDataInDatabase": newSessionData,
-		})
+		}, &response)
 	if err != nil {
 		return err
 	}
-	if response["status"].(string) == errors.UnauthorizedErrorStr {
-		return errors.UnauthorizedError{Msg: response["message"].(string)}
+	if response.Status == errors.UnauthorizedErrorStr {
+		return errors.UnauthorizedError{Msg: response.Message}
 	}
 	return nil
 }
 
-func updateJWTPayloadHelper(querier supertokens.Querier, sessionHandle string, newJWTPayload map[string]interface{}) error {
+func updateJWTPayloadHelper(ctx context.Context, querier supertokens.Querier, sessionHandle string, newJWTPayload map[string]interface{}) error {
 	if newJWTPayload == nil {
 		newJWTPayload = map[string]interface{}{}
 	}
-	response, err := querier.SendPutRequest("/recipe/jwt/data", map[string]interface{}{
+	var response statusResponse
+	err := querier.SendPutRequestAndDecode(ctx, "/recipe/jwt/data", map[string]interface{}{
 		"sessionHandle": sessionHandle,
 		"userDataInJWT": newJWTPayload,
-	})
+	}, &response)
 	if err != nil {
 		return err
 	}
-	if response["status"].(string) == errors.UnauthorizedErrorStr {
-		return errors.UnauthorizedError{Msg: response["message"].(string)}
+	if response.Status == errors.UnauthorizedErrorStr {
+		return errors.UnauthorizedError{Msg: response.Message}
 	}
 	return nil
 }
@@ -15,10 +15,12 @@
 
 package sessmodels
 
+import "context"
+
 type APIInterface struct {
-	RefreshPOST   func(options APIOptions) error
-	SignOutPOST   func(options APIOptions) (SignOutPOSTResponse, error)
-	VerifySession func(verifySessionOptions *VerifySessionOptions, options APIOptions) (*SessionContainer, error)
+	RefreshPOST   func(ctx context.Context, options APIOptions) error
+	SignOutPOST   func(ctx context.Context, options APIOptions) (SignOutPOSTResponse, error)
+	VerifySession func(ctx context.Context, verifySessionOptions *VerifySessionOptions, options APIOptions) (*SessionContainer, error)
 }
 
 type SignOutPOSTResponse struct {
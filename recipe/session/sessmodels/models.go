@@ -85,10 +85,34 @@ type TypeInput struct {
 	SessionExpiredStatusCode *int
 	CookieDomain             *string
 	AntiCsrf                 *string
+	TokenTransferMethod      *string
+	OfflineVerification      *OfflineVerificationInput
 	Override                 *OverrideStruct
 	ErrorHandlers            *ErrorHandlers
 }
 
+// OfflineVerificationInput makes GetSession verify access tokens using the given signing keys,
+// without contacting the core. Revoked sessions are then only detected once their access token expires.
+type OfflineVerificationInput struct {
+	// JwtSigningPublicKeys are the keys the core signs access tokens with. A zero ExpiryTime means
+	// that the key does not expire.
+	JwtSigningPublicKeys []KeyInfo
+	// JwtSigningPublicKeysFile is a JSON file with a list of keys ({"publicKey", "expiryTime", "createdAt"}).
+	// It is read again once none of its keys are valid anymore, and when an access token can't be
+	// verified with its keys (at most every 30 seconds), so that a new key can be added without a restart.
+	JwtSigningPublicKeysFile *string
+	// ParentRefreshTokenRotation is what happens to access tokens issued by a refresh whose new refresh
+	// token has not been used yet: "ACCEPT" (default) trusts them, "VERIFY_WITH_CORE" confirms the
+	// rotation with the core and "VERIFY_WITH_CORE_IF_AVAILABLE" does so only if the core can be reached.
+	ParentRefreshTokenRotation *string
+}
+
+type NormalisedOfflineVerification struct {
+	JwtSigningPublicKeys       []KeyInfo
+	JwtSigningPublicKeysFile   *string
+	ParentRefreshTokenRotation string
+}
+
 type OverrideStruct struct {
 	Functions func(originalImplementation RecipeInterface) RecipeInterface
 	APIs      func(originalImplementation APIInterface) APIInterface
@@ -106,6 +130,8 @@ type TypeNormalisedInput struct {
 	CookieSecure             bool
 	SessionExpiredStatusCode int
 	AntiCsrf                 string
+	TokenTransferMethod      string
+	OfflineVerification      *NormalisedOfflineVerification
 	Override                 OverrideStruct
 	ErrorHandlers            NormalisedErrorHandlers
 }
@@ -15,19 +15,22 @@
 
 package sessmodels
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 type RecipeInterface struct {
-	CreateNewSession            func(res http.ResponseWriter, userID string, jwtPayload map[string]interface{}, sessionData map[string]interface{}) (SessionContainer, error)
-	GetSession                  func(req *http.Request, res http.ResponseWriter, options *VerifySessionOptions) (*SessionContainer, error)
-	RefreshSession              func(req *http.Request, res http.ResponseWriter) (SessionContainer, error)
-	GetSessionInformation       func(sessionHandle string) (SessionInformation, error)
-	RevokeAllSessionsForUser    func(userID string) ([]string, error)
-	GetAllSessionHandlesForUser func(userID string) ([]string, error)
-	RevokeSession               func(sessionHandle string) (bool, error)
-	RevokeMultipleSessions      func(sessionHandles []string) ([]string, error)
-	UpdateSessionData           func(sessionHandle string, newSessionData map[string]interface{}) error
-	UpdateJWTPayload            func(sessionHandle string, newJWTPayload map[string]interface{}) error
-	GetAccessTokenLifeTimeMS    func() (uint64, error)
-	GetRefreshTokenLifeTimeMS   func() (uint64, error)
+	CreateNewSession            func(ctx context.Context, req *http.Request, res http.ResponseWriter, userID string, jwtPayload map[string]interface{}, sessionData map[string]interface{}) (SessionContainer, error)
+	GetSession                  func(ctx context.Context, req *http.Request, res http.ResponseWriter, options *VerifySessionOptions) (*SessionContainer, error)
+	RefreshSession              func(ctx context.Context, req *http.Request, res http.ResponseWriter) (SessionContainer, error)
+	GetSessionInformation       func(ctx context.Context, sessionHandle string) (SessionInformation, error)
+	RevokeAllSessionsForUser    func(ctx context.Context, userID string) ([]string, error)
+	GetAllSessionHandlesForUser func(ctx context.Context, userID string) ([]string, error)
+	RevokeSession               func(ctx context.Context, sessionHandle string) (bool, error)
+	RevokeMultipleSessions      func(ctx context.Context, sessionHandles []string) ([]string, error)
+	UpdateSessionData           func(ctx context.Context, sessionHandle string, newSessionData map[string]interface{}) error
+	UpdateJWTPayload            func(ctx context.Context, sessionHandle string, newJWTPayload map[string]interface{}) error
+	GetAccessTokenLifeTimeMS    func(ctx context.Context) (uint64, error)
+	GetRefreshTokenLifeTimeMS   func(ctx context.Context) (uint64, error)
 }
@@ -90,23 +90,39 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		antiCsrf = *config.AntiCsrf
 	}
 
+	tokenTransferMethod := tokenTransferMethod_COOKIE
+	if config != nil && config.TokenTransferMethod != nil {
+		if *config.TokenTransferMethod != tokenTransferMethod_COOKIE && *config.TokenTransferMethod != tokenTransferMethod_HEADER && *config.TokenTransferMethod != tokenTransferMethod_ANY {
+			return sessmodels.TypeNormalisedInput{}, errors.New("tokenTransferMethod config must be one of 'cookie' or 'header' or 'any'")
+		}
+		tokenTransferMethod = *config.TokenTransferMethod
+	}
+
+	var offlineVerification *sessmodels.NormalisedOfflineVerification = nil
+	if config != nil && config.OfflineVerification != nil {
+		offlineVerification, err = normaliseOfflineVerification(*config.OfflineVerification)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
 	errorHandlers := sessmodels.NormalisedErrorHandlers{
 		OnTokenTheftDetected: func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error {
-			recipeInstance, err := getRecipeInstanceOrThrowError()
+			recipeInstance, err := getRecipeInstanceOrThrowError(req.Context())
 			if err != nil {
 				return err
 			}
 			return sendTokenTheftDetectedResponse(*recipeInstance, sessionHandle, userID, req, res)
 		},
 		OnTryRefreshToken: func(message string, req *http.Request, res http.ResponseWriter) error {
-			recipeInstance, err := getRecipeInstanceOrThrowError()
+			recipeInstance, err := getRecipeInstanceOrThrowError(req.Context())
 			if err != nil {
 				return err
 			}
 			return sendTryRefreshTokenResponse(*recipeInstance, message, req, res)
 		},
 		OnUnauthorised: func(message string, req *http.Request, res http.ResponseWriter) error {
-			recipeInstance, err := getRecipeInstanceOrThrowError()
+			recipeInstance, err := getRecipeInstanceOrThrowError(req.Context())
 			if err != nil {
 				return err
 			}
@@ -151,6 +167,8 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		CookieSecure:             cookieSecure,
 		SessionExpiredStatusCode: sessionExpiredStatusCode,
 		AntiCsrf:                 antiCsrf,
+		TokenTransferMethod:      tokenTransferMethod,
+		OfflineVerification:      offlineVerification,
 		ErrorHandlers:            errorHandlers,
 		Override: sessmodels.OverrideStruct{
 			Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
@@ -171,6 +189,34 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	return typeNormalisedInput, nil
 }
+func normaliseOfflineVerification(config sessmodels.OfflineVerificationInput) (*sessmodels.NormalisedOfflineVerification, error) {
+	if len(config.JwtSigningPublicKeys) == 0 && config.JwtSigningPublicKeysFile == nil {
+		return nil, errors.New("offlineVerification needs jwtSigningPublicKeys or a jwtSigningPublicKeysFile")
+	}
+	keys, err := normaliseJwtSigningPublicKeys(config.JwtSigningPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	if config.JwtSigningPublicKeysFile != nil {
+		_, err := readJwtSigningPublicKeysFile(*config.JwtSigningPublicKeysFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	parentRefreshTokenRotation := parentRefreshTokenRotation_ACCEPT
+	if config.ParentRefreshTokenRotation != nil {
+		parentRefreshTokenRotation = *config.ParentRefreshTokenRotation
+		if parentRefreshTokenRotation != parentRefreshTokenRotation_ACCEPT && parentRefreshTokenRotation != parentRefreshTokenRotation_VERIFY_WITH_CORE && parentRefreshTokenRotation != parentRefreshTokenRotation_VERIFY_WITH_CORE_IF_AVAILABLE {
+			return nil, errors.New("parentRefreshTokenRotation config must be one of 'ACCEPT' or 'VERIFY_WITH_CORE' or 'VERIFY_WITH_CORE_IF_AVAILABLE'")
+		}
+	}
+	return &sessmodels.NormalisedOfflineVerification{
+		JwtSigningPublicKeys:       keys,
+		JwtSigningPublicKeysFile:   config.JwtSigningPublicKeysFile,
+		ParentRefreshTokenRotation: parentRefreshTokenRotation,
+	}, nil
+}
+
 func normaliseSameSiteOrThrowError(sameSite string) (string, error) {
 	sameSite = strings.TrimSpace(sameSite)
 	sameSite = strings.ToLower(sameSite)
@@ -237,11 +283,31 @@ func getCurrTimeInMS() uint64 {
 	return uint64(time.Now().UnixNano() / 1000000)
 }
 
-func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, response sessmodels.CreateOrRefreshAPIResponse) {
+// getTokenTransferMethodForNewSession returns how the tokens of a new session should be sent. If both
+// are allowed, the frontend can ask for headers using the st-auth-mode header, otherwise cookies are used.
+func getTokenTransferMethodForNewSession(config sessmodels.TypeNormalisedInput, req *http.Request) string {
+	if config.TokenTransferMethod != tokenTransferMethod_ANY {
+		return config.TokenTransferMethod
+	}
+	if req != nil {
+		authMode := getAuthModeFromHeader(req)
+		if authMode != nil && strings.ToLower(*authMode) == tokenTransferMethod_HEADER {
+			return tokenTransferMethod_HEADER
+		}
+	}
+	return tokenTransferMethod_COOKIE
+}
+
+func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, response sessmodels.CreateOrRefreshAPIResponse, tokenTransferMethod string) {
 	accessToken := response.AccessToken
 	refreshToken := response.RefreshToken
 	idRefreshToken := response.IDRefreshToken
 	setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInJWT)
+	if tokenTransferMethod == tokenTransferMethod_HEADER {
+		attachAccessTokenToHeader(res, accessToken.Token)
+		attachRefreshTokenToHeader(res, refreshToken.Token)
+		return
+	}
 	attachAccessTokenToCookie(config, res, accessToken.Token, accessToken.Expiry)
 	attachRefreshTokenToCookie(config, res, refreshToken.Token, refreshToken.Expiry)
 	setIDRefreshTokenInHeaderAndCookie(config, res, idRefreshToken.Token, idRefreshToken.Expiry)
@@ -258,8 +324,8 @@ func sendUnauthorisedResponse(recipeInstance Recipe, _ string, _ *http.Request,
 	return supertokens.SendNon200Response(response, "unauthorised", recipeInstance.Config.SessionExpiredStatusCode)
 }
 
-func sendTokenTheftDetectedResponse(recipeInstance Recipe, sessionHandle string, _ string, _ *http.Request, response http.ResponseWriter) error {
-	_, err := recipeInstance.RecipeImpl.RevokeSession(sessionHandle)
+func sendTokenTheftDetectedResponse(recipeInstance Recipe, sessionHandle string, _ string, req *http.Request, response http.ResponseWriter) error {
+	_, err := recipeInstance.RecipeImpl.RevokeSession(req.Context(), sessionHandle)
 	if err != nil {
 		return err
 	}
@@ -269,20 +335,3 @@ func sendTokenTheftDetectedResponse(recipeInstance Recipe, sessionHandle string,
 func frontendHasInterceptor(req *http.Request) bool {
 	return getRidFromHeader(req) != nil
 }
-
-func getKeyInfoFromJson(response map[string]interface{}) []sessmodels.KeyInfo {
-	keyList := []sessmodels.KeyInfo{}
-
-	_, ok := response["jwtSigningPublicKeyList"]
-	if ok {
-		for _, k := range response["jwtSigningPublicKeyList"].([]interface{}) {
-			keyList = append(keyList, sessmodels.KeyInfo{
-				PublicKey:  (k.((map[string]interface{})))["publicKey"].(string),
-				ExpiryTime: uint64((k.((map[string]interface{})))["expiryTime"].(float64)),
-				CreatedAt:  uint64((k.((map[string]interface{})))["createdAt"].(float64)),
-			})
-		}
-	}
-
-	return keyList
-}
@@ -0,0 +1,42 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// AppleRedirectHandler handles the form that Apple posts to the backend after the user signs in,
+// since Sign in with Apple uses response_mode form_post
+func AppleRedirectHandler(apiImplementation tpmodels.APIInterface, options tpmodels.APIOptions) error {
+	if apiImplementation.AppleRedirectHandlerPOST == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	err := options.Req.ParseForm()
+	if err != nil {
+		return supertokens.BadInputError{Msg: "Could not parse the form posted by Apple"}
+	}
+	code := options.Req.PostForm.Get("code")
+	state := options.Req.PostForm.Get("state")
+	if code == "" {
+		return supertokens.BadInputError{Msg: "Please provide the code in the request body"}
+	}
+
+	return apiImplementation.AppleRedirectHandlerPOST(options.Req.Context(), code, state, options)
+}
@@ -0,0 +1,53 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestAppleRedirectHandlerRedirectsToWebsite(t *testing.T) {
+	websiteBasePath := "/auth"
+	appInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(supertokens.AppInfo{
+		AppName:         "apple",
+		APIDomain:       "https://api.example.com",
+		WebsiteDomain:   "https://example.com",
+		WebsiteBasePath: &websiteBasePath,
+	})
+	assert.NoError(t, err)
+
+	form := url.Values{"code": {"code1"}, "state": {"state1"}, "user": {`{"name":{}}`}}
+	req := httptest.NewRequest(http.MethodPost, "/auth/callback/apple", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res := httptest.NewRecorder()
+
+	err = AppleRedirectHandler(MakeAPIImplementation(), tpmodels.APIOptions{
+		AppInfo: appInfo,
+		Req:     req,
+		Res:     res,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSeeOther, res.Code)
+	assert.Equal(t, "https://example.com/auth/callback/apple?code=code1&state=state1", res.Header().Get("Location"))
+}
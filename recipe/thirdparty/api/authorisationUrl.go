@@ -45,7 +45,7 @@ func AuthorisationUrlAPI(apiImplementation tpmodels.APIInterface, options tpmode
 		return supertokens.BadInputError{Msg: "The third party provider " + thirdPartyId + " seems to not be configured on the backend. Please check your frontend and backend configs."}
 	}
 
-	result, err := apiImplementation.AuthorisationUrlGET(provider, options)
+	result, err := apiImplementation.AuthorisationUrlGET(options.Req.Context(), provider, options)
 	if err != nil {
 		return err
 	}
@@ -17,21 +17,29 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"reflect"
+	"time"
 
 	"github.com/derekstavis/go-qs"
 	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 func MakeAPIImplementation() tpmodels.APIInterface {
 	return tpmodels.APIInterface{
-		AuthorisationUrlGET: func(provider tpmodels.TypeProvider, options tpmodels.APIOptions) (tpmodels.AuthorisationUrlGETResponse, error) {
-			providerInfo := provider.Get(nil, nil)
+		AuthorisationUrlGET: func(ctx context.Context, provider tpmodels.TypeProvider, options tpmodels.APIOptions) (tpmodels.AuthorisationUrlGETResponse, error) {
+			providerInfo, err := provider.Get(ctx, nil, nil)
+			if err != nil {
+				return tpmodels.AuthorisationUrlGETResponse{}, err
+			}
 			params := map[string]string{}
 			for key, value := range providerInfo.AuthorisationRedirect.Params {
 				if reflect.ValueOf(value).Kind() == reflect.String {
@@ -45,6 +53,37 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 					}
 				}
 			}
+
+			state, err := generateRandomString()
+			if err != nil {
+				return tpmodels.AuthorisationUrlGETResponse{}, err
+			}
+			stateInfo := tpmodels.StateInfo{
+				ThirdPartyID: provider.ID,
+				ExpiresAt:    time.Now().Add(stateValidity),
+			}
+			params["state"] = state
+			if providerInfo.UsesIDToken {
+				stateInfo.Nonce, err = generateRandomString()
+				if err != nil {
+					return tpmodels.AuthorisationUrlGETResponse{}, err
+				}
+				params["nonce"] = stateInfo.Nonce
+			}
+			if providerInfo.UsePKCE {
+				stateInfo.CodeVerifier, err = generateRandomString()
+				if err != nil {
+					return tpmodels.AuthorisationUrlGETResponse{}, err
+				}
+				params["code_challenge"] = getCodeChallenge(stateInfo.CodeVerifier)
+				params["code_challenge_method"] = "S256"
+			}
+			err = options.Config.SignInAndUpFeature.StateStore.Save(ctx, state, stateInfo)
+			if err != nil {
+				return tpmodels.AuthorisationUrlGETResponse{}, err
+			}
+			setStateCookie(options, state)
+
 			paramsString, err := getParamString(params)
 			if err != nil {
 				return tpmodels.AuthorisationUrlGETResponse{}, err
@@ -57,20 +96,51 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 			}, nil
 		},
 
-		SignInUpPOST: func(provider tpmodels.TypeProvider, code, redirectURI string, options tpmodels.APIOptions) (tpmodels.SignInUpPOSTResponse, error) {
-			providerInfo := provider.Get(&redirectURI, &code)
+		SignInUpPOST: func(ctx context.Context, provider tpmodels.TypeProvider, code, redirectURI, state string, options tpmodels.APIOptions) (tpmodels.SignInUpPOSTResponse, error) {
+			invalidStateResponse := tpmodels.SignInUpPOSTResponse{
+				InvalidStateError: &struct{}{},
+			}
+			if !isValidState(options.Req, state) {
+				return invalidStateResponse, nil
+			}
+			stateInfo, err := options.Config.SignInAndUpFeature.StateStore.Consume(ctx, state)
+			if err != nil {
+				return tpmodels.SignInUpPOSTResponse{}, err
+			}
+			clearStateCookie(options, state)
+			if stateInfo == nil || stateInfo.ThirdPartyID != provider.ID {
+				return invalidStateResponse, nil
+			}
+
+			providerInfo, err := provider.Get(ctx, &redirectURI, &code)
+			if err != nil {
+				return tpmodels.SignInUpPOSTResponse{}, err
+			}
+			if providerInfo.UsePKCE {
+				if stateInfo.CodeVerifier == "" {
+					return invalidStateResponse, nil
+				}
+				if providerInfo.AccessTokenAPI.Params == nil {
+					providerInfo.AccessTokenAPI.Params = map[string]string{}
+				}
+				providerInfo.AccessTokenAPI.Params["code_verifier"] = stateInfo.CodeVerifier
+			}
 
-			accessTokenAPIResponse, err := postRequest(providerInfo)
+			accessTokenAPIResponse, err := postRequest(ctx, providerInfo)
 
 			if err != nil {
 				return tpmodels.SignInUpPOSTResponse{}, err
 			}
 
-			userInfo, err := providerInfo.GetProfileInfo(accessTokenAPIResponse)
+			userInfo, err := providerInfo.GetProfileInfo(ctx, accessTokenAPIResponse)
 			if err != nil {
 				return tpmodels.SignInUpPOSTResponse{}, err
 			}
 
+			if providerInfo.UsesIDToken && subtle.ConstantTimeCompare([]byte(getNonceFromIDToken(accessTokenAPIResponse)), []byte(stateInfo.Nonce)) != 1 {
+				return invalidStateResponse, nil
+			}
+
 			emailInfo := userInfo.Email
 			if emailInfo == nil {
 				return tpmodels.SignInUpPOSTResponse{
@@ -78,7 +148,7 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 				}, nil
 			}
 
-			response, err := options.RecipeImplementation.SignInUp(provider.ID, userInfo.ID, *emailInfo)
+			response, err := options.RecipeImplementation.SignInUp(ctx, provider.ID, userInfo.ID, *emailInfo)
 			if err != nil {
 				return tpmodels.SignInUpPOSTResponse{}, err
 			}
@@ -90,20 +160,36 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 				}, nil
 			}
 
+			tokenStore := options.Config.SignInAndUpFeature.TokenStore
+			if tokenStore != nil {
+				providerTokens, err := getProviderTokens(accessTokenAPIResponse)
+				if err != nil {
+					return tpmodels.SignInUpPOSTResponse{}, err
+				}
+				err = tokenStore.Save(ctx, response.OK.User.ID, provider.ID, providerTokens)
+				if err != nil {
+					return tpmodels.SignInUpPOSTResponse{}, err
+				}
+			}
+
 			if emailInfo.IsVerified {
-				tokenResponse, err := options.EmailVerificationRecipeImplementation.CreateEmailVerificationToken(response.OK.User.ID, response.OK.User.Email)
+				tokenResponse, err := options.EmailVerificationRecipeImplementation.CreateEmailVerificationToken(ctx, response.OK.User.ID, response.OK.User.Email)
 				if err != nil {
 					return tpmodels.SignInUpPOSTResponse{}, err
 				}
 				if tokenResponse.OK != nil {
-					_, err := options.EmailVerificationRecipeImplementation.VerifyEmailUsingToken(tokenResponse.OK.Token)
+					_, err := options.EmailVerificationRecipeImplementation.VerifyEmailUsingToken(ctx, tokenResponse.OK.Token)
 					if err != nil {
 						return tpmodels.SignInUpPOSTResponse{}, err
 					}
 				}
 			}
 
-			_, err = session.CreateNewSession(options.Res, response.OK.User.ID, nil, nil)
+			sessionUserID := response.OK.User.ID
+			if response.OK.PrimaryUserID != "" {
+				sessionUserID = response.OK.PrimaryUserID
+			}
+			_, err = session.CreateNewSessionWithContext(ctx, options.Req, options.Res, sessionUserID, nil, nil)
 			if err != nil {
 				return tpmodels.SignInUpPOSTResponse{}, err
 			}
@@ -119,23 +205,31 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 				},
 			}, nil
 		},
+
+		AppleRedirectHandlerPOST: func(ctx context.Context, code, state string, options tpmodels.APIOptions) error {
+			redirectURL := options.AppInfo.WebsiteDomain.GetAsStringDangerous() + options.AppInfo.WebsiteBasePath.GetAsStringDangerous() + "/callback/apple?" + url.Values{
+				"state": {state},
+				"code":  {code},
+			}.Encode()
+			http.Redirect(options.Res, options.Req, redirectURL, http.StatusSeeOther)
+			return nil
+		},
 	}
 }
 
-func postRequest(providerInfo tpmodels.TypeProviderGetResponse) (map[string]interface{}, error) {
+func postRequest(ctx context.Context, providerInfo tpmodels.TypeProviderGetResponse) (map[string]interface{}, error) {
 	querystring, err := getParamString(providerInfo.AccessTokenAPI.Params)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", providerInfo.AccessTokenAPI.URL, bytes.NewBuffer([]byte(querystring)))
+	req, err := http.NewRequestWithContext(ctx, "POST", providerInfo.AccessTokenAPI.URL, bytes.NewBuffer([]byte(querystring)))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("content-type", "application/x-www-form-urlencoded")
 	req.Header.Set("accept", "application/json") // few providers like github don't send back json response by default
 
-	client := &http.Client{}
-	response, err := client.Do(req)
+	response, err := supertokens.GetHTTPClientWithContext(ctx).Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,87 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+// getProviderTokens reads the tokens from the response of a provider's access token API
+func getProviderTokens(accessTokenAPIResponse map[string]interface{}) (tpmodels.ProviderTokens, error) {
+	accessToken, _ := accessTokenAPIResponse["access_token"].(string)
+	if accessToken == "" {
+		if errorCode, ok := accessTokenAPIResponse["error"].(string); ok {
+			return tpmodels.ProviderTokens{}, errors.New("access token request failed: " + errorCode)
+		}
+		return tpmodels.ProviderTokens{}, errors.New("no access token in the access token response")
+	}
+	tokens := tpmodels.ProviderTokens{AccessToken: accessToken}
+	tokens.RefreshToken, _ = accessTokenAPIResponse["refresh_token"].(string)
+
+	// expires_in is a number, but some providers send it as a string
+	var expiresIn int64
+	switch value := accessTokenAPIResponse["expires_in"].(type) {
+	case float64:
+		expiresIn = int64(value)
+	case string:
+		expiresIn, _ = strconv.ParseInt(value, 10, 64)
+	}
+	if expiresIn > 0 {
+		tokens.ExpiresAt = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return tokens, nil
+}
+
+// RefreshProviderTokens gets a new access token using the refresh_token grant of the provider. If
+// the provider does not issue a new refresh token, the current one is kept.
+func RefreshProviderTokens(ctx context.Context, provider tpmodels.TypeProvider, tokens tpmodels.ProviderTokens) (tpmodels.ProviderTokens, error) {
+	if tokens.RefreshToken == "" {
+		return tpmodels.ProviderTokens{}, errors.New("the access token of " + provider.ID + " has expired, and there is no refresh token")
+	}
+	providerInfo, err := provider.Get(ctx, nil, nil)
+	if err != nil {
+		return tpmodels.ProviderTokens{}, err
+	}
+
+	// the client credentials are the same as for the authorisation code
+	params := map[string]string{}
+	for key, value := range providerInfo.AccessTokenAPI.Params {
+		if key == "client_id" || key == "client_secret" {
+			params[key] = value
+		}
+	}
+	params["grant_type"] = "refresh_token"
+	params["refresh_token"] = tokens.RefreshToken
+	providerInfo.AccessTokenAPI.Params = params
+
+	accessTokenAPIResponse, err := postRequest(ctx, providerInfo)
+	if err != nil {
+		return tpmodels.ProviderTokens{}, err
+	}
+	refreshedTokens, err := getProviderTokens(accessTokenAPIResponse)
+	if err != nil {
+		return tpmodels.ProviderTokens{}, err
+	}
+	if refreshedTokens.RefreshToken == "" {
+		refreshedTokens.RefreshToken = tokens.RefreshToken
+	}
+	return refreshedTokens, nil
+}
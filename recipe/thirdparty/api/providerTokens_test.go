@@ -0,0 +1,81 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+func TestRefreshProviderTokens(t *testing.T) {
+	response := `{"access_token":"access2","expires_in":3600,"token_type":"Bearer"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "refresh1", r.PostForm.Get("refresh_token"))
+		assert.Equal(t, "client1", r.PostForm.Get("client_id"))
+		assert.Equal(t, "secret", r.PostForm.Get("client_secret"))
+		assert.Empty(t, r.PostForm.Get("code"))
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	provider := tpmodels.TypeProvider{
+		ID: "custom",
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL: server.URL,
+					Params: map[string]string{
+						"client_id":     "client1",
+						"client_secret": "secret",
+						"grant_type":    "authorization_code",
+						"code":          "",
+					},
+				},
+			}, nil
+		},
+	}
+
+	tokens, err := RefreshProviderTokens(context.Background(), provider, tpmodels.ProviderTokens{
+		AccessToken:  "access1",
+		RefreshToken: "refresh1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "access2", tokens.AccessToken)
+	// the refresh token is kept if no new one is issued
+	assert.Equal(t, "refresh1", tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tokens.ExpiresAt, time.Minute)
+
+	response = `{"access_token":"access3","refresh_token":"refresh2","expires_in":"60"}`
+	tokens, err = RefreshProviderTokens(context.Background(), provider, tpmodels.ProviderTokens{RefreshToken: "refresh1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh2", tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), tokens.ExpiresAt, 10*time.Second)
+
+	response = `{"error":"invalid_grant"}`
+	_, err = RefreshProviderTokens(context.Background(), provider, tpmodels.ProviderTokens{RefreshToken: "refresh1"})
+	assert.EqualError(t, err, "access token request failed: invalid_grant")
+
+	_, err = RefreshProviderTokens(context.Background(), provider, tpmodels.ProviderTokens{AccessToken: "access1"})
+	assert.Error(t, err)
+}
@@ -28,6 +28,7 @@ type bodyParams struct {
 	ThirdPartyId string `json:"thirdPartyId"`
 	Code         string `json:"code"`
 	RedirectURI  string `json:"redirectURI"`
+	State        string `json:"state"`
 }
 
 func SignInUpAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOptions) error {
@@ -69,7 +70,7 @@ func SignInUpAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOp
 		return supertokens.BadInputError{Msg: "The third party provider " + bodyParams.ThirdPartyId + " seems to not be configured on the backend. Please check your frontend and backend configs."}
 	}
 
-	result, err := apiImplementation.SignInUpPOST(provider, bodyParams.Code, bodyParams.RedirectURI, options)
+	result, err := apiImplementation.SignInUpPOST(options.Req.Context(), provider, bodyParams.Code, bodyParams.RedirectURI, bodyParams.State, options)
 
 	if err != nil {
 		return err
@@ -85,6 +86,10 @@ func SignInUpAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOp
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "NO_EMAIL_GIVEN_BY_PROVIDER",
 		})
+	} else if result.InvalidStateError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "INVALID_STATE_ERROR",
+		})
 	} else {
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "FIELD_ERROR",
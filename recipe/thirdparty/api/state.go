@@ -0,0 +1,129 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+const (
+	// stateValidity is how long the user has to log in with the provider
+	stateValidity = 10 * time.Minute
+	// stateCookiePrefix is followed by a hash of the state, so that logins started in several tabs
+	// of one browser do not overwrite each other's cookie
+	stateCookiePrefix = "sThirdPartyState_"
+)
+
+func generateRandomString() (string, error) {
+	value := make([]byte, 32)
+	_, err := rand.Read(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// getCodeChallenge returns the S256 PKCE code challenge of a code verifier
+func getCodeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// setStateCookie binds the state to the browser that started the login, so that a code obtained
+// by someone else cannot be used to log this browser in (login CSRF)
+func setStateCookie(options tpmodels.APIOptions, state string) {
+	secure := strings.HasPrefix(options.AppInfo.APIDomain.GetAsStringDangerous(), "https://")
+	sameSite := http.SameSiteLaxMode
+	if secure {
+		// the website and the API may be on different sites
+		sameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(options.Res, &http.Cookie{
+		Name:     getStateCookieName(state),
+		Value:    state,
+		Path:     getStateCookiePath(options),
+		MaxAge:   int(stateValidity.Seconds()),
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: sameSite,
+	})
+}
+
+func clearStateCookie(options tpmodels.APIOptions, state string) {
+	http.SetCookie(options.Res, &http.Cookie{
+		Name:     getStateCookieName(state),
+		Path:     getStateCookiePath(options),
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+func getStateCookieName(state string) string {
+	hash := sha256.Sum256([]byte(state))
+	return stateCookiePrefix + hex.EncodeToString(hash[:8])
+}
+
+func getStateCookiePath(options tpmodels.APIOptions) string {
+	path := options.AppInfo.APIBasePath.GetAsStringDangerous()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// isValidState checks that the state sent by the frontend is the one given to this browser
+func isValidState(req *http.Request, state string) bool {
+	if state == "" {
+		return false
+	}
+	cookie, err := req.Cookie(getStateCookieName(state))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) == 1
+}
+
+// getNonceFromIDToken reads the nonce claim of the id token in the access token response. The id
+// token's signature must have been verified already (which GetProfileInfo does).
+func getNonceFromIDToken(accessTokenAPIResponse map[string]interface{}) string {
+	idToken, _ := accessTokenAPIResponse["id_token"].(string)
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Nonce string `json:"nonce"`
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return ""
+	}
+	return claims.Nonce
+}
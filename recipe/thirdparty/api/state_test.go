@@ -0,0 +1,220 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestSignInUpPOSTRejectsStateNotIssuedToTheBrowser(t *testing.T) {
+	appInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(supertokens.AppInfo{
+		AppName:       "state",
+		APIDomain:     "https://api.example.com",
+		WebsiteDomain: "https://example.com",
+	})
+	assert.NoError(t, err)
+
+	states := map[string]tpmodels.StateInfo{}
+	config := tpmodels.TypeNormalisedInput{
+		SignInAndUpFeature: tpmodels.TypeNormalisedInputSignInAndUp{
+			StateStore: tpmodels.StateStore{
+				Save: func(ctx context.Context, state string, info tpmodels.StateInfo) error {
+					states[state] = info
+					return nil
+				},
+				Consume: func(ctx context.Context, state string) (*tpmodels.StateInfo, error) {
+					info, ok := states[state]
+					if !ok {
+						return nil, nil
+					}
+					delete(states, state)
+					return &info, nil
+				},
+			},
+		},
+	}
+	exchangedCode := false
+	provider := tpmodels.TypeProvider{
+		ID: "oidc",
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			if authCodeFromRequest != nil {
+				exchangedCode = true
+			}
+			return tpmodels.TypeProviderGetResponse{
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    "https://provider.example.com/authorize",
+					Params: map[string]interface{}{"client_id": "client", "state": "fixed"},
+				},
+				UsesIDToken: true,
+			}, nil
+		},
+	}
+	apiImplementation := MakeAPIImplementation()
+
+	res := httptest.NewRecorder()
+	result, err := apiImplementation.AuthorisationUrlGET(context.Background(), provider, tpmodels.APIOptions{
+		Config:  config,
+		AppInfo: appInfo,
+		Req:     httptest.NewRequest(http.MethodGet, "/auth/authorisationurl?thirdPartyId=oidc", nil),
+		Res:     res,
+	})
+	assert.NoError(t, err)
+	authorisationURL, err := url.Parse(result.OK.Url)
+	assert.NoError(t, err)
+	state := authorisationURL.Query().Get("state")
+	assert.NotEqual(t, "fixed", state)
+	assert.Len(t, states, 1)
+	assert.Equal(t, "oidc", states[state].ThirdPartyID)
+	assert.Equal(t, states[state].Nonce, authorisationURL.Query().Get("nonce"))
+	assert.NotEmpty(t, states[state].Nonce)
+
+	cookies := res.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, getStateCookieName(state), cookies[0].Name)
+	assert.Equal(t, state, cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, "/auth", cookies[0].Path)
+
+	signInUp := func(cookie *http.Cookie, state string) tpmodels.SignInUpPOSTResponse {
+		req := httptest.NewRequest(http.MethodPost, "/auth/signinup", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		response, err := apiImplementation.SignInUpPOST(context.Background(), provider, "code", "https://example.com/auth/callback/oidc", state, tpmodels.APIOptions{
+			Config:  config,
+			AppInfo: appInfo,
+			Req:     req,
+			Res:     httptest.NewRecorder(),
+		})
+		assert.NoError(t, err)
+		return response
+	}
+
+	// a state without the cookie, as in a link sent by an attacker
+	assert.NotNil(t, signInUp(nil, state).InvalidStateError)
+	// a cookie that does not match the state
+	assert.NotNil(t, signInUp(&http.Cookie{Name: getStateCookieName(state), Value: "other"}, state).InvalidStateError)
+	assert.NotNil(t, signInUp(&http.Cookie{Name: getStateCookieName(""), Value: ""}, "").InvalidStateError)
+	assert.Len(t, states, 1)
+
+	// a state for another provider
+	states[state] = tpmodels.StateInfo{ThirdPartyID: "google"}
+	assert.NotNil(t, signInUp(cookies[0], state).InvalidStateError)
+	assert.Len(t, states, 0)
+	// the state can only be used once
+	assert.NotNil(t, signInUp(cookies[0], state).InvalidStateError)
+
+	assert.False(t, exchangedCode)
+}
+
+func TestSignInUpPOSTSendsPKCECodeVerifier(t *testing.T) {
+	appInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(supertokens.AppInfo{
+		AppName:       "pkce",
+		APIDomain:     "http://localhost:3001",
+		WebsiteDomain: "http://localhost:3000",
+	})
+	assert.NoError(t, err)
+
+	var codeVerifier string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		codeVerifier = r.PostForm.Get("code_verifier")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token"}`))
+	}))
+	defer tokenServer.Close()
+
+	states := map[string]tpmodels.StateInfo{}
+	config := tpmodels.TypeNormalisedInput{
+		SignInAndUpFeature: tpmodels.TypeNormalisedInputSignInAndUp{
+			StateStore: tpmodels.StateStore{
+				Save: func(ctx context.Context, state string, info tpmodels.StateInfo) error {
+					states[state] = info
+					return nil
+				},
+				Consume: func(ctx context.Context, state string) (*tpmodels.StateInfo, error) {
+					info := states[state]
+					delete(states, state)
+					return &info, nil
+				},
+			},
+		},
+	}
+	provider := tpmodels.TypeProvider{
+		ID: "custom",
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    tokenServer.URL,
+					Params: map[string]string{"client_id": "client"},
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    "https://provider.example.com/authorize",
+					Params: map[string]interface{}{"client_id": "client"},
+				},
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+					return tpmodels.UserInfo{ID: "user"}, nil
+				},
+				UsePKCE: true,
+			}, nil
+		},
+	}
+	apiImplementation := MakeAPIImplementation()
+
+	res := httptest.NewRecorder()
+	result, err := apiImplementation.AuthorisationUrlGET(context.Background(), provider, tpmodels.APIOptions{
+		Config:  config,
+		AppInfo: appInfo,
+		Req:     httptest.NewRequest(http.MethodGet, "/auth/authorisationurl?thirdPartyId=custom", nil),
+		Res:     res,
+	})
+	assert.NoError(t, err)
+	authorisationURL, err := url.Parse(result.OK.Url)
+	assert.NoError(t, err)
+	state := authorisationURL.Query().Get("state")
+	assert.Equal(t, "S256", authorisationURL.Query().Get("code_challenge_method"))
+	assert.Equal(t, getCodeChallenge(states[state].CodeVerifier), authorisationURL.Query().Get("code_challenge"))
+	assert.Empty(t, authorisationURL.Query().Get("nonce"))
+
+	cookies := res.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.False(t, cookies[0].Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/signinup", nil)
+	req.AddCookie(cookies[0])
+	expectedCodeVerifier := states[state].CodeVerifier
+	response, err := apiImplementation.SignInUpPOST(context.Background(), provider, "code", "http://localhost:3000/auth/callback/custom", state, tpmodels.APIOptions{
+		Config:  config,
+		AppInfo: appInfo,
+		Req:     req,
+		Res:     httptest.NewRecorder(),
+	})
+	assert.NoError(t, err)
+	// the provider gives no email, so the user is not signed in
+	assert.NotNil(t, response.NoEmailGivenByProviderError)
+	assert.Equal(t, expectedCodeVerifier, codeVerifier)
+	assert.Len(t, codeVerifier, 43)
+}
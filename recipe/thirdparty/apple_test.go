@@ -0,0 +1,77 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdparty
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func TestAppleAuthorisationURL(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpmodels.TypeInput{
+			SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
+				Providers: []tpmodels.TypeProvider{
+					Apple(tpmodels.AppleConfig{
+						ClientID: "com.example.app",
+						ClientSecret: tpmodels.AppleClientSecret{
+							KeyId:      "KEY123",
+							TeamId:     "TEAM123",
+							PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+						},
+					}),
+				},
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/auth/authorisationurl?thirdPartyId=apple", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+	var response struct {
+		Status string `json:"status"`
+		URL    string `json:"url"`
+	}
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &response))
+	assert.Equal(t, "OK", response.Status)
+	authorisationURL, err := url.Parse(response.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "appleid.apple.com", authorisationURL.Host)
+	assert.Equal(t, "com.example.app", authorisationURL.Query().Get("client_id"))
+	assert.Equal(t, "form_post", authorisationURL.Query().Get("response_mode"))
+	assert.Equal(t, "name email", authorisationURL.Query().Get("scope"))
+}
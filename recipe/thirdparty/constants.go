@@ -16,6 +16,7 @@
 package thirdparty
 
 const (
-	AuthorisationAPI = "/authorisationurl"
-	SignInUpAPI      = "/signinup"
+	AuthorisationAPI        = "/authorisationurl"
+	SignInUpAPI             = "/signinup"
+	AppleRedirectHandlerAPI = "/callback/apple"
 )
@@ -16,6 +16,7 @@
 package thirdparty
 
 import (
+	"context"
 	"errors"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
@@ -34,103 +35,159 @@ func Init(config *tpmodels.TypeInput) supertokens.Recipe {
 }
 
 func SignInUp(thirdPartyID string, thirdPartyUserID string, email tpmodels.EmailStruct) (tpmodels.SignInUpResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return SignInUpWithContext(context.Background(), thirdPartyID, thirdPartyUserID, email)
+}
+
+func SignInUpWithContext(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email tpmodels.EmailStruct) (tpmodels.SignInUpResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return tpmodels.SignInUpResponse{}, err
 	}
-	return instance.RecipeImpl.SignInUp(thirdPartyID, thirdPartyUserID, email)
+	return instance.RecipeImpl.SignInUp(ctx, thirdPartyID, thirdPartyUserID, email)
 }
 
 func GetUserByID(userID string) (*tpmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUserByIDWithContext(context.Background(), userID)
+}
+
+func GetUserByIDWithContext(ctx context.Context, userID string) (*tpmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetUserByID(userID)
+	return instance.RecipeImpl.GetUserByID(ctx, userID)
 }
 
 func GetUsersByEmail(email string) ([]tpmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUsersByEmailWithContext(context.Background(), email)
+}
+
+func GetUsersByEmailWithContext(ctx context.Context, email string) ([]tpmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return []tpmodels.User{}, err
 	}
-	return instance.RecipeImpl.GetUsersByEmail(email)
+	return instance.RecipeImpl.GetUsersByEmail(ctx, email)
 }
 
 func GetUserByThirdPartyInfo(thirdPartyID, thirdPartyUserID string) (*tpmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUserByThirdPartyInfoWithContext(context.Background(), thirdPartyID, thirdPartyUserID)
+}
+
+func GetUserByThirdPartyInfoWithContext(ctx context.Context, thirdPartyID, thirdPartyUserID string) (*tpmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetUserByThirdPartyInfo(thirdPartyID, thirdPartyUserID)
+	return instance.RecipeImpl.GetUserByThirdPartyInfo(ctx, thirdPartyID, thirdPartyUserID)
 }
 
 func CreateEmailVerificationToken(userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return CreateEmailVerificationTokenWithContext(context.Background(), userID)
+}
+
+func CreateEmailVerificationTokenWithContext(ctx context.Context, userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.CreateEmailVerificationToken(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.CreateEmailVerificationToken(ctx, userID, email)
 }
 
 func VerifyEmailUsingToken(token string) (*tpmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return VerifyEmailUsingTokenWithContext(context.Background(), token)
+}
+
+func VerifyEmailUsingTokenWithContext(ctx context.Context, token string) (*tpmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	response, err := instance.EmailVerificationRecipe.RecipeImpl.VerifyEmailUsingToken(token)
+	response, err := instance.EmailVerificationRecipe.RecipeImpl.VerifyEmailUsingToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
 	if response.EmailVerificationInvalidTokenError != nil {
 		return nil, errors.New("email verification token is invalid")
 	}
-	return instance.RecipeImpl.GetUserByID(response.OK.User.ID)
+	return instance.RecipeImpl.GetUserByID(ctx, response.OK.User.ID)
 }
 
 func IsEmailVerified(userID string) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return IsEmailVerifiedWithContext(context.Background(), userID)
+}
+
+func IsEmailVerifiedWithContext(ctx context.Context, userID string) (bool, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return false, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return false, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.IsEmailVerified(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.IsEmailVerified(ctx, userID, email)
 }
 
 func RevokeEmailVerificationTokens(userID string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RevokeEmailVerificationTokensWithContext(context.Background(), userID)
+}
+
+func RevokeEmailVerificationTokensWithContext(ctx context.Context, userID string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.RevokeEmailVerificationTokens(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.RevokeEmailVerificationTokens(ctx, userID, email)
 }
 
 func UnverifyEmail(userID string) (evmodels.UnverifyEmailResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UnverifyEmailWithContext(context.Background(), userID)
+}
+
+func UnverifyEmailWithContext(ctx context.Context, userID string) (evmodels.UnverifyEmailResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.UnverifyEmail(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.UnverifyEmail(ctx, userID, email)
+}
+
+func GetProviderAccessToken(userID string, thirdPartyID string) (string, error) {
+	return GetProviderAccessTokenWithContext(context.Background(), userID, thirdPartyID)
+}
+
+func GetProviderAccessTokenWithContext(ctx context.Context, userID string, thirdPartyID string) (string, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return "", err
+	}
+	return instance.GetProviderAccessToken(ctx, userID, thirdPartyID)
+}
+
+func Apple(config tpmodels.AppleConfig) tpmodels.TypeProvider {
+	return providers.Apple(config)
+}
+
+func Bitbucket(config tpmodels.BitbucketConfig) tpmodels.TypeProvider {
+	return providers.Bitbucket(config)
 }
 
-// func Apple(config tpmodels.AppleConfig) tpmodels.TypeProvider {
-// 	return providers.Apple(config)
-// }
+func Discord(config tpmodels.DiscordConfig) tpmodels.TypeProvider {
+	return providers.Discord(config)
+}
 
 func Facebook(config tpmodels.FacebookConfig) tpmodels.TypeProvider {
 	return providers.Facebook(config)
@@ -140,6 +197,14 @@ func Github(config tpmodels.GithubConfig) tpmodels.TypeProvider {
 	return providers.Github(config)
 }
 
+func Gitlab(config tpmodels.GitlabConfig) tpmodels.TypeProvider {
+	return providers.Gitlab(config)
+}
+
 func Google(config tpmodels.GoogleConfig) tpmodels.TypeProvider {
 	return providers.Google(config)
 }
+
+func Microsoft(config tpmodels.MicrosoftConfig) tpmodels.TypeProvider {
+	return providers.Microsoft(config)
+}
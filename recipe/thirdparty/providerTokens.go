@@ -0,0 +1,61 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdparty
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/api"
+)
+
+// accessTokenExpirySkew is how long before it expires an access token is refreshed
+const accessTokenExpirySkew = time.Minute
+
+// GetProviderAccessToken returns the access token that the provider issued for the user when they last
+// signed in, refreshed if it has expired
+func (r *Recipe) GetProviderAccessToken(ctx context.Context, userID string, thirdPartyID string) (string, error) {
+	tokenStore := r.Config.SignInAndUpFeature.TokenStore
+	if tokenStore == nil {
+		return "", errors.New("please set a TokenStore in the signInAndUpFeature config to get the access tokens of providers")
+	}
+	tokens, err := tokenStore.Get(ctx, userID, thirdPartyID)
+	if err != nil {
+		return "", err
+	}
+	if tokens == nil {
+		return "", errors.New("there are no " + thirdPartyID + " tokens for this user")
+	}
+	if tokens.ExpiresAt.IsZero() || time.Now().Add(accessTokenExpirySkew).Before(tokens.ExpiresAt) {
+		return tokens.AccessToken, nil
+	}
+
+	for _, provider := range r.Providers {
+		if provider.ID == thirdPartyID {
+			refreshedTokens, err := api.RefreshProviderTokens(ctx, provider, *tokens)
+			if err != nil {
+				return "", err
+			}
+			err = tokenStore.Save(ctx, userID, thirdPartyID, refreshedTokens)
+			if err != nil {
+				return "", err
+			}
+			return refreshedTokens.AccessToken, nil
+		}
+	}
+	return "", errors.New("the third party provider " + thirdPartyID + " is not configured")
+}
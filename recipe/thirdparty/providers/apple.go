@@ -15,88 +15,175 @@
 
 package providers
 
-// import (
-// 	"encoding/json"
-// 	"strings"
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
 
-// 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
-// )
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
 
-// const appleID = "apple"
+const (
+	appleID     = "apple"
+	appleIssuer = "https://appleid.apple.com"
+	// appleClientSecretValidity must be at most 6 months
+	appleClientSecretValidity = 24 * time.Hour
+	// appleRedirectHandlerPath is the API (relative to the API base path) that Apple posts the code to
+	appleRedirectHandlerPath = "/callback/apple"
+)
 
-// func Apple(config tpmodels.AppleConfig) tpmodels.TypeProvider {
-// 	return tpmodels.TypeProvider{
-// 		ID: appleID,
-// 		Get: func(redirectURI, authCodeFromRequest *string) tpmodels.TypeProviderGetResponse {
-// 			accessTokenAPIURL := "https://appleid.apple.com/auth/token"
-// 			clientSecret, _ := getClientSecret(config.ClientID, config.ClientSecret.KeyId, config.ClientSecret.TeamId, config.ClientSecret.PrivateKey)
-// 			accessTokenAPIParams := map[string]string{
-// 				"client_id":     config.ClientID,
-// 				"client_secret": clientSecret,
-// 				"grant_type":    "authorization_code",
-// 			}
-// 			if authCodeFromRequest != nil {
-// 				accessTokenAPIParams["code"] = *authCodeFromRequest
-// 			}
-// 			if redirectURI != nil {
-// 				accessTokenAPIParams["redirect_uri"] = *redirectURI
-// 			}
+func Apple(config tpmodels.AppleConfig) tpmodels.TypeProvider {
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: appleIssuer + "/auth/authorize",
+		TokenURL:         appleIssuer + "/auth/token",
+		JWKSURL:          appleIssuer + "/auth/keys",
+	})
+	return tpmodels.TypeProvider{
+		ID: appleID,
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
+			clientSecret, err := getAppleClientSecret(config.ClientID, config.ClientSecret)
+			if err != nil {
+				return tpmodels.TypeProviderGetResponse{}, err
+			}
+			accessTokenAPIParams := map[string]string{
+				"client_id":     config.ClientID,
+				"client_secret": clientSecret,
+				"grant_type":    "authorization_code",
+			}
+			if authCodeFromRequest != nil {
+				accessTokenAPIParams["code"] = *authCodeFromRequest
+			}
+			if redirectURI != nil {
+				accessTokenAPIParams["redirect_uri"] = *redirectURI
+			}
 
-// 			authorisationRedirectURL := "https://appleid.apple.com/auth/authorize"
-// 			scopes := []string{"name", "email"}
-// 			if config.Scope != nil {
-// 				scopes = append(scopes, config.Scope...)
-// 			}
+			authorisationRedirectURL := endpoints.AuthorisationURL
+			scopes := []string{"name", "email"}
+			if config.Scope != nil {
+				scopes = config.Scope
+			}
 
-// 			var additionalParams map[string]interface{} = nil
-// 			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
-// 				additionalParams = config.AuthorisationRedirect.Params
-// 			}
+			var additionalParams map[string]interface{} = nil
+			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
+				additionalParams = config.AuthorisationRedirect.Params
+			}
 
-// 			authorizationRedirectParams := map[string]interface{}{
-// 				"scope":         strings.Join(scopes, " "),
-// 				"response_mode": "form_post",
-// 				"response_type": "code",
-// 				"client_id":     config.ClientID,
-// 			}
-// 			for key, value := range additionalParams {
-// 				authorizationRedirectParams[key] = value
-// 			}
+			// Apple posts the code to the backend (response_mode form_post), which redirects the user to
+			// the frontend with the code in the query params
+			authorizationRedirectParams := map[string]interface{}{
+				"scope":         strings.Join(scopes, " "),
+				"response_mode": "form_post",
+				"response_type": "code",
+				"client_id":     config.ClientID,
+				"redirect_uri":  getAppleRedirectHandlerURL,
+			}
+			for key, value := range additionalParams {
+				authorizationRedirectParams[key] = value
+			}
 
-// 			return tpmodels.TypeProviderGetResponse{
-// 				AccessTokenAPI: tpmodels.AccessTokenAPI{
-// 					URL:    accessTokenAPIURL,
-// 					Params: accessTokenAPIParams,
-// 				},
-// 				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
-// 					URL:    authorisationRedirectURL,
-// 					Params: authorizationRedirectParams,
-// 				},
-// 				GetProfileInfo: func(authCodeResponse interface{}) (tpmodels.UserInfo, error) {
-// 					authCodeResponseJson, err := json.Marshal(authCodeResponse)
-// 					if err != nil {
-// 						return tpmodels.UserInfo{}, err
-// 					}
-// 					var accessTokenAPIResponse appleGetProfileInfoInput
-// 					err = json.Unmarshal(authCodeResponseJson, &accessTokenAPIResponse)
-// 					if err != nil {
-// 						return tpmodels.UserInfo{}, err
-// 					}
-// 					return tpmodels.UserInfo{}, nil
-// 				},
-// 			}
-// 		},
-// 	}
-// }
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    accessTokenAPIURL,
+					Params: accessTokenAPIParams,
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    authorisationRedirectURL,
+					Params: authorizationRedirectParams,
+				},
+				UsesIDToken: true,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+					authCodeResponseJson, err := json.Marshal(authCodeResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					var accessTokenAPIResponse appleGetProfileInfoInput
+					err = json.Unmarshal(authCodeResponseJson, &accessTokenAPIResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					claims, err := verifyIDToken(ctx, accessTokenAPIResponse.IDToken, getJWKS(endpoints.JWKSURL), func(issuer string) bool {
+						return issuer == appleIssuer
+					}, config.ClientID)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					return getUserInfoFromClaims(claims)
+				},
+			}, nil
+		},
+	}
+}
 
-// func getClientSecret(clientId, keyId, teamId, privateKey string) (string, error) {
-// 	return "", nil
-// }
+func getAppleRedirectHandlerURL(req *http.Request) string {
+	instance, err := supertokens.GetInstanceOrThrowError(req.Context())
+	if err != nil {
+		return ""
+	}
+	return instance.AppInfo.APIDomain.GetAsStringDangerous() + instance.AppInfo.APIBasePath.GetAsStringDangerous() + appleRedirectHandlerPath
+}
 
-// type appleGetProfileInfoInput struct {
-// 	AccessToken  string `json:"access_token"`
-// 	ExpiresIn    int    `json:"expires_in"`
-// 	TokenType    string `json:"token_type"`
-// 	RefreshToken string `json:"refresh_token"`
-// 	IDToken      string `json:"id_token"`
-// }
+// getAppleClientSecret returns the client secret JWT that Apple requires instead of a static secret,
+// signed with ES256 using the private key of the Sign in with Apple key
+func getAppleClientSecret(clientId string, clientSecret tpmodels.AppleClientSecret) (string, error) {
+	block, _ := pem.Decode([]byte(clientSecret.PrivateKey))
+	if block == nil {
+		return "", errors.New("Apple private key must be in PEM format")
+	}
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	privateKey, ok := parsedKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return "", errors.New("Apple private key must be an EC key")
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "ES256",
+		"kid": clientSecret.KeyId,
+	})
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	payload, err := json.Marshal(map[string]interface{}{
+		"iss": clientSecret.TeamId,
+		"iat": now.Unix(),
+		"exp": now.Add(appleClientSecretValidity).Unix(),
+		"aud": appleIssuer,
+		"sub": clientId,
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+type appleGetProfileInfoInput struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+}
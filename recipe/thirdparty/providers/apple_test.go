@@ -0,0 +1,94 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+func TestAppleClientSecret(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+	privateKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	provider := Apple(tpmodels.AppleConfig{
+		ClientID: "com.example.app",
+		ClientSecret: tpmodels.AppleClientSecret{
+			KeyId:      "KEY123",
+			TeamId:     "TEAM123",
+			PrivateKey: privateKeyPEM,
+		},
+	})
+	providerInfo, err := provider.Get(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "form_post", providerInfo.AuthorisationRedirect.Params["response_mode"])
+	assert.Equal(t, "name email", providerInfo.AuthorisationRedirect.Params["scope"])
+
+	parts := strings.Split(providerInfo.AccessTokenAPI.Params["client_secret"], ".")
+	assert.Len(t, parts, 3)
+	var header map[string]string
+	assert.NoError(t, decodeJWTPart(parts[0], &header))
+	assert.Equal(t, map[string]string{"alg": "ES256", "kid": "KEY123"}, header)
+	var claims map[string]interface{}
+	assert.NoError(t, decodeJWTPart(parts[1], &claims))
+	assert.Equal(t, "TEAM123", claims["iss"])
+	assert.Equal(t, "com.example.app", claims["sub"])
+	assert.Equal(t, "https://appleid.apple.com", claims["aud"])
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(&privateKey.PublicKey, digest[:], r, s))
+
+	provider = Apple(tpmodels.AppleConfig{
+		ClientID: "com.example.app",
+		ClientSecret: tpmodels.AppleClientSecret{
+			KeyId:      "KEY123",
+			TeamId:     "TEAM123",
+			PrivateKey: privateKeyPEM,
+		},
+		Scope: []string{"email"},
+	})
+	providerInfo, err = provider.Get(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "email", providerInfo.AuthorisationRedirect.Params["scope"])
+}
+
+func TestAppleInvalidPrivateKey(t *testing.T) {
+	provider := Apple(tpmodels.AppleConfig{
+		ClientID:     "com.example.app",
+		ClientSecret: tpmodels.AppleClientSecret{PrivateKey: "not a key"},
+	})
+	_, err := provider.Get(context.Background(), nil, nil)
+	assert.Error(t, err)
+}
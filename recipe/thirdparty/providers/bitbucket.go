@@ -0,0 +1,153 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+const bitbucketID = "bitbucket"
+
+func Bitbucket(config tpmodels.BitbucketConfig) tpmodels.TypeProvider {
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: "https://bitbucket.org/site/oauth2/authorize",
+		TokenURL:         "https://bitbucket.org/site/oauth2/access_token",
+		UserInfoURL:      "https://api.bitbucket.org/2.0/user",
+	})
+	return tpmodels.TypeProvider{
+		ID: bitbucketID,
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
+			accessTokenAPIParams := map[string]string{
+				"client_id":     config.ClientID,
+				"client_secret": config.ClientSecret,
+				"grant_type":    "authorization_code",
+			}
+			if authCodeFromRequest != nil {
+				accessTokenAPIParams["code"] = *authCodeFromRequest
+			}
+			if redirectURI != nil {
+				accessTokenAPIParams["redirect_uri"] = *redirectURI
+			}
+
+			authorisationRedirectURL := endpoints.AuthorisationURL
+			scopes := []string{"account", "email"}
+			if config.Scope != nil {
+				scopes = config.Scope
+			}
+
+			var additionalParams map[string]interface{} = nil
+			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
+				additionalParams = config.AuthorisationRedirect.Params
+			}
+
+			authorizationRedirectParams := map[string]interface{}{
+				"scope":         strings.Join(scopes, " "),
+				"response_type": "code",
+				"client_id":     config.ClientID,
+			}
+			for key, value := range additionalParams {
+				authorizationRedirectParams[key] = value
+			}
+
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    accessTokenAPIURL,
+					Params: accessTokenAPIParams,
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    authorisationRedirectURL,
+					Params: authorizationRedirectParams,
+				},
+				UsePKCE: config.UsePKCE,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+					authCodeResponseJson, err := json.Marshal(authCodeResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					var accessTokenAPIResponse bitbucketGetProfileInfoInput
+					err = json.Unmarshal(authCodeResponseJson, &accessTokenAPIResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					authHeader := "Bearer " + accessTokenAPIResponse.AccessToken
+					userInfo, err := getBitbucketUserInfo(ctx, endpoints.UserInfoURL, authHeader)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					if userInfo.UUID == "" {
+						return tpmodels.UserInfo{}, errors.New("Bitbucket did not return the user's ID")
+					}
+					// the user API never has the email, so the primary one is taken from the emails API
+					emailsInfo, err := getBitbucketEmailsInfo(ctx, endpoints.UserInfoURL+"/emails", authHeader)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					for _, emailInfo := range emailsInfo.Values {
+						if emailInfo.IsPrimary {
+							return tpmodels.UserInfo{
+								ID: userInfo.UUID,
+								Email: &tpmodels.EmailStruct{
+									ID:         emailInfo.Email,
+									IsVerified: emailInfo.IsConfirmed,
+								},
+							}, nil
+						}
+					}
+					return tpmodels.UserInfo{
+						ID: userInfo.UUID,
+					}, nil
+				},
+			}, nil
+		},
+	}
+}
+
+func getBitbucketUserInfo(ctx context.Context, url string, authHeader string) (bitbucketUser, error) {
+	var user bitbucketUser
+	err := getJSON(ctx, url, map[string]string{"Authorization": authHeader}, &user)
+	return user, err
+}
+
+func getBitbucketEmailsInfo(ctx context.Context, url string, authHeader string) (bitbucketEmails, error) {
+	var emails bitbucketEmails
+	err := getJSON(ctx, url, map[string]string{"Authorization": authHeader}, &emails)
+	return emails, err
+}
+
+type bitbucketUser struct {
+	UUID string `json:"uuid"`
+}
+
+type bitbucketEmails struct {
+	Values []struct {
+		Email       string `json:"email"`
+		IsPrimary   bool   `json:"is_primary"`
+		IsConfirmed bool   `json:"is_confirmed"`
+	} `json:"values"`
+}
+
+type bitbucketGetProfileInfoInput struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,64 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+func TestBitbucketProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"uuid":"{c8b0a8a4-d9a0-4f5e-9e3b-7b3d2a9e1f00}","username":"user"}`))
+	})
+	mux.HandleFunc("/2.0/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"values":[
+			{"email":"old@example.com","is_primary":false,"is_confirmed":true},
+			{"email":"user@example.com","is_primary":true,"is_confirmed":true}
+		]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	providerInfo, err := Bitbucket(tpmodels.BitbucketConfig{
+		ClientID:     "client1",
+		ClientSecret: "secret",
+		Endpoints: &tpmodels.ProviderEndpoints{
+			AuthorisationURL: server.URL + "/site/oauth2/authorize",
+			TokenURL:         server.URL + "/site/oauth2/access_token",
+			UserInfoURL:      server.URL + "/2.0/user",
+		},
+	}).Get(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/site/oauth2/authorize", providerInfo.AuthorisationRedirect.URL)
+	assert.Equal(t, "account email", providerInfo.AuthorisationRedirect.Params["scope"])
+	assert.Equal(t, server.URL+"/site/oauth2/access_token", providerInfo.AccessTokenAPI.URL)
+
+	userInfo, err := providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{"access_token": "token1"})
+	assert.NoError(t, err)
+	assert.Equal(t, tpmodels.UserInfo{
+		ID:    "{c8b0a8a4-d9a0-4f5e-9e3b-7b3d2a9e1f00}",
+		Email: &tpmodels.EmailStruct{ID: "user@example.com", IsVerified: true},
+	}, userInfo)
+}
@@ -0,0 +1,134 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+const discordID = "discord"
+
+func Discord(config tpmodels.DiscordConfig) tpmodels.TypeProvider {
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: "https://discord.com/oauth2/authorize",
+		TokenURL:         "https://discord.com/api/oauth2/token",
+		UserInfoURL:      "https://discord.com/api/users/@me",
+	})
+	return tpmodels.TypeProvider{
+		ID: discordID,
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
+			accessTokenAPIParams := map[string]string{
+				"client_id":     config.ClientID,
+				"client_secret": config.ClientSecret,
+				"grant_type":    "authorization_code",
+			}
+			if authCodeFromRequest != nil {
+				accessTokenAPIParams["code"] = *authCodeFromRequest
+			}
+			if redirectURI != nil {
+				accessTokenAPIParams["redirect_uri"] = *redirectURI
+			}
+
+			authorisationRedirectURL := endpoints.AuthorisationURL
+			scopes := []string{"identify", "email"}
+			if config.Scope != nil {
+				scopes = config.Scope
+			}
+
+			var additionalParams map[string]interface{} = nil
+			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
+				additionalParams = config.AuthorisationRedirect.Params
+			}
+
+			authorizationRedirectParams := map[string]interface{}{
+				"scope":         strings.Join(scopes, " "),
+				"response_type": "code",
+				"client_id":     config.ClientID,
+			}
+			for key, value := range additionalParams {
+				authorizationRedirectParams[key] = value
+			}
+
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    accessTokenAPIURL,
+					Params: accessTokenAPIParams,
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    authorisationRedirectURL,
+					Params: authorizationRedirectParams,
+				},
+				UsePKCE: config.UsePKCE,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+					authCodeResponseJson, err := json.Marshal(authCodeResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					var accessTokenAPIResponse discordGetProfileInfoInput
+					err = json.Unmarshal(authCodeResponseJson, &accessTokenAPIResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					userInfo, err := getDiscordUserInfo(ctx, endpoints.UserInfoURL, "Bearer "+accessTokenAPIResponse.AccessToken)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					if userInfo.ID == "" {
+						return tpmodels.UserInfo{}, errors.New("Discord did not return the user's ID")
+					}
+					// the email is only returned with the email scope
+					if userInfo.Email == nil || *userInfo.Email == "" {
+						return tpmodels.UserInfo{
+							ID: userInfo.ID,
+						}, nil
+					}
+					return tpmodels.UserInfo{
+						ID: userInfo.ID,
+						Email: &tpmodels.EmailStruct{
+							ID:         *userInfo.Email,
+							IsVerified: userInfo.Verified,
+						},
+					}, nil
+				},
+			}, nil
+		},
+	}
+}
+
+func getDiscordUserInfo(ctx context.Context, url string, authHeader string) (discordUser, error) {
+	var user discordUser
+	err := getJSON(ctx, url, map[string]string{"Authorization": authHeader}, &user)
+	return user, err
+}
+
+type discordUser struct {
+	ID       string  `json:"id"`
+	Email    *string `json:"email"`
+	Verified bool    `json:"verified"`
+}
+
+type discordGetProfileInfoInput struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,68 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+func TestDiscordProvider(t *testing.T) {
+	response := `{"id":"80351110224678912","username":"user","email":"user@example.com","verified":true}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/users/@me", r.URL.Path)
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	providerInfo, err := Discord(tpmodels.DiscordConfig{
+		ClientID:     "client1",
+		ClientSecret: "secret",
+		Endpoints: &tpmodels.ProviderEndpoints{
+			AuthorisationURL: server.URL + "/oauth2/authorize",
+			TokenURL:         server.URL + "/api/oauth2/token",
+			UserInfoURL:      server.URL + "/api/users/@me",
+		},
+	}).Get(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/oauth2/authorize", providerInfo.AuthorisationRedirect.URL)
+	assert.Equal(t, "identify email", providerInfo.AuthorisationRedirect.Params["scope"])
+	assert.Equal(t, server.URL+"/api/oauth2/token", providerInfo.AccessTokenAPI.URL)
+
+	userInfo, err := providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{"access_token": "token1"})
+	assert.NoError(t, err)
+	assert.Equal(t, tpmodels.UserInfo{
+		ID:    "80351110224678912",
+		Email: &tpmodels.EmailStruct{ID: "user@example.com", IsVerified: true},
+	}, userInfo)
+
+	response = `{"id":"80351110224678912","username":"user","email":"user@example.com","verified":false}`
+	userInfo, err = providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{"access_token": "token1"})
+	assert.NoError(t, err)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@example.com", IsVerified: false}, userInfo.Email)
+
+	// without the email scope
+	response = `{"id":"80351110224678912","username":"user"}`
+	userInfo, err = providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{"access_token": "token1"})
+	assert.NoError(t, err)
+	assert.Equal(t, tpmodels.UserInfo{ID: "80351110224678912"}, userInfo)
+}
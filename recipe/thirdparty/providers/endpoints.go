@@ -0,0 +1,39 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import "github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+
+// getEndpoints returns the default endpoints of a provider, with the ones set in its config overridden
+func getEndpoints(overrides *tpmodels.ProviderEndpoints, defaults tpmodels.ProviderEndpoints) tpmodels.ProviderEndpoints {
+	if overrides == nil {
+		return defaults
+	}
+	endpoints := defaults
+	if overrides.AuthorisationURL != "" {
+		endpoints.AuthorisationURL = overrides.AuthorisationURL
+	}
+	if overrides.TokenURL != "" {
+		endpoints.TokenURL = overrides.TokenURL
+	}
+	if overrides.UserInfoURL != "" {
+		endpoints.UserInfoURL = overrides.UserInfoURL
+	}
+	if overrides.JWKSURL != "" {
+		endpoints.JWKSURL = overrides.JWKSURL
+	}
+	return endpoints
+}
@@ -16,6 +16,7 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -26,10 +27,15 @@ import (
 const facebookID = "facebook"
 
 func Facebook(config tpmodels.FacebookConfig) tpmodels.TypeProvider {
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: "https://www.facebook.com/v9.0/dialog/oauth",
+		TokenURL:         "https://graph.facebook.com/v9.0/oauth/access_token",
+		UserInfoURL:      "https://graph.facebook.com/me",
+	})
 	return tpmodels.TypeProvider{
 		ID: facebookID,
-		Get: func(redirectURI, authCodeFromRequest *string) tpmodels.TypeProviderGetResponse {
-			accessTokenAPIURL := "https://graph.facebook.com/v9.0/oauth/access_token"
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
 			accessTokenAPIParams := map[string]string{
 				"client_id":     config.ClientID,
 				"client_secret": config.ClientSecret,
@@ -41,7 +47,7 @@ func Facebook(config tpmodels.FacebookConfig) tpmodels.TypeProvider {
 				accessTokenAPIParams["redirect_uri"] = *redirectURI
 			}
 
-			authorisationRedirectURL := "https://www.facebook.com/v9.0/dialog/oauth"
+			authorisationRedirectURL := endpoints.AuthorisationURL
 			scopes := []string{"email"}
 			if config.Scope != nil {
 				scopes = config.Scope
@@ -62,7 +68,8 @@ func Facebook(config tpmodels.FacebookConfig) tpmodels.TypeProvider {
 					URL:    authorisationRedirectURL,
 					Params: authorizationRedirectParams,
 				},
-				GetProfileInfo: func(authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+				UsePKCE: config.UsePKCE,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
 					authCodeResponseJson, err := json.Marshal(authCodeResponse)
 					if err != nil {
 						return tpmodels.UserInfo{}, err
@@ -73,7 +80,7 @@ func Facebook(config tpmodels.FacebookConfig) tpmodels.TypeProvider {
 						return tpmodels.UserInfo{}, err
 					}
 					accessToken := accessTokenAPIResponse.AccessToken
-					response, err := getFacebookAuthRequest(accessToken)
+					response, err := getFacebookAuthRequest(ctx, endpoints.UserInfoURL, accessToken)
 					if err != nil {
 						return tpmodels.UserInfo{}, err
 					}
@@ -94,14 +101,13 @@ func Facebook(config tpmodels.FacebookConfig) tpmodels.TypeProvider {
 						},
 					}, nil
 				},
-			}
+			}, nil
 		},
 	}
 }
 
-func getFacebookAuthRequest(accessToken string) (interface{}, error) {
-	url := "https://graph.facebook.com/me"
-	req, err := http.NewRequest("GET", url, nil)
+func getFacebookAuthRequest(ctx context.Context, url string, accessToken string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
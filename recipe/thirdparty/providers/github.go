@@ -16,6 +16,7 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -27,10 +28,15 @@ import (
 const githubID = "github"
 
 func Github(config tpmodels.GithubConfig) tpmodels.TypeProvider {
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: "https://github.com/login/oauth/authorize",
+		TokenURL:         "https://github.com/login/oauth/access_token",
+		UserInfoURL:      "https://api.github.com/user",
+	})
 	return tpmodels.TypeProvider{
 		ID: githubID,
-		Get: func(redirectURI, authCodeFromRequest *string) tpmodels.TypeProviderGetResponse {
-			accessTokenAPIURL := "https://github.com/login/oauth/access_token"
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
 			accessTokenAPIParams := map[string]string{
 				"client_id":     config.ClientID,
 				"client_secret": config.ClientSecret,
@@ -42,7 +48,7 @@ func Github(config tpmodels.GithubConfig) tpmodels.TypeProvider {
 				accessTokenAPIParams["redirect_uri"] = *redirectURI
 			}
 
-			authorisationRedirectURL := "https://github.com/login/oauth/authorize"
+			authorisationRedirectURL := endpoints.AuthorisationURL
 			scopes := []string{"read:user", "user:email"}
 			if config.Scope != nil {
 				scopes = config.Scope
@@ -70,7 +76,8 @@ func Github(config tpmodels.GithubConfig) tpmodels.TypeProvider {
 					URL:    authorisationRedirectURL,
 					Params: authorizationRedirectParams,
 				},
-				GetProfileInfo: func(authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+				UsePKCE: config.UsePKCE,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
 					authCodeResponseJson, err := json.Marshal(authCodeResponse)
 					if err != nil {
 						return tpmodels.UserInfo{}, err
@@ -82,12 +89,12 @@ func Github(config tpmodels.GithubConfig) tpmodels.TypeProvider {
 					}
 					accessToken := accessTokenAPIResponse.AccessToken
 					authHeader := "Bearer " + accessToken
-					response, err := getGithubAuthRequest(authHeader)
+					response, err := getGithubAuthRequest(ctx, endpoints.UserInfoURL, authHeader)
 					if err != nil {
 						return tpmodels.UserInfo{}, err
 					}
 					userInfo := response.(map[string]interface{})
-					emailsInfoResponse, err := getGithubEmailsInfo(authHeader)
+					emailsInfoResponse, err := getGithubEmailsInfo(ctx, endpoints.UserInfoURL+"/emails", authHeader)
 					if err != nil {
 						return tpmodels.UserInfo{}, err
 					}
@@ -121,14 +128,13 @@ func Github(config tpmodels.GithubConfig) tpmodels.TypeProvider {
 						},
 					}, nil
 				},
-			}
+			}, nil
 		},
 	}
 }
 
-func getGithubAuthRequest(authHeader string) (interface{}, error) {
-	url := "https://api.github.com/user"
-	req, err := http.NewRequest("GET", url, nil)
+func getGithubAuthRequest(ctx context.Context, url string, authHeader string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -137,9 +143,8 @@ func getGithubAuthRequest(authHeader string) (interface{}, error) {
 	return doGetRequest(req)
 }
 
-func getGithubEmailsInfo(authHeader string) (interface{}, error) {
-	url := "https://api.github.com/user/emails"
-	req, err := http.NewRequest("GET", url, nil)
+func getGithubEmailsInfo(ctx context.Context, url string, authHeader string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
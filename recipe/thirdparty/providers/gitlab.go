@@ -0,0 +1,169 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+const (
+	gitlabID             = "gitlab"
+	gitlabDefaultBaseURL = "https://gitlab.com"
+)
+
+// Gitlab returns a provider for gitlab.com, or for a self-hosted GitLab instance if GitlabBaseURL is set
+func Gitlab(config tpmodels.GitlabConfig) tpmodels.TypeProvider {
+	baseURL := gitlabDefaultBaseURL
+	if config.GitlabBaseURL != nil {
+		baseURL = strings.TrimSuffix(*config.GitlabBaseURL, "/")
+	}
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: baseURL + "/oauth/authorize",
+		TokenURL:         baseURL + "/oauth/token",
+		UserInfoURL:      baseURL + "/api/v4/user",
+	})
+	return tpmodels.TypeProvider{
+		ID: gitlabID,
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
+			accessTokenAPIParams := map[string]string{
+				"client_id":     config.ClientID,
+				"client_secret": config.ClientSecret,
+				"grant_type":    "authorization_code",
+			}
+			if authCodeFromRequest != nil {
+				accessTokenAPIParams["code"] = *authCodeFromRequest
+			}
+			if redirectURI != nil {
+				accessTokenAPIParams["redirect_uri"] = *redirectURI
+			}
+
+			authorisationRedirectURL := endpoints.AuthorisationURL
+			scopes := []string{"read_user"}
+			if config.Scope != nil {
+				scopes = config.Scope
+			}
+
+			var additionalParams map[string]interface{} = nil
+			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
+				additionalParams = config.AuthorisationRedirect.Params
+			}
+
+			authorizationRedirectParams := map[string]interface{}{
+				"scope":         strings.Join(scopes, " "),
+				"response_type": "code",
+				"client_id":     config.ClientID,
+			}
+			for key, value := range additionalParams {
+				authorizationRedirectParams[key] = value
+			}
+
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    accessTokenAPIURL,
+					Params: accessTokenAPIParams,
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    authorisationRedirectURL,
+					Params: authorizationRedirectParams,
+				},
+				UsePKCE: config.UsePKCE,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+					authCodeResponseJson, err := json.Marshal(authCodeResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					var accessTokenAPIResponse gitlabGetProfileInfoInput
+					err = json.Unmarshal(authCodeResponseJson, &accessTokenAPIResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					authHeader := "Bearer " + accessTokenAPIResponse.AccessToken
+					userInfo, err := getGitlabUserInfo(ctx, endpoints.UserInfoURL, authHeader)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					if userInfo.ID == 0 {
+						return tpmodels.UserInfo{}, errors.New("GitLab did not return the user's ID")
+					}
+					ID := strconv.FormatInt(userInfo.ID, 10)
+					if userInfo.Email == "" {
+						return tpmodels.UserInfo{
+							ID: ID,
+						}, nil
+					}
+					// the user's confirmed_at is when their account (and so the primary email) was
+					// confirmed. The emails API, which has the confirmation of each email, is used if it has
+					// the primary email, since the primary email can be changed.
+					isVerified := userInfo.ConfirmedAt != nil
+					emailsInfo, err := getGitlabEmailsInfo(ctx, endpoints.UserInfoURL+"/emails", authHeader)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					for _, emailInfo := range emailsInfo {
+						if strings.EqualFold(emailInfo.Email, userInfo.Email) {
+							isVerified = emailInfo.ConfirmedAt != nil
+							break
+						}
+					}
+					return tpmodels.UserInfo{
+						ID: ID,
+						Email: &tpmodels.EmailStruct{
+							ID:         userInfo.Email,
+							IsVerified: isVerified,
+						},
+					}, nil
+				},
+			}, nil
+		},
+	}
+}
+
+func getGitlabUserInfo(ctx context.Context, url string, authHeader string) (gitlabUser, error) {
+	var user gitlabUser
+	err := getJSON(ctx, url, map[string]string{"Authorization": authHeader}, &user)
+	return user, err
+}
+
+func getGitlabEmailsInfo(ctx context.Context, url string, authHeader string) ([]gitlabEmail, error) {
+	var emails []gitlabEmail
+	err := getJSON(ctx, url, map[string]string{"Authorization": authHeader}, &emails)
+	return emails, err
+}
+
+type gitlabUser struct {
+	ID          int64   `json:"id"`
+	Email       string  `json:"email"`
+	ConfirmedAt *string `json:"confirmed_at"`
+}
+
+type gitlabEmail struct {
+	Email       string  `json:"email"`
+	ConfirmedAt *string `json:"confirmed_at"`
+}
+
+type gitlabGetProfileInfoInput struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+}
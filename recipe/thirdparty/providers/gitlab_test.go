@@ -0,0 +1,81 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+func TestGitlabProviderWithSelfHostedInstance(t *testing.T) {
+	emails := []map[string]interface{}{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           42,
+			"email":        "user@example.com",
+			"confirmed_at": "2021-01-01T00:00:00.000Z",
+		})
+	})
+	mux.HandleFunc("/api/v4/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(emails)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	baseURL := server.URL + "/"
+	provider := Gitlab(tpmodels.GitlabConfig{
+		ClientID:      "client1",
+		ClientSecret:  "secret",
+		GitlabBaseURL: &baseURL,
+	})
+	assert.Equal(t, "gitlab", provider.ID)
+	providerInfo, err := provider.Get(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/oauth/authorize", providerInfo.AuthorisationRedirect.URL)
+	assert.Equal(t, "read_user", providerInfo.AuthorisationRedirect.Params["scope"])
+	assert.Equal(t, server.URL+"/oauth/token", providerInfo.AccessTokenAPI.URL)
+
+	userInfo, err := providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{"access_token": "token1"})
+	assert.NoError(t, err)
+	assert.Equal(t, tpmodels.UserInfo{
+		ID:    "42",
+		Email: &tpmodels.EmailStruct{ID: "user@example.com", IsVerified: true},
+	}, userInfo)
+
+	// a primary email that was changed to an unconfirmed email
+	emails = []map[string]interface{}{
+		{"id": 1, "email": "other@example.com", "confirmed_at": "2021-01-01T00:00:00.000Z"},
+		{"id": 2, "email": "user@example.com", "confirmed_at": nil},
+	}
+	userInfo, err = providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{"access_token": "token1"})
+	assert.NoError(t, err)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@example.com", IsVerified: false}, userInfo.Email)
+}
+
+func TestGitlabProviderDefaultsToGitlabDotCom(t *testing.T) {
+	providerInfo, err := Gitlab(tpmodels.GitlabConfig{ClientID: "client1"}).Get(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://gitlab.com/oauth/authorize", providerInfo.AuthorisationRedirect.URL)
+}
@@ -16,21 +16,28 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"strings"
 
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 const googleID = "google"
 
 func Google(config tpmodels.GoogleConfig) tpmodels.TypeProvider {
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:         "https://accounts.google.com/o/oauth2/token",
+		UserInfoURL:      "https://www.googleapis.com/oauth2/v1/userinfo?alt=json",
+	})
 	return tpmodels.TypeProvider{
 		ID: googleID,
-		Get: func(redirectURI, authCodeFromRequest *string) tpmodels.TypeProviderGetResponse {
-			accessTokenAPIURL := "https://accounts.google.com/o/oauth2/token"
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
 			accessTokenAPIParams := map[string]string{
 				"client_id":     config.ClientID,
 				"client_secret": config.ClientSecret,
@@ -43,7 +50,7 @@ func Google(config tpmodels.GoogleConfig) tpmodels.TypeProvider {
 				accessTokenAPIParams["redirect_uri"] = *redirectURI
 			}
 
-			authorisationRedirectURL := "https://accounts.google.com/o/oauth2/v2/auth"
+			authorisationRedirectURL := endpoints.AuthorisationURL
 			scopes := []string{"https://www.googleapis.com/auth/userinfo.email"}
 			if config.Scope != nil {
 				scopes = config.Scope
@@ -74,7 +81,8 @@ func Google(config tpmodels.GoogleConfig) tpmodels.TypeProvider {
 					URL:    authorisationRedirectURL,
 					Params: authorizationRedirectParams,
 				},
-				GetProfileInfo: func(authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+				UsePKCE: config.UsePKCE,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
 					authCodeResponseJson, err := json.Marshal(authCodeResponse)
 					if err != nil {
 						return tpmodels.UserInfo{}, err
@@ -86,7 +94,7 @@ func Google(config tpmodels.GoogleConfig) tpmodels.TypeProvider {
 					}
 					accessToken := accessTokenAPIResponse.AccessToken
 					authHeader := "Bearer " + accessToken
-					response, err := getGoogleAuthRequest(authHeader)
+					response, err := getGoogleAuthRequest(ctx, endpoints.UserInfoURL, authHeader)
 					if err != nil {
 						return tpmodels.UserInfo{}, err
 					}
@@ -107,14 +115,13 @@ func Google(config tpmodels.GoogleConfig) tpmodels.TypeProvider {
 						},
 					}, nil
 				},
-			}
+			}, nil
 		},
 	}
 }
 
-func getGoogleAuthRequest(authHeader string) (interface{}, error) {
-	url := "https://www.googleapis.com/oauth2/v1/userinfo?alt=json"
-	req, err := http.NewRequest("GET", url, nil)
+func getGoogleAuthRequest(ctx context.Context, url string, authHeader string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -123,8 +130,7 @@ func getGoogleAuthRequest(authHeader string) (interface{}, error) {
 }
 
 func doGetRequest(req *http.Request) (interface{}, error) {
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := supertokens.GetHTTPClientWithContext(req.Context()).Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,319 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	// jwksRefreshInterval is how long the keys of a provider are cached for
+	jwksRefreshInterval = 24 * time.Hour
+	// jwksMinRefetchInterval limits how often the keys are fetched again because of an unknown key ID
+	jwksMinRefetchInterval = time.Minute
+	idTokenClockSkew       = time.Minute
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks caches the public keys served by a provider's JWKS endpoint
+type jwks struct {
+	url       string
+	mutex     sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+var (
+	jwksByURL      = map[string]*jwks{}
+	jwksByURLMutex sync.Mutex
+)
+
+func getJWKS(url string) *jwks {
+	jwksByURLMutex.Lock()
+	defer jwksByURLMutex.Unlock()
+	if _, ok := jwksByURL[url]; !ok {
+		jwksByURL[url] = &jwks{url: url}
+	}
+	return jwksByURL[url]
+}
+
+func (j *jwks) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	key, found := j.keys[kid]
+	sinceFetched := time.Since(j.fetchedAt)
+	if j.keys == nil || sinceFetched > jwksRefreshInterval || (!found && sinceFetched > jwksMinRefetchInterval) {
+		err := j.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		key, found = j.keys[kid]
+	}
+	if !found {
+		return nil, errors.New("no public key found for the id token's key ID: " + kid)
+	}
+	return key, nil
+}
+
+func (j *jwks) fetch(ctx context.Context) error {
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err := getJSON(ctx, j.url, nil, &keySet)
+	if err != nil {
+		return err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range keySet.Keys {
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			// keys of unsupported types are skipped
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	return nil
+}
+
+func parseJSONWebKey(jwk jsonWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, errors.New("unsupported curve: " + jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, errors.New("unsupported key type: " + jwk.Kty)
+	}
+}
+
+// verifyIDToken checks the signature, issuer, audience and expiry of an id token, and returns its claims
+func verifyIDToken(ctx context.Context, idToken string, keys *jwks, isValidIssuer func(issuer string) bool, clientID string) (map[string]interface{}, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid id token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	err := decodeJWTPart(parts[0], &header)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	key, err := keys.getKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	err = decodeJWTPart(parts[1], &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if !isValidIssuer(issuer) {
+		return nil, errors.New("invalid id token issuer: " + issuer)
+	}
+	if !hasAudience(claims["aud"], clientID) {
+		return nil, errors.New("id token was not issued for this client")
+	}
+	now := time.Now()
+	expiry, ok := claims["exp"].(float64)
+	if !ok || now.Add(-idTokenClockSkew).After(time.Unix(int64(expiry), 0)) {
+		return nil, errors.New("id token has expired")
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now.Add(idTokenClockSkew).Before(time.Unix(int64(notBefore), 0)) {
+		return nil, errors.New("id token is not valid yet")
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, result interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, result)
+}
+
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return errors.New("unsupported id token algorithm: " + alg)
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return errors.New("id token algorithm does not match its key")
+		}
+		return rsa.VerifyPKCS1v15(publicKey, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size {
+			return errors.New("invalid id token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return errors.New("invalid id token signature")
+		}
+		return nil
+	default:
+		return errors.New("unsupported public key type")
+	}
+}
+
+func hasAudience(audience interface{}, clientID string) bool {
+	switch value := audience.(type) {
+	case string:
+		return value == clientID
+	case []interface{}:
+		for _, item := range value {
+			if item == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getUserInfoFromClaims reads the standard sub, email and email_verified claims
+func getUserInfoFromClaims(claims map[string]interface{}) (tpmodels.UserInfo, error) {
+	ID, _ := claims["sub"].(string)
+	if ID == "" {
+		return tpmodels.UserInfo{}, errors.New("id token has no subject")
+	}
+	userInfo := tpmodels.UserInfo{ID: ID}
+	if email, _ := claims["email"].(string); email != "" {
+		userInfo.Email = &tpmodels.EmailStruct{
+			ID:         email,
+			IsVerified: isTrueClaim(claims["email_verified"]),
+		}
+	}
+	return userInfo, nil
+}
+
+// isTrueClaim is needed because some providers (like Apple) send boolean claims as strings
+func isTrueClaim(claim interface{}) bool {
+	switch value := claim.(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+func getJSON(ctx context.Context, url string, headers map[string]string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := supertokens.GetHTTPClientWithContext(ctx).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("request to %s failed with status code %d: %s", url, resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, result)
+}
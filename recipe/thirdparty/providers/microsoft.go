@@ -0,0 +1,172 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+const (
+	microsoftID = "microsoft"
+	// microsoftConsumersTenantID is the tenant of personal Microsoft accounts
+	microsoftConsumersTenantID = "9188040d-6c67-4c5b-b112-36a304b66dad"
+)
+
+const microsoftLoginURL = "https://login.microsoftonline.com"
+
+var microsoftTenantIDRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Microsoft returns a provider for Microsoft accounts (Azure AD work or school accounts and / or
+// personal accounts, depending on the tenant)
+func Microsoft(config tpmodels.MicrosoftConfig) tpmodels.TypeProvider {
+	tenant := config.Tenant
+	if tenant == "" {
+		tenant = "common"
+	}
+	endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+		AuthorisationURL: microsoftLoginURL + "/" + tenant + "/oauth2/v2.0/authorize",
+		TokenURL:         microsoftLoginURL + "/" + tenant + "/oauth2/v2.0/token",
+		JWKSURL:          microsoftLoginURL + "/" + tenant + "/discovery/v2.0/keys",
+	})
+	return tpmodels.TypeProvider{
+		ID: microsoftID,
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			accessTokenAPIURL := endpoints.TokenURL
+			accessTokenAPIParams := map[string]string{
+				"client_id":     config.ClientID,
+				"client_secret": config.ClientSecret,
+				"grant_type":    "authorization_code",
+			}
+			if authCodeFromRequest != nil {
+				accessTokenAPIParams["code"] = *authCodeFromRequest
+			}
+			if redirectURI != nil {
+				accessTokenAPIParams["redirect_uri"] = *redirectURI
+			}
+
+			authorisationRedirectURL := endpoints.AuthorisationURL
+			scopes := []string{"openid", "email", "profile"}
+			if config.Scope != nil {
+				scopes = config.Scope
+			}
+
+			var additionalParams map[string]interface{} = nil
+			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
+				additionalParams = config.AuthorisationRedirect.Params
+			}
+
+			authorizationRedirectParams := map[string]interface{}{
+				"scope":         strings.Join(scopes, " "),
+				"response_type": "code",
+				"response_mode": "query",
+				"client_id":     config.ClientID,
+			}
+			for key, value := range additionalParams {
+				authorizationRedirectParams[key] = value
+			}
+
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    accessTokenAPIURL,
+					Params: accessTokenAPIParams,
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    authorisationRedirectURL,
+					Params: authorizationRedirectParams,
+				},
+				UsePKCE:     config.UsePKCE,
+				UsesIDToken: true,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+					accessTokenAPIResponse, err := getOIDCTokenResponse(authCodeResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					claims, err := verifyIDToken(ctx, accessTokenAPIResponse.IDToken, getJWKS(endpoints.JWKSURL), func(issuer string) bool {
+						return isValidMicrosoftIssuer(tenant, issuer)
+					}, config.ClientID)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					// with the multi tenant endpoints, the issuer is only known to be of some tenant
+					tenantID, _ := claims["tid"].(string)
+					if claims["iss"] != microsoftLoginURL+"/"+tenantID+"/v2.0" {
+						return tpmodels.UserInfo{}, errors.New("id token issuer does not match its tenant")
+					}
+					return getMicrosoftUserInfo(claims)
+				},
+			}, nil
+		},
+	}
+}
+
+func isValidMicrosoftIssuer(tenant string, issuer string) bool {
+	if !strings.HasPrefix(issuer, microsoftLoginURL+"/") || !strings.HasSuffix(issuer, "/v2.0") {
+		return false
+	}
+	issuerTenantID := strings.TrimSuffix(strings.TrimPrefix(issuer, microsoftLoginURL+"/"), "/v2.0")
+	if !microsoftTenantIDRegex.MatchString(issuerTenantID) {
+		return false
+	}
+	switch tenant {
+	case "common":
+		return true
+	case "organizations":
+		return issuerTenantID != microsoftConsumersTenantID
+	case "consumers":
+		return issuerTenantID == microsoftConsumersTenantID
+	default:
+		return strings.EqualFold(issuerTenantID, tenant)
+	}
+}
+
+// getMicrosoftUserInfo uses the tenant ID and the object ID as the user ID, since the object ID is
+// the same for all apps but only unique within a tenant (and a guest has one in every tenant).
+// The email and preferred_username of a work account can be set by its tenant's admins, so they are
+// only verified for personal accounts, or if the xms_edov claim says the domain is verified
+func getMicrosoftUserInfo(claims map[string]interface{}) (tpmodels.UserInfo, error) {
+	tenantID, _ := claims["tid"].(string)
+	objectID, _ := claims["oid"].(string)
+	// without the profile scope there is no object ID, and sub (which is unique across tenants) is used
+	ID, _ := claims["sub"].(string)
+	if tenantID != "" && objectID != "" {
+		ID = tenantID + ":" + objectID
+	}
+	if ID == "" {
+		return tpmodels.UserInfo{}, errors.New("id token has no subject")
+	}
+	userInfo := tpmodels.UserInfo{ID: ID}
+
+	email, _ := claims["email"].(string)
+	if email == "" {
+		// for work accounts, this is usually the user principal name, which is often (but not always) an email
+		preferredUsername, _ := claims["preferred_username"].(string)
+		if strings.Contains(preferredUsername, "@") {
+			email = preferredUsername
+		}
+	}
+	if email != "" {
+		userInfo.Email = &tpmodels.EmailStruct{
+			ID:         email,
+			IsVerified: claims["tid"] == microsoftConsumersTenantID || isTrueClaim(claims["xms_edov"]),
+		}
+	}
+	return userInfo, nil
+}
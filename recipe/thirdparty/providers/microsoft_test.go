@@ -0,0 +1,156 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+func TestMicrosoftProvider(t *testing.T) {
+	issuer := newFakeOIDCIssuer(t)
+	defer issuer.server.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/discovery/v2.0/keys") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key1",
+				"n":   base64.RawURLEncoding.EncodeToString(issuer.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(issuer.key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	workTenantID := "72f988bf-86f1-41af-91ab-2d7cd011db47"
+	getUserInfo := func(tenant string, claims map[string]interface{}) (tpmodels.UserInfo, error) {
+		provider := Microsoft(tpmodels.MicrosoftConfig{
+			ClientID:     "client1",
+			ClientSecret: "secret",
+			Tenant:       tenant,
+			Endpoints: &tpmodels.ProviderEndpoints{
+				JWKSURL: server.URL + "/" + tenant + "/discovery/v2.0/keys",
+			},
+		})
+		providerInfo, err := provider.Get(context.Background(), nil, nil)
+		assert.NoError(t, err)
+		assert.True(t, providerInfo.UsesIDToken)
+		if tenant == "" {
+			tenant = "common"
+		}
+		assert.Equal(t, microsoftLoginURL+"/"+tenant+"/oauth2/v2.0/authorize", providerInfo.AuthorisationRedirect.URL)
+		assert.Equal(t, microsoftLoginURL+"/"+tenant+"/oauth2/v2.0/token", providerInfo.AccessTokenAPI.URL)
+
+		tokenClaims := map[string]interface{}{
+			"iss": microsoftLoginURL + "/" + claims["tid"].(string) + "/v2.0",
+			"sub": "pairwise-subject",
+			"aud": "client1",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+		for key, value := range claims {
+			tokenClaims[key] = value
+		}
+		return providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{
+			"id_token": issuer.signIDToken(tokenClaims),
+		})
+	}
+
+	// personal accounts have a verified email
+	userInfo, err := getUserInfo("", map[string]interface{}{
+		"tid":   microsoftConsumersTenantID,
+		"oid":   "00000000-0000-0000-66f3-3332eca7ea81",
+		"email": "user@outlook.com",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, microsoftConsumersTenantID+":00000000-0000-0000-66f3-3332eca7ea81", userInfo.ID)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@outlook.com", IsVerified: true}, userInfo.Email)
+
+	// the email of work accounts is set by the tenant's admins
+	userInfo, err = getUserInfo("organizations", map[string]interface{}{
+		"tid":   workTenantID,
+		"oid":   "oid1",
+		"email": "user@contoso.com",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@contoso.com", IsVerified: false}, userInfo.Email)
+
+	userInfo, err = getUserInfo(workTenantID, map[string]interface{}{
+		"tid":      workTenantID,
+		"oid":      "oid1",
+		"email":    "user@contoso.com",
+		"xms_edov": true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@contoso.com", IsVerified: true}, userInfo.Email)
+
+	userInfo, err = getUserInfo("common", map[string]interface{}{
+		"tid":                workTenantID,
+		"oid":                "oid1",
+		"preferred_username": "user@contoso.onmicrosoft.com",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@contoso.onmicrosoft.com", IsVerified: false}, userInfo.Email)
+
+	userInfo, err = getUserInfo("common", map[string]interface{}{
+		"tid":                workTenantID,
+		"oid":                "oid1",
+		"preferred_username": "+15555550100",
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, userInfo.Email)
+
+	// a user has the same object ID in every app, but a guest user of two tenants is two users
+	otherTenantID := "11111111-1111-1111-1111-111111111111"
+	userInfo, err = getUserInfo("common", map[string]interface{}{"tid": workTenantID, "oid": "oid1"})
+	assert.NoError(t, err)
+	otherUserInfo, err := getUserInfo("common", map[string]interface{}{"tid": otherTenantID, "oid": "oid1"})
+	assert.NoError(t, err)
+	assert.Equal(t, workTenantID+":oid1", userInfo.ID)
+	assert.Equal(t, otherTenantID+":oid1", otherUserInfo.ID)
+
+	// without the profile scope
+	userInfo, err = getUserInfo("common", map[string]interface{}{"tid": workTenantID})
+	assert.NoError(t, err)
+	assert.Equal(t, "pairwise-subject", userInfo.ID)
+
+	// accounts of other tenants are rejected
+	_, err = getUserInfo("organizations", map[string]interface{}{"tid": microsoftConsumersTenantID, "oid": "oid1"})
+	assert.Error(t, err)
+	_, err = getUserInfo("consumers", map[string]interface{}{"tid": workTenantID, "oid": "oid1"})
+	assert.Error(t, err)
+	_, err = getUserInfo(workTenantID, map[string]interface{}{"tid": "11111111-1111-1111-1111-111111111111", "oid": "oid1"})
+	assert.Error(t, err)
+	_, err = getUserInfo("common", map[string]interface{}{
+		"tid": workTenantID,
+		"iss": microsoftLoginURL + "/11111111-1111-1111-1111-111111111111/v2.0",
+		"oid": "oid1",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// oidcDiscovery fetches the discovery document of an issuer once, and caches it
+type oidcDiscovery struct {
+	issuerURL string
+	mutex     sync.Mutex
+	document  *oidcDiscoveryDocument
+}
+
+func (d *oidcDiscovery) get(ctx context.Context) (oidcDiscoveryDocument, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.document != nil {
+		return *d.document, nil
+	}
+
+	var document oidcDiscoveryDocument
+	err := getJSON(ctx, d.issuerURL+oidcDiscoveryPath, nil, &document)
+	if err != nil {
+		return oidcDiscoveryDocument{}, err
+	}
+	if strings.TrimSuffix(document.Issuer, "/") != d.issuerURL {
+		return oidcDiscoveryDocument{}, errors.New("issuer in the discovery document does not match the issuer URL: " + document.Issuer)
+	}
+	if document.AuthorizationEndpoint == "" || document.TokenEndpoint == "" || document.JwksURI == "" {
+		return oidcDiscoveryDocument{}, errors.New("discovery document of " + d.issuerURL + " is missing required endpoints")
+	}
+	d.document = &document
+	return document, nil
+}
+
+// OIDC returns a provider for any OpenID Connect compliant identity provider. Its endpoints are read
+// from the issuer's discovery document, and the user info from the verified id token.
+func OIDC(config tpmodels.OIDCConfig) tpmodels.TypeProvider {
+	discovery := &oidcDiscovery{issuerURL: strings.TrimSuffix(config.IssuerURL, "/")}
+	return tpmodels.TypeProvider{
+		ID: config.ID,
+		Get: func(ctx context.Context, redirectURI, authCodeFromRequest *string) (tpmodels.TypeProviderGetResponse, error) {
+			document, err := discovery.get(ctx)
+			if err != nil {
+				return tpmodels.TypeProviderGetResponse{}, err
+			}
+			endpoints := getEndpoints(config.Endpoints, tpmodels.ProviderEndpoints{
+				AuthorisationURL: document.AuthorizationEndpoint,
+				TokenURL:         document.TokenEndpoint,
+				UserInfoURL:      document.UserinfoEndpoint,
+				JWKSURL:          document.JwksURI,
+			})
+
+			accessTokenAPIParams := map[string]string{
+				"client_id":  config.ClientID,
+				"grant_type": "authorization_code",
+			}
+			if config.ClientSecret != "" {
+				accessTokenAPIParams["client_secret"] = config.ClientSecret
+			}
+			if authCodeFromRequest != nil {
+				accessTokenAPIParams["code"] = *authCodeFromRequest
+			}
+			if redirectURI != nil {
+				accessTokenAPIParams["redirect_uri"] = *redirectURI
+			}
+
+			scopes := []string{"openid", "email", "profile"}
+			if config.Scope != nil {
+				scopes = config.Scope
+			}
+
+			var additionalParams map[string]interface{} = nil
+			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
+				additionalParams = config.AuthorisationRedirect.Params
+			}
+
+			authorizationRedirectParams := map[string]interface{}{
+				"scope":         strings.Join(scopes, " "),
+				"response_type": "code",
+				"client_id":     config.ClientID,
+			}
+			for key, value := range additionalParams {
+				authorizationRedirectParams[key] = value
+			}
+
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    endpoints.TokenURL,
+					Params: accessTokenAPIParams,
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    endpoints.AuthorisationURL,
+					Params: authorizationRedirectParams,
+				},
+				UsePKCE:     config.UsePKCE,
+				UsesIDToken: true,
+				GetProfileInfo: func(ctx context.Context, authCodeResponse interface{}) (tpmodels.UserInfo, error) {
+					accessTokenAPIResponse, err := getOIDCTokenResponse(authCodeResponse)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					claims, err := verifyIDToken(ctx, accessTokenAPIResponse.IDToken, getJWKS(endpoints.JWKSURL), func(issuer string) bool {
+						return issuer == document.Issuer
+					}, config.ClientID)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					userInfo, err := getUserInfoFromClaims(claims)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					if userInfo.Email != nil || endpoints.UserInfoURL == "" || accessTokenAPIResponse.AccessToken == "" {
+						return userInfo, nil
+					}
+
+					// some providers only put the email in the id token if it is asked for
+					// with the claims parameter, but always return it from the userinfo endpoint
+					var userinfoClaims map[string]interface{}
+					err = getJSON(ctx, endpoints.UserInfoURL, map[string]string{
+						"Authorization": "Bearer " + accessTokenAPIResponse.AccessToken,
+					}, &userinfoClaims)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+					if userinfoClaims["sub"] != userInfo.ID {
+						return tpmodels.UserInfo{}, errors.New("userinfo response is for a different subject than the id token")
+					}
+					return getUserInfoFromClaims(userinfoClaims)
+				},
+			}, nil
+		},
+	}
+}
+
+func getOIDCTokenResponse(authCodeResponse interface{}) (oidcGetProfileInfoInput, error) {
+	authCodeResponseJson, err := json.Marshal(authCodeResponse)
+	if err != nil {
+		return oidcGetProfileInfoInput{}, err
+	}
+	var accessTokenAPIResponse oidcGetProfileInfoInput
+	err = json.Unmarshal(authCodeResponseJson, &accessTokenAPIResponse)
+	if err != nil {
+		return oidcGetProfileInfoInput{}, err
+	}
+	if accessTokenAPIResponse.IDToken == "" {
+		return oidcGetProfileInfoInput{}, errors.New("no id token in the access token response; is the openid scope requested?")
+	}
+	return accessTokenAPIResponse, nil
+}
+
+type oidcGetProfileInfoInput struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+}
@@ -0,0 +1,183 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+type fakeOIDCIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newFakeOIDCIssuer(t *testing.T) *fakeOIDCIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &fakeOIDCIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"userinfo_endpoint":      issuer.server.URL + "/userinfo",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":            "user1",
+			"email":          "user@example.com",
+			"email_verified": true,
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	return issuer
+}
+
+func (i *fakeOIDCIssuer) signIDToken(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "key1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCProvider(t *testing.T) {
+	issuer := newFakeOIDCIssuer(t)
+	defer issuer.server.Close()
+
+	provider := OIDC(tpmodels.OIDCConfig{
+		ID:           "keycloak",
+		IssuerURL:    issuer.server.URL + "/",
+		ClientID:     "client1",
+		ClientSecret: "secret",
+	})
+	assert.Equal(t, "keycloak", provider.ID)
+
+	code := "code1"
+	providerInfo, err := provider.Get(context.Background(), nil, &code)
+	assert.NoError(t, err)
+	assert.Equal(t, issuer.server.URL+"/authorize", providerInfo.AuthorisationRedirect.URL)
+	assert.Equal(t, "openid email profile", providerInfo.AuthorisationRedirect.Params["scope"])
+	assert.Equal(t, issuer.server.URL+"/token", providerInfo.AccessTokenAPI.URL)
+	assert.Equal(t, "code1", providerInfo.AccessTokenAPI.Params["code"])
+
+	claims := map[string]interface{}{
+		"iss":            issuer.server.URL,
+		"sub":            "user1",
+		"aud":            "client1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"email":          "user@example.com",
+		"email_verified": "true",
+	}
+	userInfo, err := providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{
+		"id_token": issuer.signIDToken(claims),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", userInfo.ID)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@example.com", IsVerified: true}, userInfo.Email)
+
+	// the email is read from the userinfo endpoint if the id token does not have it
+	delete(claims, "email")
+	userInfo, err = providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{
+		"id_token":     issuer.signIDToken(claims),
+		"access_token": "access-token",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &tpmodels.EmailStruct{ID: "user@example.com", IsVerified: true}, userInfo.Email)
+}
+
+func TestOIDCProviderRejectsInvalidIDTokens(t *testing.T) {
+	issuer := newFakeOIDCIssuer(t)
+	defer issuer.server.Close()
+
+	provider := OIDC(tpmodels.OIDCConfig{
+		ID:        "keycloak",
+		IssuerURL: issuer.server.URL,
+		ClientID:  "client1",
+	})
+	providerInfo, err := provider.Get(context.Background(), nil, nil)
+	assert.NoError(t, err)
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": issuer.server.URL,
+			"sub": "user1",
+			"aud": "client1",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	getProfileInfo := func(idToken string) error {
+		_, err := providerInfo.GetProfileInfo(context.Background(), map[string]interface{}{"id_token": idToken})
+		return err
+	}
+
+	assert.NoError(t, getProfileInfo(issuer.signIDToken(validClaims())))
+
+	claims := validClaims()
+	claims["aud"] = "client2"
+	assert.Error(t, getProfileInfo(issuer.signIDToken(claims)))
+
+	claims = validClaims()
+	claims["iss"] = "https://attacker.example.com"
+	assert.Error(t, getProfileInfo(issuer.signIDToken(claims)))
+
+	claims = validClaims()
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	assert.Error(t, getProfileInfo(issuer.signIDToken(claims)))
+
+	idToken := issuer.signIDToken(validClaims())
+	claims = validClaims()
+	claims["sub"] = "user2"
+	forgedPayload, _ := json.Marshal(claims)
+	parts := strings.Split(idToken, ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forgedPayload) + "." + parts[2]
+	assert.Error(t, getProfileInfo(forged))
+}
@@ -16,6 +16,7 @@
 package thirdparty
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -36,14 +37,14 @@ type Recipe struct {
 	Providers               []tpmodels.TypeProvider
 }
 
-var singletonInstance *Recipe
-
-func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *tpmodels.TypeInput, emailVerificationInstance *emailverification.Recipe, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+func MakeRecipe(recipeId string, instance *supertokens.SuperTokens, config *tpmodels.TypeInput, emailVerificationInstance *emailverification.Recipe) (Recipe, error) {
+	appInfo := instance.AppInfo
+	onGeneralError := instance.OnGeneralError
 	r := &Recipe{}
 
 	r.RecipeModule = supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, r.handleError, onGeneralError)
 
-	querierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(recipeId)
+	querierInstance, err := instance.GetNewQuerierInstanceOrThrowError(recipeId)
 	if err != nil {
 		return Recipe{}, err
 	}
@@ -57,7 +58,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	r.Providers = config.SignInAndUpFeature.Providers
 
 	if emailVerificationInstance == nil {
-		emailVerificationRecipe, err := emailverification.MakeRecipe(recipeId, appInfo, verifiedConfig.EmailVerificationFeature, onGeneralError)
+		emailVerificationRecipe, err := emailverification.MakeRecipe(recipeId, instance, verifiedConfig.EmailVerificationFeature)
 		if err != nil {
 			return Recipe{}, err
 		}
@@ -71,22 +72,25 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 }
 
 func recipeInit(config *tpmodels.TypeInput) supertokens.Recipe {
-	return func(appInfo supertokens.NormalisedAppinfo, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
-			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, nil, onGeneralError)
+	return func(instance *supertokens.SuperTokens) (*supertokens.RecipeModule, error) {
+		if instance.GetRecipeInstance(RECIPE_ID) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, instance, config, nil)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			instance.AddRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, errors.New("ThirdParty recipe has already been initialised. Please check your code for bugs.")
 	}
 }
 
-func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+func getRecipeInstanceOrThrowError(ctx context.Context) (*Recipe, error) {
+	instance, err := supertokens.GetInstanceOrThrowError(ctx)
+	if err == nil {
+		if recipe, ok := instance.GetRecipeInstance(RECIPE_ID).(*Recipe); ok {
+			return recipe, nil
+		}
 	}
 	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
 }
@@ -102,6 +106,10 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	appleRedirectHandlerAPI, err := supertokens.NewNormalisedURLPath(AppleRedirectHandlerAPI)
+	if err != nil {
+		return nil, err
+	}
 	emailverificationAPIhandled, err := r.EmailVerificationRecipe.RecipeModule.GetAPIsHandled()
 	if err != nil {
 		return nil, err
@@ -116,6 +124,11 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 		PathWithoutAPIBasePath: authorisationAPI,
 		ID:                     AuthorisationAPI,
 		Disabled:               r.APIImpl.AuthorisationUrlGET == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: appleRedirectHandlerAPI,
+		ID:                     AppleRedirectHandlerAPI,
+		Disabled:               r.APIImpl.AppleRedirectHandlerPOST == nil,
 	}}, emailverificationAPIhandled...), nil
 }
 
@@ -124,6 +137,7 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		Config:                                r.Config,
 		OtherHandler:                          theirHandler,
 		RecipeID:                              r.RecipeModule.GetRecipeID(),
+		AppInfo:                               r.RecipeModule.GetAppInfo(),
 		RecipeImplementation:                  r.RecipeImpl,
 		EmailVerificationRecipeImplementation: r.EmailVerificationRecipe.RecipeImpl,
 		Providers:                             r.Providers,
@@ -134,6 +148,8 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		return api.SignInUpAPI(r.APIImpl, options)
 	} else if id == AuthorisationAPI {
 		return api.AuthorisationUrlAPI(r.APIImpl, options)
+	} else if id == AppleRedirectHandlerAPI {
+		return api.AppleRedirectHandler(r.APIImpl, options)
 	}
 	return r.EmailVerificationRecipe.RecipeModule.HandleAPIRequest(id, req, res, theirHandler, path, method)
 }
@@ -146,8 +162,8 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 	return r.EmailVerificationRecipe.RecipeModule.HandleError(err, req, res)
 }
 
-func (r *Recipe) getEmailForUserId(userID string) (string, error) {
-	userInfo, err := r.RecipeImpl.GetUserByID(userID)
+func (r *Recipe) getEmailForUserId(ctx context.Context, userID string) (string, error) {
+	userInfo, err := r.RecipeImpl.GetUserByID(ctx, userID)
 	if err != nil {
 		return "", err
 	}
@@ -16,83 +16,90 @@
 package thirdparty
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
+type userResponse struct {
+	Status string        `json:"status"`
+	User   tpmodels.User `json:"user"`
+}
+
 func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterface {
 	return tpmodels.RecipeInterface{
-		SignInUp: func(thirdPartyID, thirdPartyUserID string, email tpmodels.EmailStruct) (tpmodels.SignInUpResponse, error) {
-			response, err := querier.SendPostRequest("/recipe/signinup", map[string]interface{}{
+		SignInUp: func(ctx context.Context, thirdPartyID, thirdPartyUserID string, email tpmodels.EmailStruct) (tpmodels.SignInUpResponse, error) {
+			var response struct {
+				userResponse
+				CreatedNewUser bool `json:"createdNewUser"`
+			}
+			err := querier.SendPostRequestAndDecode(ctx, "/recipe/signinup", map[string]interface{}{
 				"thirdPartyId":     thirdPartyID,
 				"thirdPartyUserId": thirdPartyUserID,
 				"email":            email,
-			})
+			}, &response)
 			if err != nil {
 				return tpmodels.SignInUpResponse{}, err
 			}
-			user, err := parseUser(response["user"])
-			if err != nil {
-				return tpmodels.SignInUpResponse{}, err
+			if response.Status != "OK" {
+				return tpmodels.SignInUpResponse{}, supertokens.NewUnexpectedCoreStatusError("/recipe/signinup", response.Status)
 			}
 			return tpmodels.SignInUpResponse{
 				OK: &struct {
 					CreatedNewUser bool
 					User           tpmodels.User
+					PrimaryUserID  string
 				}{
-					CreatedNewUser: response["createdNewUser"].(bool),
-					User:           *user,
+					CreatedNewUser: response.CreatedNewUser,
+					User:           response.User,
 				},
 			}, nil
 		},
 
-		GetUserByID: func(userID string) (*tpmodels.User, error) {
-			response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		GetUserByID: func(ctx context.Context, userID string) (*tpmodels.User, error) {
+			return getUser(ctx, querier, map[string]string{
 				"userId": userID,
 			})
-			if err != nil {
-				return nil, err
-			}
-			if response["status"] == "OK" {
-				user, err := parseUser(response["user"])
-				if err != nil {
-					return nil, err
-				}
-				return user, nil
-			}
-			return nil, nil
 		},
 
-		GetUserByThirdPartyInfo: func(thirdPartyID, thirdPartyUserID string) (*tpmodels.User, error) {
-			response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		GetUserByThirdPartyInfo: func(ctx context.Context, thirdPartyID, thirdPartyUserID string) (*tpmodels.User, error) {
+			return getUser(ctx, querier, map[string]string{
 				"thirdPartyId":     thirdPartyID,
 				"thirdPartyUserId": thirdPartyUserID,
 			})
-			if err != nil {
-				return nil, err
-			}
-			if response["status"] == "OK" {
-				user, err := parseUser(response["user"])
-				if err != nil {
-					return nil, err
-				}
-				return user, nil
-			}
-			return nil, nil
 		},
 
-		GetUsersByEmail: func(email string) ([]tpmodels.User, error) {
-			response, err := querier.SendGetRequest("/recipe/users/by-email", map[string]string{
+		GetUsersByEmail: func(ctx context.Context, email string) ([]tpmodels.User, error) {
+			var response struct {
+				Users []tpmodels.User `json:"users"`
+			}
+			err := querier.SendGetRequestAndDecode(ctx, "/recipe/users/by-email", map[string]string{
 				"email": email,
-			})
+			}, &response)
 			if err != nil {
 				return []tpmodels.User{}, err
 			}
-			users, err := parseUsers(response["users"])
-			if err != nil {
-				return []tpmodels.User{}, err
+			if response.Users == nil {
+				return []tpmodels.User{}, nil
 			}
-			return users, nil
+			return response.Users, nil
 		},
 	}
 }
+
+func getUser(ctx context.Context, querier supertokens.Querier, params map[string]string) (*tpmodels.User, error) {
+	var response userResponse
+	err := querier.SendGetRequestAndDecode(ctx, "/recipe/user", params, &response)
+	if err != nil {
+		return nil, err
+	}
+	switch response.Status {
+	case "OK":
+		return &response.User, nil
+	case "UNKNOWN_USER_ID_ERROR", "UNKNOWN_THIRD_PARTY_USER_ERROR":
+		return nil, nil
+	default:
+		return nil, supertokens.NewUnexpectedCoreStatusError("/recipe/user", response.Status)
+	}
+}
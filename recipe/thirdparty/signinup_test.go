@@ -0,0 +1,174 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdparty
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func newMockGithubServer(t *testing.T) *httptest.Server {
+	github := http.NewServeMux()
+	github.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("code") != "code1" || r.PostForm.Get("client_secret") != "secret" {
+			w.Write([]byte(`{"error":"bad_verification_code"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"token1","token_type":"bearer"}`))
+	})
+	github.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":1234,"login":"user"}`))
+	})
+	github.HandleFunc("/api/v3/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		w.Write([]byte(`[{"email":"user@example.com","primary":true,"verified":true}]`))
+	})
+	return httptest.NewServer(github)
+}
+
+func mockGithubProvider(githubServer *httptest.Server) tpmodels.TypeProvider {
+	return Github(tpmodels.GithubConfig{
+		ClientID:     "client1",
+		ClientSecret: "secret",
+		Endpoints: &tpmodels.ProviderEndpoints{
+			AuthorisationURL: githubServer.URL + "/login/oauth/authorize",
+			TokenURL:         githubServer.URL + "/login/oauth/access_token",
+			UserInfoURL:      githubServer.URL + "/api/v3/user",
+		},
+	})
+}
+
+func TestSignInUpWithMockGithub(t *testing.T) {
+	githubServer := newMockGithubServer(t)
+	defer githubServer.Close()
+
+	tokenStore := MakeInMemoryTokenStore()
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpmodels.TypeInput{
+			SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
+				Providers:  []tpmodels.TypeProvider{mockGithubProvider(githubServer)},
+				TokenStore: &tokenStore,
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/authorisationurl?thirdPartyId=github", nil)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var authorisationURLResponse struct {
+		URL string `json:"url"`
+	}
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &authorisationURLResponse))
+	authorisationURL, err := url.Parse(authorisationURLResponse.URL)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(authorisationURLResponse.URL, githubServer.URL+"/login/oauth/authorize?"))
+	stateCookies := res.Result().Cookies()
+
+	body := `{"thirdPartyId":"github","code":"code1","redirectURI":"http://localhost:3000/auth/callback/github","state":"` + authorisationURL.Query().Get("state") + `"}`
+	req = httptest.NewRequest(http.MethodPost, "/auth/signinup", strings.NewReader(body))
+	for _, cookie := range stateCookies {
+		req.AddCookie(cookie)
+	}
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var signInUpResponse struct {
+		Status         string
+		CreatedNewUser bool
+		User           tpmodels.User
+	}
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &signInUpResponse))
+	assert.Equal(t, "OK", signInUpResponse.Status)
+	assert.True(t, signInUpResponse.CreatedNewUser)
+	assert.Equal(t, "user@example.com", signInUpResponse.User.Email)
+
+	ctx := supertokens.WithInstance(context.Background(), instance)
+	isVerified, err := IsEmailVerifiedWithContext(ctx, signInUpResponse.User.ID)
+	assert.NoError(t, err)
+	assert.True(t, isVerified)
+	accessToken, err := GetProviderAccessTokenWithContext(ctx, signInUpResponse.User.ID, "github")
+	assert.NoError(t, err)
+	assert.Equal(t, "token1", accessToken)
+}
+
+func TestConcurrentSignInUpsFromOneBrowser(t *testing.T) {
+	githubServer := newMockGithubServer(t)
+	defer githubServer.Close()
+
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpmodels.TypeInput{
+			SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
+				Providers: []tpmodels.TypeProvider{mockGithubProvider(githubServer)},
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	// the login is started in two tabs before it is finished in either of them, so the browser
+	// sends both state cookies with each request
+	var states []string
+	var stateCookies []*http.Cookie
+	for i := 0; i < 2; i++ {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/auth/authorisationurl?thirdPartyId=github", nil))
+		assert.Equal(t, http.StatusOK, res.Code)
+		var authorisationURLResponse struct {
+			URL string `json:"url"`
+		}
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &authorisationURLResponse))
+		authorisationURL, err := url.Parse(authorisationURLResponse.URL)
+		assert.NoError(t, err)
+		states = append(states, authorisationURL.Query().Get("state"))
+		stateCookies = append(stateCookies, res.Result().Cookies()...)
+	}
+	assert.Len(t, stateCookies, 2)
+	assert.NotEqual(t, stateCookies[0].Name, stateCookies[1].Name)
+
+	for _, state := range states {
+		body := `{"thirdPartyId":"github","code":"code1","redirectURI":"http://localhost:3000/auth/callback/github","state":"` + state + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/signinup", strings.NewReader(body))
+		for _, cookie := range stateCookies {
+			req.AddCookie(cookie)
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		var signInUpResponse struct {
+			Status string
+		}
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &signInUpResponse))
+		assert.Equal(t, "OK", signInUpResponse.Status)
+	}
+}
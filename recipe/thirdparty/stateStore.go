@@ -0,0 +1,58 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdparty
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+// MakeInMemoryStateStore returns a StateStore backed by a map, from which expired states are removed
+// on every Save
+func MakeInMemoryStateStore() tpmodels.StateStore {
+	var mutex sync.Mutex
+	states := map[string]tpmodels.StateInfo{}
+	return tpmodels.StateStore{
+		Save: func(ctx context.Context, state string, info tpmodels.StateInfo) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			now := time.Now()
+			for key, value := range states {
+				if now.After(value.ExpiresAt) {
+					delete(states, key)
+				}
+			}
+			states[state] = info
+			return nil
+		},
+		Consume: func(ctx context.Context, state string) (*tpmodels.StateInfo, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			info, ok := states[state]
+			if !ok {
+				return nil, nil
+			}
+			delete(states, state)
+			if time.Now().After(info.ExpiresAt) {
+				return nil, nil
+			}
+			return &info, nil
+		},
+	}
+}
@@ -0,0 +1,49 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdparty
+
+import (
+	"context"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+)
+
+// MakeInMemoryTokenStore returns a TokenStore backed by a map, keyed by provider and user ID
+func MakeInMemoryTokenStore() tpmodels.TokenStore {
+	var mutex sync.Mutex
+	tokensByUser := map[string]tpmodels.ProviderTokens{}
+	getKey := func(userID, thirdPartyID string) string {
+		return thirdPartyID + "\x00" + userID
+	}
+	return tpmodels.TokenStore{
+		Save: func(ctx context.Context, userID string, thirdPartyID string, tokens tpmodels.ProviderTokens) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			tokensByUser[getKey(userID, thirdPartyID)] = tokens
+			return nil
+		},
+		Get: func(ctx context.Context, userID string, thirdPartyID string) (*tpmodels.ProviderTokens, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			tokens, ok := tokensByUser[getKey(userID, thirdPartyID)]
+			if !ok {
+				return nil, nil
+			}
+			return &tokens, nil
+		},
+	}
+}
@@ -16,14 +16,17 @@
 package tpmodels
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 type APIInterface struct {
-	AuthorisationUrlGET func(provider TypeProvider, options APIOptions) (AuthorisationUrlGETResponse, error)
-	SignInUpPOST        func(provider TypeProvider, code string, redirectURI string, options APIOptions) (SignInUpPOSTResponse, error)
+	AuthorisationUrlGET      func(ctx context.Context, provider TypeProvider, options APIOptions) (AuthorisationUrlGETResponse, error)
+	SignInUpPOST             func(ctx context.Context, provider TypeProvider, code string, redirectURI string, state string, options APIOptions) (SignInUpPOSTResponse, error)
+	AppleRedirectHandlerPOST func(ctx context.Context, code string, state string, options APIOptions) error
 }
 
 type AuthorisationUrlGETResponse struct {
@@ -37,7 +40,10 @@ type SignInUpPOSTResponse struct {
 		AuthCodeResponse interface{}
 	}
 	NoEmailGivenByProviderError *struct{}
-	FieldError                  *struct{ Error string }
+	// InvalidStateError is returned if the state (or nonce) does not match the one generated
+	// for this browser in AuthorisationUrlGET
+	InvalidStateError *struct{}
+	FieldError        *struct{ Error string }
 }
 
 type APIOptions struct {
@@ -45,6 +51,7 @@ type APIOptions struct {
 	EmailVerificationRecipeImplementation evmodels.RecipeInterface
 	Config                                TypeNormalisedInput
 	RecipeID                              string
+	AppInfo                               supertokens.NormalisedAppinfo
 	Providers                             []TypeProvider
 	Req                                   *http.Request
 	Res                                   http.ResponseWriter
@@ -16,6 +16,10 @@
 package tpmodels
 
 import (
+	"context"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 )
 
@@ -32,7 +36,13 @@ type EmailStruct struct {
 type TypeProviderGetResponse struct {
 	AccessTokenAPI        AccessTokenAPI
 	AuthorisationRedirect AuthorisationRedirect
-	GetProfileInfo        func(authCodeResponse interface{}) (UserInfo, error)
+	GetProfileInfo        func(ctx context.Context, authCodeResponse interface{}) (UserInfo, error)
+	// UsesIDToken is set by OpenID Connect providers, so that a nonce is sent in the authorisation
+	// request and checked against the nonce claim of the id token
+	UsesIDToken bool
+	// UsePKCE makes AuthorisationUrlGET send an S256 code_challenge, and SignInUpPOST send the
+	// matching code_verifier in the access token request
+	UsePKCE bool
 }
 
 type AccessTokenAPI struct {
@@ -47,7 +57,7 @@ type AuthorisationRedirect struct {
 
 type TypeProvider struct {
 	ID  string
-	Get func(redirectURI *string, authCodeFromRequest *string) TypeProviderGetResponse
+	Get func(ctx context.Context, redirectURI *string, authCodeFromRequest *string) (TypeProviderGetResponse, error)
 }
 
 type User struct {
@@ -67,15 +77,57 @@ type TypeInputEmailVerificationFeature struct {
 
 type TypeInputSignInAndUp struct {
 	Providers []TypeProvider
+	// StateStore defaults to MakeInMemoryStateStore()
+	StateStore *StateStore
+	// TokenStore, if set, is given the provider's tokens after every sign in, so that they can be
+	// used later with GetProviderAccessToken
+	TokenStore *TokenStore
 }
 
 type TypeNormalisedInputSignInAndUp struct {
-	Providers []TypeProvider
+	Providers  []TypeProvider
+	StateStore StateStore
+	TokenStore *TokenStore
+}
+
+// StateInfo is what is remembered about an authorisation request until the user comes back from the provider
+type StateInfo struct {
+	ThirdPartyID string
+	Nonce        string
+	// CodeVerifier is the PKCE code verifier, if the provider uses PKCE
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// ProviderTokens are the tokens a provider issued for a user
+type ProviderTokens struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is the zero time if the provider did not say when the access token expires
+	ExpiresAt time.Time
+}
+
+// TokenStore keeps the latest tokens of each user and provider
+type TokenStore struct {
+	Save func(ctx context.Context, userID string, thirdPartyID string, tokens ProviderTokens) error
+	// Get returns nil if there are no tokens for the user and provider
+	Get func(ctx context.Context, userID string, thirdPartyID string) (*ProviderTokens, error)
+}
+
+// StateStore keeps the state of authorisation requests between AuthorisationUrlGET and SignInUpPOST.
+// Consume must delete the state, and return nil if it is unknown or has expired. The store must be
+// shared by all backend processes (like Redis), since the two requests of a login can reach
+// different ones.
+type StateStore struct {
+	Save    func(ctx context.Context, state string, info StateInfo) error
+	Consume func(ctx context.Context, state string) (*StateInfo, error)
 }
 
 type TypeInput struct {
 	SignInAndUpFeature       TypeInputSignInAndUp
 	EmailVerificationFeature *TypeInputEmailVerificationFeature
+	EmailDelivery            *emaildelivery.EmailDeliveryInterface
+	EmailTemplates           *emaildelivery.TemplatesInput
 	Override                 *OverrideStruct
 }
 
@@ -15,6 +15,18 @@
 
 package tpmodels
 
+// ProviderEndpoints overrides the endpoints of a built-in provider, for example to use GitHub
+// Enterprise, or to test against a mock provider. Empty URLs keep the provider's default.
+type ProviderEndpoints struct {
+	AuthorisationURL string
+	TokenURL         string
+	// UserInfoURL is the API the user's profile is read from. Providers that read the user's emails
+	// from a separate API (GitHub, GitLab and Bitbucket) use UserInfoURL + "/emails" for it.
+	UserInfoURL string
+	// JWKSURL is where the keys of id tokens are read from, for providers that use id tokens
+	JWKSURL string
+}
+
 type GoogleConfig struct {
 	ClientID              string
 	ClientSecret          string
@@ -22,6 +34,10 @@ type GoogleConfig struct {
 	AuthorisationRedirect *struct {
 		Params map[string]interface{}
 	}
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
 }
 
 type GithubConfig struct {
@@ -31,25 +47,115 @@ type GithubConfig struct {
 	AuthorisationRedirect *struct {
 		Params map[string]interface{}
 	}
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
+}
+
+type GitlabConfig struct {
+	ClientID     string
+	ClientSecret string
+	// GitlabBaseURL is the URL of a self-hosted GitLab instance. It defaults to https://gitlab.com
+	GitlabBaseURL         *string
+	Scope                 []string
+	AuthorisationRedirect *struct {
+		Params map[string]interface{}
+	}
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
+}
+
+type DiscordConfig struct {
+	ClientID              string
+	ClientSecret          string
+	Scope                 []string
+	AuthorisationRedirect *struct {
+		Params map[string]interface{}
+	}
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
+}
+
+type BitbucketConfig struct {
+	ClientID              string
+	ClientSecret          string
+	Scope                 []string
+	AuthorisationRedirect *struct {
+		Params map[string]interface{}
+	}
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
 }
 
 type FacebookConfig struct {
 	ClientID     string
 	ClientSecret string
 	Scope        []string
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
+}
+
+type OIDCConfig struct {
+	// ID is the third party ID the frontend uses for this provider, for example "keycloak"
+	ID string
+	// IssuerURL is the issuer identifier, without the /.well-known/openid-configuration path
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// Scope defaults to openid, email and profile
+	Scope                 []string
+	AuthorisationRedirect *struct {
+		Params map[string]interface{}
+	}
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow. Public
+	// clients, which have no ClientSecret, need it.
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
+}
+
+type AppleConfig struct {
+	ClientID string
+	// ClientSecret is used to generate the client secret JWT that Apple expects
+	ClientSecret AppleClientSecret
+	// Scope defaults to name and email
+	Scope                 []string
+	AuthorisationRedirect *struct {
+		Params map[string]interface{}
+	}
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
 }
 
-// type AppleConfig struct {
-// 	ClientID              string
-// 	ClientSecret          AppleClientSecret
-// 	Scope                 []string
-// 	AuthorisationRedirect *struct {
-// 		Params map[string]interface{}
-// 	}
-// }
-
-// type AppleClientSecret struct {
-// 	KeyId      string
-// 	PrivateKey string
-// 	TeamId     string
-// }
+type AppleClientSecret struct {
+	KeyId string
+	// PrivateKey is the content of the .p8 key file downloaded from Apple
+	PrivateKey string
+	TeamId     string
+}
+
+type MicrosoftConfig struct {
+	ClientID     string
+	ClientSecret string
+	// Tenant is "common" (the default: work, school and personal accounts), "organizations" (work and
+	// school accounts), "consumers" (personal accounts), or the ID of a tenant
+	Tenant string
+	// Scope defaults to openid, email and profile
+	Scope                 []string
+	AuthorisationRedirect *struct {
+		Params map[string]interface{}
+	}
+	// UsePKCE enables PKCE (with the S256 challenge method) for the authorisation code flow
+	UsePKCE bool
+	// Endpoints overrides the provider's default endpoints
+	Endpoints *ProviderEndpoints
+}
@@ -15,17 +15,22 @@
 
 package tpmodels
 
+import "context"
+
 type RecipeInterface struct {
-	GetUserByID             func(userID string) (*User, error)
-	GetUsersByEmail         func(email string) ([]User, error)
-	GetUserByThirdPartyInfo func(thirdPartyID string, thirdPartyUserID string) (*User, error)
-	SignInUp                func(thirdPartyID string, thirdPartyUserID string, email EmailStruct) (SignInUpResponse, error)
+	GetUserByID             func(ctx context.Context, userID string) (*User, error)
+	GetUsersByEmail         func(ctx context.Context, email string) ([]User, error)
+	GetUserByThirdPartyInfo func(ctx context.Context, thirdPartyID string, thirdPartyUserID string) (*User, error)
+	SignInUp                func(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email EmailStruct) (SignInUpResponse, error)
 }
 
 type SignInUpResponse struct {
 	OK *struct {
 		CreatedNewUser bool
 		User           User
+		// PrimaryUserID is set if the user is linked to another user (with the account linking of
+		// thirdpartyemailpassword). Sessions are created for the primary user.
+		PrimaryUserID string
 	}
 	FieldError *struct{ Error string }
 }
@@ -16,7 +16,7 @@
 package thirdparty
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
@@ -76,10 +76,12 @@ func validateAndNormaliseEmailVerificationConfig(recipeInstance *Recipe, config
 		if config.Override != nil {
 			emailverificationTypeInput.Override = config.Override.EmailVerificationFeature
 		}
+		emailverificationTypeInput.EmailDelivery = config.EmailDelivery
+		emailverificationTypeInput.EmailTemplates = config.EmailTemplates
 		if config.EmailVerificationFeature != nil {
 			if config.EmailVerificationFeature.CreateAndSendCustomEmail != nil {
 				emailverificationTypeInput.CreateAndSendCustomEmail = func(user evmodels.User, link string) {
-					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(user.ID)
+					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(context.Background(), user.ID)
 					if err != nil {
 						return
 					}
@@ -92,7 +94,7 @@ func validateAndNormaliseEmailVerificationConfig(recipeInstance *Recipe, config
 
 			if config.EmailVerificationFeature.GetEmailVerificationURL != nil {
 				emailverificationTypeInput.GetEmailVerificationURL = func(user evmodels.User) (string, error) {
-					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(user.ID)
+					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(context.Background(), user.ID)
 					if err != nil {
 						return "", err
 					}
@@ -113,33 +115,13 @@ func validateAndNormaliseSignInAndUpConfig(config tpmodels.TypeInputSignInAndUp)
 	if len(providers) == 0 {
 		return tpmodels.TypeNormalisedInputSignInAndUp{}, supertokens.BadInputError{Msg: "thirdparty recipe requires at least 1 provider to be passed in signInAndUpFeature.providers config"}
 	}
+	stateStore := MakeInMemoryStateStore()
+	if config.StateStore != nil {
+		stateStore = *config.StateStore
+	}
 	return tpmodels.TypeNormalisedInputSignInAndUp{
-		Providers: providers,
+		Providers:  providers,
+		StateStore: stateStore,
+		TokenStore: config.TokenStore,
 	}, nil
 }
-
-func parseUser(value interface{}) (*tpmodels.User, error) {
-	respJSON, err := json.Marshal(value)
-	if err != nil {
-		return nil, err
-	}
-	var user tpmodels.User
-	err = json.Unmarshal(respJSON, &user)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
-
-func parseUsers(value interface{}) ([]tpmodels.User, error) {
-	respJSON, err := json.Marshal(value)
-	if err != nil {
-		return nil, err
-	}
-	var user []tpmodels.User
-	err = json.Unmarshal(respJSON, &user)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
-}
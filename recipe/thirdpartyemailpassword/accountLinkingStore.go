@@ -0,0 +1,66 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+)
+
+// MakeInMemoryAccountLinkingStore returns an AccountLinkingStore backed by a map from each linked user
+// ID to its primary user ID
+func MakeInMemoryAccountLinkingStore() tpepmodels.AccountLinkingStore {
+	var mutex sync.Mutex
+	primaryUserIDs := map[string]string{}
+	return tpepmodels.AccountLinkingStore{
+		GetPrimaryUserID: func(ctx context.Context, userID string) (*string, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			primaryUserID, ok := primaryUserIDs[userID]
+			if !ok {
+				return nil, nil
+			}
+			return &primaryUserID, nil
+		},
+		GetLinkedUserIDs: func(ctx context.Context, primaryUserID string) ([]string, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			linkedUserIDs := []string{}
+			for userID, linkedTo := range primaryUserIDs {
+				if linkedTo == primaryUserID {
+					linkedUserIDs = append(linkedUserIDs, userID)
+				}
+			}
+			sort.Strings(linkedUserIDs)
+			return linkedUserIDs, nil
+		},
+		Link: func(ctx context.Context, primaryUserID string, userID string) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			primaryUserIDs[userID] = primaryUserID
+			return nil
+		},
+		Unlink: func(ctx context.Context, userID string) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			delete(primaryUserIDs, userID)
+			return nil
+		},
+	}
+}
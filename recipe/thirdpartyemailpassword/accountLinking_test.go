@@ -0,0 +1,246 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func TestAccountLinking(t *testing.T) {
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpepmodels.TypeInput{
+			AccountLinking: &tpepmodels.TypeInputAccountLinking{
+				Store:                           MakeInMemoryAccountLinkingStore(),
+				AutomaticallyLinkVerifiedEmails: true,
+			},
+		}),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	signUpResponse, err := SignUpWithContext(ctx, "user@example.com", "password123")
+	assert.NoError(t, err)
+	emailPasswordUserID := signUpResponse.OK.User.ID
+
+	// the emailpassword user's email is not verified yet, so a google user is not linked to it
+	signInUpResponse, err := SignInUpWithContext(ctx, "google", "google1", tpepmodels.EmailStruct{ID: "user@example.com", IsVerified: true})
+	assert.NoError(t, err)
+	googleUserID := signInUpResponse.OK.User.ID
+	assert.NotEqual(t, emailPasswordUserID, googleUserID)
+	// SignInUpPOST verifies the emails that the provider says are verified
+	verifyEmail := func(userID string) {
+		tokenResponse, err := CreateEmailVerificationTokenWithContext(ctx, userID)
+		assert.NoError(t, err)
+		_, err = VerifyEmailUsingTokenWithContext(ctx, tokenResponse.OK.Token)
+		assert.NoError(t, err)
+	}
+	verifyEmail(googleUserID)
+
+	// once it is verified, the emailpassword user is linked to the google user when signing in
+	verifyEmail(emailPasswordUserID)
+	signInResponse, err := SignInWithContext(ctx, "user@example.com", "password123")
+	assert.NoError(t, err)
+	assert.Equal(t, emailPasswordUserID, signInResponse.OK.User.ID)
+	assert.Equal(t, googleUserID, signInResponse.OK.PrimaryUserID)
+
+	primaryUser, err := GetPrimaryUserWithContext(ctx, emailPasswordUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, googleUserID, primaryUser.ID)
+	assert.Equal(t, []string{"user@example.com"}, primaryUser.Emails)
+	assert.Len(t, primaryUser.LoginMethods, 2)
+
+	// a github user with a verified email is linked when it signs up
+	signInUpResponse, err = SignInUpWithContext(ctx, "github", "github1", tpepmodels.EmailStruct{ID: "user@example.com", IsVerified: true})
+	assert.NoError(t, err)
+	assert.True(t, signInUpResponse.OK.CreatedNewUser)
+	assert.Equal(t, googleUserID, signInUpResponse.OK.PrimaryUserID)
+	githubUser, err := GetUserByThirdPartyInfoWithContext(ctx, "github", "github1", tpmodels.EmailStruct{})
+	assert.NoError(t, err)
+	assert.Equal(t, githubUser.ID, signInUpResponse.OK.User.ID)
+	assert.Equal(t, "github1", signInUpResponse.OK.User.ThirdParty.UserID)
+
+	// but not if its email is not verified
+	signInUpResponse, err = SignInUpWithContext(ctx, "gitlab", "gitlab1", tpepmodels.EmailStruct{ID: "user@example.com", IsVerified: false})
+	assert.NoError(t, err)
+	gitlabUserID := signInUpResponse.OK.User.ID
+	assert.NotEqual(t, googleUserID, gitlabUserID)
+	assert.Empty(t, signInUpResponse.OK.PrimaryUserID)
+
+	unlinkResponse, err := UnlinkAccountWithContext(ctx, googleUserID)
+	assert.NoError(t, err)
+	assert.NotNil(t, unlinkResponse.PrimaryUserHasLinkedAccountsError)
+	unlinkResponse, err = UnlinkAccountWithContext(ctx, githubUser.ID)
+	assert.NoError(t, err)
+	assert.True(t, unlinkResponse.OK.WasLinked)
+	primaryUser, err = GetPrimaryUserWithContext(ctx, googleUserID)
+	assert.NoError(t, err)
+	assert.Len(t, primaryUser.LoginMethods, 2)
+
+	linkResponse, err := LinkAccountsWithContext(ctx, githubUser.ID, gitlabUserID)
+	assert.NoError(t, err)
+	assert.NotNil(t, linkResponse.OK)
+	// the github user has users linked to it now
+	linkResponse, err = LinkAccountsWithContext(ctx, emailPasswordUserID, githubUser.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, githubUser.ID, linkResponse.AccountAlreadyLinkedError.PrimaryUserID)
+	linkResponse, err = LinkAccountsWithContext(ctx, githubUser.ID, emailPasswordUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, googleUserID, linkResponse.AccountAlreadyLinkedError.PrimaryUserID)
+	linkResponse, err = LinkAccountsWithContext(ctx, "unknown", gitlabUserID)
+	assert.NoError(t, err)
+	assert.NotNil(t, linkResponse.UnknownUserIDError)
+
+	signInUpResponse, err = SignInUpWithContext(ctx, "gitlab", "gitlab1", tpepmodels.EmailStruct{ID: "user@example.com", IsVerified: false})
+	assert.NoError(t, err)
+	assert.Equal(t, gitlabUserID, signInUpResponse.OK.User.ID)
+	assert.Equal(t, githubUser.ID, signInUpResponse.OK.PrimaryUserID)
+}
+
+func TestAccountLinkingUsesTheOverriddenRecipeImplementation(t *testing.T) {
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpepmodels.TypeInput{
+			AccountLinking: &tpepmodels.TypeInputAccountLinking{
+				Store:                           MakeInMemoryAccountLinkingStore(),
+				AutomaticallyLinkVerifiedEmails: true,
+			},
+			Override: &tpepmodels.OverrideStruct{
+				Functions: func(originalImplementation tpepmodels.RecipeInterface) tpepmodels.RecipeInterface {
+					// hides the users of other providers, so that they are never linked to
+					originalImplementation.GetUsersByEmail = func(ctx context.Context, email string) ([]tpepmodels.User, error) {
+						return []tpepmodels.User{}, nil
+					}
+					return originalImplementation
+				},
+			},
+		}),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	signInUpResponse, err := SignInUpWithContext(ctx, "google", "google1", tpepmodels.EmailStruct{ID: "user@example.com", IsVerified: true})
+	assert.NoError(t, err)
+	tokenResponse, err := CreateEmailVerificationTokenWithContext(ctx, signInUpResponse.OK.User.ID)
+	assert.NoError(t, err)
+	_, err = VerifyEmailUsingTokenWithContext(ctx, tokenResponse.OK.Token)
+	assert.NoError(t, err)
+
+	signInUpResponse, err = SignInUpWithContext(ctx, "github", "github1", tpepmodels.EmailStruct{ID: "user@example.com", IsVerified: true})
+	assert.NoError(t, err)
+	assert.Empty(t, signInUpResponse.OK.PrimaryUserID)
+}
+
+func TestThirdPartySignInUpPOSTOfALinkedUser(t *testing.T) {
+	github := http.NewServeMux()
+	github.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"token1","token_type":"bearer"}`))
+	})
+	github.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1234,"login":"user"}`))
+	})
+	github.HandleFunc("/api/v3/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"email":"user@example.com","primary":true,"verified":true}]`))
+	})
+	githubServer := httptest.NewServer(github)
+	defer githubServer.Close()
+
+	tokenStore := thirdparty.MakeInMemoryTokenStore()
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpepmodels.TypeInput{
+			Providers: []tpmodels.TypeProvider{
+				thirdparty.Github(tpmodels.GithubConfig{
+					ClientID:     "client1",
+					ClientSecret: "secret",
+					Endpoints: &tpmodels.ProviderEndpoints{
+						AuthorisationURL: githubServer.URL + "/login/oauth/authorize",
+						TokenURL:         githubServer.URL + "/login/oauth/access_token",
+						UserInfoURL:      githubServer.URL + "/api/v3/user",
+					},
+				}),
+			},
+			TokenStore: &tokenStore,
+			AccountLinking: &tpepmodels.TypeInputAccountLinking{
+				Store:                           MakeInMemoryAccountLinkingStore(),
+				AutomaticallyLinkVerifiedEmails: true,
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	signUpResponse, err := SignUpWithContext(ctx, "user@example.com", "password123")
+	assert.NoError(t, err)
+	emailPasswordUserID := signUpResponse.OK.User.ID
+	tokenResponse, err := CreateEmailVerificationTokenWithContext(ctx, emailPasswordUserID)
+	assert.NoError(t, err)
+	_, err = VerifyEmailUsingTokenWithContext(ctx, tokenResponse.OK.Token)
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/auth/authorisationurl?thirdPartyId=github", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+	var authorisationURLResponse struct {
+		URL string `json:"url"`
+	}
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &authorisationURLResponse))
+	authorisationURL, err := url.Parse(authorisationURLResponse.URL)
+	assert.NoError(t, err)
+
+	body := `{"thirdPartyId":"github","code":"code1","redirectURI":"http://localhost:3000/auth/callback/github","state":"` + authorisationURL.Query().Get("state") + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/signinup", strings.NewReader(body))
+	for _, cookie := range res.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	users, err := GetUsersByEmailWithContext(ctx, "user@example.com")
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	var githubUser tpepmodels.User
+	for _, user := range users {
+		if user.ThirdParty != nil {
+			githubUser = user
+		}
+	}
+	assert.NotEqual(t, emailPasswordUserID, githubUser.ID)
+
+	// the session is for the primary user, but the email and the provider's tokens are of the github user
+	sessionHandles, err := session.GetAllSessionHandlesForUserWithContext(ctx, emailPasswordUserID)
+	assert.NoError(t, err)
+	assert.Len(t, sessionHandles, 1)
+	isVerified, err := IsEmailVerifiedWithContext(ctx, githubUser.ID)
+	assert.NoError(t, err)
+	assert.True(t, isVerified)
+	accessToken, err := GetProviderAccessTokenWithContext(ctx, githubUser.ID, "github")
+	assert.NoError(t, err)
+	assert.Equal(t, "token1", accessToken)
+}
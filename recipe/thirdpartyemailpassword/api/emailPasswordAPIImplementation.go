@@ -16,6 +16,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
@@ -37,8 +38,8 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 		EmailExistsGET:                 apiImplmentation.EmailExistsGET,
 		GeneratePasswordResetTokenPOST: apiImplmentation.GeneratePasswordResetTokenPOST,
 		PasswordResetPOST:              apiImplmentation.PasswordResetPOST,
-		SignInPOST: func(formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignInResponse, error) {
-			resp, err := signInUpPOST(tpepmodels.SignInUpAPIInput{
+		SignInPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignInResponse, error) {
+			resp, err := signInUpPOST(ctx, tpepmodels.SignInUpAPIInput{
 				EmailpasswordInput: &tpepmodels.EmailpasswordInput{
 					FormFields: formFields,
 					Options:    options,
@@ -52,7 +53,10 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 			if result != nil {
 				if result.OK != nil {
 					return epmodels.SignInResponse{
-						OK: &struct{ User epmodels.User }{
+						OK: &struct {
+							User          epmodels.User
+							PrimaryUserID string
+						}{
 							User: epmodels.User{
 								ID:         result.OK.User.ID,
 								Email:      result.OK.User.Email,
@@ -64,12 +68,16 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 					return epmodels.SignInResponse{
 						WrongCredentialsError: &struct{}{},
 					}, nil
+				} else if result.TooManyAttemptsError != nil {
+					return epmodels.SignInResponse{
+						TooManyAttemptsError: result.TooManyAttemptsError,
+					}, nil
 				}
 			}
 			return epmodels.SignInResponse{}, errors.New("should never come here")
 		},
-		SignUpPOST: func(formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignUpResponse, error) {
-			resp, err := signInUpPOST(tpepmodels.SignInUpAPIInput{
+		SignUpPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.SignUpResponse, error) {
+			resp, err := signInUpPOST(ctx, tpepmodels.SignInUpAPIInput{
 				EmailpasswordInput: &tpepmodels.EmailpasswordInput{
 					FormFields: formFields,
 					Options:    options,
@@ -83,7 +91,10 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 			if result != nil {
 				if result.OK != nil {
 					return epmodels.SignUpResponse{
-						OK: &struct{ User epmodels.User }{
+						OK: &struct {
+							User          epmodels.User
+							PrimaryUserID string
+						}{
 							User: epmodels.User{
 								ID:         result.OK.User.ID,
 								Email:      result.OK.User.Email,
@@ -16,6 +16,8 @@
 package api
 
 import (
+	"context"
+
 	epapi "github.com/supertokens/supertokens-golang/recipe/emailpassword/api"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	tpapi "github.com/supertokens/supertokens-golang/recipe/thirdparty/api"
@@ -27,22 +29,22 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 	emailPasswordImplementation := epapi.MakeAPIImplementation()
 	thirdPartyImplementation := tpapi.MakeAPIImplementation()
 	return tpepmodels.APIInterface{
-		EmailExistsGET: func(email string, options epmodels.APIOptions) (epmodels.EmailExistsGETResponse, error) {
-			return emailPasswordImplementation.EmailExistsGET(email, options)
+		EmailExistsGET: func(ctx context.Context, email string, options epmodels.APIOptions) (epmodels.EmailExistsGETResponse, error) {
+			return emailPasswordImplementation.EmailExistsGET(ctx, email, options)
 
 		},
-		GeneratePasswordResetTokenPOST: func(formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.GeneratePasswordResetTokenPOSTResponse, error) {
-			return emailPasswordImplementation.GeneratePasswordResetTokenPOST(formFields, options)
+		GeneratePasswordResetTokenPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.GeneratePasswordResetTokenPOSTResponse, error) {
+			return emailPasswordImplementation.GeneratePasswordResetTokenPOST(ctx, formFields, options)
 		},
 
-		PasswordResetPOST: func(formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions) (epmodels.ResetPasswordUsingTokenResponse, error) {
-			return emailPasswordImplementation.PasswordResetPOST(formFields, token, options)
+		PasswordResetPOST: func(ctx context.Context, formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions) (epmodels.ResetPasswordUsingTokenResponse, error) {
+			return emailPasswordImplementation.PasswordResetPOST(ctx, formFields, token, options)
 		},
 
-		SignInUpPOST: func(input tpepmodels.SignInUpAPIInput) (tpepmodels.SignInUpAPIOutput, error) {
+		SignInUpPOST: func(ctx context.Context, input tpepmodels.SignInUpAPIInput) (tpepmodels.SignInUpAPIOutput, error) {
 			if input.EmailpasswordInput != nil {
 				if input.EmailpasswordInput.IsSignIn {
-					response, err := emailPasswordImplementation.SignInPOST(input.EmailpasswordInput.FormFields, input.EmailpasswordInput.Options)
+					response, err := emailPasswordImplementation.SignInPOST(ctx, input.EmailpasswordInput.FormFields, input.EmailpasswordInput.Options)
 					if err != nil {
 						return tpepmodels.SignInUpAPIOutput{}, err
 					}
@@ -63,6 +65,12 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 								},
 							},
 						}, nil
+					} else if response.TooManyAttemptsError != nil {
+						return tpepmodels.SignInUpAPIOutput{
+							EmailpasswordOutput: &tpepmodels.EmailpasswordOutput{
+								TooManyAttemptsError: response.TooManyAttemptsError,
+							},
+						}, nil
 					} else {
 						return tpepmodels.SignInUpAPIOutput{
 							EmailpasswordOutput: &tpepmodels.EmailpasswordOutput{
@@ -71,7 +79,7 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 						}, nil
 					}
 				} else {
-					response, err := emailPasswordImplementation.SignUpPOST(input.EmailpasswordInput.FormFields, input.EmailpasswordInput.Options)
+					response, err := emailPasswordImplementation.SignUpPOST(ctx, input.EmailpasswordInput.FormFields, input.EmailpasswordInput.Options)
 					if err != nil {
 						return tpepmodels.SignInUpAPIOutput{}, err
 					}
@@ -101,7 +109,7 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 					}
 				}
 			} else {
-				response, err := thirdPartyImplementation.SignInUpPOST(input.ThirdPartyInput.Provider, input.ThirdPartyInput.Code, input.ThirdPartyInput.RedirectURI, input.ThirdPartyInput.Options)
+				response, err := thirdPartyImplementation.SignInUpPOST(ctx, input.ThirdPartyInput.Provider, input.ThirdPartyInput.Code, input.ThirdPartyInput.RedirectURI, input.ThirdPartyInput.State, input.ThirdPartyInput.Options)
 				if err != nil {
 					return tpepmodels.SignInUpAPIOutput{}, err
 				}
@@ -117,6 +125,12 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 							NoEmailGivenByProviderError: &struct{}{},
 						},
 					}, nil
+				} else if response.InvalidStateError != nil {
+					return tpepmodels.SignInUpAPIOutput{
+						ThirdPartyOutput: &tpepmodels.ThirdPartyOutput{
+							InvalidStateError: &struct{}{},
+						},
+					}, nil
 				} else {
 					return tpepmodels.SignInUpAPIOutput{
 						ThirdPartyOutput: &tpepmodels.ThirdPartyOutput{
@@ -140,8 +154,12 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 			}
 		},
 
-		AuthorisationUrlGET: func(provider tpmodels.TypeProvider, options tpmodels.APIOptions) (tpmodels.AuthorisationUrlGETResponse, error) {
-			return thirdPartyImplementation.AuthorisationUrlGET(provider, options)
+		AuthorisationUrlGET: func(ctx context.Context, provider tpmodels.TypeProvider, options tpmodels.APIOptions) (tpmodels.AuthorisationUrlGETResponse, error) {
+			return thirdPartyImplementation.AuthorisationUrlGET(ctx, provider, options)
+		},
+
+		AppleRedirectHandlerPOST: func(ctx context.Context, code, state string, options tpmodels.APIOptions) error {
+			return thirdPartyImplementation.AppleRedirectHandlerPOST(ctx, code, state, options)
 		},
 	}
 }
@@ -16,6 +16,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
@@ -26,20 +27,24 @@ func GetThirdPartyIterfaceImpl(apiImplmentation tpepmodels.APIInterface) tpmodel
 	signInUpPOST := apiImplmentation.SignInUpPOST
 	if signInUpPOST == nil {
 		return tpmodels.APIInterface{
-			AuthorisationUrlGET: apiImplmentation.AuthorisationUrlGET,
-			SignInUpPOST:        nil,
+			AuthorisationUrlGET:      apiImplmentation.AuthorisationUrlGET,
+			SignInUpPOST:             nil,
+			AppleRedirectHandlerPOST: apiImplmentation.AppleRedirectHandlerPOST,
 		}
 	}
 	return tpmodels.APIInterface{
 
 		AuthorisationUrlGET: apiImplmentation.AuthorisationUrlGET,
 
-		SignInUpPOST: func(provider tpmodels.TypeProvider, code, redirectURI string, options tpmodels.APIOptions) (tpmodels.SignInUpPOSTResponse, error) {
-			resp, err := signInUpPOST(tpepmodels.SignInUpAPIInput{
+		AppleRedirectHandlerPOST: apiImplmentation.AppleRedirectHandlerPOST,
+
+		SignInUpPOST: func(ctx context.Context, provider tpmodels.TypeProvider, code, redirectURI, state string, options tpmodels.APIOptions) (tpmodels.SignInUpPOSTResponse, error) {
+			resp, err := signInUpPOST(ctx, tpepmodels.SignInUpAPIInput{
 				ThirdPartyInput: &tpepmodels.ThirdPartyInput{
 					Provider:    provider,
 					Code:        code,
 					RedirectURI: redirectURI,
+					State:       state,
 					Options:     options,
 				},
 			})
@@ -68,6 +73,10 @@ func GetThirdPartyIterfaceImpl(apiImplmentation tpepmodels.APIInterface) tpmodel
 					return tpmodels.SignInUpPOSTResponse{
 						NoEmailGivenByProviderError: &struct{}{},
 					}, nil
+				} else if result.InvalidStateError != nil {
+					return tpmodels.SignInUpPOSTResponse{
+						InvalidStateError: &struct{}{},
+					}, nil
 				} else if result.FieldError != nil {
 					return tpmodels.SignInUpPOSTResponse{
 						FieldError: &struct{ Error string }{
@@ -0,0 +1,84 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/breachedpasswords"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+type requestIDKey struct{}
+
+func TestBreachedPasswordCheck(t *testing.T) {
+	breachedPrefix, breachedSuffix := breachedpasswords.HashPassword("password123")
+	var requestIDs []interface{}
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpepmodels.TypeInput{
+			BreachedPasswordCheck: &epmodels.TypeInputBreachedPasswordCheck{
+				Source: epmodels.BreachedPasswordSource{
+					GetBreachCount: func(ctx context.Context, hashPrefix string, hashSuffix string) (int, error) {
+						requestIDs = append(requestIDs, ctx.Value(requestIDKey{}))
+						if hashPrefix == breachedPrefix && hashSuffix == breachedSuffix {
+							return 10, nil
+						}
+						return 0, nil
+					},
+				},
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	signUp := func(password string) (string, string) {
+		body := `{"formFields":[{"id":"email","value":"user@example.com"},{"id":"password","value":"` + password + `"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/signup", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), requestIDKey{}, password))
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		var response struct {
+			Status     string `json:"status"`
+			FormFields []struct {
+				ID    string `json:"id"`
+				Error string `json:"error"`
+			} `json:"formFields"`
+		}
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &response))
+		if len(response.FormFields) == 0 {
+			return response.Status, ""
+		}
+		return response.Status, response.FormFields[0].Error
+	}
+
+	status, msg := signUp("password123")
+	assert.Equal(t, "FIELD_ERROR", status)
+	assert.Equal(t, "Password has appeared in a data breach. Please choose a different password", msg)
+	status, _ = signUp("staple-lamp-orbit1")
+	assert.Equal(t, "OK", status)
+	assert.Equal(t, []interface{}{"password123", "staple-lamp-orbit1"}, requestIDs)
+}
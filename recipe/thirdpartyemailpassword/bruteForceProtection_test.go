@@ -0,0 +1,85 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+)
+
+func TestBruteForceProtection(t *testing.T) {
+	emailDelivery := emaildelivery.MakeLogService(emaildelivery.LogServiceConfig{Writer: ioutil.Discard})
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpepmodels.TypeInput{
+			EmailDelivery: &emailDelivery,
+			BruteForceProtection: &epmodels.TypeInputBruteForceProtection{
+				MaxFailedAttemptsPerEmail: 2,
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	_, err := SignUpWithContext(ctx, "user@example.com", "password123")
+	assert.NoError(t, err)
+
+	post := func(path string, formFields string) (string, string) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"formFields":`+formFields+`}`))
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		var response struct {
+			Status string `json:"status"`
+		}
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &response))
+		return response.Status, res.Header().Get("Retry-After")
+	}
+	signIn := func(password string) (string, string) {
+		return post("/auth/signin", `[{"id":"email","value":"user@example.com"},{"id":"password","value":"`+password+`"}]`)
+	}
+
+	status, _ := signIn("password123")
+	assert.Equal(t, "OK", status)
+	for i := 0; i < 2; i++ {
+		status, _ = signIn("wrong123")
+		assert.Equal(t, "WRONG_CREDENTIALS_ERROR", status)
+	}
+	status, retryAfter := signIn("password123")
+	assert.Equal(t, "TOO_MANY_ATTEMPTS_ERROR", status)
+	assert.Equal(t, "60", retryAfter)
+
+	for i := 0; i < 2; i++ {
+		status, _ = post("/auth/user/password/reset/token", `[{"id":"email","value":"user@example.com"}]`)
+		assert.Equal(t, "OK", status)
+	}
+	status, _ = post("/auth/user/password/reset/token", `[{"id":"email","value":"user@example.com"}]`)
+	assert.Equal(t, "TOO_MANY_ATTEMPTS_ERROR", status)
+}
@@ -0,0 +1,76 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/coretest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestImportUserWithPasswordHash(t *testing.T) {
+	store := emailpassword.MakeInMemoryLegacyPasswordHashStore()
+	_, instance, cleanup := coretest.NewInstance(t,
+		Init(&tpepmodels.TypeInput{
+			PasswordHashing: &epmodels.TypeInputPasswordHashing{
+				Store: &store,
+			},
+		}),
+		session.Init(nil),
+	)
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+	handler := instance.Middleware(http.NotFoundHandler())
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("legacy123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	importResponse, err := ImportUserWithPasswordHashWithContext(ctx, "legacy@example.com", string(bcryptHash))
+	assert.NoError(t, err)
+	userID := importResponse.OK.User.ID
+	_, err = ImportUserWithPasswordHashWithContext(ctx, "other@example.com", "not a hash")
+	assert.Error(t, err)
+
+	signIn := func(password string) string {
+		body := `{"formFields":[{"id":"email","value":"legacy@example.com"},{"id":"password","value":"` + password + `"}]}`
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/auth/signin", strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, res.Code)
+		var response struct {
+			Status string
+		}
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &response))
+		return response.Status
+	}
+	assert.Equal(t, "WRONG_CREDENTIALS_ERROR", signIn("wrong123"))
+	// the first sign in is checked against the imported hash, and sets the password in the core
+	assert.Equal(t, "OK", signIn("legacy123"))
+	storedHash, err := store.Get(ctx, userID)
+	assert.NoError(t, err)
+	assert.Nil(t, storedHash)
+	assert.Equal(t, "OK", signIn("legacy123"))
+}
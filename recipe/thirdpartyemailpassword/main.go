@@ -16,8 +16,10 @@
 package thirdpartyemailpassword
 
 import (
+	"context"
 	"errors"
 
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
@@ -30,136 +32,271 @@ func Init(config *tpepmodels.TypeInput) supertokens.Recipe {
 }
 
 func SignInUp(thirdPartyID string, thirdPartyUserID string, email tpepmodels.EmailStruct) (tpepmodels.SignInUpResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return SignInUpWithContext(context.Background(), thirdPartyID, thirdPartyUserID, email)
+}
+
+func SignInUpWithContext(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email tpepmodels.EmailStruct) (tpepmodels.SignInUpResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return tpepmodels.SignInUpResponse{}, err
 	}
-	return instance.RecipeImpl.SignInUp(thirdPartyID, thirdPartyUserID, email)
+	return instance.RecipeImpl.SignInUp(ctx, thirdPartyID, thirdPartyUserID, email)
 }
 
 func GetUserByThirdPartyInfo(thirdPartyID string, thirdPartyUserID string, email tpmodels.EmailStruct) (*tpepmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUserByThirdPartyInfoWithContext(context.Background(), thirdPartyID, thirdPartyUserID, email)
+}
+
+func GetUserByThirdPartyInfoWithContext(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email tpmodels.EmailStruct) (*tpepmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetUserByThirdPartyInfo(thirdPartyID, thirdPartyUserID)
+	return instance.RecipeImpl.GetUserByThirdPartyInfo(ctx, thirdPartyID, thirdPartyUserID)
 }
 
 func SignUp(email, password string) (tpepmodels.SignUpResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return SignUpWithContext(context.Background(), email, password)
+}
+
+func SignUpWithContext(ctx context.Context, email, password string) (tpepmodels.SignUpResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return tpepmodels.SignUpResponse{}, err
 	}
-	return instance.RecipeImpl.SignUp(email, password)
+	return instance.RecipeImpl.SignUp(ctx, email, password)
 }
 
 func SignIn(email, password string) (tpepmodels.SignInResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return SignInWithContext(context.Background(), email, password)
+}
+
+func SignInWithContext(ctx context.Context, email, password string) (tpepmodels.SignInResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return tpepmodels.SignInResponse{}, err
 	}
-	return instance.RecipeImpl.SignIn(email, password)
+	return instance.RecipeImpl.SignIn(ctx, email, password)
 }
 
 func GetUserById(userID string) (*tpepmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUserByIdWithContext(context.Background(), userID)
+}
+
+func GetUserByIdWithContext(ctx context.Context, userID string) (*tpepmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetUserByID(userID)
+	return instance.RecipeImpl.GetUserByID(ctx, userID)
 }
 
 func GetUsersByEmail(email string) ([]tpepmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return GetUsersByEmailWithContext(context.Background(), email)
+}
+
+func GetUsersByEmailWithContext(ctx context.Context, email string) ([]tpepmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.RecipeImpl.GetUsersByEmail(email)
+	return instance.RecipeImpl.GetUsersByEmail(ctx, email)
 }
 
 func CreateResetPasswordToken(userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return CreateResetPasswordTokenWithContext(context.Background(), userID)
+}
+
+func CreateResetPasswordTokenWithContext(ctx context.Context, userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.CreateResetPasswordTokenResponse{}, err
 	}
-	return instance.RecipeImpl.CreateResetPasswordToken(userID)
+	return instance.RecipeImpl.CreateResetPasswordToken(ctx, userID)
 }
 
 func ResetPasswordUsingToken(token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return ResetPasswordUsingTokenWithContext(context.Background(), token, newPassword)
+}
+
+func ResetPasswordUsingTokenWithContext(ctx context.Context, token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.ResetPasswordUsingTokenResponse{}, err
 	}
-	return instance.RecipeImpl.ResetPasswordUsingToken(token, newPassword)
+	return instance.RecipeImpl.ResetPasswordUsingToken(ctx, token, newPassword)
 }
 
 func UpdateEmailOrPassword(userId string, email *string, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UpdateEmailOrPasswordWithContext(context.Background(), userId, email, password)
+}
+
+func UpdateEmailOrPasswordWithContext(ctx context.Context, userId string, email *string, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return epmodels.UpdateEmailOrPasswordResponse{}, err
 	}
-	return instance.RecipeImpl.UpdateEmailOrPassword(userId, email, password)
+	return instance.RecipeImpl.UpdateEmailOrPassword(ctx, userId, email, password)
+}
+
+// ImportUserWithPasswordHash creates an emailpassword user with the password hash of another system,
+// like emailpassword.ImportUserWithPasswordHash. A PasswordHashing.Store must be configured.
+func ImportUserWithPasswordHash(email string, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	return ImportUserWithPasswordHashWithContext(context.Background(), email, passwordHash)
+}
+
+func ImportUserWithPasswordHashWithContext(ctx context.Context, email string, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	return instance.RecipeImpl.ImportUserWithPasswordHash(ctx, email, passwordHash)
+}
+
+// ValidatePasswordHash returns an error if users can't be imported with the password hash, because it
+// is not in a supported format or no PasswordHashing.Store is configured
+func ValidatePasswordHash(passwordHash string) error {
+	return ValidatePasswordHashWithContext(context.Background(), passwordHash)
+}
+
+func ValidatePasswordHashWithContext(ctx context.Context, passwordHash string) error {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return err
+	}
+	return emailpassword.ValidatePasswordHashForImport(instance.Config.PasswordHashing, passwordHash)
 }
 
 func CreateEmailVerificationToken(userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return CreateEmailVerificationTokenWithContext(context.Background(), userID)
+}
+
+func CreateEmailVerificationTokenWithContext(ctx context.Context, userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.CreateEmailVerificationToken(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.CreateEmailVerificationToken(ctx, userID, email)
 }
 
 func VerifyEmailUsingToken(token string) (*tpepmodels.User, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return VerifyEmailUsingTokenWithContext(context.Background(), token)
+}
+
+func VerifyEmailUsingTokenWithContext(ctx context.Context, token string) (*tpepmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return nil, err
 	}
-	response, err := instance.EmailVerificationRecipe.RecipeImpl.VerifyEmailUsingToken(token)
+	response, err := instance.EmailVerificationRecipe.RecipeImpl.VerifyEmailUsingToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
 	if response.EmailVerificationInvalidTokenError != nil {
 		return nil, errors.New("email verification token is invalid")
 	}
-	return instance.RecipeImpl.GetUserByID(response.OK.User.ID)
+	return instance.RecipeImpl.GetUserByID(ctx, response.OK.User.ID)
 }
 
 func IsEmailVerified(userID string) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return IsEmailVerifiedWithContext(context.Background(), userID)
+}
+
+func IsEmailVerifiedWithContext(ctx context.Context, userID string) (bool, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return false, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return false, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.IsEmailVerified(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.IsEmailVerified(ctx, userID, email)
 }
 
 func RevokeEmailVerificationTokens(userID string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return RevokeEmailVerificationTokensWithContext(context.Background(), userID)
+}
+
+func RevokeEmailVerificationTokensWithContext(ctx context.Context, userID string) (evmodels.RevokeEmailVerificationTokensResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.RevokeEmailVerificationTokens(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.RevokeEmailVerificationTokens(ctx, userID, email)
 }
 
 func UnverifyEmail(userID string) (evmodels.UnverifyEmailResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	return UnverifyEmailWithContext(context.Background(), userID)
+}
+
+func UnverifyEmailWithContext(ctx context.Context, userID string) (evmodels.UnverifyEmailResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
-	email, err := instance.getEmailForUserId(userID)
+	email, err := instance.getEmailForUserId(ctx, userID)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
-	return instance.EmailVerificationRecipe.RecipeImpl.UnverifyEmail(userID, email)
+	return instance.EmailVerificationRecipe.RecipeImpl.UnverifyEmail(ctx, userID, email)
+}
+
+func GetProviderAccessToken(userID string, thirdPartyID string) (string, error) {
+	return GetProviderAccessTokenWithContext(context.Background(), userID, thirdPartyID)
+}
+
+func GetProviderAccessTokenWithContext(ctx context.Context, userID string, thirdPartyID string) (string, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return "", err
+	}
+	if instance.thirdPartyRecipe == nil {
+		return "", errors.New("no third party providers are configured")
+	}
+	return instance.thirdPartyRecipe.GetProviderAccessToken(ctx, userID, thirdPartyID)
+}
+
+func GetPrimaryUser(userID string) (*tpepmodels.PrimaryUser, error) {
+	return GetPrimaryUserWithContext(context.Background(), userID)
+}
+
+func GetPrimaryUserWithContext(ctx context.Context, userID string) (*tpepmodels.PrimaryUser, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instance.RecipeImpl.GetPrimaryUser(ctx, userID)
+}
+
+func LinkAccounts(primaryUserID string, userID string) (tpepmodels.LinkAccountsResponse, error) {
+	return LinkAccountsWithContext(context.Background(), primaryUserID, userID)
+}
+
+func LinkAccountsWithContext(ctx context.Context, primaryUserID string, userID string) (tpepmodels.LinkAccountsResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return tpepmodels.LinkAccountsResponse{}, err
+	}
+	return instance.RecipeImpl.LinkAccounts(ctx, primaryUserID, userID)
+}
+
+func UnlinkAccount(userID string) (tpepmodels.UnlinkAccountResponse, error) {
+	return UnlinkAccountWithContext(context.Background(), userID)
+}
+
+func UnlinkAccountWithContext(ctx context.Context, userID string) (tpepmodels.UnlinkAccountResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError(ctx)
+	if err != nil {
+		return tpepmodels.UnlinkAccountResponse{}, err
+	}
+	return instance.RecipeImpl.UnlinkAccount(ctx, userID)
 }
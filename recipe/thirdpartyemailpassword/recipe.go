@@ -16,6 +16,7 @@
 package thirdpartyemailpassword
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -42,9 +43,9 @@ type Recipe struct {
 	APIImpl                 tpepmodels.APIInterface
 }
 
-var singletonInstance *Recipe
-
-func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *tpepmodels.TypeInput, emailVerificationInstance *emailverification.Recipe, thirdPartyInstance *thirdparty.Recipe, emailPasswordInstance *emailpassword.Recipe, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+func MakeRecipe(recipeId string, instance *supertokens.SuperTokens, config *tpepmodels.TypeInput, emailVerificationInstance *emailverification.Recipe, thirdPartyInstance *thirdparty.Recipe, emailPasswordInstance *emailpassword.Recipe) (Recipe, error) {
+	appInfo := instance.AppInfo
+	onGeneralError := instance.OnGeneralError
 	r := &Recipe{}
 	r.RecipeModule = supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, r.handleError, onGeneralError)
 
@@ -54,21 +55,23 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	}
 	r.Config = verifiedConfig
 	{
-		emailpasswordquerierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(emailpassword.RECIPE_ID)
+		emailpasswordquerierInstance, err := instance.GetNewQuerierInstanceOrThrowError(emailpassword.RECIPE_ID)
 		if err != nil {
 			return Recipe{}, err
 		}
-		thirdpartyquerierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(thirdparty.RECIPE_ID)
+		thirdpartyquerierInstance, err := instance.GetNewQuerierInstanceOrThrowError(thirdparty.RECIPE_ID)
 		if err != nil {
 			return Recipe{}, err
 		}
 
-		r.RecipeImpl = verifiedConfig.Override.Functions(recipeimplementation.MakeRecipeImplementation(*emailpasswordquerierInstance, thirdpartyquerierInstance))
+		r.RecipeImpl = verifiedConfig.Override.Functions(recipeimplementation.MakeRecipeImplementation(*emailpasswordquerierInstance, thirdpartyquerierInstance, verifiedConfig.PasswordHashing, verifiedConfig.PasswordPolicy, verifiedConfig.AccountLinking, func() tpepmodels.RecipeInterface {
+			return r.RecipeImpl
+		}, r.isEmailVerified))
 	}
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
 
 	if emailVerificationInstance == nil {
-		emailVerificationRecipe, err := emailverification.MakeRecipe(recipeId, appInfo, verifiedConfig.EmailVerificationFeature, onGeneralError)
+		emailVerificationRecipe, err := emailverification.MakeRecipe(recipeId, instance, verifiedConfig.EmailVerificationFeature)
 		if err != nil {
 			return Recipe{}, err
 		}
@@ -83,6 +86,11 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 		emailPasswordConfig := &epmodels.TypeInput{
 			SignUpFeature:                  verifiedConfig.SignUpFeature,
 			ResetPasswordUsingTokenFeature: verifiedConfig.ResetPasswordUsingTokenFeature,
+			EmailDelivery:                  verifiedConfig.EmailDelivery,
+			EmailTemplates:                 verifiedConfig.EmailTemplates,
+			BruteForceProtection:           verifiedConfig.BruteForceProtection,
+			PasswordPolicy:                 verifiedConfig.PasswordPolicy,
+			BreachedPasswordCheck:          verifiedConfig.BreachedPasswordCheck,
 			Override: &epmodels.OverrideStruct{
 				Functions: func(_ epmodels.RecipeInterface) epmodels.RecipeInterface {
 					return recipeimplementation.MakeEmailPasswordRecipeImplementation(r.RecipeImpl)
@@ -93,7 +101,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 				EmailVerificationFeature: nil,
 			},
 		}
-		emailPasswordRecipe, err = emailpassword.MakeRecipe(recipeId, appInfo, emailPasswordConfig, &r.EmailVerificationRecipe, onGeneralError)
+		emailPasswordRecipe, err = emailpassword.MakeRecipe(recipeId, instance, emailPasswordConfig, &r.EmailVerificationRecipe)
 		if err != nil {
 			return Recipe{}, err
 		}
@@ -106,7 +114,9 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 		if thirdPartyInstance == nil {
 			thirdPartyConfig := &tpmodels.TypeInput{
 				SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
-					Providers: verifiedConfig.Providers,
+					Providers:  verifiedConfig.Providers,
+					StateStore: verifiedConfig.StateStore,
+					TokenStore: verifiedConfig.TokenStore,
 				},
 				Override: &tpmodels.OverrideStruct{
 					Functions: func(_ tpmodels.RecipeInterface) tpmodels.RecipeInterface {
@@ -118,7 +128,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 					EmailVerificationFeature: nil,
 				},
 			}
-			thirdPartyRecipeinstance, err := thirdparty.MakeRecipe(recipeId, appInfo, thirdPartyConfig, &r.EmailVerificationRecipe, onGeneralError)
+			thirdPartyRecipeinstance, err := thirdparty.MakeRecipe(recipeId, instance, thirdPartyConfig, &r.EmailVerificationRecipe)
 			if err != nil {
 				return Recipe{}, err
 			}
@@ -132,22 +142,25 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 }
 
 func recipeInit(config *tpepmodels.TypeInput) supertokens.Recipe {
-	return func(appInfo supertokens.NormalisedAppinfo, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
-			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, nil, nil, nil, onGeneralError)
+	return func(instance *supertokens.SuperTokens) (*supertokens.RecipeModule, error) {
+		if instance.GetRecipeInstance(RECIPE_ID) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, instance, config, nil, nil, nil)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			instance.AddRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, errors.New("ThirdPartyEmailPassword recipe has already been initialised. Please check your code for bugs.")
 	}
 }
 
-func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+func getRecipeInstanceOrThrowError(ctx context.Context) (*Recipe, error) {
+	instance, err := supertokens.GetInstanceOrThrowError(ctx)
+	if err == nil {
+		if recipe, ok := instance.GetRecipeInstance(RECIPE_ID).(*Recipe); ok {
+			return recipe, nil
+		}
 	}
 	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
 }
@@ -216,8 +229,8 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 	return r.EmailVerificationRecipe.RecipeModule.HandleError(err, req, res)
 }
 
-func (r *Recipe) getEmailForUserId(userID string) (string, error) {
-	userInfo, err := r.RecipeImpl.GetUserByID(userID)
+func (r *Recipe) getEmailForUserId(ctx context.Context, userID string) (string, error) {
+	userInfo, err := r.RecipeImpl.GetUserByID(ctx, userID)
 	if err != nil {
 		return "", err
 	}
@@ -226,3 +239,7 @@ func (r *Recipe) getEmailForUserId(userID string) (string, error) {
 	}
 	return userInfo.Email, nil
 }
+
+func (r *Recipe) isEmailVerified(ctx context.Context, userID string, email string) (bool, error) {
+	return r.EmailVerificationRecipe.RecipeImpl.IsEmailVerified(ctx, userID, email)
+}
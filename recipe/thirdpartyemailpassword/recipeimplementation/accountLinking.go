@@ -0,0 +1,311 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package recipeimplementation
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+)
+
+type accountLinking struct {
+	config *tpepmodels.TypeInputAccountLinking
+	// getRecipeImpl returns the recipe implementation with the user's overrides applied
+	getRecipeImpl   func() tpepmodels.RecipeInterface
+	isEmailVerified func(ctx context.Context, userID string, email string) (bool, error)
+}
+
+var errAccountLinkingNotEnabled = errors.New("account linking is not enabled. Please set AccountLinking in the thirdpartyemailpassword config")
+
+// getPrimaryUserID returns the user's own ID if it is not linked to another user
+func (a accountLinking) getPrimaryUserID(ctx context.Context, userID string) (string, error) {
+	if a.config == nil {
+		return userID, nil
+	}
+	primaryUserID, err := a.config.Store.GetPrimaryUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if primaryUserID == nil {
+		return userID, nil
+	}
+	return *primaryUserID, nil
+}
+
+// onSignIn links a user that just signed in (or up) automatically if enabled, and returns the ID that
+// their session should be created with
+func (a accountLinking) onSignIn(ctx context.Context, user tpepmodels.User, isEmailVerified bool) (string, error) {
+	if a.config == nil {
+		return user.ID, nil
+	}
+	primaryUserID, err := a.config.Store.GetPrimaryUserID(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if primaryUserID != nil {
+		return *primaryUserID, nil
+	}
+	if !a.config.AutomaticallyLinkVerifiedEmails {
+		return user.ID, nil
+	}
+	linkedUserIDs, err := a.config.Store.GetLinkedUserIDs(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(linkedUserIDs) > 0 {
+		return user.ID, nil
+	}
+
+	// linking an unverified account would let whoever created it access the existing user
+	if !isEmailVerified {
+		isEmailVerified, err = a.isEmailVerified(ctx, user.ID, user.Email)
+		if err != nil {
+			return "", err
+		}
+		if !isEmailVerified {
+			return user.ID, nil
+		}
+	}
+	usersWithSameEmail, err := a.getRecipeImpl().GetUsersByEmail(ctx, user.Email)
+	if err != nil {
+		return "", err
+	}
+	sort.SliceStable(usersWithSameEmail, func(i, j int) bool {
+		return usersWithSameEmail[i].TimeJoined < usersWithSameEmail[j].TimeJoined
+	})
+	for _, existingUser := range usersWithSameEmail {
+		if existingUser.ID == user.ID {
+			continue
+		}
+		isExistingUserVerified, err := a.isEmailVerified(ctx, existingUser.ID, existingUser.Email)
+		if err != nil {
+			return "", err
+		}
+		if !isExistingUserVerified {
+			continue
+		}
+		primaryUserID, err := a.getPrimaryUserID(ctx, existingUser.ID)
+		if err != nil {
+			return "", err
+		}
+		err = a.config.Store.Link(ctx, primaryUserID, user.ID)
+		if err != nil {
+			return "", err
+		}
+		return primaryUserID, nil
+	}
+	return user.ID, nil
+}
+
+func (a accountLinking) getPrimaryUser(ctx context.Context, userID string) (*tpepmodels.PrimaryUser, error) {
+	primaryUserID, err := a.getPrimaryUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := a.getRecipeImpl().GetUserByID(ctx, primaryUserID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	loginMethods := []tpepmodels.User{*user}
+	if a.config != nil {
+		linkedUserIDs, err := a.config.Store.GetLinkedUserIDs(ctx, primaryUserID)
+		if err != nil {
+			return nil, err
+		}
+		for _, linkedUserID := range linkedUserIDs {
+			linkedUser, err := a.getRecipeImpl().GetUserByID(ctx, linkedUserID)
+			if err != nil {
+				return nil, err
+			}
+			// the user may have been deleted from the core
+			if linkedUser != nil {
+				loginMethods = append(loginMethods, *linkedUser)
+			}
+		}
+	}
+
+	primaryUser := &tpepmodels.PrimaryUser{
+		ID:           primaryUserID,
+		TimeJoined:   user.TimeJoined,
+		Emails:       []string{},
+		LoginMethods: loginMethods,
+	}
+	for _, loginMethod := range loginMethods {
+		if loginMethod.TimeJoined < primaryUser.TimeJoined {
+			primaryUser.TimeJoined = loginMethod.TimeJoined
+		}
+		isNewEmail := true
+		for _, email := range primaryUser.Emails {
+			if email == loginMethod.Email {
+				isNewEmail = false
+				break
+			}
+		}
+		if isNewEmail {
+			primaryUser.Emails = append(primaryUser.Emails, loginMethod.Email)
+		}
+	}
+	return primaryUser, nil
+}
+
+func (a accountLinking) linkAccounts(ctx context.Context, primaryUserID string, userID string) (tpepmodels.LinkAccountsResponse, error) {
+	if a.config == nil {
+		return tpepmodels.LinkAccountsResponse{}, errAccountLinkingNotEnabled
+	}
+	// linking to a user that is itself linked links to its primary user
+	primaryUserID, err := a.getPrimaryUserID(ctx, primaryUserID)
+	if err != nil {
+		return tpepmodels.LinkAccountsResponse{}, err
+	}
+	for _, ID := range []string{primaryUserID, userID} {
+		user, err := a.getRecipeImpl().GetUserByID(ctx, ID)
+		if err != nil {
+			return tpepmodels.LinkAccountsResponse{}, err
+		}
+		if user == nil {
+			return tpepmodels.LinkAccountsResponse{
+				UnknownUserIDError: &struct{}{},
+			}, nil
+		}
+	}
+
+	currentPrimaryUserID, err := a.getPrimaryUserID(ctx, userID)
+	if err != nil {
+		return tpepmodels.LinkAccountsResponse{}, err
+	}
+	if currentPrimaryUserID == primaryUserID {
+		return tpepmodels.LinkAccountsResponse{
+			OK: &struct{}{},
+		}, nil
+	}
+	if currentPrimaryUserID != userID {
+		return tpepmodels.LinkAccountsResponse{
+			AccountAlreadyLinkedError: &struct{ PrimaryUserID string }{
+				PrimaryUserID: currentPrimaryUserID,
+			},
+		}, nil
+	}
+	linkedUserIDs, err := a.config.Store.GetLinkedUserIDs(ctx, userID)
+	if err != nil {
+		return tpepmodels.LinkAccountsResponse{}, err
+	}
+	if len(linkedUserIDs) > 0 {
+		return tpepmodels.LinkAccountsResponse{
+			AccountAlreadyLinkedError: &struct{ PrimaryUserID string }{
+				PrimaryUserID: userID,
+			},
+		}, nil
+	}
+
+	err = a.config.Store.Link(ctx, primaryUserID, userID)
+	if err != nil {
+		return tpepmodels.LinkAccountsResponse{}, err
+	}
+	return tpepmodels.LinkAccountsResponse{
+		OK: &struct{}{},
+	}, nil
+}
+
+func (a accountLinking) unlinkAccount(ctx context.Context, userID string) (tpepmodels.UnlinkAccountResponse, error) {
+	if a.config == nil {
+		return tpepmodels.UnlinkAccountResponse{}, errAccountLinkingNotEnabled
+	}
+	primaryUserID, err := a.config.Store.GetPrimaryUserID(ctx, userID)
+	if err != nil {
+		return tpepmodels.UnlinkAccountResponse{}, err
+	}
+	if primaryUserID == nil {
+		linkedUserIDs, err := a.config.Store.GetLinkedUserIDs(ctx, userID)
+		if err != nil {
+			return tpepmodels.UnlinkAccountResponse{}, err
+		}
+		if len(linkedUserIDs) > 0 {
+			return tpepmodels.UnlinkAccountResponse{
+				PrimaryUserHasLinkedAccountsError: &struct{}{},
+			}, nil
+		}
+		return tpepmodels.UnlinkAccountResponse{
+			OK: &struct{ WasLinked bool }{WasLinked: false},
+		}, nil
+	}
+	err = a.config.Store.Unlink(ctx, userID)
+	if err != nil {
+		return tpepmodels.UnlinkAccountResponse{}, err
+	}
+	return tpepmodels.UnlinkAccountResponse{
+		OK: &struct{ WasLinked bool }{WasLinked: true},
+	}, nil
+}
+
+// withAccountLinking adds the account linking functions, and makes SignUp, SignIn and SignInUp return
+// the ID of the primary user of linked users, so that sessions are always created for it
+func withAccountLinking(recipeImplementation tpepmodels.RecipeInterface, linking accountLinking) tpepmodels.RecipeInterface {
+	signUp := recipeImplementation.SignUp
+	signIn := recipeImplementation.SignIn
+	signInUp := recipeImplementation.SignInUp
+
+	recipeImplementation.SignUp = func(ctx context.Context, email, password string) (tpepmodels.SignUpResponse, error) {
+		response, err := signUp(ctx, email, password)
+		if err != nil || response.OK == nil {
+			return response, err
+		}
+		primaryUserID, err := linking.onSignIn(ctx, response.OK.User, false)
+		if err != nil {
+			return tpepmodels.SignUpResponse{}, err
+		}
+		if primaryUserID != response.OK.User.ID {
+			response.OK.PrimaryUserID = primaryUserID
+		}
+		return response, nil
+	}
+
+	recipeImplementation.SignIn = func(ctx context.Context, email, password string) (tpepmodels.SignInResponse, error) {
+		response, err := signIn(ctx, email, password)
+		if err != nil || response.OK == nil {
+			return response, err
+		}
+		primaryUserID, err := linking.onSignIn(ctx, response.OK.User, false)
+		if err != nil {
+			return tpepmodels.SignInResponse{}, err
+		}
+		if primaryUserID != response.OK.User.ID {
+			response.OK.PrimaryUserID = primaryUserID
+		}
+		return response, nil
+	}
+
+	recipeImplementation.SignInUp = func(ctx context.Context, thirdPartyID, thirdPartyUserID string, email tpepmodels.EmailStruct) (tpepmodels.SignInUpResponse, error) {
+		response, err := signInUp(ctx, thirdPartyID, thirdPartyUserID, email)
+		if err != nil || response.OK == nil {
+			return response, err
+		}
+		primaryUserID, err := linking.onSignIn(ctx, response.OK.User, email.IsVerified)
+		if err != nil {
+			return tpepmodels.SignInUpResponse{}, err
+		}
+		if primaryUserID != response.OK.User.ID {
+			response.OK.PrimaryUserID = primaryUserID
+		}
+		return response, nil
+	}
+
+	recipeImplementation.GetPrimaryUser = linking.getPrimaryUser
+	recipeImplementation.LinkAccounts = linking.linkAccounts
+	recipeImplementation.UnlinkAccount = linking.unlinkAccount
+	return recipeImplementation
+}
@@ -16,14 +16,16 @@
 package recipeimplementation
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
 )
 
 func MakeEmailPasswordRecipeImplementation(recipeImplementation tpepmodels.RecipeInterface) epmodels.RecipeInterface {
 	return epmodels.RecipeInterface{
-		SignUp: func(email, password string) (epmodels.SignUpResponse, error) {
-			response, err := recipeImplementation.SignUp(email, password)
+		SignUp: func(ctx context.Context, email, password string) (epmodels.SignUpResponse, error) {
+			response, err := recipeImplementation.SignUp(ctx, email, password)
 			if err != nil {
 				return epmodels.SignUpResponse{}, err
 			}
@@ -33,18 +35,22 @@ func MakeEmailPasswordRecipeImplementation(recipeImplementation tpepmodels.Recip
 				}, nil
 			}
 			return epmodels.SignUpResponse{
-				OK: &struct{ User epmodels.User }{
+				OK: &struct {
+					User          epmodels.User
+					PrimaryUserID string
+				}{
 					User: epmodels.User{
 						ID:         response.OK.User.ID,
 						Email:      response.OK.User.Email,
 						TimeJoined: response.OK.User.TimeJoined,
 					},
+					PrimaryUserID: response.OK.PrimaryUserID,
 				},
 			}, nil
 		},
 
-		SignIn: func(email, password string) (epmodels.SignInResponse, error) {
-			response, err := recipeImplementation.SignIn(email, password)
+		SignIn: func(ctx context.Context, email, password string) (epmodels.SignInResponse, error) {
+			response, err := recipeImplementation.SignIn(ctx, email, password)
 			if err != nil {
 				return epmodels.SignInResponse{}, err
 			}
@@ -54,18 +60,22 @@ func MakeEmailPasswordRecipeImplementation(recipeImplementation tpepmodels.Recip
 				}, nil
 			}
 			return epmodels.SignInResponse{
-				OK: &struct{ User epmodels.User }{
+				OK: &struct {
+					User          epmodels.User
+					PrimaryUserID string
+				}{
 					User: epmodels.User{
 						ID:         response.OK.User.ID,
 						Email:      response.OK.User.Email,
 						TimeJoined: response.OK.User.TimeJoined,
 					},
+					PrimaryUserID: response.OK.PrimaryUserID,
 				},
 			}, nil
 		},
 
-		GetUserByID: func(userId string) (*epmodels.User, error) {
-			user, err := recipeImplementation.GetUserByID(userId)
+		GetUserByID: func(ctx context.Context, userId string) (*epmodels.User, error) {
+			user, err := recipeImplementation.GetUserByID(ctx, userId)
 			if err != nil {
 				return nil, err
 			}
@@ -79,8 +89,8 @@ func MakeEmailPasswordRecipeImplementation(recipeImplementation tpepmodels.Recip
 			}, nil
 		},
 
-		GetUserByEmail: func(email string) (*epmodels.User, error) {
-			users, err := recipeImplementation.GetUsersByEmail(email)
+		GetUserByEmail: func(ctx context.Context, email string) (*epmodels.User, error) {
+			users, err := recipeImplementation.GetUsersByEmail(ctx, email)
 			if err != nil {
 				return nil, err
 			}
@@ -97,14 +107,17 @@ func MakeEmailPasswordRecipeImplementation(recipeImplementation tpepmodels.Recip
 			return nil, nil
 		},
 
-		CreateResetPasswordToken: func(userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
-			return recipeImplementation.CreateResetPasswordToken(userID)
+		CreateResetPasswordToken: func(ctx context.Context, userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
+			return recipeImplementation.CreateResetPasswordToken(ctx, userID)
+		},
+		ResetPasswordUsingToken: func(ctx context.Context, token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
+			return recipeImplementation.ResetPasswordUsingToken(ctx, token, newPassword)
 		},
-		ResetPasswordUsingToken: func(token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
-			return recipeImplementation.ResetPasswordUsingToken(token, newPassword)
+		UpdateEmailOrPassword: func(ctx context.Context, userId string, email, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
+			return recipeImplementation.UpdateEmailOrPassword(ctx, userId, email, password)
 		},
-		UpdateEmailOrPassword: func(userId string, email, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
-			return recipeImplementation.UpdateEmailOrPassword(userId, email, password)
+		ImportUserWithPasswordHash: func(ctx context.Context, email, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+			return recipeImplementation.ImportUserWithPasswordHash(ctx, email, passwordHash)
 		},
 	}
 }
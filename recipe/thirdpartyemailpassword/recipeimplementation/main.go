@@ -16,6 +16,7 @@
 package recipeimplementation
 
 import (
+	"context"
 	"errors"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
@@ -26,16 +27,16 @@ import (
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPartyQuerier *supertokens.Querier) tpepmodels.RecipeInterface {
-	emailPasswordImplementation := emailpassword.MakeRecipeImplementation(emailPasswordQuerier)
+func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPartyQuerier *supertokens.Querier, passwordHashing epmodels.TypeNormalisedInputPasswordHashing, passwordPolicy *epmodels.PasswordPolicy, accountLinkingConfig *tpepmodels.TypeInputAccountLinking, getRecipeImpl func() tpepmodels.RecipeInterface, isEmailVerified func(ctx context.Context, userID string, email string) (bool, error)) tpepmodels.RecipeInterface {
+	emailPasswordImplementation := emailpassword.MakeRecipeImplementation(emailPasswordQuerier, passwordHashing, passwordPolicy)
 	var thirdPartyImplementation *tpmodels.RecipeInterface
 	if thirdPartyQuerier != nil {
 		thirdPartyImplementationTemp := thirdparty.MakeRecipeImplementation(*thirdPartyQuerier)
 		thirdPartyImplementation = &thirdPartyImplementationTemp
 	}
-	return tpepmodels.RecipeInterface{
-		SignUp: func(email, password string) (tpepmodels.SignUpResponse, error) {
-			response, err := emailPasswordImplementation.SignUp(email, password)
+	recipeImplementation := tpepmodels.RecipeInterface{
+		SignUp: func(ctx context.Context, email, password string) (tpepmodels.SignUpResponse, error) {
+			response, err := emailPasswordImplementation.SignUp(ctx, email, password)
 			if err != nil {
 				return tpepmodels.SignUpResponse{}, err
 			}
@@ -45,7 +46,10 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 				}, nil
 			}
 			return tpepmodels.SignUpResponse{
-				OK: &struct{ User tpepmodels.User }{
+				OK: &struct {
+					User          tpepmodels.User
+					PrimaryUserID string
+				}{
 					User: tpepmodels.User{
 						ID:         response.OK.User.ID,
 						Email:      response.OK.User.Email,
@@ -56,8 +60,8 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 			}, nil
 		},
 
-		SignIn: func(email, password string) (tpepmodels.SignInResponse, error) {
-			response, err := emailPasswordImplementation.SignIn(email, password)
+		SignIn: func(ctx context.Context, email, password string) (tpepmodels.SignInResponse, error) {
+			response, err := emailPasswordImplementation.SignIn(ctx, email, password)
 			if err != nil {
 				return tpepmodels.SignInResponse{}, err
 			}
@@ -67,7 +71,10 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 				}, nil
 			}
 			return tpepmodels.SignInResponse{
-				OK: &struct{ User tpepmodels.User }{
+				OK: &struct {
+					User          tpepmodels.User
+					PrimaryUserID string
+				}{
 					User: tpepmodels.User{
 						ID:         response.OK.User.ID,
 						Email:      response.OK.User.Email,
@@ -78,11 +85,11 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 			}, nil
 		},
 
-		SignInUp: func(thirdPartyID, thirdPartyUserID string, email tpepmodels.EmailStruct) (tpepmodels.SignInUpResponse, error) {
+		SignInUp: func(ctx context.Context, thirdPartyID, thirdPartyUserID string, email tpepmodels.EmailStruct) (tpepmodels.SignInUpResponse, error) {
 			if thirdPartyImplementation == nil {
 				return tpepmodels.SignInUpResponse{}, errors.New("no thirdparty provider configured")
 			}
-			result, err := (*thirdPartyImplementation).SignInUp(thirdPartyID, thirdPartyUserID, tpmodels.EmailStruct{
+			result, err := (*thirdPartyImplementation).SignInUp(ctx, thirdPartyID, thirdPartyUserID, tpmodels.EmailStruct{
 				ID:         email.ID,
 				IsVerified: email.IsVerified,
 			})
@@ -100,6 +107,7 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 				OK: &struct {
 					CreatedNewUser bool
 					User           tpepmodels.User
+					PrimaryUserID  string
 				}{
 					CreatedNewUser: result.OK.CreatedNewUser,
 					User: tpepmodels.User{
@@ -112,8 +120,8 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 			}, nil
 		},
 
-		GetUserByID: func(userID string) (*tpepmodels.User, error) {
-			user, err := emailPasswordImplementation.GetUserByID(userID)
+		GetUserByID: func(ctx context.Context, userID string) (*tpepmodels.User, error) {
+			user, err := emailPasswordImplementation.GetUserByID(ctx, userID)
 			if err != nil {
 				return nil, err
 			}
@@ -129,7 +137,7 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 				return nil, nil
 			}
 
-			userinfo, err := thirdPartyImplementation.GetUserByID(userID)
+			userinfo, err := thirdPartyImplementation.GetUserByID(ctx, userID)
 			if err != nil {
 				return nil, err
 			}
@@ -145,15 +153,15 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 			return nil, nil
 		},
 
-		GetUsersByEmail: func(email string) ([]tpepmodels.User, error) {
-			fromEP, err := emailPasswordImplementation.GetUserByEmail(email)
+		GetUsersByEmail: func(ctx context.Context, email string) ([]tpepmodels.User, error) {
+			fromEP, err := emailPasswordImplementation.GetUserByEmail(ctx, email)
 			if err != nil {
 				return []tpepmodels.User{}, err
 			}
 
 			fromTP := []tpmodels.User{}
 			if thirdPartyImplementation != nil {
-				fromTP, err = (*thirdPartyImplementation).GetUsersByEmail(email)
+				fromTP, err = (*thirdPartyImplementation).GetUsersByEmail(ctx, email)
 				if err != nil {
 					return []tpepmodels.User{}, err
 				}
@@ -181,12 +189,12 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 			return finalResult, nil
 		},
 
-		GetUserByThirdPartyInfo: func(thirdPartyID string, thirdPartyUserID string) (*tpepmodels.User, error) {
+		GetUserByThirdPartyInfo: func(ctx context.Context, thirdPartyID string, thirdPartyUserID string) (*tpepmodels.User, error) {
 			if thirdPartyImplementation == nil {
 				return nil, nil
 			}
 
-			userinfo, err := thirdPartyImplementation.GetUserByThirdPartyInfo(thirdPartyID, thirdPartyUserID)
+			userinfo, err := thirdPartyImplementation.GetUserByThirdPartyInfo(ctx, thirdPartyID, thirdPartyUserID)
 			if err != nil {
 				return nil, err
 			}
@@ -202,14 +210,23 @@ func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPar
 			return nil, nil
 		},
 
-		CreateResetPasswordToken: func(userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
-			return emailPasswordImplementation.CreateResetPasswordToken(userID)
+		CreateResetPasswordToken: func(ctx context.Context, userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
+			return emailPasswordImplementation.CreateResetPasswordToken(ctx, userID)
+		},
+		ResetPasswordUsingToken: func(ctx context.Context, token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
+			return emailPasswordImplementation.ResetPasswordUsingToken(ctx, token, newPassword)
 		},
-		ResetPasswordUsingToken: func(token, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error) {
-			return emailPasswordImplementation.ResetPasswordUsingToken(token, newPassword)
+		UpdateEmailOrPassword: func(ctx context.Context, userId string, email, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
+			return emailPasswordImplementation.UpdateEmailOrPassword(ctx, userId, email, password)
 		},
-		UpdateEmailOrPassword: func(userId string, email, password *string) (epmodels.UpdateEmailOrPasswordResponse, error) {
-			return emailPasswordImplementation.UpdateEmailOrPassword(userId, email, password)
+		ImportUserWithPasswordHash: func(ctx context.Context, email, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error) {
+			return emailPasswordImplementation.ImportUserWithPasswordHash(ctx, email, passwordHash)
 		},
 	}
+
+	return withAccountLinking(recipeImplementation, accountLinking{
+		config:          accountLinkingConfig,
+		getRecipeImpl:   getRecipeImpl,
+		isEmailVerified: isEmailVerified,
+	})
 }
@@ -16,6 +16,8 @@
 package recipeimplementation
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
 )
@@ -23,8 +25,8 @@ import (
 func MakeThirdPartyRecipeImplementation(recipeImplementation tpepmodels.RecipeInterface) tpmodels.RecipeInterface {
 	return tpmodels.RecipeInterface{
 
-		GetUserByThirdPartyInfo: func(thirdPartyID string, thirdPartyUserID string) (*tpmodels.User, error) {
-			user, err := recipeImplementation.GetUserByThirdPartyInfo(thirdPartyID, thirdPartyUserID)
+		GetUserByThirdPartyInfo: func(ctx context.Context, thirdPartyID string, thirdPartyUserID string) (*tpmodels.User, error) {
+			user, err := recipeImplementation.GetUserByThirdPartyInfo(ctx, thirdPartyID, thirdPartyUserID)
 			if err != nil {
 				return nil, err
 			}
@@ -39,8 +41,8 @@ func MakeThirdPartyRecipeImplementation(recipeImplementation tpepmodels.RecipeIn
 			}, nil
 		},
 
-		SignInUp: func(thirdPartyID string, thirdPartyUserID string, email tpmodels.EmailStruct) (tpmodels.SignInUpResponse, error) {
-			result, err := recipeImplementation.SignInUp(thirdPartyID, thirdPartyUserID, tpepmodels.EmailStruct{
+		SignInUp: func(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email tpmodels.EmailStruct) (tpmodels.SignInUpResponse, error) {
+			result, err := recipeImplementation.SignInUp(ctx, thirdPartyID, thirdPartyUserID, tpepmodels.EmailStruct{
 				ID:         email.ID,
 				IsVerified: email.IsVerified,
 			})
@@ -59,6 +61,7 @@ func MakeThirdPartyRecipeImplementation(recipeImplementation tpepmodels.RecipeIn
 				OK: &struct {
 					CreatedNewUser bool
 					User           tpmodels.User
+					PrimaryUserID  string
 				}{
 					CreatedNewUser: result.OK.CreatedNewUser,
 					User: tpmodels.User{
@@ -67,12 +70,13 @@ func MakeThirdPartyRecipeImplementation(recipeImplementation tpepmodels.RecipeIn
 						TimeJoined: result.OK.User.TimeJoined,
 						ThirdParty: *result.OK.User.ThirdParty,
 					},
+					PrimaryUserID: result.OK.PrimaryUserID,
 				},
 			}, nil
 		},
 
-		GetUserByID: func(userID string) (*tpmodels.User, error) {
-			user, err := recipeImplementation.GetUserByID(userID)
+		GetUserByID: func(ctx context.Context, userID string) (*tpmodels.User, error) {
+			user, err := recipeImplementation.GetUserByID(ctx, userID)
 			if err != nil {
 				return nil, err
 			}
@@ -16,16 +16,19 @@
 package tpepmodels
 
 import (
+	"context"
+
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 )
 
 type APIInterface struct {
-	AuthorisationUrlGET            func(provider tpmodels.TypeProvider, options tpmodels.APIOptions) (tpmodels.AuthorisationUrlGETResponse, error)
-	EmailExistsGET                 func(email string, options epmodels.APIOptions) (epmodels.EmailExistsGETResponse, error)
-	GeneratePasswordResetTokenPOST func(formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.GeneratePasswordResetTokenPOSTResponse, error)
-	PasswordResetPOST              func(formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions) (epmodels.ResetPasswordUsingTokenResponse, error)
-	SignInUpPOST                   func(input SignInUpAPIInput) (SignInUpAPIOutput, error)
+	AuthorisationUrlGET            func(ctx context.Context, provider tpmodels.TypeProvider, options tpmodels.APIOptions) (tpmodels.AuthorisationUrlGETResponse, error)
+	EmailExistsGET                 func(ctx context.Context, email string, options epmodels.APIOptions) (epmodels.EmailExistsGETResponse, error)
+	GeneratePasswordResetTokenPOST func(ctx context.Context, formFields []epmodels.TypeFormField, options epmodels.APIOptions) (epmodels.GeneratePasswordResetTokenPOSTResponse, error)
+	PasswordResetPOST              func(ctx context.Context, formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions) (epmodels.ResetPasswordUsingTokenResponse, error)
+	SignInUpPOST                   func(ctx context.Context, input SignInUpAPIInput) (SignInUpAPIOutput, error)
+	AppleRedirectHandlerPOST       func(ctx context.Context, code string, state string, options tpmodels.APIOptions) error
 }
 
 type SignInUpAPIInput struct {
@@ -43,6 +46,7 @@ type ThirdPartyInput struct {
 	Provider    tpmodels.TypeProvider
 	Code        string
 	RedirectURI string
+	State       string
 	Options     tpmodels.APIOptions
 }
 
@@ -58,6 +62,8 @@ type EmailpasswordOutput struct {
 	}
 	EmailAlreadyExistsError *struct{}
 	WrongCredentialsError   *struct{}
+	// TooManyAttemptsError is only returned when signing in, if BruteForceProtection is enabled
+	TooManyAttemptsError *epmodels.TooManyAttemptsError
 }
 
 type ThirdPartyOutput struct {
@@ -67,5 +73,6 @@ type ThirdPartyOutput struct {
 		AuthCodeResponse interface{}
 	}
 	NoEmailGivenByProviderError *struct{}
+	InvalidStateError           *struct{}
 	FieldError                  *struct{ Error string }
 }
@@ -16,6 +16,9 @@
 package tpepmodels
 
 import (
+	"context"
+
+	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
@@ -44,16 +47,35 @@ type TypeInputEmailVerificationFeature struct {
 type TypeInput struct {
 	SignUpFeature                  *epmodels.TypeInputSignUp
 	Providers                      []tpmodels.TypeProvider
+	StateStore                     *tpmodels.StateStore
+	TokenStore                     *tpmodels.TokenStore
 	ResetPasswordUsingTokenFeature *epmodels.TypeInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       *TypeInputEmailVerificationFeature
-	Override                       *OverrideStruct
+	EmailDelivery                  *emaildelivery.EmailDeliveryInterface
+	EmailTemplates                 *emaildelivery.TemplatesInput
+	AccountLinking                 *TypeInputAccountLinking
+	// PasswordHashing is needed to import emailpassword users with ImportUserWithPasswordHash
+	PasswordHashing       *epmodels.TypeInputPasswordHashing
+	BruteForceProtection  *epmodels.TypeInputBruteForceProtection
+	PasswordPolicy        *epmodels.PasswordPolicy
+	BreachedPasswordCheck *epmodels.TypeInputBreachedPasswordCheck
+	Override              *OverrideStruct
 }
 
 type TypeNormalisedInput struct {
 	SignUpFeature                  *epmodels.TypeInputSignUp
 	Providers                      []tpmodels.TypeProvider
+	StateStore                     *tpmodels.StateStore
+	TokenStore                     *tpmodels.TokenStore
 	ResetPasswordUsingTokenFeature *epmodels.TypeInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       evmodels.TypeInput
+	EmailDelivery                  *emaildelivery.EmailDeliveryInterface
+	EmailTemplates                 *emaildelivery.TemplatesInput
+	AccountLinking                 *TypeInputAccountLinking
+	PasswordHashing                epmodels.TypeNormalisedInputPasswordHashing
+	BruteForceProtection           *epmodels.TypeInputBruteForceProtection
+	PasswordPolicy                 *epmodels.PasswordPolicy
+	BreachedPasswordCheck          *epmodels.TypeInputBreachedPasswordCheck
 	Override                       OverrideStruct
 }
 
@@ -63,6 +85,34 @@ type OverrideStruct struct {
 	EmailVerificationFeature *evmodels.OverrideStruct
 }
 
+// PrimaryUser is a user with all the login methods (emailpassword and third party users) linked to it.
+// Its ID is the ID of the user the others were linked to.
+type PrimaryUser struct {
+	ID string
+	// TimeJoined is when the oldest login method was created
+	TimeJoined   uint64
+	Emails       []string
+	LoginMethods []User
+}
+
+type TypeInputAccountLinking struct {
+	// Store is required, since the core does not keep which users are linked
+	Store AccountLinkingStore
+	// AutomaticallyLinkVerifiedEmails links a user that signs in to the existing user with the same email,
+	// if the emails of both are verified
+	AutomaticallyLinkVerifiedEmails bool
+}
+
+// AccountLinkingStore keeps which users are linked to which primary user
+type AccountLinkingStore struct {
+	// GetPrimaryUserID returns nil if the user is not linked to another user
+	GetPrimaryUserID func(ctx context.Context, userID string) (*string, error)
+	// GetLinkedUserIDs returns the users linked to a primary user, without the primary user itself
+	GetLinkedUserIDs func(ctx context.Context, primaryUserID string) ([]string, error)
+	Link             func(ctx context.Context, primaryUserID string, userID string) error
+	Unlink           func(ctx context.Context, userID string) error
+}
+
 type EmailStruct struct {
 	ID         string `json:"id"`
 	IsVerified bool   `json:"isVerified"`
@@ -15,24 +15,37 @@
 
 package tpepmodels
 
-import "github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+import (
+	"context"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
 
 type RecipeInterface struct {
-	GetUserByID              func(userID string) (*User, error)
-	GetUsersByEmail          func(email string) ([]User, error)
-	GetUserByThirdPartyInfo  func(thirdPartyID string, thirdPartyUserID string) (*User, error)
-	SignInUp                 func(thirdPartyID string, thirdPartyUserID string, email EmailStruct) (SignInUpResponse, error)
-	SignUp                   func(email string, password string) (SignUpResponse, error)
-	SignIn                   func(email string, password string) (SignInResponse, error)
-	CreateResetPasswordToken func(userID string) (epmodels.CreateResetPasswordTokenResponse, error)
-	ResetPasswordUsingToken  func(token string, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error)
-	UpdateEmailOrPassword    func(userId string, email *string, password *string) (epmodels.UpdateEmailOrPasswordResponse, error)
+	GetUserByID              func(ctx context.Context, userID string) (*User, error)
+	GetUsersByEmail          func(ctx context.Context, email string) ([]User, error)
+	GetUserByThirdPartyInfo  func(ctx context.Context, thirdPartyID string, thirdPartyUserID string) (*User, error)
+	SignInUp                 func(ctx context.Context, thirdPartyID string, thirdPartyUserID string, email EmailStruct) (SignInUpResponse, error)
+	SignUp                   func(ctx context.Context, email string, password string) (SignUpResponse, error)
+	SignIn                   func(ctx context.Context, email string, password string) (SignInResponse, error)
+	CreateResetPasswordToken func(ctx context.Context, userID string) (epmodels.CreateResetPasswordTokenResponse, error)
+	ResetPasswordUsingToken  func(ctx context.Context, token string, newPassword string) (epmodels.ResetPasswordUsingTokenResponse, error)
+	UpdateEmailOrPassword    func(ctx context.Context, userId string, email *string, password *string) (epmodels.UpdateEmailOrPasswordResponse, error)
+	// ImportUserWithPasswordHash creates an emailpassword user that signs in with the password of a hash
+	// from another system
+	ImportUserWithPasswordHash func(ctx context.Context, email string, passwordHash string) (epmodels.ImportUserWithPasswordHashResponse, error)
+	GetPrimaryUser             func(ctx context.Context, userID string) (*PrimaryUser, error)
+	LinkAccounts               func(ctx context.Context, primaryUserID string, userID string) (LinkAccountsResponse, error)
+	UnlinkAccount              func(ctx context.Context, userID string) (UnlinkAccountResponse, error)
 }
 
 type SignInUpResponse struct {
 	OK *struct {
 		CreatedNewUser bool
 		User           User
+		// PrimaryUserID is set if the user is linked to another user with AccountLinking. Sessions are
+		// created for the primary user.
+		PrimaryUserID string
 	}
 	FieldError *struct{ Error string }
 }
@@ -40,6 +53,9 @@ type SignInUpResponse struct {
 type SignUpResponse struct {
 	OK *struct {
 		User User
+		// PrimaryUserID is set if the user is linked to another user with AccountLinking. Sessions are
+		// created for the primary user.
+		PrimaryUserID string
 	}
 	EmailAlreadyExistsError *struct{}
 }
@@ -47,6 +63,23 @@ type SignUpResponse struct {
 type SignInResponse struct {
 	OK *struct {
 		User User
+		// PrimaryUserID is set if the user is linked to another user with AccountLinking. Sessions are
+		// created for the primary user.
+		PrimaryUserID string
 	}
 	WrongCredentialsError *struct{}
 }
+
+type LinkAccountsResponse struct {
+	OK                 *struct{}
+	UnknownUserIDError *struct{}
+	// AccountAlreadyLinkedError is returned if the user is linked to another primary user, or if other
+	// users are linked to it
+	AccountAlreadyLinkedError *struct{ PrimaryUserID string }
+}
+
+type UnlinkAccountResponse struct {
+	OK *struct{ WasLinked bool }
+	// PrimaryUserHasLinkedAccountsError is returned for a primary user that other users are still linked to
+	PrimaryUserHasLinkedAccountsError *struct{}
+}
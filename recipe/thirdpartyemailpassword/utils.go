@@ -16,8 +16,11 @@
 package thirdpartyemailpassword
 
 import (
+	"context"
 	"errors"
 
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/passwordpolicy"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
@@ -34,12 +37,60 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 		typeNormalisedInput.Providers = config.Providers
 	}
 
+	if config != nil && config.StateStore != nil {
+		typeNormalisedInput.StateStore = config.StateStore
+	}
+
+	if config != nil && config.TokenStore != nil {
+		typeNormalisedInput.TokenStore = config.TokenStore
+	}
+
 	typeNormalisedInput.EmailVerificationFeature = validateAndNormaliseEmailVerificationConfig(recipeInstance, config)
 
 	if config != nil && config.ResetPasswordUsingTokenFeature != nil {
 		typeNormalisedInput.ResetPasswordUsingTokenFeature = config.ResetPasswordUsingTokenFeature
 	}
 
+	if config != nil && config.EmailDelivery != nil {
+		typeNormalisedInput.EmailDelivery = config.EmailDelivery
+	}
+
+	if config != nil && config.EmailTemplates != nil {
+		typeNormalisedInput.EmailTemplates = config.EmailTemplates
+	}
+
+	if config != nil && config.AccountLinking != nil {
+		store := config.AccountLinking.Store
+		if store.GetPrimaryUserID == nil || store.GetLinkedUserIDs == nil || store.Link == nil || store.Unlink == nil {
+			return tpepmodels.TypeNormalisedInput{}, supertokens.BadInputError{Msg: "account linking requires all the functions of the Store to be set"}
+		}
+		typeNormalisedInput.AccountLinking = config.AccountLinking
+	}
+
+	if config != nil {
+		passwordHashing, err := emailpassword.NormalisePasswordHashing(config.PasswordHashing)
+		if err != nil {
+			return tpepmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.PasswordHashing = passwordHashing
+	}
+
+	if config != nil && config.PasswordPolicy != nil {
+		passwordPolicy, err := passwordpolicy.Normalise(*config.PasswordPolicy)
+		if err != nil {
+			return tpepmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.PasswordPolicy = &passwordPolicy
+	}
+
+	if config != nil && config.BruteForceProtection != nil {
+		typeNormalisedInput.BruteForceProtection = config.BruteForceProtection
+	}
+
+	if config != nil && config.BreachedPasswordCheck != nil {
+		typeNormalisedInput.BreachedPasswordCheck = config.BreachedPasswordCheck
+	}
+
 	if config != nil && config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
@@ -83,10 +134,12 @@ func validateAndNormaliseEmailVerificationConfig(recipeInstance *Recipe, config
 		if config.Override != nil {
 			emailverificationTypeInput.Override = config.Override.EmailVerificationFeature
 		}
+		emailverificationTypeInput.EmailDelivery = config.EmailDelivery
+		emailverificationTypeInput.EmailTemplates = config.EmailTemplates
 		if config.EmailVerificationFeature != nil {
 			if config.EmailVerificationFeature.CreateAndSendCustomEmail != nil {
 				emailverificationTypeInput.CreateAndSendCustomEmail = func(user evmodels.User, link string) {
-					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(user.ID)
+					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(context.Background(), user.ID)
 					if err != nil {
 						return
 					}
@@ -99,7 +152,7 @@ func validateAndNormaliseEmailVerificationConfig(recipeInstance *Recipe, config
 
 			if config.EmailVerificationFeature.GetEmailVerificationURL != nil {
 				emailverificationTypeInput.GetEmailVerificationURL = func(user evmodels.User) (string, error) {
-					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(user.ID)
+					userInfo, err := recipeInstance.RecipeImpl.GetUserByID(context.Background(), user.ID)
 					if err != nil {
 						return "", err
 					}
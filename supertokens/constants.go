@@ -24,5 +24,7 @@ const (
 const VERSION = "0.0.3"
 
 var (
-	cdiSupported = []string{"2.8", "2.9"}
+	// CDI 2.10 and 2.11 only add APIs (the passwordless ones, which check for 2.11), so the requests
+	// of the other recipes are the same with every supported version
+	cdiSupported = []string{"2.8", "2.9", "2.10", "2.11"}
 )
@@ -0,0 +1,300 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package coretest provides an in-memory fake of the SuperTokens core that can be used to test
+// applications (and this SDK) without running the real core.
+package coretest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// Config changes the behaviour of the fake core. Zero values mean that the defaults are used.
+type Config struct {
+	// APIKey, if set, needs to be sent by the SDK in the api-key header
+	APIKey string
+	// AccessTokenValidity defaults to an hour
+	AccessTokenValidity time.Duration
+	// RefreshTokenValidity defaults to 100 days
+	RefreshTokenValidity time.Duration
+	// AccessTokenBlacklisting makes the SDK verify every access token with the core
+	AccessTokenBlacklisting bool
+	// PasswordlessCodeLifetime defaults to 15 minutes
+	PasswordlessCodeLifetime time.Duration
+	// PasswordlessMaxCodeInputAttempts defaults to 5
+	PasswordlessMaxCodeInputAttempts int
+}
+
+// Core is a fake SuperTokens core served by an httptest.Server. All the state is kept in memory
+// and is lost when the core is closed.
+type Core struct {
+	Server *httptest.Server
+
+	config         Config
+	lock           sync.Mutex
+	signingKey     *rsa.PrivateKey
+	signingKeyTime uint64
+	jwtKey         *rsa.PrivateKey
+	jwtKeyID       string
+	handlers       map[string]func(req *http.Request, body map[string]interface{}) (map[string]interface{}, int)
+	overrides      map[string]string
+
+	users                   []*user
+	sessions                map[string]*sessionState
+	emailVerificationTokens map[string]emailVerificationToken
+	verifiedEmails          map[string]bool
+	passwordResetTokens     map[string]string
+	passwordlessDevices     map[string]*passwordlessDevice
+}
+
+// New starts a fake core. Close needs to be called once the test is done with it.
+func New(config *Config) *Core {
+	c := &Core{}
+	if config != nil {
+		c.config = *config
+	}
+	if c.config.AccessTokenValidity == 0 {
+		c.config.AccessTokenValidity = time.Hour
+	}
+	if c.config.RefreshTokenValidity == 0 {
+		c.config.RefreshTokenValidity = 100 * 24 * time.Hour
+	}
+	if c.config.PasswordlessCodeLifetime == 0 {
+		c.config.PasswordlessCodeLifetime = 15 * time.Minute
+	}
+	if c.config.PasswordlessMaxCodeInputAttempts == 0 {
+		c.config.PasswordlessMaxCodeInputAttempts = 5
+	}
+
+	var err error
+	c.signingKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	c.jwtKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	c.signingKeyTime = currTimeInMS()
+	c.jwtKeyID = "s-" + newID()
+	c.Reset()
+
+	c.handlers = map[string]func(req *http.Request, body map[string]interface{}) (map[string]interface{}, int){
+		"GET /apiversion":                             c.apiVersion,
+		"GET /telemetry":                              c.telemetry,
+		"POST /recipe/handshake":                      c.handshake,
+		"POST /recipe/session":                        c.createSession,
+		"GET /recipe/session":                         c.getSessionInformation,
+		"POST /recipe/session/verify":                 c.verifySession,
+		"POST /recipe/session/refresh":                c.refreshSession,
+		"POST /recipe/session/regenerate":             c.regenerateSession,
+		"POST /recipe/session/remove":                 c.removeSessions,
+		"GET /recipe/session/user":                    c.getSessionHandlesForUser,
+		"PUT /recipe/session/data":                    c.updateSessionData,
+		"PUT /recipe/jwt/data":                        c.updateJWTData,
+		"POST /recipe/signup":                         c.signUp,
+		"POST /recipe/signin":                         c.signIn,
+		"POST /recipe/signinup":                       c.signInUp,
+		"POST /recipe/signinup/code":                  c.createPasswordlessCode,
+		"POST /recipe/signinup/code/consume":          c.consumePasswordlessCode,
+		"POST /recipe/signinup/code/remove":           c.removePasswordlessCode,
+		"POST /recipe/signinup/codes/remove":          c.removePasswordlessCodes,
+		"GET /recipe/signinup/codes":                  c.listPasswordlessCodes,
+		"GET /recipe/user":                            c.getUser,
+		"PUT /recipe/user":                            c.updateUser,
+		"GET /recipe/users/by-email":                  c.getUsersByEmail,
+		"POST /recipe/user/password/reset/token":      c.createPasswordResetToken,
+		"POST /recipe/user/password/reset":            c.resetPassword,
+		"POST /recipe/user/email/verify/token":        c.createEmailVerificationToken,
+		"POST /recipe/user/email/verify":              c.verifyEmail,
+		"GET /recipe/user/email/verify":               c.isEmailVerified,
+		"POST /recipe/user/email/verify/token/remove": c.revokeEmailVerificationTokens,
+		"POST /recipe/user/email/verify/remove":       c.unverifyEmail,
+		"POST /recipe/jwt":                            c.createJWT,
+		"GET /recipe/jwt/jwks":                        c.getJWKS,
+		"GET /users":                                  c.getUsers,
+		"GET /users/count":                            c.getUserCount,
+	}
+
+	c.Server = httptest.NewServer(http.HandlerFunc(c.serveHTTP))
+	return c
+}
+
+// URL is the connection URI of the fake core
+func (c *Core) URL() string {
+	return c.Server.URL
+}
+
+// ConnectionInfo returns what needs to be passed as supertokens.TypeInput.Supertokens to use this core
+func (c *Core) ConnectionInfo() *supertokens.ConnectionInfo {
+	return &supertokens.ConnectionInfo{
+		ConnectionURI: c.Server.URL,
+		APIKey:        c.config.APIKey,
+	}
+}
+
+// OverrideResponse makes the core answer every request to path (like "GET /recipe/jwt/jwks") with
+// body, to test how the SDK handles unexpected responses
+func (c *Core) OverrideResponse(methodAndPath string, body string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.overrides == nil {
+		c.overrides = map[string]string{}
+	}
+	c.overrides[methodAndPath] = body
+}
+
+// Close shuts the server down
+func (c *Core) Close() {
+	c.Server.Close()
+}
+
+// Reset removes all users, sessions and tokens, but keeps the signing keys
+func (c *Core) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.users = nil
+	c.sessions = map[string]*sessionState{}
+	c.emailVerificationTokens = map[string]emailVerificationToken{}
+	c.verifiedEmails = map[string]bool{}
+	c.passwordResetTokens = map[string]string{}
+	c.passwordlessDevices = map[string]*passwordlessDevice{}
+}
+
+func (c *Core) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.config.APIKey != "" && r.Header.Get("api-key") != c.config.APIKey {
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	methodAndPath := r.Method + " " + strings.TrimSuffix(r.URL.Path, "/")
+	c.lock.Lock()
+	override, overridden := c.overrides[methodAndPath]
+	c.lock.Unlock()
+	if overridden {
+		w.Header().Set("content-type", "application/json; charset=utf-8")
+		w.Write([]byte(override))
+		return
+	}
+	handler, ok := c.handlers[methodAndPath]
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	body := map[string]interface{}{}
+	if r.Body != nil && r.Method != http.MethodGet {
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil && err.Error() != "EOF" {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	c.lock.Lock()
+	response, statusCode := handler(r, body)
+	c.lock.Unlock()
+
+	if statusCode != http.StatusOK {
+		http.Error(w, response["message"].(string), statusCode)
+		return
+	}
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *Core) apiVersion(_ *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	return map[string]interface{}{
+		"versions": []string{"2.8", "2.9", "2.10", "2.11"},
+	}, http.StatusOK
+}
+
+func (c *Core) telemetry(_ *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	return map[string]interface{}{
+		"status": "OK",
+		"exists": false,
+	}, http.StatusOK
+}
+
+func ok(fields map[string]interface{}) (map[string]interface{}, int) {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	fields["status"] = "OK"
+	return fields, http.StatusOK
+}
+
+func status(status string, message string) (map[string]interface{}, int) {
+	response := map[string]interface{}{
+		"status": status,
+	}
+	if message != "" {
+		response["message"] = message
+	}
+	return response, http.StatusOK
+}
+
+func badRequest(message string) (map[string]interface{}, int) {
+	return map[string]interface{}{
+		"message": message,
+	}, http.StatusBadRequest
+}
+
+func getString(body map[string]interface{}, key string) (string, bool) {
+	value, ok := body[key].(string)
+	return value, ok
+}
+
+func getMap(body map[string]interface{}, key string) map[string]interface{} {
+	value, ok := body[key].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return value
+}
+
+func getBool(body map[string]interface{}, key string) bool {
+	value, _ := body[key].(bool)
+	return value
+}
+
+func newID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bytes[0:4]) + "-" + hex.EncodeToString(bytes[4:6]) + "-" + hex.EncodeToString(bytes[6:8]) + "-" + hex.EncodeToString(bytes[8:10]) + "-" + hex.EncodeToString(bytes[10:])
+}
+
+func newToken() string {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bytes)
+}
+
+func currTimeInMS() uint64 {
+	return uint64(time.Now().UnixNano() / int64(time.Millisecond))
+}
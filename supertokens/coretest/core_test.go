@@ -0,0 +1,92 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package coretest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestSignUpAndSessionAgainstFakeCore(t *testing.T) {
+	_, instance, cleanup := NewInstance(t, emailpassword.Init(nil), session.Init(nil))
+	defer cleanup()
+	ctx := supertokens.WithInstance(context.Background(), instance)
+
+	signUpResponse, err := emailpassword.SignUpWithContext(ctx, "test@example.com", "password123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.OK)
+
+	signUpResponse, err = emailpassword.SignUpWithContext(ctx, "test@example.com", "password123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.EmailAlreadyExistsError)
+
+	signInResponse, err := emailpassword.SignInWithContext(ctx, "test@example.com", "wrong")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.WrongCredentialsError)
+
+	signInResponse, err = emailpassword.SignInWithContext(ctx, "test@example.com", "password123")
+	assert.NoError(t, err)
+	userID := signInResponse.OK.User.ID
+
+	res := httptest.NewRecorder()
+	created, err := session.CreateNewSessionWithContext(ctx, nil, res, userID, map[string]interface{}{"role": "admin"}, nil)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range res.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	verified, err := session.GetSessionWithContext(ctx, req, httptest.NewRecorder(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, verified.GetUserID())
+	assert.Equal(t, "admin", verified.GetJWTPayload()["role"])
+
+	info, err := session.GetSessionInformationWithContext(ctx, created.GetHandle())
+	assert.NoError(t, err)
+	assert.Equal(t, userID, info.UserId)
+
+	revoked, err := session.RevokeSessionWithContext(ctx, created.GetHandle())
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	count, err := instance.GetUserCount(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), count)
+}
+
+func TestAPIKeyIsRequired(t *testing.T) {
+	core := New(&Config{APIKey: "secret"})
+	defer core.Close()
+
+	resp, err := http.Get(core.URL() + "/apiversion")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodGet, core.URL()+"/apiversion", nil)
+	req.Header.Set("api-key", "secret")
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,81 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package coretest
+
+import (
+	"net/http"
+)
+
+type emailVerificationToken struct {
+	userID string
+	email  string
+}
+
+func (c *Core) createEmailVerificationToken(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	userID, _ := getString(body, "userId")
+	email, _ := getString(body, "email")
+	if c.verifiedEmails[userID+"|"+email] {
+		return status("EMAIL_ALREADY_VERIFIED_ERROR", "")
+	}
+	token := newToken()
+	c.emailVerificationTokens[token] = emailVerificationToken{userID: userID, email: email}
+	return ok(map[string]interface{}{
+		"token": token,
+	})
+}
+
+func (c *Core) verifyEmail(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	token, _ := getString(body, "token")
+	info, found := c.emailVerificationTokens[token]
+	if !found {
+		return status("EMAIL_VERIFICATION_INVALID_TOKEN_ERROR", "")
+	}
+	c.removeEmailVerificationTokens(info.userID, info.email)
+	c.verifiedEmails[info.userID+"|"+info.email] = true
+	return ok(map[string]interface{}{
+		"userId": info.userID,
+		"email":  info.email,
+	})
+}
+
+func (c *Core) isEmailVerified(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	query := req.URL.Query()
+	return ok(map[string]interface{}{
+		"isVerified": c.verifiedEmails[query.Get("userId")+"|"+query.Get("email")],
+	})
+}
+
+func (c *Core) revokeEmailVerificationTokens(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	userID, _ := getString(body, "userId")
+	email, _ := getString(body, "email")
+	c.removeEmailVerificationTokens(userID, email)
+	return ok(nil)
+}
+
+func (c *Core) unverifyEmail(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	userID, _ := getString(body, "userId")
+	email, _ := getString(body, "email")
+	delete(c.verifiedEmails, userID+"|"+email)
+	return ok(nil)
+}
+
+func (c *Core) removeEmailVerificationTokens(userID string, email string) {
+	for token, info := range c.emailVerificationTokens {
+		if info.userID == userID && info.email == email {
+			delete(c.emailVerificationTokens, token)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package coretest
+
+import (
+	"testing"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	// TestAPIDomain and TestWebsiteDomain are the domains of the app of the instances created by NewInstance
+	TestAPIDomain     = "http://localhost:3001"
+	TestWebsiteDomain = "http://localhost:3000"
+	TestAppName       = "coretest"
+)
+
+// NewInstance starts a fake core and creates a SuperTokens instance with the given recipes that uses
+// it. The cleanup function closes the core.
+func NewInstance(t testing.TB, recipes ...supertokens.Recipe) (*Core, *supertokens.SuperTokens, func()) {
+	core := New(nil)
+	return core, core.NewInstance(t, recipes...), core.Close
+}
+
+// NewInstance creates a SuperTokens instance with the given recipes that uses this core. Several
+// instances can share a core.
+func (c *Core) NewInstance(t testing.TB, recipes ...supertokens.Recipe) *supertokens.SuperTokens {
+	t.Helper()
+	falseValue := false
+	instance, err := supertokens.New(supertokens.TypeInput{
+		AppInfo: supertokens.AppInfo{
+			AppName:       TestAppName,
+			APIDomain:     TestAPIDomain,
+			WebsiteDomain: TestWebsiteDomain,
+		},
+		Supertokens: c.ConnectionInfo(),
+		RecipeList:  recipes,
+		Telemetry:   &falseValue,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return instance
+}
@@ -0,0 +1,83 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package coretest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	b64 "encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+func (c *Core) createJWT(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	if algorithm, _ := getString(body, "algorithm"); algorithm != "RS256" {
+		return status("UNSUPPORTED_ALGORITHM_ERROR", "")
+	}
+	validity := int64(0)
+	if number, found := body["validity"].(json.Number); found {
+		validity, _ = number.Int64()
+	}
+	jwksDomain, _ := getString(body, "jwksDomain")
+
+	claims := map[string]interface{}{}
+	for key, value := range getMap(body, "payload") {
+		claims[key] = value
+	}
+	now := time.Now().Unix()
+	claims["iat"] = now
+	claims["exp"] = now + validity
+	claims["iss"] = jwksDomain
+
+	headerJSON, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": c.jwtKeyID,
+	})
+	if err != nil {
+		panic(err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		panic(err)
+	}
+	signingInput := b64.RawURLEncoding.EncodeToString(headerJSON) + "." + b64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.jwtKey, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return ok(map[string]interface{}{
+		"jwt": signingInput + "." + b64.RawURLEncoding.EncodeToString(signature),
+	})
+}
+
+func (c *Core) getJWKS(_ *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	return ok(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": c.jwtKeyID,
+			"n":   b64.RawURLEncoding.EncodeToString(c.jwtKey.PublicKey.N.Bytes()),
+			"e":   b64.RawURLEncoding.EncodeToString(big.NewInt(int64(c.jwtKey.PublicKey.E)).Bytes()),
+			"alg": "RS256",
+			"use": "sig",
+		}},
+	})
+}
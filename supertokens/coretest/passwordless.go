@@ -0,0 +1,279 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package coretest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+type passwordlessCode struct {
+	codeID        string
+	linkCode      string
+	userInputCode string
+	timeCreated   uint64
+	codeLifetime  uint64
+}
+
+// passwordlessDevice is a login attempt of an email or phone number, which can have several codes
+type passwordlessDevice struct {
+	deviceID         string
+	preAuthSessionID string
+	email            string
+	phoneNumber      string
+	failedAttempts   int
+	codes            []*passwordlessCode
+}
+
+func (d *passwordlessDevice) toJSON() map[string]interface{} {
+	codes := []map[string]interface{}{}
+	for _, code := range d.codes {
+		codes = append(codes, map[string]interface{}{
+			"codeId":       code.codeID,
+			"timeCreated":  code.timeCreated,
+			"codeLifetime": code.codeLifetime,
+		})
+	}
+	device := map[string]interface{}{
+		"preAuthSessionId":            d.preAuthSessionID,
+		"failedCodeInputAttemptCount": d.failedAttempts,
+		"codes":                       codes,
+	}
+	if d.email != "" {
+		device["email"] = d.email
+	}
+	if d.phoneNumber != "" {
+		device["phoneNumber"] = d.phoneNumber
+	}
+	return device
+}
+
+func (c *Core) createPasswordlessCode(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	var device *passwordlessDevice
+	if deviceID, found := getString(body, "deviceId"); found {
+		device = c.passwordlessDevices[deviceID]
+		if device == nil {
+			return status("RESTART_FLOW_ERROR", "")
+		}
+	} else {
+		email, _ := getString(body, "email")
+		phoneNumber, _ := getString(body, "phoneNumber")
+		if (email == "") == (phoneNumber == "") {
+			return badRequest("Please provide exactly one of email or phoneNumber")
+		}
+		deviceID := newBase64Token()
+		preAuthSessionID := sha256.Sum256([]byte(deviceID))
+		device = &passwordlessDevice{
+			deviceID:         deviceID,
+			preAuthSessionID: base64.RawURLEncoding.EncodeToString(preAuthSessionID[:]),
+			email:            email,
+			phoneNumber:      phoneNumber,
+		}
+		c.passwordlessDevices[deviceID] = device
+	}
+
+	userInputCode, found := getString(body, "userInputCode")
+	if !found {
+		userInputCode = newUserInputCode()
+	}
+	for _, code := range device.codes {
+		if code.userInputCode == userInputCode {
+			return status("USER_INPUT_CODE_ALREADY_USED_ERROR", "")
+		}
+	}
+	code := &passwordlessCode{
+		codeID:        newID(),
+		linkCode:      newBase64Token(),
+		userInputCode: userInputCode,
+		timeCreated:   currTimeInMS(),
+		codeLifetime:  uint64(c.config.PasswordlessCodeLifetime / time.Millisecond),
+	}
+	device.codes = append(device.codes, code)
+	return ok(map[string]interface{}{
+		"preAuthSessionId": device.preAuthSessionID,
+		"codeId":           code.codeID,
+		"deviceId":         device.deviceID,
+		"userInputCode":    code.userInputCode,
+		"linkCode":         code.linkCode,
+		"timeCreated":      code.timeCreated,
+		"codeLifetime":     code.codeLifetime,
+	})
+}
+
+func (c *Core) consumePasswordlessCode(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	preAuthSessionID, _ := getString(body, "preAuthSessionId")
+	var (
+		device *passwordlessDevice
+		code   *passwordlessCode
+	)
+	if linkCode, found := getString(body, "linkCode"); found {
+		device = c.findPasswordlessDevice(func(d *passwordlessDevice) bool { return d.preAuthSessionID == preAuthSessionID })
+		if device != nil {
+			for _, deviceCode := range device.codes {
+				if deviceCode.linkCode == linkCode {
+					code = deviceCode
+				}
+			}
+		}
+		if code == nil || currTimeInMS() > code.timeCreated+code.codeLifetime {
+			return status("RESTART_FLOW_ERROR", "")
+		}
+	} else {
+		deviceID, _ := getString(body, "deviceId")
+		userInputCode, _ := getString(body, "userInputCode")
+		device = c.passwordlessDevices[deviceID]
+		if device == nil || device.preAuthSessionID != preAuthSessionID {
+			return status("RESTART_FLOW_ERROR", "")
+		}
+		for _, deviceCode := range device.codes {
+			if deviceCode.userInputCode == userInputCode {
+				code = deviceCode
+			}
+		}
+		if code == nil || currTimeInMS() > code.timeCreated+code.codeLifetime {
+			device.failedAttempts++
+			if device.failedAttempts >= c.config.PasswordlessMaxCodeInputAttempts {
+				delete(c.passwordlessDevices, device.deviceID)
+				return status("RESTART_FLOW_ERROR", "")
+			}
+			errorStatus := "INCORRECT_USER_INPUT_CODE_ERROR"
+			if code != nil {
+				errorStatus = "EXPIRED_USER_INPUT_CODE_ERROR"
+			}
+			response, statusCode := status(errorStatus, "")
+			response["failedCodeInputAttemptCount"] = device.failedAttempts
+			response["maximumCodeInputAttempts"] = c.config.PasswordlessMaxCodeInputAttempts
+			return response, statusCode
+		}
+	}
+
+	// all login attempts of the email or phone number end once one of them succeeds
+	c.removePasswordlessDevices(device.email, device.phoneNumber)
+	u := c.findUser(passwordlessRecipeID, func(u *user) bool {
+		return (device.email != "" && u.Email == device.email) || (device.phoneNumber != "" && u.PhoneNumber == device.phoneNumber)
+	})
+	createdNewUser := u == nil
+	if createdNewUser {
+		u = &user{Email: device.email, PhoneNumber: device.phoneNumber, recipeID: passwordlessRecipeID}
+		c.addUser(u)
+	}
+	return ok(map[string]interface{}{
+		"createdNewUser": createdNewUser,
+		"user":           u,
+	})
+}
+
+func (c *Core) removePasswordlessCode(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	codeID, _ := getString(body, "codeId")
+	for _, device := range c.passwordlessDevices {
+		for i, code := range device.codes {
+			if code.codeID == codeID {
+				device.codes = append(device.codes[:i], device.codes[i+1:]...)
+				if len(device.codes) == 0 {
+					delete(c.passwordlessDevices, device.deviceID)
+				}
+				return ok(nil)
+			}
+		}
+	}
+	return ok(nil)
+}
+
+func (c *Core) removePasswordlessCodes(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	email, _ := getString(body, "email")
+	phoneNumber, _ := getString(body, "phoneNumber")
+	c.removePasswordlessDevices(email, phoneNumber)
+	return ok(nil)
+}
+
+// listPasswordlessCodes returns the devices with the deviceId, preAuthSessionId, email or phoneNumber
+// of the query
+func (c *Core) listPasswordlessCodes(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	query := req.URL.Query()
+	devices := []map[string]interface{}{}
+	for _, device := range c.passwordlessDevices {
+		if (query.Get("deviceId") != "" && device.deviceID == query.Get("deviceId")) ||
+			(query.Get("preAuthSessionId") != "" && device.preAuthSessionID == query.Get("preAuthSessionId")) ||
+			(query.Get("email") != "" && device.email == query.Get("email")) ||
+			(query.Get("phoneNumber") != "" && device.phoneNumber == query.Get("phoneNumber")) {
+			devices = append(devices, device.toJSON())
+		}
+	}
+	return ok(map[string]interface{}{
+		"devices": devices,
+	})
+}
+
+func (c *Core) updatePasswordlessUser(body map[string]interface{}) (map[string]interface{}, int) {
+	userID, _ := getString(body, "userId")
+	u := c.findUser(passwordlessRecipeID, func(u *user) bool { return u.ID == userID })
+	if u == nil {
+		return status("UNKNOWN_USER_ID_ERROR", "")
+	}
+	email, updateEmail := getString(body, "email")
+	if updateEmail && email != "" && c.findUser(passwordlessRecipeID, func(other *user) bool { return other != u && other.Email == email }) != nil {
+		return status("EMAIL_ALREADY_EXISTS_ERROR", "")
+	}
+	phoneNumber, updatePhoneNumber := getString(body, "phoneNumber")
+	if updatePhoneNumber && phoneNumber != "" && c.findUser(passwordlessRecipeID, func(other *user) bool { return other != u && other.PhoneNumber == phoneNumber }) != nil {
+		return status("PHONE_NUMBER_ALREADY_EXISTS_ERROR", "")
+	}
+	if updateEmail {
+		u.Email = email
+	}
+	if updatePhoneNumber {
+		u.PhoneNumber = phoneNumber
+	}
+	return ok(nil)
+}
+
+func (c *Core) findPasswordlessDevice(matches func(d *passwordlessDevice) bool) *passwordlessDevice {
+	for _, device := range c.passwordlessDevices {
+		if matches(device) {
+			return device
+		}
+	}
+	return nil
+}
+
+func (c *Core) removePasswordlessDevices(email string, phoneNumber string) {
+	for deviceID, device := range c.passwordlessDevices {
+		if (email != "" && device.email == email) || (phoneNumber != "" && device.phoneNumber == phoneNumber) {
+			delete(c.passwordlessDevices, deviceID)
+		}
+	}
+}
+
+func newBase64Token() string {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func newUserInputCode() string {
+	number, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%06d", number.Int64())
+}
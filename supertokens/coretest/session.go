@@ -0,0 +1,359 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package coretest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	b64 "encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// accessTokenHeader is the header of version 2 access tokens, which is what the SDK expects
+const accessTokenHeader = "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCIsInZlcnNpb24iOiIyIn0="
+
+type sessionState struct {
+	handle             string
+	userID             string
+	userDataInJWT      map[string]interface{}
+	userDataInDatabase map[string]interface{}
+	refreshToken       string
+	oldRefreshTokens   map[string]bool
+	antiCsrfToken      *string
+	timeCreated        uint64
+	expiry             uint64
+}
+
+type token struct {
+	Token       string `json:"token"`
+	Expiry      uint64 `json:"expiry"`
+	CreatedTime uint64 `json:"createdTime"`
+}
+
+type accessTokenPayload struct {
+	SessionHandle     string                 `json:"sessionHandle"`
+	UserID            string                 `json:"userId"`
+	RefreshTokenHash1 string                 `json:"refreshTokenHash1"`
+	UserData          map[string]interface{} `json:"userData"`
+	AntiCsrfToken     *string                `json:"antiCsrfToken"`
+	ExpiryTime        uint64                 `json:"expiryTime"`
+	TimeCreated       uint64                 `json:"timeCreated"`
+}
+
+// JwtSigningPublicKey returns the key that access tokens are signed with, in the format used by the core
+func (c *Core) JwtSigningPublicKey() string {
+	return c.publicKey()
+}
+
+func (c *Core) publicKey() string {
+	der, err := x509.MarshalPKIXPublicKey(&c.signingKey.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+	return b64.StdEncoding.EncodeToString(der)
+}
+
+func (c *Core) addSigningKeyInfo(response map[string]interface{}) map[string]interface{} {
+	expiryTime := currTimeInMS() + uint64(24*60*60*1000)
+	response["jwtSigningPublicKey"] = c.publicKey()
+	response["jwtSigningPublicKeyExpiryTime"] = expiryTime
+	response["jwtSigningPublicKeyList"] = []map[string]interface{}{{
+		"publicKey":  c.publicKey(),
+		"expiryTime": expiryTime,
+		"createdAt":  c.signingKeyTime,
+	}}
+	return response
+}
+
+func (c *Core) handshake(_ *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	return ok(c.addSigningKeyInfo(map[string]interface{}{
+		"accessTokenBlacklistingEnabled": c.config.AccessTokenBlacklisting,
+		"accessTokenValidity":            c.config.AccessTokenValidity.Milliseconds(),
+		"refreshTokenValidity":           c.config.RefreshTokenValidity.Milliseconds(),
+	}))
+}
+
+func (c *Core) createAccessToken(s *sessionState, expiry uint64) token {
+	now := currTimeInMS()
+	if expiry == 0 {
+		expiry = now + uint64(c.config.AccessTokenValidity.Milliseconds())
+	}
+	refreshTokenHash := sha256.Sum256([]byte(s.refreshToken))
+	payloadJSON, err := json.Marshal(accessTokenPayload{
+		SessionHandle:     s.handle,
+		UserID:            s.userID,
+		RefreshTokenHash1: hex.EncodeToString(refreshTokenHash[:]),
+		UserData:          s.userDataInJWT,
+		AntiCsrfToken:     s.antiCsrfToken,
+		ExpiryTime:        expiry,
+		TimeCreated:       now,
+	})
+	if err != nil {
+		panic(err)
+	}
+	payload := b64.StdEncoding.EncodeToString(payloadJSON)
+	digest := sha256.Sum256([]byte(accessTokenHeader + "." + payload))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.signingKey, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return token{
+		Token:       accessTokenHeader + "." + payload + "." + b64.StdEncoding.EncodeToString(signature),
+		Expiry:      expiry,
+		CreatedTime: now,
+	}
+}
+
+// parseAccessToken checks the signature of the access token and returns its payload. Whether the
+// token has expired is left to the caller.
+func (c *Core) parseAccessToken(accessToken string) (accessTokenPayload, error) {
+	splitted := strings.Split(accessToken, ".")
+	if len(splitted) != 3 || splitted[0] != accessTokenHeader {
+		return accessTokenPayload{}, errors.New("Invalid JWT")
+	}
+	signature, err := b64.StdEncoding.DecodeString(splitted[2])
+	if err != nil {
+		return accessTokenPayload{}, err
+	}
+	digest := sha256.Sum256([]byte(splitted[0] + "." + splitted[1]))
+	if err := rsa.VerifyPKCS1v15(&c.signingKey.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return accessTokenPayload{}, errors.New("JWT verification failed")
+	}
+	payloadJSON, err := b64.StdEncoding.DecodeString(splitted[1])
+	if err != nil {
+		return accessTokenPayload{}, err
+	}
+	var payload accessTokenPayload
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		return accessTokenPayload{}, err
+	}
+	return payload, nil
+}
+
+func (c *Core) sessionTokens(s *sessionState) map[string]interface{} {
+	now := currTimeInMS()
+	response := map[string]interface{}{
+		"session":     c.sessionStruct(s),
+		"accessToken": c.createAccessToken(s, 0),
+		"refreshToken": token{
+			Token:       s.refreshToken,
+			Expiry:      s.expiry,
+			CreatedTime: now,
+		},
+		"idRefreshToken": token{
+			Token:       newID(),
+			Expiry:      s.expiry,
+			CreatedTime: now,
+		},
+	}
+	if s.antiCsrfToken != nil {
+		response["antiCsrfToken"] = *s.antiCsrfToken
+	}
+	return response
+}
+
+func (c *Core) sessionStruct(s *sessionState) map[string]interface{} {
+	return map[string]interface{}{
+		"handle":        s.handle,
+		"userId":        s.userID,
+		"userDataInJWT": s.userDataInJWT,
+	}
+}
+
+func (c *Core) createSession(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	userID, found := getString(body, "userId")
+	if !found {
+		return badRequest("Field name 'userId' is invalid in JSON input")
+	}
+	now := currTimeInMS()
+	s := &sessionState{
+		handle:             newID(),
+		userID:             userID,
+		userDataInJWT:      getMap(body, "userDataInJWT"),
+		userDataInDatabase: getMap(body, "userDataInDatabase"),
+		refreshToken:       newToken(),
+		oldRefreshTokens:   map[string]bool{},
+		timeCreated:        now,
+		expiry:             now + uint64(c.config.RefreshTokenValidity.Milliseconds()),
+	}
+	if getBool(body, "enableAntiCsrf") {
+		antiCsrfToken := newID()
+		s.antiCsrfToken = &antiCsrfToken
+	}
+	c.sessions[s.handle] = s
+	return ok(c.addSigningKeyInfo(c.sessionTokens(s)))
+}
+
+func (c *Core) getSessionInformation(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	s := c.sessions[req.URL.Query().Get("sessionHandle")]
+	if s == nil {
+		return status("UNAUTHORISED", "Session does not exist.")
+	}
+	return ok(map[string]interface{}{
+		"sessionHandle":      s.handle,
+		"userId":             s.userID,
+		"userDataInDatabase": s.userDataInDatabase,
+		"userDataInJWT":      s.userDataInJWT,
+		"expiry":             s.expiry,
+		"timeCreated":        s.timeCreated,
+	})
+}
+
+func (c *Core) verifySession(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	accessToken, _ := getString(body, "accessToken")
+	payload, err := c.parseAccessToken(accessToken)
+	if err != nil {
+		response, statusCode := status("TRY_REFRESH_TOKEN", err.Error())
+		return c.addSigningKeyInfo(response), statusCode
+	}
+	if payload.ExpiryTime < currTimeInMS() {
+		response, statusCode := status("TRY_REFRESH_TOKEN", "Access token expired")
+		return c.addSigningKeyInfo(response), statusCode
+	}
+	s := c.sessions[payload.SessionHandle]
+	if s == nil {
+		return status("UNAUTHORISED", "Either the session has ended or has been blacklisted")
+	}
+	if getBool(body, "doAntiCsrfCheck") && getBool(body, "enableAntiCsrf") {
+		antiCsrfToken, _ := getString(body, "antiCsrfToken")
+		if payload.AntiCsrfToken == nil || *payload.AntiCsrfToken != antiCsrfToken {
+			response, statusCode := status("TRY_REFRESH_TOKEN", "anti-csrf check failed")
+			return c.addSigningKeyInfo(response), statusCode
+		}
+	}
+	return ok(c.addSigningKeyInfo(map[string]interface{}{
+		"session": map[string]interface{}{
+			"handle":        payload.SessionHandle,
+			"userId":        payload.UserID,
+			"userDataInJWT": payload.UserData,
+		},
+	}))
+}
+
+func (c *Core) refreshSession(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	refreshToken, _ := getString(body, "refreshToken")
+	for _, s := range c.sessions {
+		if s.oldRefreshTokens[refreshToken] {
+			delete(c.sessions, s.handle)
+			response, statusCode := status("TOKEN_THEFT_DETECTED", "")
+			response["session"] = map[string]interface{}{
+				"handle": s.handle,
+				"userId": s.userID,
+			}
+			return response, statusCode
+		}
+		if s.refreshToken != refreshToken {
+			continue
+		}
+		if s.expiry < currTimeInMS() {
+			delete(c.sessions, s.handle)
+			return status("UNAUTHORISED", "Refresh token expired")
+		}
+		if getBool(body, "enableAntiCsrf") && s.antiCsrfToken != nil {
+			antiCsrfToken, _ := getString(body, "antiCsrfToken")
+			if antiCsrfToken != *s.antiCsrfToken {
+				return status("UNAUTHORISED", "Anti CSRF token missing, or not matching")
+			}
+		}
+		s.oldRefreshTokens[s.refreshToken] = true
+		s.refreshToken = newToken()
+		s.expiry = currTimeInMS() + uint64(c.config.RefreshTokenValidity.Milliseconds())
+		return ok(c.sessionTokens(s))
+	}
+	return status("UNAUTHORISED", "Refresh token not found")
+}
+
+func (c *Core) regenerateSession(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	accessToken, _ := getString(body, "accessToken")
+	payload, err := c.parseAccessToken(accessToken)
+	if err != nil {
+		return status("UNAUTHORISED", err.Error())
+	}
+	s := c.sessions[payload.SessionHandle]
+	if s == nil {
+		return status("UNAUTHORISED", "Session does not exist.")
+	}
+	if userDataInJWT, found := body["userDataInJWT"].(map[string]interface{}); found {
+		s.userDataInJWT = userDataInJWT
+	}
+	return ok(map[string]interface{}{
+		"session":     c.sessionStruct(s),
+		"accessToken": c.createAccessToken(s, payload.ExpiryTime),
+	})
+}
+
+func (c *Core) removeSessions(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	revoked := []string{}
+	if userID, found := getString(body, "userId"); found {
+		for handle, s := range c.sessions {
+			if s.userID == userID {
+				delete(c.sessions, handle)
+				revoked = append(revoked, handle)
+			}
+		}
+	} else if handles, found := body["sessionHandles"].([]interface{}); found {
+		for _, handle := range handles {
+			handle, _ := handle.(string)
+			if c.sessions[handle] != nil {
+				delete(c.sessions, handle)
+				revoked = append(revoked, handle)
+			}
+		}
+	}
+	return ok(map[string]interface{}{
+		"sessionHandlesRevoked": revoked,
+	})
+}
+
+func (c *Core) getSessionHandlesForUser(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	userID := req.URL.Query().Get("userId")
+	handles := []string{}
+	for handle, s := range c.sessions {
+		if s.userID == userID {
+			handles = append(handles, handle)
+		}
+	}
+	return ok(map[string]interface{}{
+		"sessionHandles": handles,
+	})
+}
+
+func (c *Core) updateSessionData(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	handle, _ := getString(body, "sessionHandle")
+	s := c.sessions[handle]
+	if s == nil {
+		return status("UNAUTHORISED", "Session does not exist.")
+	}
+	s.userDataInDatabase = getMap(body, "userDataInDatabase")
+	return ok(nil)
+}
+
+func (c *Core) updateJWTData(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	handle, _ := getString(body, "sessionHandle")
+	s := c.sessions[handle]
+	if s == nil {
+		return status("UNAUTHORISED", "Session does not exist.")
+	}
+	s.userDataInJWT = getMap(body, "userDataInJWT")
+	return ok(nil)
+}
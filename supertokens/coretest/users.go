@@ -0,0 +1,289 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package coretest
+
+import (
+	b64 "encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	emailPasswordRecipeID = "emailpassword"
+	thirdPartyRecipeID    = "thirdparty"
+	passwordlessRecipeID  = "passwordless"
+)
+
+type thirdPartyInfo struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"`
+}
+
+type user struct {
+	ID          string          `json:"id"`
+	Email       string          `json:"email,omitempty"`
+	PhoneNumber string          `json:"phoneNumber,omitempty"`
+	TimeJoined  uint64          `json:"timeJoined"`
+	ThirdParty  *thirdPartyInfo `json:"thirdParty,omitempty"`
+
+	recipeID string
+	password string
+}
+
+func (c *Core) findUser(recipeID string, matches func(u *user) bool) *user {
+	for _, u := range c.users {
+		if (recipeID == "" || u.recipeID == recipeID) && matches(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (c *Core) addUser(u *user) {
+	u.ID = newID()
+	u.TimeJoined = currTimeInMS()
+	if len(c.users) > 0 && c.users[len(c.users)-1].TimeJoined >= u.TimeJoined {
+		// keeps the order of users by time joined strict, which the pagination relies on
+		u.TimeJoined = c.users[len(c.users)-1].TimeJoined + 1
+	}
+	c.users = append(c.users, u)
+}
+
+// recipeIDFromRequest returns the recipe that the request is meant for. Some APIs, like
+// /recipe/user, are shared by recipes, and the core tells them apart using the rid header.
+func recipeIDFromRequest(req *http.Request) string {
+	rid := req.Header.Get("rid")
+	if rid == thirdPartyRecipeID || rid == passwordlessRecipeID {
+		return rid
+	}
+	return emailPasswordRecipeID
+}
+
+func (c *Core) signUp(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	email, _ := getString(body, "email")
+	password, _ := getString(body, "password")
+	if c.findUser(emailPasswordRecipeID, func(u *user) bool { return u.Email == email }) != nil {
+		return status("EMAIL_ALREADY_EXISTS_ERROR", "")
+	}
+	u := &user{Email: email, recipeID: emailPasswordRecipeID, password: password}
+	c.addUser(u)
+	return ok(map[string]interface{}{
+		"user": u,
+	})
+}
+
+func (c *Core) signIn(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	email, _ := getString(body, "email")
+	password, _ := getString(body, "password")
+	u := c.findUser(emailPasswordRecipeID, func(u *user) bool { return u.Email == email })
+	if u == nil || u.password != password {
+		return status("WRONG_CREDENTIALS_ERROR", "")
+	}
+	return ok(map[string]interface{}{
+		"user": u,
+	})
+}
+
+func (c *Core) signInUp(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	thirdPartyID, _ := getString(body, "thirdPartyId")
+	thirdPartyUserID, _ := getString(body, "thirdPartyUserId")
+	email, _ := getString(getMap(body, "email"), "id")
+	u := c.findUser(thirdPartyRecipeID, func(u *user) bool {
+		return u.ThirdParty.ID == thirdPartyID && u.ThirdParty.UserID == thirdPartyUserID
+	})
+	createdNewUser := u == nil
+	if createdNewUser {
+		u = &user{
+			recipeID: thirdPartyRecipeID,
+			ThirdParty: &thirdPartyInfo{
+				ID:     thirdPartyID,
+				UserID: thirdPartyUserID,
+			},
+		}
+		c.addUser(u)
+	}
+	u.Email = email
+	return ok(map[string]interface{}{
+		"createdNewUser": createdNewUser,
+		"user":           u,
+	})
+}
+
+func (c *Core) getUser(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	query := req.URL.Query()
+	recipeID := recipeIDFromRequest(req)
+	var u *user
+	errorStatus := "UNKNOWN_USER_ID_ERROR"
+	if query.Get("userId") != "" {
+		u = c.findUser(recipeID, func(u *user) bool { return u.ID == query.Get("userId") })
+	} else if query.Get("email") != "" {
+		errorStatus = "UNKNOWN_EMAIL_ERROR"
+		u = c.findUser(recipeID, func(u *user) bool { return u.Email == query.Get("email") })
+	} else if query.Get("phoneNumber") != "" {
+		errorStatus = "UNKNOWN_PHONE_NUMBER_ERROR"
+		u = c.findUser(passwordlessRecipeID, func(u *user) bool { return u.PhoneNumber == query.Get("phoneNumber") })
+	} else if query.Get("thirdPartyId") != "" {
+		errorStatus = "UNKNOWN_THIRD_PARTY_USER_ERROR"
+		u = c.findUser(thirdPartyRecipeID, func(u *user) bool {
+			return u.ThirdParty.ID == query.Get("thirdPartyId") && u.ThirdParty.UserID == query.Get("thirdPartyUserId")
+		})
+	} else {
+		return badRequest("Please provide one of userId, email, phoneNumber or thirdPartyId")
+	}
+	if u == nil {
+		return status(errorStatus, "")
+	}
+	return ok(map[string]interface{}{
+		"user": u,
+	})
+}
+
+func (c *Core) updateUser(req *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	if recipeIDFromRequest(req) == passwordlessRecipeID {
+		return c.updatePasswordlessUser(body)
+	}
+	userID, _ := getString(body, "userId")
+	u := c.findUser(emailPasswordRecipeID, func(u *user) bool { return u.ID == userID })
+	if u == nil {
+		return status("UNKNOWN_USER_ID_ERROR", "")
+	}
+	if email, found := getString(body, "email"); found {
+		if c.findUser(emailPasswordRecipeID, func(other *user) bool { return other != u && other.Email == email }) != nil {
+			return status("EMAIL_ALREADY_EXISTS_ERROR", "")
+		}
+		u.Email = email
+	}
+	if password, found := getString(body, "password"); found {
+		u.password = password
+	}
+	return ok(nil)
+}
+
+func (c *Core) getUsersByEmail(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	email := req.URL.Query().Get("email")
+	users := []*user{}
+	for _, u := range c.users {
+		if u.recipeID == thirdPartyRecipeID && u.Email == email {
+			users = append(users, u)
+		}
+	}
+	return ok(map[string]interface{}{
+		"users": users,
+	})
+}
+
+func (c *Core) createPasswordResetToken(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	userID, _ := getString(body, "userId")
+	if c.findUser(emailPasswordRecipeID, func(u *user) bool { return u.ID == userID }) == nil {
+		return status("UNKNOWN_USER_ID_ERROR", "")
+	}
+	token := newToken()
+	c.passwordResetTokens[token] = userID
+	return ok(map[string]interface{}{
+		"token": token,
+	})
+}
+
+func (c *Core) resetPassword(_ *http.Request, body map[string]interface{}) (map[string]interface{}, int) {
+	token, _ := getString(body, "token")
+	newPassword, _ := getString(body, "newPassword")
+	userID, found := c.passwordResetTokens[token]
+	u := c.findUser(emailPasswordRecipeID, func(u *user) bool { return u.ID == userID })
+	if !found || u == nil {
+		return status("RESET_PASSWORD_INVALID_TOKEN_ERROR", "")
+	}
+	for t, id := range c.passwordResetTokens {
+		if id == userID {
+			delete(c.passwordResetTokens, t)
+		}
+	}
+	u.password = newPassword
+	return ok(map[string]interface{}{
+		"userId": userID,
+	})
+}
+
+func (c *Core) filterUsers(includeRecipeIDs string) []*user {
+	users := []*user{}
+	for _, u := range c.users {
+		if includeRecipeIDs == "" {
+			users = append(users, u)
+			continue
+		}
+		for _, recipeID := range strings.Split(includeRecipeIDs, ",") {
+			if strings.TrimSpace(recipeID) == u.recipeID {
+				users = append(users, u)
+				break
+			}
+		}
+	}
+	return users
+}
+
+func (c *Core) getUsers(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	query := req.URL.Query()
+	users := c.filterUsers(query.Get("includeRecipeIds"))
+	if query.Get("timeJoinedOrder") == "DESC" {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
+	limit := 100
+	if query.Get("limit") != "" {
+		parsed, err := strconv.Atoi(query.Get("limit"))
+		if err != nil || parsed <= 0 {
+			return badRequest("limit must be a positive integer")
+		}
+		limit = parsed
+	}
+	offset := 0
+	if query.Get("paginationToken") != "" {
+		decoded, err := b64.StdEncoding.DecodeString(query.Get("paginationToken"))
+		if err == nil {
+			offset, err = strconv.Atoi(string(decoded))
+		}
+		if err != nil || offset < 0 || offset > len(users) {
+			return badRequest("invalid pagination token")
+		}
+	}
+
+	page := []map[string]interface{}{}
+	end := offset + limit
+	if end > len(users) {
+		end = len(users)
+	}
+	for _, u := range users[offset:end] {
+		page = append(page, map[string]interface{}{
+			"recipeId": u.recipeID,
+			"user":     u,
+		})
+	}
+	response := map[string]interface{}{
+		"users": page,
+	}
+	if end < len(users) {
+		response["nextPaginationToken"] = b64.StdEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	return ok(response)
+}
+
+func (c *Core) getUserCount(req *http.Request, _ map[string]interface{}) (map[string]interface{}, int) {
+	return ok(map[string]interface{}{
+		"count": len(c.filterUsers(req.URL.Query().Get("includeRecipeIds"))),
+	})
+}
@@ -15,6 +15,8 @@
 
 package supertokens
 
+import "fmt"
+
 // BadInputError used for non specific exceptions
 type BadInputError struct {
 	Msg string
@@ -23,3 +25,31 @@ type BadInputError struct {
 func (err BadInputError) Error() string {
 	return err.Msg
 }
+
+// CoreUnavailableError is returned when requests to the SuperTokens core are not sent because
+// too many of the previous ones failed
+type CoreUnavailableError struct {
+	Msg string
+}
+
+func (err CoreUnavailableError) Error() string {
+	return err.Msg
+}
+
+// UnexpectedCoreResponseError is returned when a response of the SuperTokens core does not have the
+// expected shape, which usually means that the core version is not compatible with this SDK
+type UnexpectedCoreResponseError struct {
+	Msg string
+}
+
+func (err UnexpectedCoreResponseError) Error() string {
+	return err.Msg
+}
+
+// NewUnexpectedCoreStatusError is returned by recipes when the core answers a request to path with a
+// status they do not know about
+func NewUnexpectedCoreStatusError(path string, status string) UnexpectedCoreResponseError {
+	return UnexpectedCoreResponseError{
+		Msg: fmt.Sprintf("SuperTokens core returned an unexpected status for a request to path: '%s': %s", path, status),
+	}
+}
@@ -0,0 +1,53 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"net/http"
+)
+
+// defaultHTTPClient is used when there is no SuperTokens instance to take the client from
+var defaultHTTPClient = &http.Client{}
+
+func newHTTPClient(config *ConnectionInfo) *http.Client {
+	if config == nil {
+		return defaultHTTPClient
+	}
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+	if config.Transport != nil {
+		return &http.Client{Transport: config.Transport}
+	}
+	return defaultHTTPClient
+}
+
+// GetHTTPClient returns the client used for every outgoing request made by the SDK - to the
+// SuperTokens core, for telemetry and to third party providers - of the default instance.
+func GetHTTPClient() *http.Client {
+	return GetHTTPClientWithContext(context.Background())
+}
+
+// GetHTTPClientWithContext is like GetHTTPClient, but uses the SuperTokens instance that is handling
+// the request the context belongs to, if any.
+func GetHTTPClientWithContext(ctx context.Context) *http.Client {
+	instance, err := GetInstanceOrThrowError(ctx)
+	if err != nil {
+		return defaultHTTPClient
+	}
+	return instance.httpClient
+}
@@ -16,13 +16,23 @@
 package supertokens
 
 import (
+	"context"
 	"net/http"
 )
 
+// Init creates the default SuperTokens instance, which is used by all the package level functions
+// of this and the recipe packages.
 func Init(config TypeInput) error {
 	return supertokensInit(config)
 }
 
+// New creates a SuperTokens instance that is independent of the default one and of any other
+// instance. Requests handled by its Middleware use its recipes and core, including in the
+// recipe level functions called with the request's context.
+func New(config TypeInput) (*SuperTokens, error) {
+	return newSuperTokens(config)
+}
+
 func Middleware(theirHandler http.Handler) http.Handler {
 	instance, err := getInstanceOrThrowError()
 	if err != nil {
@@ -32,7 +42,7 @@ func Middleware(theirHandler http.Handler) http.Handler {
 }
 
 func ErrorHandler(err error, req *http.Request, res http.ResponseWriter) error {
-	instance, instanceErr := getInstanceOrThrowError()
+	instance, instanceErr := GetInstanceOrThrowError(req.Context())
 	if instanceErr != nil {
 		return instanceErr
 	}
@@ -48,13 +58,63 @@ func GetAllCORSHeaders() []string {
 }
 
 func GetUserCount(includeRecipeIds *[]string) (float64, error) {
-	return getUserCount(includeRecipeIds)
+	return GetUserCountWithContext(context.Background(), includeRecipeIds)
+}
+
+func GetUserCountWithContext(ctx context.Context, includeRecipeIds *[]string) (float64, error) {
+	instance, err := GetInstanceOrThrowError(ctx)
+	if err != nil {
+		return -1, err
+	}
+	return instance.getUserCount(ctx, includeRecipeIds)
 }
 
 func GetUsersOldestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
-	return getUsers("ASC", paginationToken, limit, includeRecipeIds)
+	return GetUsersOldestFirstWithContext(context.Background(), paginationToken, limit, includeRecipeIds)
+}
+
+func GetUsersOldestFirstWithContext(ctx context.Context, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+	instance, err := GetInstanceOrThrowError(ctx)
+	if err != nil {
+		return UserPaginationResult{}, err
+	}
+	return instance.getUsers(ctx, "ASC", paginationToken, limit, includeRecipeIds)
 }
 
 func GetUsersNewestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
-	return getUsers("DESC", paginationToken, limit, includeRecipeIds)
+	return GetUsersNewestFirstWithContext(context.Background(), paginationToken, limit, includeRecipeIds)
+}
+
+func GetUsersNewestFirstWithContext(ctx context.Context, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+	instance, err := GetInstanceOrThrowError(ctx)
+	if err != nil {
+		return UserPaginationResult{}, err
+	}
+	return instance.getUsers(ctx, "DESC", paginationToken, limit, includeRecipeIds)
+}
+
+// Middleware handles the SuperTokens APIs of this instance and makes the recipe functions called
+// with the request's context use this instance.
+func (s *SuperTokens) Middleware(theirHandler http.Handler) http.Handler {
+	return s.middleware(theirHandler)
+}
+
+func (s *SuperTokens) ErrorHandler(err error, req *http.Request, res http.ResponseWriter) error {
+	return s.errorHandler(err, req.WithContext(WithInstance(req.Context(), s)), res)
+}
+
+func (s *SuperTokens) GetAllCORSHeaders() []string {
+	return s.getAllCORSHeaders()
+}
+
+func (s *SuperTokens) GetUserCount(ctx context.Context, includeRecipeIds *[]string) (float64, error) {
+	return s.getUserCount(WithInstance(ctx, s), includeRecipeIds)
+}
+
+func (s *SuperTokens) GetUsersOldestFirst(ctx context.Context, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+	return s.getUsers(WithInstance(ctx, s), "ASC", paginationToken, limit, includeRecipeIds)
+}
+
+func (s *SuperTokens) GetUsersNewestFirst(ctx context.Context, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+	return s.getUsers(WithInstance(ctx, s), "DESC", paginationToken, limit, includeRecipeIds)
 }
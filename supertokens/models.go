@@ -15,7 +15,10 @@
 
 package supertokens
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 type NormalisedAppinfo struct {
 	AppName         string
@@ -35,7 +38,7 @@ type AppInfo struct {
 	APIGatewayPath  *string
 }
 
-type Recipe func(appInfo NormalisedAppinfo, onGeneralError func(err error, req *http.Request, res http.ResponseWriter)) (*RecipeModule, error)
+type Recipe func(instance *SuperTokens) (*RecipeModule, error)
 
 type TypeInput struct {
 	Supertokens    *ConnectionInfo
@@ -48,6 +51,29 @@ type TypeInput struct {
 type ConnectionInfo struct {
 	ConnectionURI string
 	APIKey        string
+	// HTTPClient, if set, is used for all requests to the core, for telemetry and by third party providers
+	HTTPClient *http.Client
+	// Transport is used to build the shared client when HTTPClient is not set
+	Transport http.RoundTripper
+	Retry     *RetryConfig
+}
+
+// RetryConfig controls how requests to the core are retried and when core hosts are considered unhealthy.
+// Zero values mean that the defaults are used.
+type RetryConfig struct {
+	// MaxRetries is the number of times a failed request is retried. Defaults to 3
+	MaxRetries *int
+	// InitialBackoff is the upper bound of the (jittered) wait before the first retry. Defaults to 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponentially growing wait between retries. Defaults to 2s
+	MaxBackoff time.Duration
+	// HostEjectionDuration is how long a core host that failed is skipped for. Defaults to 30s
+	HostEjectionDuration time.Duration
+	// CircuitBreakerThreshold is the number of requests in a row that need to fail for the querier to stop
+	// sending requests to the core for CircuitBreakerCooldown. Defaults to 5, 0 disables the circuit breaker
+	CircuitBreakerThreshold *int
+	// CircuitBreakerCooldown defaults to 10s
+	CircuitBreakerCooldown time.Duration
 }
 
 type APIHandled struct {
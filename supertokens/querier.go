@@ -17,59 +17,133 @@ package supertokens
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
 	"sync"
+	"time"
 )
 
 type Querier struct {
 	RIDToCore string
+	core      *coreConnection
 }
 
-var (
-	querierInitCalled     bool                  = false
-	querierHosts          []NormalisedURLDomain = nil
-	querierAPIKey         *string
-	querierAPIVersion     string
-	querierLastTriedIndex int
-	querierLock           sync.Mutex
-	querierHostLock       sync.Mutex
-)
+// coreConnection holds the state needed to talk to the SuperTokens core(s) of one SuperTokens instance.
+type coreConnection struct {
+	hosts          []NormalisedURLDomain
+	apiKey         *string
+	apiVersion     string
+	apiVersionLock sync.Mutex
+	// apiVersionFetch is the request for the API version that is in flight, if any
+	apiVersionFetch *apiVersionFetch
+	retryConfig     normalisedRetryConfig
+	hostHealth      *hostHealth
+	circuitBreaker  *circuitBreaker
+	httpClient      *http.Client
+}
 
-func (q *Querier) getQuerierAPIVersion() (string, error) {
-	querierLock.Lock()
-	defer querierLock.Unlock()
-	if querierAPIVersion != "" {
-		return querierAPIVersion, nil
+func newCoreConnection(hosts []NormalisedURLDomain, APIKey string, retryConfig *RetryConfig, httpClient *http.Client) *coreConnection {
+	core := &coreConnection{
+		hosts:       hosts,
+		retryConfig: normaliseRetryConfig(retryConfig),
+		hostHealth:  newHostHealth(len(hosts)),
+		httpClient:  httpClient,
 	}
-	response, err := q.sendRequestHelper(NormalisedURLPath{value: "/apiversion"}, func(url string) (*http.Response, error) {
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
+	if APIKey != "" {
+		core.apiKey = &APIKey
+	}
+	core.circuitBreaker = &circuitBreaker{
+		threshold: core.retryConfig.circuitBreakerThreshold,
+		cooldown:  core.retryConfig.circuitBreakerCooldown,
+	}
+	return core
+}
+
+// apiVersionFetch lets concurrent requests wait for the same API version request, instead of each
+// sending one or queueing behind a lock while it is retried
+type apiVersionFetch struct {
+	done    chan struct{}
+	version string
+	err     error
+}
+
+func (q *Querier) getQuerierAPIVersion(ctx context.Context) (string, error) {
+	for {
+		q.core.apiVersionLock.Lock()
+		if q.core.apiVersion != "" {
+			q.core.apiVersionLock.Unlock()
+			return q.core.apiVersion, nil
 		}
-		if querierAPIKey != nil {
-			req.Header.Set("api-key", *querierAPIKey)
+		fetch := q.core.apiVersionFetch
+		if fetch == nil {
+			fetch = &apiVersionFetch{done: make(chan struct{})}
+			q.core.apiVersionFetch = fetch
+			q.core.apiVersionLock.Unlock()
+
+			fetch.version, fetch.err = q.fetchQuerierAPIVersion(ctx)
+			q.core.apiVersionLock.Lock()
+			if fetch.err == nil {
+				q.core.apiVersion = fetch.version
+			}
+			q.core.apiVersionFetch = nil
+			q.core.apiVersionLock.Unlock()
+			close(fetch.done)
+			return fetch.version, fetch.err
 		}
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(querierHosts))
+		q.core.apiVersionLock.Unlock()
 
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-fetch.done:
+		}
+		if fetch.err == nil {
+			return fetch.version, nil
+		}
+		// the request that fetched the version may have been cancelled, which is no reason for this one to fail
+		if !errors.Is(fetch.err, context.Canceled) && !errors.Is(fetch.err, context.DeadlineExceeded) {
+			return "", fetch.err
+		}
+	}
+}
+
+// CheckAPIVersion returns an error if the CDI version used with the core is older than minVersion,
+// for the APIs that older cores don't have
+func (q *Querier) CheckAPIVersion(ctx context.Context, minVersion string) error {
+	apiVersion, err := q.getQuerierAPIVersion(ctx)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if apiVersion != minVersion && maxVersion(apiVersion, minVersion) == minVersion {
+		return fmt.Errorf("this feature needs CDI %s, but the running SuperTokens core only supports up to CDI %s. Please visit https://supertokens.io/docs/community/compatibility-table to find the right version", minVersion, apiVersion)
 	}
+	return nil
+}
+
+func (q *Querier) fetchQuerierAPIVersion(ctx context.Context) (string, error) {
+	response, err := q.sendRequestHelper(ctx, NormalisedURLPath{value: "/apiversion"}, true, func(url string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if q.core.apiKey != nil {
+			req.Header.Set("api-key", *q.core.apiKey)
+		}
+		return q.core.httpClient.Do(req)
+	})
 
-	respJSON, err := json.Marshal(response)
 	if err != nil {
 		return "", err
 	}
+
 	var cdiSupportedByServer struct {
 		Versions []string `json:"versions"`
 	}
-	err = json.Unmarshal(respJSON, &cdiSupportedByServer)
+	err = decodeResponse("/apiversion", response, &cdiSupportedByServer)
 	if err != nil {
 		return "", err
 	}
@@ -77,37 +151,50 @@ func (q *Querier) getQuerierAPIVersion() (string, error) {
 	if supportedVersion == nil {
 		return "", errors.New("the running SuperTokens core version is not compatible with this Golang SDK. Please visit https://supertokens.io/docs/community/compatibility-table to find the right version")
 	}
-
-	querierAPIVersion = *supportedVersion
-
-	return querierAPIVersion, nil
+	return *supportedVersion, nil
 }
 
+// GetNewQuerierInstanceOrThrowError returns a querier for the core of the default SuperTokens instance
 func GetNewQuerierInstanceOrThrowError(rIDToCore string) (*Querier, error) {
-	if !querierInitCalled {
+	instance, err := getInstanceOrThrowError()
+	if err != nil {
 		return nil, errors.New("please call the supertokens.init function before using SuperTokens")
 	}
-	return &Querier{RIDToCore: rIDToCore}, nil
+	return instance.GetNewQuerierInstanceOrThrowError(rIDToCore)
 }
 
-func initQuerier(hosts []NormalisedURLDomain, APIKey string) {
-	if !querierInitCalled {
-		querierInitCalled = true
-		querierHosts = hosts
-		if APIKey != "" {
-			querierAPIKey = &APIKey
-		}
-		querierAPIVersion = ""
-		querierLastTriedIndex = 0
+func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return q.SendPostRequestWithContext(context.Background(), path, data)
+}
+
+func (q *Querier) SendPostRequestWithContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	response, err := q.sendPostRequest(ctx, path, data)
+	if err != nil {
+		return nil, err
 	}
+	return decodeResponseToMap(response), nil
 }
 
-func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+// SendPostRequestAndDecode decodes the JSON response of the core into result, returning an
+// UnexpectedCoreResponseError if it does not match
+func (q *Querier) SendPostRequestAndDecode(ctx context.Context, path string, data map[string]interface{}, result interface{}) error {
+	response, err := q.sendPostRequest(ctx, path, data)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(path, response, result)
+}
+
+func (q *Querier) sendPostRequest(ctx context.Context, path string, data map[string]interface{}) ([]byte, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	apiVerion, err := q.getQuerierAPIVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.sendRequestHelper(ctx, nP, false, func(url string) (*http.Response, error) {
 		if data == nil {
 			data = map[string]interface{}{}
 		}
@@ -115,71 +202,111 @@ func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion()
-		if querierAPIVersionError != nil {
-			return nil, querierAPIVersionError
-		}
-
 		req.Header.Set("content-type", "application/json; charset=utf-8")
 		req.Header.Set("cdi-version", apiVerion)
-		if querierAPIKey != nil {
-			req.Header.Set("api-key", *querierAPIKey)
+		if q.core.apiKey != nil {
+			req.Header.Set("api-key", *q.core.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(querierHosts))
+		return q.core.httpClient.Do(req)
+	})
 }
 
 func (q *Querier) SendDeleteRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return q.SendDeleteRequestWithContext(context.Background(), path, data)
+}
+
+func (q *Querier) SendDeleteRequestWithContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	response, err := q.sendDeleteRequest(ctx, path, data)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponseToMap(response), nil
+}
+
+// SendDeleteRequestAndDecode decodes the JSON response of the core into result, returning an
+// UnexpectedCoreResponseError if it does not match
+func (q *Querier) SendDeleteRequestAndDecode(ctx context.Context, path string, data map[string]interface{}, result interface{}) error {
+	response, err := q.sendDeleteRequest(ctx, path, data)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(path, response, result)
+}
+
+func (q *Querier) sendDeleteRequest(ctx context.Context, path string, data map[string]interface{}) ([]byte, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	apiVerion, err := q.getQuerierAPIVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.sendRequestHelper(ctx, nP, true, func(url string) (*http.Response, error) {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "DELETE", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion()
-		if querierAPIVersionError != nil {
-			return nil, querierAPIVersionError
-		}
-
 		req.Header.Set("content-type", "application/json; charset=utf-8")
 		req.Header.Set("cdi-version", apiVerion)
-		if querierAPIKey != nil {
-			req.Header.Set("api-key", *querierAPIKey)
+		if q.core.apiKey != nil {
+			req.Header.Set("api-key", *q.core.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(querierHosts))
+		return q.core.httpClient.Do(req)
+	})
 }
 
 func (q *Querier) SendGetRequest(path string, params map[string]string) (map[string]interface{}, error) {
+	return q.SendGetRequestWithContext(context.Background(), path, params)
+}
+
+func (q *Querier) SendGetRequestWithContext(ctx context.Context, path string, params map[string]string) (map[string]interface{}, error) {
+	response, err := q.sendGetRequest(ctx, path, params)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponseToMap(response), nil
+}
+
+// SendGetRequestAndDecode decodes the JSON response of the core into result, returning an
+// UnexpectedCoreResponseError if it does not match
+func (q *Querier) SendGetRequestAndDecode(ctx context.Context, path string, params map[string]string, result interface{}) error {
+	response, err := q.sendGetRequest(ctx, path, params)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(path, response, result)
+}
+
+func (q *Querier) sendGetRequest(ctx context.Context, path string, params map[string]string) ([]byte, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
-		req, err := http.NewRequest("GET", url, nil)
+	apiVerion, err := q.getQuerierAPIVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.sendRequestHelper(ctx, nP, true, func(url string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -191,101 +318,159 @@ func (q *Querier) SendGetRequest(path string, params map[string]string) (map[str
 		}
 		req.URL.RawQuery = query.Encode()
 
-		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion()
-		if querierAPIVersionError != nil {
-			return nil, querierAPIVersionError
-		}
 		req.Header.Set("cdi-version", apiVerion)
-		if querierAPIKey != nil {
-			req.Header.Set("api-key", *querierAPIKey)
+		if q.core.apiKey != nil {
+			req.Header.Set("api-key", *q.core.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(querierHosts))
+		return q.core.httpClient.Do(req)
+	})
 }
 
 func (q *Querier) SendPutRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return q.SendPutRequestWithContext(context.Background(), path, data)
+}
+
+func (q *Querier) SendPutRequestWithContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	response, err := q.sendPutRequest(ctx, path, data)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponseToMap(response), nil
+}
+
+// SendPutRequestAndDecode decodes the JSON response of the core into result, returning an
+// UnexpectedCoreResponseError if it does not match
+func (q *Querier) SendPutRequestAndDecode(ctx context.Context, path string, data map[string]interface{}, result interface{}) error {
+	response, err := q.sendPutRequest(ctx, path, data)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(path, response, result)
+}
+
+func (q *Querier) sendPutRequest(ctx context.Context, path string, data map[string]interface{}) ([]byte, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	apiVerion, err := q.getQuerierAPIVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.sendRequestHelper(ctx, nP, true, func(url string) (*http.Response, error) {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion()
-		if querierAPIVersionError != nil {
-			return nil, querierAPIVersionError
-		}
-
 		req.Header.Set("content-type", "application/json; charset=utf-8")
 		req.Header.Set("cdi-version", apiVerion)
-		if querierAPIKey != nil {
-			req.Header.Set("api-key", *querierAPIKey)
+		if q.core.apiKey != nil {
+			req.Header.Set("api-key", *q.core.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(querierHosts))
+		return q.core.httpClient.Do(req)
+	})
 }
 
 type httpRequestFunction func(url string) (*http.Response, error)
 
-func (q *Querier) sendRequestHelper(path NormalisedURLPath, httpRequest httpRequestFunction, numberOfTries int) (map[string]interface{}, error) {
-	if numberOfTries == 0 {
+// sendRequestHelper sends the request to one of the core hosts, retrying with backoff on a different
+// host if the core could not be reached. Requests that were received by the core are only retried
+// (on 5xx responses or timeouts) if they are idempotent.
+func (q *Querier) sendRequestHelper(ctx context.Context, path NormalisedURLPath, idempotent bool, httpRequest httpRequestFunction) ([]byte, error) {
+	if len(q.core.hosts) == 0 {
 		return nil, errors.New("no SuperTokens core available to query")
 	}
+	if !q.core.circuitBreaker.allow(time.Now()) {
+		return nil, CoreUnavailableError{Msg: "too many requests to the SuperTokens core have failed, not sending any more for now"}
+	}
 
-	querierHostLock.Lock()
-	currentHost := querierHosts[querierLastTriedIndex].GetAsStringDangerous()
-	querierLastTriedIndex = (querierLastTriedIndex + 1) % len(querierHosts)
-	querierHostLock.Unlock()
+	var lastErr error
+	for attempt := 0; attempt <= q.core.retryConfig.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepWithContext(ctx, q.core.retryConfig.getBackoff(attempt)); err != nil {
+				q.core.circuitBreaker.release()
+				return nil, err
+			}
+		}
+		hostIndex := q.core.hostHealth.pickHost(time.Now())
+		result, hostFailed, retryable, err := q.sendRequestToHost(q.core.hosts[hostIndex], path, idempotent, httpRequest)
+		if err == nil || !hostFailed {
+			q.core.hostHealth.markHealthy(hostIndex)
+			q.core.circuitBreaker.onSuccess()
+			return result, err
+		}
+		if ctx.Err() != nil {
+			q.core.circuitBreaker.release()
+			return nil, err
+		}
+		q.core.hostHealth.markUnhealthy(hostIndex, time.Now().Add(q.core.retryConfig.hostEjectionDuration))
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+	q.core.circuitBreaker.onFailure(time.Now())
+	return nil, lastErr
+}
 
-	resp, err := httpRequest(currentHost + path.GetAsStringDangerous())
+// sendRequestToHost returns whether the error (if any) was caused by the host being unhealthy,
+// and whether the request can safely be retried.
+func (q *Querier) sendRequestToHost(host NormalisedURLDomain, path NormalisedURLPath, idempotent bool, httpRequest httpRequestFunction) (result []byte, hostFailed bool, retryable bool, err error) {
+	resp, err := httpRequest(host.GetAsStringDangerous() + path.GetAsStringDangerous())
 
 	if err != nil {
-		if strings.Contains(err.Error(), "connection refused") {
-			return q.sendRequestHelper(path, httpRequest, numberOfTries-1)
-		}
 		if resp != nil {
 			resp.Body.Close()
 		}
-		return nil, err
+		return nil, true, idempotent || isConnectionError(err), err
 	}
 
 	defer resp.Body.Close()
 
 	body, readErr := ioutil.ReadAll(resp.Body)
 	if readErr != nil {
-		return nil, readErr
+		return nil, true, idempotent, readErr
 	}
 	if resp.StatusCode != 200 {
-		return nil, errors.New(fmt.Sprintf("SuperTokens core threw an error for a request to path: '%s' with status code: %v and message: %s", path.GetAsStringDangerous(), resp.StatusCode, body))
+		err := errors.New(fmt.Sprintf("SuperTokens core threw an error for a request to path: '%s' with status code: %v and message: %s", path.GetAsStringDangerous(), resp.StatusCode, body))
+		if resp.StatusCode >= 500 {
+			return nil, true, idempotent, err
+		}
+		return nil, false, false, err
 	}
+	return body, false, false, nil
+}
 
+func decodeResponseToMap(body []byte) map[string]interface{} {
 	finalResult := make(map[string]interface{})
 	jsonError := json.Unmarshal(body, &finalResult)
 	if jsonError != nil {
 		return map[string]interface{}{
 			"result": string(body),
-		}, nil
+		}
 	}
-	return finalResult, nil
+	return finalResult
 }
 
-func ResetQuerierForTest() {
-	querierInitCalled = false
+func decodeResponse(path string, body []byte, result interface{}) error {
+	err := json.Unmarshal(body, result)
+	if err != nil {
+		return UnexpectedCoreResponseError{
+			Msg: fmt.Sprintf("SuperTokens core returned an unexpected response for a request to path: '%s': %s", path, err.Error()),
+		}
+	}
+	return nil
 }
@@ -0,0 +1,208 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	defaultErrors "errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultQuerierMaxRetries              = 3
+	defaultQuerierInitialBackoff          = 100 * time.Millisecond
+	defaultQuerierMaxBackoff              = 2 * time.Second
+	defaultQuerierHostEjectionDuration    = 30 * time.Second
+	defaultQuerierCircuitBreakerThreshold = 5
+	defaultQuerierCircuitBreakerCooldown  = 10 * time.Second
+)
+
+type normalisedRetryConfig struct {
+	maxRetries              int
+	initialBackoff          time.Duration
+	maxBackoff              time.Duration
+	hostEjectionDuration    time.Duration
+	circuitBreakerThreshold int
+	circuitBreakerCooldown  time.Duration
+}
+
+func normaliseRetryConfig(config *RetryConfig) normalisedRetryConfig {
+	result := normalisedRetryConfig{
+		maxRetries:              defaultQuerierMaxRetries,
+		initialBackoff:          defaultQuerierInitialBackoff,
+		maxBackoff:              defaultQuerierMaxBackoff,
+		hostEjectionDuration:    defaultQuerierHostEjectionDuration,
+		circuitBreakerThreshold: defaultQuerierCircuitBreakerThreshold,
+		circuitBreakerCooldown:  defaultQuerierCircuitBreakerCooldown,
+	}
+	if config == nil {
+		return result
+	}
+	if config.MaxRetries != nil && *config.MaxRetries >= 0 {
+		result.maxRetries = *config.MaxRetries
+	}
+	if config.InitialBackoff > 0 {
+		result.initialBackoff = config.InitialBackoff
+	}
+	if config.MaxBackoff > 0 {
+		result.maxBackoff = config.MaxBackoff
+	}
+	if result.maxBackoff < result.initialBackoff {
+		result.maxBackoff = result.initialBackoff
+	}
+	if config.HostEjectionDuration > 0 {
+		result.hostEjectionDuration = config.HostEjectionDuration
+	}
+	if config.CircuitBreakerThreshold != nil && *config.CircuitBreakerThreshold >= 0 {
+		result.circuitBreakerThreshold = *config.CircuitBreakerThreshold
+	}
+	if config.CircuitBreakerCooldown > 0 {
+		result.circuitBreakerCooldown = config.CircuitBreakerCooldown
+	}
+	return result
+}
+
+// getBackoff returns how long to wait before the given retry (starting at 1). It grows exponentially
+// from initialBackoff, is capped at maxBackoff and uses "full jitter" so that many clients retrying
+// at the same time do not hit the core in lockstep.
+func (c normalisedRetryConfig) getBackoff(retry int) time.Duration {
+	backoff := c.initialBackoff
+	for i := 1; i < retry && backoff < c.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > c.maxBackoff {
+		backoff = c.maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isConnectionError returns true if the request never reached the core, in which case it is
+// safe to retry it even if it is not idempotent.
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	return defaultErrors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// hostHealth keeps track of which core hosts have recently failed so that they can be skipped
+// for hostEjectionDuration.
+type hostHealth struct {
+	lock         sync.Mutex
+	ejectedUntil []time.Time
+	lastTried    int
+}
+
+func newHostHealth(numberOfHosts int) *hostHealth {
+	return &hostHealth{
+		ejectedUntil: make([]time.Time, numberOfHosts),
+	}
+}
+
+// pickHost round robins through the hosts, skipping the ones that are ejected. If all of them
+// are ejected, the one that will be back the soonest is used.
+func (h *hostHealth) pickHost(now time.Time) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	numberOfHosts := len(h.ejectedUntil)
+	soonest := h.lastTried % numberOfHosts
+	for i := 0; i < numberOfHosts; i++ {
+		index := (h.lastTried + i) % numberOfHosts
+		if !h.ejectedUntil[index].After(now) {
+			h.lastTried = (index + 1) % numberOfHosts
+			return index
+		}
+		if h.ejectedUntil[index].Before(h.ejectedUntil[soonest]) {
+			soonest = index
+		}
+	}
+	h.lastTried = (soonest + 1) % numberOfHosts
+	return soonest
+}
+
+func (h *hostHealth) markHealthy(index int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.ejectedUntil[index] = time.Time{}
+}
+
+func (h *hostHealth) markUnhealthy(index int, until time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.ejectedUntil[index] = until
+}
+
+// circuitBreaker stops requests to the core altogether once threshold requests in a row have
+// failed. After cooldown, a single request is let through and its result decides whether the
+// breaker closes again.
+type circuitBreaker struct {
+	lock                sync.Mutex
+	threshold           int
+	cooldown            time.Duration
+	consecutiveFailures int
+	openUntil           time.Time
+	halfOpenInFlight    bool
+}
+
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.threshold == 0 || b.consecutiveFailures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.halfOpenInFlight {
+		return false
+	}
+	b.halfOpenInFlight = true
+	return true
+}
+
+func (b *circuitBreaker) onSuccess() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.consecutiveFailures = 0
+	b.halfOpenInFlight = false
+}
+
+func (b *circuitBreaker) onFailure(now time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.consecutiveFailures++
+	b.halfOpenInFlight = false
+	if b.threshold != 0 && b.consecutiveFailures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// release is called when a request that was let through ends without telling us anything about
+// the health of the core (for example if its context was cancelled).
+func (b *circuitBreaker) release() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.halfOpenInFlight = false
+}
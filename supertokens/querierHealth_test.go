@@ -0,0 +1,238 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBackoffIsCapped(t *testing.T) {
+	config := normaliseRetryConfig(&RetryConfig{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	})
+	for retry := 1; retry < 10; retry++ {
+		backoff := config.getBackoff(retry)
+		assert.GreaterOrEqual(t, int64(backoff), int64(0))
+		assert.LessOrEqual(t, int64(backoff), int64(50*time.Millisecond))
+	}
+}
+
+func TestPickHostSkipsEjectedHosts(t *testing.T) {
+	now := time.Now()
+	health := newHostHealth(3)
+	health.markUnhealthy(1, now.Add(time.Minute))
+
+	assert.Equal(t, 0, health.pickHost(now))
+	assert.Equal(t, 2, health.pickHost(now))
+	assert.Equal(t, 0, health.pickHost(now))
+
+	health.markUnhealthy(0, now.Add(2*time.Minute))
+	health.markUnhealthy(2, now.Add(3*time.Minute))
+	assert.Equal(t, 1, health.pickHost(now))
+
+	health.markHealthy(2)
+	assert.Equal(t, 2, health.pickHost(now))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := &circuitBreaker{threshold: 2, cooldown: time.Second}
+
+	breaker.onFailure(now)
+	assert.True(t, breaker.allow(now))
+	breaker.onFailure(now)
+	assert.False(t, breaker.allow(now))
+
+	later := now.Add(2 * time.Second)
+	assert.True(t, breaker.allow(later))
+	assert.False(t, breaker.allow(later))
+	breaker.onSuccess()
+	assert.True(t, breaker.allow(later))
+}
+
+func TestDecodeResponseReturnsUnexpectedCoreResponseError(t *testing.T) {
+	var response struct {
+		Status string `json:"status"`
+		Token  string `json:"token"`
+	}
+	err := decodeResponse("/recipe/user/email/verify/token", []byte(`{"status":"OK","token":1}`), &response)
+	_, ok := err.(UnexpectedCoreResponseError)
+	assert.True(t, ok)
+
+	err = decodeResponse("/recipe/user/email/verify/token", []byte(`{"status":"OK","token":"abc"}`), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", response.Token)
+}
+
+// newTestCoreServer serves the API version, and answers every other request with handler
+func newTestCoreServer(hits *int32, handler http.HandlerFunc) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			w.Write([]byte(`{"versions":["2.9"]}`))
+			return
+		}
+		atomic.AddInt32(hits, 1)
+		handler(w, r)
+	}))
+}
+
+func newTestQuerier(t *testing.T, timeout time.Duration, servers ...*httptest.Server) *Querier {
+	var hosts []NormalisedURLDomain
+	for _, server := range servers {
+		host, err := NewNormalisedURLDomain(server.URL)
+		assert.NoError(t, err)
+		hosts = append(hosts, host)
+	}
+	maxRetries := 2
+	return &Querier{core: newCoreConnection(hosts, "", &RetryConfig{
+		MaxRetries:     &maxRetries,
+		InitialBackoff: time.Millisecond,
+	}, &http.Client{Timeout: timeout})}
+}
+
+func TestQuerierRetriesGetRequestsOn5xx(t *testing.T) {
+	var hits int32
+	server := newTestCoreServer(&hits, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&hits) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+	defer server.Close()
+
+	var response struct {
+		Status string `json:"status"`
+	}
+	err := newTestQuerier(t, time.Second, server).SendGetRequestAndDecode(context.Background(), "/recipe/user", nil, &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", response.Status)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+}
+
+func TestQuerierRetriesGetRequestsOnTimeout(t *testing.T) {
+	var hits int32
+	server := newTestCoreServer(&hits, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&hits) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+	defer server.Close()
+
+	_, err := newTestQuerier(t, 50*time.Millisecond, server).SendGetRequestWithContext(context.Background(), "/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestQuerierDoesNotRetryPostRequests(t *testing.T) {
+	var hits int32
+	server := newTestCoreServer(&hits, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	_, err := newTestQuerier(t, time.Second, server).SendPostRequestWithContext(context.Background(), "/recipe/signup", nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestQuerierFailsOverToAnotherHost(t *testing.T) {
+	var failingHits, healthyHits int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&failingHits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	healthy := newTestCoreServer(&healthyHits, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+	defer healthy.Close()
+
+	querier := newTestQuerier(t, time.Second, failing, healthy)
+	for i := 0; i < 3; i++ {
+		_, err := querier.SendGetRequestWithContext(context.Background(), "/recipe/user", nil)
+		assert.NoError(t, err)
+	}
+	// the failing host is skipped once it has been ejected
+	assert.Equal(t, int32(1), atomic.LoadInt32(&failingHits))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&healthyHits))
+}
+
+func TestQuerierAPIVersionIsFetchedWithoutBlockingOtherRequests(t *testing.T) {
+	release := make(chan struct{})
+	var versionRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&versionRequests, 1)
+		<-release
+		w.Write([]byte(`{"versions":["2.9"]}`))
+	}))
+	defer server.Close()
+	querier := newTestQuerier(t, time.Second, server)
+
+	go querier.getQuerierAPIVersion(context.Background())
+	time.Sleep(20 * time.Millisecond)
+
+	// a request waiting for the version gives up with its own context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := querier.getQuerierAPIVersion(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	close(release)
+	version, err := querier.getQuerierAPIVersion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "2.9", version)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&versionRequests))
+}
+
+func TestQuerierUsesTheLargestCommonAPIVersion(t *testing.T) {
+	var versions, cdiVersion atomic.Value
+	versions.Store(`{"versions":["2.8","2.9"]}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			w.Write([]byte(versions.Load().(string)))
+			return
+		}
+		cdiVersion.Store(r.Header.Get("cdi-version"))
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer server.Close()
+
+	// an older core is still used with CDI 2.9, but the APIs that need a newer one fail clearly
+	querier := newTestQuerier(t, time.Second, server)
+	_, err := querier.SendGetRequest("/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.9", cdiVersion.Load())
+	assert.NoError(t, querier.CheckAPIVersion(context.Background(), "2.9"))
+	assert.Error(t, querier.CheckAPIVersion(context.Background(), "2.11"))
+
+	// versions are compared by number, so 2.11 is picked over 2.9
+	versions.Store(`{"versions":["2.9","2.11","3.0"]}`)
+	querier = newTestQuerier(t, time.Second, server)
+	_, err = querier.SendGetRequest("/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.11", cdiVersion.Load())
+	assert.NoError(t, querier.CheckAPIVersion(context.Background(), "2.11"))
+}
@@ -17,6 +17,7 @@ package supertokens
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -25,32 +26,54 @@ import (
 	"strings"
 )
 
-type superTokens struct {
-	AppInfo        NormalisedAppinfo
-	RecipeModules  []RecipeModule
-	OnGeneralError func(err error, req *http.Request, res http.ResponseWriter)
+// SuperTokens is one independently configured SuperTokens app: its AppInfo, recipes and connection
+// to the core. Most applications only need the default instance created by Init.
+type SuperTokens struct {
+	AppInfo         NormalisedAppinfo
+	RecipeModules   []RecipeModule
+	OnGeneralError  func(err error, req *http.Request, res http.ResponseWriter)
+	core            *coreConnection
+	httpClient      *http.Client
+	recipeInstances map[string]interface{}
 }
 
+type instanceContextKey int
+
+const superTokensInstanceContext instanceContextKey = iota
+
 // this will be set to true if this is used in a test app environment
 var IsTestFlag = false
 
-var superTokensInstance *superTokens
+var superTokensInstance *SuperTokens
 
 func supertokensInit(config TypeInput) error {
 	if superTokensInstance != nil {
 		return nil
 	}
-	superTokens := &superTokens{}
+	superTokens, err := newSuperTokens(config)
+	if err != nil {
+		return err
+	}
+	superTokensInstance = superTokens
+	return nil
+}
+
+func newSuperTokens(config TypeInput) (*SuperTokens, error) {
+	superTokens := &SuperTokens{
+		recipeInstances: map[string]interface{}{},
+	}
 
 	superTokens.OnGeneralError = defaultOnGeneralError
 	if config.OnGeneralError != nil {
 		superTokens.OnGeneralError = config.OnGeneralError
 	}
 
+	superTokens.httpClient = newHTTPClient(config.Supertokens)
+
 	var err error
 	superTokens.AppInfo, err = NormaliseInputAppInfoOrThrowError(config.AppInfo)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if config.Supertokens != nil {
@@ -59,72 +82,112 @@ func supertokensInit(config TypeInput) error {
 		for _, h := range hostList {
 			host, err := NewNormalisedURLDomain(h)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			hosts = append(hosts, host)
 		}
-		initQuerier(hosts, config.Supertokens.APIKey)
+		superTokens.core = newCoreConnection(hosts, config.Supertokens.APIKey, config.Supertokens.Retry, superTokens.httpClient)
 	} else {
 		// TODO: Add tests for init without supertokens core.
 	}
 
 	if config.RecipeList == nil || len(config.RecipeList) == 0 {
-		return errors.New("please provide at least one recipe to the supertokens.init function call")
+		return nil, errors.New("please provide at least one recipe to the supertokens.init function call")
 	}
 
 	for _, elem := range config.RecipeList {
-		recipeModule, err := elem(superTokens.AppInfo, superTokens.OnGeneralError)
+		recipeModule, err := elem(superTokens)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		superTokens.RecipeModules = append(superTokens.RecipeModules, *recipeModule)
 	}
 
-	superTokensInstance = superTokens
-
 	if config.Telemetry == nil || *config.Telemetry {
-		sendTelemetry()
+		superTokens.sendTelemetry()
 	}
 
-	return nil
+	return superTokens, nil
 }
 
 func defaultOnGeneralError(err error, req *http.Request, res http.ResponseWriter) {
 	http.Error(res, err.Error(), 500)
 }
 
-func getInstanceOrThrowError() (*superTokens, error) {
+func getInstanceOrThrowError() (*SuperTokens, error) {
 	if superTokensInstance != nil {
 		return superTokensInstance, nil
 	}
 	return nil, errors.New("initialisation not done. Did you forget to call the SuperTokens.init function?")
 }
 
-func sendTelemetry() {
+// GetInstanceOrThrowError returns the instance whose Middleware is handling the request the context
+// belongs to, falling back to the default instance created by Init.
+func GetInstanceOrThrowError(ctx context.Context) (*SuperTokens, error) {
+	if ctx != nil {
+		if instance, ok := ctx.Value(superTokensInstanceContext).(*SuperTokens); ok {
+			return instance, nil
+		}
+	}
+	return getInstanceOrThrowError()
+}
+
+// WithInstance returns a copy of ctx that makes the recipe functions called with it use the given instance.
+// The Middleware of an instance does this for every request it handles.
+func WithInstance(ctx context.Context, instance *SuperTokens) context.Context {
+	return context.WithValue(ctx, superTokensInstanceContext, instance)
+}
+
+func (s *SuperTokens) GetNewQuerierInstanceOrThrowError(rIDToCore string) (*Querier, error) {
+	if s.core == nil {
+		return nil, errors.New("please call the supertokens.init function before using SuperTokens")
+	}
+	return &Querier{RIDToCore: rIDToCore, core: s.core}, nil
+}
+
+func (s *SuperTokens) GetHTTPClient() *http.Client {
+	return s.httpClient
+}
+
+// AddRecipeInstance is called by recipes while the instance is being created so that the recipe level
+// functions can find them later on.
+func (s *SuperTokens) AddRecipeInstance(recipeID string, recipe interface{}) {
+	s.recipeInstances[recipeID] = recipe
+}
+
+// GetRecipeInstance returns the object added by the recipe with AddRecipeInstance, or nil.
+func (s *SuperTokens) GetRecipeInstance(recipeID string) interface{} {
+	return s.recipeInstances[recipeID]
+}
+
+func (s *SuperTokens) sendTelemetry() {
 	if IsRunningInTestMode() {
 		// if running in test mode, we do not want to send this.
 		return
 	}
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	querier, err := s.GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
 		return
 	}
 
-	response, err := querier.SendGetRequest("/telemetry", nil)
+	var response struct {
+		Exists      bool   `json:"exists"`
+		TelemetryID string `json:"telemetryId"`
+	}
+	err = querier.SendGetRequestAndDecode(context.Background(), "/telemetry", nil, &response)
 	if err != nil {
 		return
 	}
-	exists := response["exists"].(bool)
 
 	url := "https://api.supertokens.io/0/st/telemetry"
 
 	data := map[string]interface{}{
-		"appName":       superTokensInstance.AppInfo.AppName,
-		"websiteDomain": superTokensInstance.AppInfo.WebsiteDomain.GetAsStringDangerous(),
+		"appName":       s.AppInfo.AppName,
+		"websiteDomain": s.AppInfo.WebsiteDomain.GetAsStringDangerous(),
 		"sdk":           "golang",
 	}
-	if exists {
-		data["telemetryId"] = response["telemetryId"].(string)
+	if response.Exists {
+		data["telemetryId"] = response.TelemetryID
 	}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -137,15 +200,15 @@ func sendTelemetry() {
 	req.Header.Set("content-type", "application/json; charset=utf-8")
 	req.Header.Set("api-version", "2")
 
-	client := &http.Client{}
-	client.Do(req)
+	s.httpClient.Do(req)
 }
 
-func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
+func (s *SuperTokens) middleware(theirHandler http.Handler) http.Handler {
 	if theirHandler == nil {
 		theirHandler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(WithInstance(r.Context(), s))
 		reqURL, err := NewNormalisedURLPath(r.URL.Path)
 		if err != nil {
 			err = s.errorHandler(err, r, w)
@@ -223,7 +286,7 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 	})
 }
 
-func (s *superTokens) getAllCORSHeaders() []string {
+func (s *SuperTokens) getAllCORSHeaders() []string {
 	headerMap := map[string]bool{HeaderRID: true, HeaderFDI: true}
 	for _, recipe := range s.RecipeModules {
 		headers := recipe.GetAllCORSHeaders()
@@ -238,7 +301,7 @@ func (s *superTokens) getAllCORSHeaders() []string {
 	return headers
 }
 
-func (s *superTokens) errorHandler(originalError error, req *http.Request, res http.ResponseWriter) error {
+func (s *SuperTokens) errorHandler(originalError error, req *http.Request, res http.ResponseWriter) error {
 	if errors.As(originalError, &BadInputError{}) {
 		if catcher := SendNon200Response(res, originalError.Error(), 400); catcher != nil {
 			s.OnGeneralError(originalError, req, res)
@@ -268,9 +331,9 @@ type UserPaginationResult struct {
 }
 
 // TODO: Add tests
-func getUsers(timeJoinedOrder string, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+func (s *SuperTokens) getUsers(ctx context.Context, timeJoinedOrder string, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
 
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	querier, err := s.GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
 		return UserPaginationResult{}, err
 	}
@@ -288,21 +351,8 @@ func getUsers(timeJoinedOrder string, paginationToken *string, limit *int, inclu
 		requestBody["includeRecipeIds"] = strings.Join((*includeRecipeIds)[:], ",")
 	}
 
-	resp, err := querier.SendGetRequest("/users", requestBody)
-
-	if err != nil {
-		return UserPaginationResult{}, err
-	}
-
-	temporaryVariable, err := json.Marshal(resp)
-	if err != nil {
-		return UserPaginationResult{}, err
-	}
-
 	var result = UserPaginationResult{}
-
-	err = json.Unmarshal(temporaryVariable, &result)
-
+	err = querier.SendGetRequestAndDecode(ctx, "/users", requestBody, &result)
 	if err != nil {
 		return UserPaginationResult{}, err
 	}
@@ -311,9 +361,9 @@ func getUsers(timeJoinedOrder string, paginationToken *string, limit *int, inclu
 }
 
 // TODO: Add tests
-func getUserCount(includeRecipeIds *[]string) (float64, error) {
+func (s *SuperTokens) getUserCount(ctx context.Context, includeRecipeIds *[]string) (float64, error) {
 
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	querier, err := s.GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
 		return -1, err
 	}
@@ -324,17 +374,18 @@ func getUserCount(includeRecipeIds *[]string) (float64, error) {
 		requestBody["includeRecipeIds"] = strings.Join((*includeRecipeIds)[:], ",")
 	}
 
-	resp, err := querier.SendGetRequest("/users/count", requestBody)
-
+	var response struct {
+		Count float64 `json:"count"`
+	}
+	err = querier.SendGetRequestAndDecode(ctx, "/users/count", requestBody, &response)
 	if err != nil {
 		return -1, err
 	}
 
-	return resp["count"].(float64), nil
+	return response.Count, nil
 }
 
 func ResetForTest() {
-	ResetQuerierForTest()
 	superTokensInstance = nil
 }
 
@@ -0,0 +1,60 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestInstance(t *testing.T, appName string) *SuperTokens {
+	falseValue := false
+	instance, err := New(TypeInput{
+		AppInfo: AppInfo{
+			AppName:       appName,
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		Supertokens: &ConnectionInfo{
+			ConnectionURI: "http://localhost:3567",
+		},
+		RecipeList: []Recipe{func(instance *SuperTokens) (*RecipeModule, error) {
+			instance.AddRecipeInstance("test", appName)
+			return &RecipeModule{recipeID: "test", appInfo: instance.AppInfo}, nil
+		}},
+		Telemetry: &falseValue,
+	})
+	assert.NoError(t, err)
+	return instance
+}
+
+func TestInstancesAreIndependent(t *testing.T) {
+	first := makeTestInstance(t, "first")
+	second := makeTestInstance(t, "second")
+
+	assert.Equal(t, "first", first.GetRecipeInstance("test"))
+	assert.Equal(t, "second", second.GetRecipeInstance("test"))
+	assert.NotSame(t, first.core, second.core)
+
+	instance, err := GetInstanceOrThrowError(WithInstance(context.Background(), second))
+	assert.NoError(t, err)
+	assert.Same(t, second, instance)
+
+	_, err = GetInstanceOrThrowError(context.Background())
+	assert.Error(t, err)
+}
@@ -248,7 +248,7 @@ func login(response http.ResponseWriter, request *http.Request) {
 	var body map[string]interface{}
 	_ = json.NewDecoder(request.Body).Decode(&body)
 	userID := body["userId"].(string)
-	session.CreateNewSession(response, userID, nil, nil)
+	session.CreateNewSession(request, response, userID, nil, nil)
 	response.Write([]byte(userID))
 }
 